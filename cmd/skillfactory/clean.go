@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// newCleanCmd creates the clean command
+func newCleanCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove old archived builds and deployment backups",
+		Long: `Remove archived builds and deployment backups beyond the retention
+policy of ~/.config/skillfactory/config.yaml. Deploys keep the newest keep_builds
+builds of each skill in dist/builds and back up a replaced deployment to
+~/.skillfactory/backups for keep_backups_days days; both are pruned after
+every deploy. Run clean after lowering the limits.
+
+Examples:
+  skillfactory clean --dry-run
+  skillfactory config set keep_builds 3 && skillfactory clean`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			settings, err := config.LoadSettings()
+			if err != nil {
+				return err
+			}
+			removed, err := pipeline.Clean(tui.GetProjectRoot(), pipeline.RetentionFrom(settings), dryRun)
+			verb := "Removed"
+			if dryRun {
+				verb = "Would remove"
+			}
+			for _, path := range removed {
+				fmt.Printf("%s %s\n", verb, path)
+			}
+			if err != nil {
+				return err
+			}
+			if len(removed) == 0 {
+				fmt.Println("Nothing to clean")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List what would be removed without removing it")
+	return cmd
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// secretMask replaces secret profile values in config list
+const secretMask = "********"
+
+// newConfigCmd creates the config command group
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read and change the SkillFactory configuration",
+		Long: `Read and change the global config file and the saved skill profiles
+without opening the TUI.
+
+Keys are the settings of ~/.config/skillfactory/config.yaml
+(skills_folder, parallel_builds, theme) or fields of a profile:
+
+  profiles.<skill>.<profile>.values.<VARIABLE>
+  profiles.<skill>.<profile>.skills_folder
+  profiles.<skill>.<profile>.skill_folder_name
+
+Examples:
+  skillfactory config list
+  skillfactory config get theme
+  skillfactory config set parallel_builds 4
+  skillfactory config set profiles.vikunja.work.values.VIKUNJA_URL https://tasks.example.com/api/v1
+  skillfactory config unset theme`,
+	}
+	cmd.AddCommand(
+		newConfigGetCmd(),
+		newConfigListCmd(),
+		newConfigMigrateCmd(),
+		newConfigSetCmd(),
+		newConfigUnsetCmd(),
+	)
+	return cmd
+}
+
+// newConfigGetCmd creates the config get command
+func newConfigGetCmd() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of a key",
+		Long: `Print the effective value of a key, including overrides from
+SKILLFACTORY_* environment variables. --json adds where the value
+comes from (default, config.yaml, env or profiles).
+
+Examples:
+  skillfactory config get skills_folder
+  skillfactory config get theme --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entry, err := config.Get(args[0])
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return printConfigJSON(entry)
+			}
+			fmt.Println(entry.Value)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print key, value and source as JSON")
+	return cmd
+}
+
+// newConfigSetCmd creates the config set command
+func newConfigSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting or profile field",
+		Long: `Change a setting in the global config file or a field of a skill
+profile. Settings are validated before the file is written; comments
+in config.yaml are kept. A missing profile is created.
+
+Examples:
+  skillfactory config set theme mono
+  skillfactory config set skills_folder ~/.claude/skills
+  skillfactory config set profiles.habitwire.default.values.HABITWIRE_URL https://habits.example.com`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return config.Set(args[0], args[1])
+		},
+	}
+}
+
+// newConfigUnsetCmd creates the config unset command
+func newConfigUnsetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a setting or profile field",
+		Long: `Remove a setting from the global config file, so its default applies
+again, or a field of a skill profile. A profile left empty is deleted.
+
+Examples:
+  skillfactory config unset parallel_builds
+  skillfactory config unset profiles.vikunja.work.values.VIKUNJA_GZIP_REQUESTS`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return config.Unset(args[0])
+		},
+	}
+}
+
+// newConfigListCmd creates the config list command
+func newConfigListCmd() *cobra.Command {
+	var asJSON bool
+	var showSecrets bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all settings and profile fields",
+		Long: `List the effective settings with their source, followed by the fields
+of all saved profiles. Values of type: secret variables are masked
+unless --show-secrets is given.
+
+Examples:
+  skillfactory config list
+  skillfactory config list --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := config.List()
+			if err != nil {
+				return err
+			}
+			if !showSecrets {
+				maskSecrets(entries)
+			}
+			if asJSON {
+				if entries == nil {
+					entries = []config.Entry{}
+				}
+				return printConfigJSON(entries)
+			}
+
+			for _, e := range entries {
+				fmt.Printf("%-48s %-24s %s\n", e.Key, orDash(e.Value), e.Source)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the entries as JSON")
+	cmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "Print secret profile values in plain text")
+	return cmd
+}
+
+// maskSecrets replaces the profile values of type: secret variables
+func maskSecrets(entries []config.Entry) {
+	manifests, _, err := skill.DiscoverSkills(tui.GetProjectRoot())
+	if err != nil {
+		return
+	}
+	secrets := make(map[string]bool)
+	for _, m := range manifests {
+		for _, v := range m.Variables {
+			if v.Type == "secret" {
+				secrets[m.Name+"."+v.Name] = true
+			}
+		}
+	}
+
+	for i, e := range entries {
+		rest, ok := strings.CutPrefix(e.Key, "profiles.")
+		if !ok || e.Value == "" {
+			continue
+		}
+		// <skill>.<profile>.values.<VARIABLE>
+		parts := strings.SplitN(rest, ".", 4)
+		if len(parts) == 4 && parts[2] == "values" && secrets[parts[0]+"."+parts[3]] {
+			entries[i].Value = secretMask
+		}
+	}
+}
+
+// printConfigJSON prints v as indented JSON
+func printConfigJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// newConfigMigrateCmd creates the config migrate command
+func newConfigMigrateCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade config.json and profiles to the current format",
+		Long: `Upgrade ~/.skillfactory/config.json and the per-skill profiles in
+~/.skillfactory/profiles to the format of this SkillFactory version.
+Each changed file is copied to <file>.v<old version>.bak first.
+
+SkillFactory migrates files automatically when it reads them; run this
+command to upgrade all files at once or to preview the changes with
+--dry-run. Files written by a newer SkillFactory are never downgraded.
+
+Examples:
+  skillfactory config migrate --dry-run
+  skillfactory config migrate`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			migrations, err := config.Migrate(dryRun)
+			if err != nil {
+				return err
+			}
+			if len(migrations) == 0 {
+				fmt.Println("Config is up to date")
+				return nil
+			}
+
+			for _, m := range migrations {
+				fmt.Printf("%s: version %d -> %d\n", m.Path, m.From, m.To)
+				for _, step := range m.Steps {
+					fmt.Printf("  - %s\n", step)
+				}
+				if m.Backup != "" {
+					fmt.Printf("  backup: %s\n", m.Backup)
+				}
+			}
+			if dryRun {
+				fmt.Printf("\n%d file(s) would be migrated (dry run)\n", len(migrations))
+			} else {
+				fmt.Printf("\n%d file(s) migrated\n", len(migrations))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the migrations without changing files")
+	return cmd
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/internal/stats"
+	"github.com/petervogelmann/skillfactory/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// newDeployCmd creates the deploy command
+func newDeployCmd() *cobra.Command {
+	var profileName string
+	var skillsFolder string
+	var folderName string
+	var runTests bool
+	var docsMode string
+
+	cmd := &cobra.Command{
+		Use:   "deploy [skill]",
+		Short: "Build and deploy a skill using a saved profile",
+		Long: `Build and deploy a skill without the TUI.
+
+Variable values and the deploy target are taken from a profile saved
+by the TUI (~/.skillfactory/profiles/<skill>.json).
+
+Tests run before the build when build.test is set in skill.yaml, when
+test_before_deploy is set in the global config file, or with --test.
+Failing tests refuse the deploy.
+
+A deployed SKILL.md edited by hand since the last deploy is not
+overwritten silently: choose with --docs whether to overwrite, keep or
+merge the edits (three-way merge with git merge-file).
+
+Examples:
+  skillfactory deploy vikunja --profile work
+  skillfactory deploy vikunja --profile personal --folder-name vikunja-personal
+  skillfactory deploy vikunja --test
+  skillfactory deploy vikunja --docs merge`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manifest, err := skill.FindSkill(tui.GetProjectRoot(), args[0])
+			if err != nil {
+				return err
+			}
+			return deployProfile(os.Stdout, os.Stderr, manifest, deployRequest{
+				Profile:      profileName,
+				SkillsFolder: skillsFolder,
+				FolderName:   folderName,
+				Test:         runTests,
+				Docs:         docsMode,
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&profileName, "profile", "p", config.DefaultProfile, "Profile to deploy")
+	cmd.Flags().StringVar(&skillsFolder, "skills-folder", "", "Skills folder (default: from profile)")
+	cmd.Flags().StringVar(&folderName, "folder-name", "", "Skill folder name (default: from profile)")
+	cmd.Flags().BoolVar(&runTests, "test", false, "Run go test ./... before building")
+	cmd.Flags().StringVar(&docsMode, "docs", "", "Hand-edited SKILL.md: overwrite, keep or merge")
+	return cmd
+}
+
+// deployRequest selects the profile of a deploy and overrides its target,
+// from the flags of the deploy command or a request of the web UI
+type deployRequest struct {
+	Profile      string
+	SkillsFolder string // Default: from the profile or the saved config
+	FolderName   string // Default: from the profile or the skill name
+	Test         bool   // Run go test ./... before building
+	Docs         string // Handling of a hand-edited SKILL.md, see pipeline.DocsModes
+}
+
+// deployProfile deploys a skill with the variable values and target of a
+// saved profile, writing the progress to out and warnings to errOut
+func deployProfile(out, errOut io.Writer, manifest *skill.Manifest, req deployRequest) error {
+	profile, err := config.LoadProfile(manifest.Name, req.Profile)
+	if err != nil {
+		return err
+	}
+	values, err := pipeline.ProfileValues(profile)
+	if err != nil {
+		return err
+	}
+	for _, v := range manifest.Variables {
+		if v.Required && !v.FromCommand() && values[v.Name] == "" {
+			return fmt.Errorf("profile %q has no value for required variable %s", req.Profile, v.Name)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	skillsFolder := config.ExpandPath(firstNonEmpty(req.SkillsFolder, profile.SkillsFolder, cfg.SkillsFolder))
+	if skillsFolder == "" {
+		return fmt.Errorf("no skills folder configured (use --skills-folder)")
+	}
+	folderName := firstNonEmpty(req.FolderName, profile.SkillFolderName, manifest.Name)
+	deployPath := filepath.Join(skillsFolder, folderName)
+
+	if req.Docs != "" && !slices.Contains(pipeline.DocsModes, req.Docs) {
+		return fmt.Errorf("invalid --docs %q (expected %s)", req.Docs, strings.Join(pipeline.DocsModes, ", "))
+	}
+	if lock, err := pipeline.ReadLock(deployPath); err == nil && pipeline.DocsEdited(deployPath, lock) && req.Docs == "" {
+		return fmt.Errorf("%s was edited since the last deploy, use --docs overwrite, keep or merge", filepath.Join(deployPath, "SKILL.md"))
+	}
+
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+	runTests := pipeline.TestsEnabled(manifest, req.Test || settings.TestBeforeDeploy)
+	return deploySkill(out, errOut, manifest, runTests, pipeline.RetentionFrom(settings), pipeline.DeployOptions{
+		Manifest:    manifest,
+		DeployPath:  deployPath,
+		Values:      values,
+		UseKeychain: cfg.UseKeychain(),
+		EncryptEnv:  cfg.EncryptEnv,
+		DocsMode:    req.Docs,
+		Backup:      settings.KeepBackupsDays > 0,
+	})
+}
+
+// deploySkill builds a skill into a temporary directory and deploys it.
+// With runTests the skill's tests must pass before it is built. The build is
+// archived and old builds and backups pruned by retention.
+func deploySkill(out, errOut io.Writer, manifest *skill.Manifest, runTests bool, retention pipeline.Retention, opts pipeline.DeployOptions) (err error) {
+	tmpDir, err := os.MkdirTemp("", "skillfactory-deploy-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	opts.BinaryPath = filepath.Join(tmpDir, manifest.BinaryName())
+
+	if err := pipeline.PreflightDeploy(manifest, opts.DeployPath); err != nil {
+		return err
+	}
+
+	if warning := pipeline.PlatformMismatch(manifest, opts.DeployPath); warning != "" {
+		fmt.Fprintf(errOut, "Warning: %s\n", warning)
+	}
+
+	if git := pipeline.GitStatus(manifest.Path); git.Dirty {
+		fmt.Fprintf(errOut, "Warning: %s has uncommitted changes (%s)\n", manifest.Path, git)
+	}
+
+	if runTests {
+		fmt.Fprintf(out, "Testing %s...\n", manifest.Name)
+		start := time.Now()
+		output, err := pipeline.RunTests(manifest)
+		if err != nil {
+			fmt.Fprint(errOut, output)
+			pipeline.RecordEvent(pipeline.NewEvent(pipeline.ActionBuild, manifest, opts.DeployPath, time.Since(start), output, err))
+			return fmt.Errorf("%w, deploy refused", err)
+		}
+	}
+
+	hookEnv := pipeline.HookEnv{BinaryPath: opts.BinaryPath}
+	if err := runHook(out, manifest, pipeline.HookPreBuild, hookEnv); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Building %s...\n", manifest.Name)
+	start := time.Now()
+	output, err := pipeline.Build(manifest, opts.BinaryPath)
+	duration := time.Since(start)
+	pipeline.RecordEvent(pipeline.NewEvent(pipeline.ActionBuild, manifest, opts.DeployPath, duration, output, err))
+	if err != nil {
+		fmt.Fprint(errOut, output)
+		printDiagnoses(errOut, manifest, output)
+		return err
+	}
+
+	// Record the outcome of the deployment once the build succeeded
+	deployStart := time.Now()
+	defer func() {
+		pipeline.RecordEvent(pipeline.NewEvent(pipeline.ActionDeploy, manifest, opts.DeployPath, time.Since(deployStart), "", err))
+	}()
+
+	stats.RecordBuild(manifest.Name, duration)
+	records, err := pipeline.RecordBuildDuration(manifest.Name, pipeline.BuildRecord{
+		At:         time.Now().UTC(),
+		DurationMS: duration.Milliseconds(),
+		GoVersion:  pipeline.GoVersion(manifest.Path),
+	})
+	if err == nil {
+		fmt.Fprintf(out, "Build: %s\n", pipeline.BuildTrend(records))
+	}
+
+	if err := runHook(out, manifest, pipeline.HookPostBuild, hookEnv); err != nil {
+		return err
+	}
+
+	// Warn before replacing commands Claude may rely on
+	if diff, err := pipeline.DiffDeployed(manifest, opts.BinaryPath, opts.DeployPath); err == nil && len(diff.Removed) > 0 {
+		fmt.Fprintf(errOut, "Warning: the new build removes commands of the deployed skill: %s\n", strings.Join(diff.Removed, ", "))
+	}
+
+	if pipeline.VulncheckEnabled(manifest) {
+		stats.Record(stats.FeatureVulncheck)
+		result, err := pipeline.Vulncheck(manifest)
+		if err != nil {
+			return fmt.Errorf("vulnerability scan failed: %w", err)
+		}
+		switch {
+		case !result.Ran:
+			fmt.Fprintln(out, "Vulnerability scan skipped: "+result.Output)
+		case result.Vulnerable:
+			fmt.Fprintf(out, "Known vulnerabilities: %s\n", strings.Join(result.IDs, ", "))
+			if manifest.Build.Vulncheck == pipeline.VulncheckBlock {
+				return fmt.Errorf("deploy blocked: known vulnerabilities found")
+			}
+		default:
+			fmt.Fprintln(out, "Vulnerability scan: no known vulnerabilities")
+		}
+	}
+
+	fmt.Fprintf(out, "Deploying %s to %s...\n", manifest.Name, opts.DeployPath)
+	opts.Progress = printStep(out)
+	if _, err := pipeline.Deploy(opts); err != nil {
+		return err
+	}
+	if len(manifest.Hooks.PostDeploy) > 0 {
+		hookEnv = pipeline.HookEnv{
+			BinaryPath: filepath.Join(opts.DeployPath, "bin", manifest.BinaryName()),
+			DeployPath: opts.DeployPath,
+		}
+		var output string
+		err := pipeline.RunStep(opts.Progress, pipeline.StepHooks, func() error {
+			var err error
+			output, err = pipeline.RunHook(manifest, pipeline.HookPostDeploy, hookEnv)
+			return err
+		})
+		fmt.Fprint(out, output)
+		if err != nil {
+			return err
+		}
+	}
+
+	// A failed healthcheck is reported, the deploy itself succeeded
+	if manifest.Healthcheck != "" {
+		var result pipeline.HealthcheckResult
+		pipeline.RunStep(opts.Progress, pipeline.StepHealthcheck, func() error {
+			result = pipeline.Healthcheck(manifest, opts.DeployPath)
+			return result.Err
+		})
+		if result.Err != nil {
+			fmt.Fprintf(errOut, "Warning: healthcheck %q failed: %v\n", result.Command, result.Err)
+		}
+	}
+
+	// Archiving and pruning are best effort, the deploy succeeded
+	projectRoot := tui.GetProjectRoot()
+	if err := pipeline.ArchiveBuild(projectRoot, manifest, opts.BinaryPath, retention); err != nil {
+		fmt.Fprintf(errOut, "Warning: %v\n", err)
+	}
+	if _, err := pipeline.Clean(projectRoot, retention, false); err != nil {
+		fmt.Fprintf(errOut, "Warning: cleanup failed: %v\n", err)
+	}
+	stats.Record(stats.FeatureDeploy)
+	fmt.Fprintf(out, "Deployed %s to %s\n", manifest.Name, opts.DeployPath)
+	return nil
+}
+
+// printStep returns a deploy progress callback printing the outcome of each
+// step, e.g. "  ✓ Write .env (2ms)"
+func printStep(w io.Writer) func(pipeline.DeployStep) {
+	return func(step pipeline.DeployStep) {
+		switch step.State {
+		case pipeline.StepDone:
+			fmt.Fprintf(w, "  ✓ %s (%s)\n", step.Name, pipeline.FormatDuration(step.Duration))
+		case pipeline.StepFailed:
+			fmt.Fprintf(w, "  ✗ %s (%s): %v\n", step.Name, pipeline.FormatDuration(step.Duration), step.Err)
+		}
+	}
+}
+
+// printDiagnoses prints the recognized causes of a failed build with their remedies
+func printDiagnoses(w io.Writer, manifest *skill.Manifest, output string) {
+	for _, d := range pipeline.DiagnoseBuild(output, manifest) {
+		fmt.Fprintf(w, "\nHint: %s\n  %s\n", d.Cause, d.Advice)
+		if fix := d.FixCommand(); fix != "" {
+			fmt.Fprintf(w, "  Fix: cd %s && %s\n", manifest.Path, fix)
+		}
+	}
+}
+
+// runHook runs a skill.yaml hook and prints its output
+func runHook(w io.Writer, manifest *skill.Manifest, hook string, env pipeline.HookEnv) error {
+	output, err := pipeline.RunHook(manifest, hook, env)
+	fmt.Fprint(w, output)
+	return err
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
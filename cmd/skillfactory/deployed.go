@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// newDeployedCmd creates the deployed command
+func newDeployedCmd() *cobra.Command {
+	var skillsFolder string
+
+	cmd := &cobra.Command{
+		Use:   "deployed",
+		Short: "List the skills deployed in a skills folder",
+		Long: `List every skill deployed in the skills folder with its version, binary
+size and deploy date, read from deploy.lock and SKILL.md. The SOURCE
+column shows whether the skill still exists in this repository.
+
+Examples:
+  skillfactory deployed
+  skillfactory deployed --skills-folder ~/.claude/skills`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if skillsFolder == "" {
+				cfg, err := config.Load()
+				if err != nil {
+					return err
+				}
+				skillsFolder = cfg.SkillsFolder
+			}
+			skillsFolder = config.ExpandPath(skillsFolder)
+			if skillsFolder == "" {
+				return fmt.Errorf("no skills folder configured (use --skills-folder)")
+			}
+
+			manifests, _, err := skill.DiscoverSkills(tui.GetProjectRoot())
+			if err != nil {
+				return err
+			}
+			deployed, err := pipeline.ScanDeployed(skillsFolder, manifests)
+			if err != nil {
+				return err
+			}
+			if len(deployed) == 0 {
+				fmt.Printf("No skills deployed in %s\n", skillsFolder)
+				return nil
+			}
+
+			fmt.Printf("%-20s %-16s %-10s %-9s %-17s %s\n", "FOLDER", "SKILL", "VERSION", "SIZE", "DEPLOYED", "SOURCE")
+			for _, d := range deployed {
+				size, deployedAt, source := "-", "-", "missing"
+				if d.Binary != "" {
+					size = pipeline.FormatSize(d.BinarySize)
+				}
+				if !d.DeployedAt.IsZero() {
+					deployedAt = d.DeployedAt.Local().Format("2006-01-02 15:04")
+				}
+				if d.SourceExists {
+					source = "ok"
+				}
+				fmt.Printf("%-20s %-16s %-10s %-9s %-17s %s\n", d.Folder, d.Name, orDash(d.Version), size, deployedAt, source)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&skillsFolder, "skills-folder", "", "Skills folder to scan (default: saved from TUI)")
+	return cmd
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/internal/stats"
+	"github.com/petervogelmann/skillfactory/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// newDepsCmd creates the deps command
+func newDepsCmd() *cobra.Command {
+	var tidy bool
+	var update string
+	var verify bool
+
+	cmd := &cobra.Command{
+		Use:   "deps [skill]",
+		Short: "Tidy and update a skill's Go dependencies",
+		Long: `Tidy and update the Go dependencies of a skill module.
+
+Shows which dependency versions changed and optionally rebuilds and
+tests the skill to confirm the update.
+
+Examples:
+  skillfactory deps habitwire --tidy
+  skillfactory deps habitwire --update minor --verify
+  skillfactory deps vikunja --update patch --tidy`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !tidy && update == "" {
+				return fmt.Errorf("nothing to do: use --tidy and/or --update patch|minor")
+			}
+
+			manifest, err := skill.FindSkill(tui.GetProjectRoot(), args[0])
+			if err != nil {
+				return err
+			}
+			stats.Record(stats.FeatureDeps)
+
+			gomod, err := pipeline.ModuleFile(manifest.Path)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Module: %s\n", gomod)
+			if filepath.Dir(gomod) != filepath.Clean(manifest.Path) {
+				fmt.Println("Note: skill has no go.mod of its own, updating the enclosing module")
+			}
+
+			before, err := pipeline.ListModules(manifest.Path)
+			if err != nil {
+				return err
+			}
+
+			if update != "" {
+				if output, err := pipeline.UpdateDeps(manifest.Path, update); err != nil {
+					fmt.Fprint(os.Stderr, output)
+					return err
+				}
+			}
+			if tidy {
+				if output, err := pipeline.Tidy(manifest.Path); err != nil {
+					fmt.Fprint(os.Stderr, output)
+					return err
+				}
+			}
+
+			after, err := pipeline.ListModules(manifest.Path)
+			if err != nil {
+				return err
+			}
+			printDepChanges(pipeline.DiffModules(before, after))
+
+			if verify {
+				return verifySkill(manifest)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&tidy, "tidy", false, "Run go mod tidy")
+	cmd.Flags().StringVar(&update, "update", "", "Update dependencies: patch or minor")
+	cmd.Flags().BoolVar(&verify, "verify", false, "Rebuild and run tests after updating")
+	return cmd
+}
+
+// printDepChanges prints the dependency diff
+func printDepChanges(changes []pipeline.DepChange) {
+	if len(changes) == 0 {
+		fmt.Println("Dependencies unchanged")
+		return
+	}
+
+	fmt.Printf("%d dependencies changed:\n", len(changes))
+	for _, c := range changes {
+		switch {
+		case c.Old == "":
+			fmt.Printf("  + %s %s\n", c.Path, c.New)
+		case c.New == "":
+			fmt.Printf("  - %s %s\n", c.Path, c.Old)
+		default:
+			fmt.Printf("  ~ %s %s → %s\n", c.Path, c.Old, c.New)
+		}
+	}
+}
+
+// verifySkill rebuilds and tests a skill into a temporary directory
+func verifySkill(manifest *skill.Manifest) error {
+	tmpDir, err := os.MkdirTemp("", "skillfactory-verify-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if output, err := pipeline.Build(manifest, filepath.Join(tmpDir, manifest.BinaryName())); err != nil {
+		fmt.Fprint(os.Stderr, output)
+		return err
+	}
+	fmt.Println("Build: ok")
+
+	if output, err := pipeline.Test(manifest.Path); err != nil {
+		fmt.Fprint(os.Stderr, output)
+		return err
+	}
+	fmt.Println("Tests: ok")
+	return nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// newDiffCmd creates the diff command
+func newDiffCmd() *cobra.Command {
+	var against string
+	var profileName string
+	var skillsFolder string
+	var folderName string
+	var asJSON bool
+	var markdown bool
+
+	cmd := &cobra.Command{
+		Use:   "diff [skill]",
+		Short: "Compare the commands of a fresh build with the deployed skill",
+		Long: `Build the skill and compare its command tree with the deployed binary:
+added and removed commands, and added, removed or retyped flags of the
+commands in both. Use it for release notes, and before a redeploy that
+removes commands Claude may rely on.
+
+The deploy target is taken from a profile, as for deploy. --against also
+accepts the path of another binary of the skill.
+
+Examples:
+  skillfactory diff vikunja
+  skillfactory diff vikunja --profile work --markdown
+  skillfactory diff vikunja --against dist/vikunja-1.0.0 --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manifest, err := skill.FindSkill(tui.GetProjectRoot(), args[0])
+			if err != nil {
+				return err
+			}
+
+			// Binary to compare against
+			old := against
+			if against == "deployed" {
+				profile, err := config.LoadProfile(manifest.Name, profileName)
+				if err != nil {
+					return err
+				}
+				cfg, err := config.Load()
+				if err != nil {
+					return err
+				}
+				skillsFolder = config.ExpandPath(firstNonEmpty(skillsFolder, profile.SkillsFolder, cfg.SkillsFolder))
+				if skillsFolder == "" {
+					return fmt.Errorf("no skills folder configured (use --skills-folder)")
+				}
+				folderName = firstNonEmpty(folderName, profile.SkillFolderName, manifest.Name)
+				old = filepath.Join(skillsFolder, folderName, "bin", manifest.BinaryName())
+			}
+			if _, err := os.Stat(old); err != nil {
+				return fmt.Errorf("no binary to compare against at %s", old)
+			}
+
+			tmpDir, err := os.MkdirTemp("", "skillfactory-diff-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(tmpDir)
+			binaryPath := filepath.Join(tmpDir, manifest.BinaryName())
+			fmt.Fprintf(os.Stderr, "Building %s...\n", manifest.Name)
+			if output, err := pipeline.Build(manifest, binaryPath); err != nil {
+				fmt.Fprint(os.Stderr, output)
+				printDiagnoses(os.Stderr, manifest, output)
+				return err
+			}
+
+			before, err := pipeline.CommandSurface(old, manifest.Docs.Depth())
+			if err != nil {
+				return err
+			}
+			after, err := pipeline.CommandSurface(binaryPath, manifest.Docs.Depth())
+			if err != nil {
+				return err
+			}
+			diff := pipeline.DiffCommands(before, after)
+
+			switch {
+			case asJSON:
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(diff); err != nil {
+					return err
+				}
+			case markdown:
+				printDiffMarkdown(diff)
+			default:
+				printDiff(diff)
+			}
+			if len(diff.Removed) > 0 {
+				fmt.Fprintf(os.Stderr, "Warning: %d commands removed, SKILL.md instructions or prompts using them will fail\n", len(diff.Removed))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&against, "against", "deployed", `"deployed" or the path of a binary to compare with`)
+	cmd.Flags().StringVarP(&profileName, "profile", "p", config.DefaultProfile, "Profile with the deploy target")
+	cmd.Flags().StringVar(&skillsFolder, "skills-folder", "", "Skills folder (default: from profile)")
+	cmd.Flags().StringVar(&folderName, "folder-name", "", "Skill folder name (default: from profile)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the differences as JSON")
+	cmd.Flags().BoolVar(&markdown, "markdown", false, "Print the differences as Markdown release notes")
+	return cmd
+}
+
+// printDiff prints the command differences for the terminal
+func printDiff(diff pipeline.CommandDiff) {
+	if diff.Empty() {
+		fmt.Println("No command changes")
+		return
+	}
+	for _, path := range diff.Added {
+		fmt.Printf("+ %s\n", path)
+	}
+	for _, path := range diff.Removed {
+		fmt.Printf("- %s\n", path)
+	}
+	for _, c := range diff.Changed {
+		fmt.Printf("~ %s\n", c.Path)
+		for _, change := range c.Changes {
+			fmt.Printf("    %s\n", change)
+		}
+	}
+	fmt.Printf("\n%d added, %d removed, %d changed\n", len(diff.Added), len(diff.Removed), len(diff.Changed))
+}
+
+// printDiffMarkdown prints the command differences as release notes
+func printDiffMarkdown(diff pipeline.CommandDiff) {
+	if diff.Empty() {
+		fmt.Println("No command changes.")
+		return
+	}
+	section := func(title string, paths []string) {
+		if len(paths) == 0 {
+			return
+		}
+		fmt.Printf("### %s\n\n", title)
+		for _, path := range paths {
+			fmt.Printf("- `%s`\n", path)
+		}
+		fmt.Println()
+	}
+	section("New commands", diff.Added)
+	section("Removed commands", diff.Removed)
+	if len(diff.Changed) > 0 {
+		fmt.Printf("### Changed commands\n\n")
+		for _, c := range diff.Changed {
+			fmt.Printf("- `%s`\n", c.Path)
+			for _, change := range c.Changes {
+				fmt.Printf("  - %s\n", change)
+			}
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// newDocsCmd creates the docs command
+func newDocsCmd() *cobra.Command {
+	var profileName string
+	var skillsFolder string
+	var folderName string
+	var docsMode string
+
+	cmd := &cobra.Command{
+		Use:   "docs [skill]",
+		Short: "Regenerate SKILL.md of a deployed skill without building",
+		Long: `Regenerate the SKILL.md (and the docs.formats files) of a deployed skill
+from its docs template, without building or replacing the binary.
+
+Commands are extracted from the deployed binary, so a typo in the template
+is fixed without a build/deploy cycle. Variable values and the deploy
+target are taken from a profile, as for deploy. Changes to the commands
+themselves still need a deploy.
+
+Examples:
+  skillfactory docs vikunja
+  skillfactory docs vikunja --profile work --folder-name vikunja-work
+  skillfactory docs vikunja --docs merge`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manifest, err := skill.FindSkill(tui.GetProjectRoot(), args[0])
+			if err != nil {
+				return err
+			}
+
+			profile, err := config.LoadProfile(manifest.Name, profileName)
+			if err != nil {
+				return err
+			}
+			values, err := pipeline.ProfileValues(profile)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			skillsFolder = config.ExpandPath(firstNonEmpty(skillsFolder, profile.SkillsFolder, cfg.SkillsFolder))
+			if skillsFolder == "" {
+				return fmt.Errorf("no skills folder configured (use --skills-folder)")
+			}
+			folderName = firstNonEmpty(folderName, profile.SkillFolderName, manifest.Name)
+			deployPath := filepath.Join(skillsFolder, folderName)
+
+			if docsMode != "" && !slices.Contains(pipeline.DocsModes, docsMode) {
+				return fmt.Errorf("invalid --docs %q (expected %s)", docsMode, strings.Join(pipeline.DocsModes, ", "))
+			}
+			if lock, err := pipeline.ReadLock(deployPath); err == nil && pipeline.DocsEdited(deployPath, lock) && docsMode == "" {
+				return fmt.Errorf("%s was edited since the last deploy, use --docs overwrite, keep or merge", filepath.Join(deployPath, "SKILL.md"))
+			}
+
+			err = pipeline.RegenerateDocs(pipeline.DeployOptions{
+				Manifest:    manifest,
+				DeployPath:  deployPath,
+				Values:      values,
+				UseKeychain: cfg.UseKeychain(),
+				DocsMode:    docsMode,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Regenerated %s\n", filepath.Join(deployPath, "SKILL.md"))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&profileName, "profile", "p", config.DefaultProfile, "Profile with the variable values")
+	cmd.Flags().StringVar(&skillsFolder, "skills-folder", "", "Skills folder (default: from profile)")
+	cmd.Flags().StringVar(&folderName, "folder-name", "", "Skill folder name (default: from profile)")
+	cmd.Flags().StringVar(&docsMode, "docs", "", "Hand-edited SKILL.md: overwrite, keep or merge")
+	return cmd
+}
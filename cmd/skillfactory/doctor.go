@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	goversion "go/version"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// pingTimeout limits the request to a skill's API endpoint
+const pingTimeout = 5 * time.Second
+
+// check is one line of the doctor report
+type check struct {
+	name   string
+	ok     bool
+	skip   bool // Not applicable, neither passed nor failed
+	detail string
+}
+
+// newDoctorCmd creates the doctor command
+func newDoctorCmd() *cobra.Command {
+	var skillsFolder string
+	var offline bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the Go toolchain, skills and deploy folder",
+		Long: `Diagnose the environment SkillFactory builds and deploys in:
+
+  - the go command is in PATH and satisfies the go version of go.mod
+  - every skill.yaml is valid
+  - the external tools each skill requires are in PATH
+  - the skills folder exists and is writable
+  - the API endpoint of each skill (the first http(s) URL in its default
+    profile) answers
+
+Any HTTP response but a server error (5xx) passes, no credentials are
+sent. The command exits with an error if a check failed.
+
+Examples:
+  skillfactory doctor
+  skillfactory doctor --offline
+  skillfactory doctor --skills-folder ~/.claude/skills`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectRoot := tui.GetProjectRoot()
+			if skillsFolder == "" {
+				cfg, err := config.Load()
+				if err != nil {
+					return err
+				}
+				skillsFolder = cfg.SkillsFolder
+			}
+			skillsFolder = config.ExpandPath(skillsFolder)
+
+			checks := []check{checkGo(projectRoot), checkSkillsFolder(skillsFolder)}
+
+			manifests, skillErrors, err := skill.DiscoverSkills(projectRoot)
+			if err != nil {
+				checks = append(checks, check{name: "skills", detail: err.Error()})
+			}
+			for _, manifest := range manifests {
+				checks = append(checks, check{name: manifest.Name + " skill.yaml", ok: true, detail: "valid"})
+			}
+			for _, e := range skillErrors {
+				checks = append(checks, check{name: e.Name + " skill.yaml", detail: string(e.Kind) + ": " + e.Error.Error()})
+			}
+			for _, manifest := range manifests {
+				if len(manifest.Requires) > 0 {
+					checks = append(checks, checkRequires(manifest))
+				}
+			}
+			if !offline {
+				for _, manifest := range manifests {
+					checks = append(checks, checkEndpoint(manifest))
+				}
+			}
+
+			failed := 0
+			for _, c := range checks {
+				status := "PASS"
+				switch {
+				case c.skip:
+					status = "SKIP"
+				case !c.ok:
+					status = "FAIL"
+					failed++
+				}
+				fmt.Printf("%-5s %-26s %s\n", status, c.name, c.detail)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+			}
+			fmt.Println("\nAll checks passed")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&skillsFolder, "skills-folder", "", "Skills folder to check (default: saved from TUI)")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Skip the API endpoint checks")
+	return cmd
+}
+
+// checkGo checks that go is installed and not older than the go directive of go.mod
+func checkGo(projectRoot string) check {
+	c := check{name: "go toolchain"}
+	path, err := exec.LookPath("go")
+	if err != nil {
+		c.detail = "go not found in PATH"
+		return c
+	}
+	installed := pipeline.GoVersion(projectRoot)
+	if installed == "" {
+		c.detail = path + ": go env GOVERSION failed"
+		return c
+	}
+
+	c.ok = true
+	c.detail = installed + " (" + path + ")"
+	if required := goDirective(filepath.Join(projectRoot, "go.mod")); required != "" {
+		if goversion.Compare(installed, "go"+required) < 0 {
+			c.ok = false
+			c.detail = fmt.Sprintf("%s is older than go %s required by go.mod", installed, required)
+		}
+	}
+	return c
+}
+
+// goDirective returns the go version of a go.mod, empty if unknown
+func goDirective(goModPath string) string {
+	f, err := os.Open(goModPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "go "); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// checkSkillsFolder checks that the skills folder exists and is writable
+func checkSkillsFolder(folder string) check {
+	c := check{name: "skills folder"}
+	if folder == "" {
+		c.detail = "not configured (use --skills-folder or set it in the TUI)"
+		return c
+	}
+	info, err := os.Stat(folder)
+	if err != nil {
+		c.detail = err.Error()
+		return c
+	}
+	if !info.IsDir() {
+		c.detail = folder + " is not a directory"
+		return c
+	}
+	f, err := os.CreateTemp(folder, ".skillfactory-doctor-*")
+	if err != nil {
+		c.detail = folder + " is not writable: " + err.Error()
+		return c
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	c.ok = true
+	c.detail = folder
+	return c
+}
+
+// checkRequires looks up the external tools a skill requires in PATH
+func checkRequires(manifest *skill.Manifest) check {
+	c := check{name: manifest.Name + " tools"}
+	if missing := pipeline.MissingTools(manifest); len(missing) > 0 {
+		c.detail = "not in PATH: " + strings.Join(missing, ", ")
+		return c
+	}
+	c.ok = true
+	c.detail = strings.Join(manifest.Requires, ", ")
+	return c
+}
+
+// checkEndpoint requests the first http(s) URL configured in the default
+// profile of a skill
+func checkEndpoint(manifest *skill.Manifest) check {
+	c := check{name: manifest.Name + " API"}
+	profile, err := config.LoadProfile(manifest.Name, config.DefaultProfile)
+	if err != nil {
+		c.skip = true
+		c.detail = "no default profile"
+		return c
+	}
+
+	endpoint := ""
+	for _, v := range manifest.Variables {
+		value := profile.Values[v.Name]
+		if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+			endpoint = value
+			break
+		}
+	}
+	if endpoint == "" {
+		c.skip = true
+		c.detail = "no URL configured"
+		return c
+	}
+
+	client := &http.Client{Timeout: pingTimeout}
+	start := time.Now()
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		c.detail = err.Error()
+		return c
+	}
+	resp.Body.Close()
+
+	c.ok = resp.StatusCode < 500
+	c.detail = fmt.Sprintf("%s: %s in %s", endpoint, resp.Status, pipeline.FormatDuration(time.Since(start)))
+	return c
+}
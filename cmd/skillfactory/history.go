@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/spf13/cobra"
+)
+
+// newHistoryCmd creates the history command
+func newHistoryCmd() *cobra.Command {
+	var action string
+	var failed bool
+	var since string
+	var limit int
+	var showOutput bool
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "history [skill]",
+		Short: "Show past builds and deployments",
+		Long: `Show builds and deployments recorded by the TUI, deploy and install,
+newest first. Every event is appended to
+~/.local/state/skillfactory/history.jsonl ($XDG_STATE_HOME if set) with
+skill, version, target path, duration, outcome and build output.
+
+--since accepts a duration (e.g. 36h, 7d) or a date (2006-01-02).
+
+Examples:
+  skillfactory history
+  skillfactory history vikunja --action deploy
+  skillfactory history --failed --since 7d --output
+  skillfactory history vikunja --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := pipeline.EventFilter{Action: action, Failed: failed}
+			if len(args) == 1 {
+				filter.Skill = args[0]
+			}
+			if action != "" && action != pipeline.ActionBuild && action != pipeline.ActionDeploy {
+				return fmt.Errorf("invalid --action %q (expected %s or %s)", action, pipeline.ActionBuild, pipeline.ActionDeploy)
+			}
+			if since != "" {
+				t, err := parseSince(since)
+				if err != nil {
+					return err
+				}
+				filter.Since = t
+			}
+
+			events, err := pipeline.LoadEvents(filter)
+			if err != nil {
+				return err
+			}
+			if limit > 0 && len(events) > limit {
+				events = events[len(events)-limit:]
+			}
+
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				for i := len(events) - 1; i >= 0; i-- {
+					if err := enc.Encode(events[i]); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			if len(events) == 0 {
+				fmt.Println("No builds or deployments recorded")
+				return nil
+			}
+
+			fmt.Printf("%-17s %-7s %-16s %-10s %-9s %-8s %s\n", "TIME", "ACTION", "SKILL", "VERSION", "DURATION", "OUTCOME", "TARGET")
+			for i := len(events) - 1; i >= 0; i-- {
+				e := events[i]
+				detail := e.Target
+				if e.Error != "" {
+					detail = e.Error
+				}
+				fmt.Printf("%-17s %-7s %-16s %-10s %-9s %-8s %s\n",
+					e.At.Local().Format("2006-01-02 15:04"), e.Action, e.Skill, orDash(e.Version),
+					pipeline.FormatDuration(e.Duration()), e.Outcome, detail)
+				if showOutput && strings.TrimSpace(e.Output) != "" {
+					fmt.Println("  " + strings.ReplaceAll(strings.TrimRight(e.Output, "\n"), "\n", "\n  "))
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&action, "action", "", "Only show build or deploy events")
+	cmd.Flags().BoolVar(&failed, "failed", false, "Only show failed events")
+	cmd.Flags().StringVar(&since, "since", "", "Only show events since a duration ago or a date")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Number of events to show (0 for all)")
+	cmd.Flags().BoolVar(&showOutput, "output", false, "Include the build output")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print events as JSON lines")
+	return cmd
+}
+
+// parseSince parses a duration ago ("36h", "7d") or a date ("2006-01-02")
+func parseSince(value string) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (expected a duration like 7d or a date like 2006-01-02)", value)
+}
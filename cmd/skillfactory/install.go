@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/spf13/cobra"
+)
+
+// newInstallCmd creates the install command
+func newInstallCmd() *cobra.Command {
+	var skillsFolder string
+	var folderName string
+	var sets []string
+	var yes bool
+	var allowCommands bool
+
+	cmd := &cobra.Command{
+		Use:   "install [package|url]",
+		Short: "Install a packaged skill into the skills folder",
+		Long: `Install a skill package created with skillfactory package. The package
+is unpacked into the skills folder and the .env is written from the
+skill's variables. Values not given with --set are asked for; with --yes
+defaults are used and missing required values are an error.
+
+Variables with source: command take their value from a shell command
+declared in the package's skill.yaml. These commands are listed and only
+run after confirmation, or with --allow-commands; with --yes they are
+refused. Give their values with --set to skip them.
+
+Examples:
+  skillfactory install vikunja-1.0.0-linux-amd64.tar.gz
+  skillfactory install https://example.com/vikunja.tar.gz --set VIKUNJA_URL=https://tasks.example.com/api/v1
+  skillfactory install vikunja.tar.gz --folder-name vikunja-work --yes --set VIKUNJA_TOKEN=tk_...`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			settings, err := config.LoadSettings()
+			if err != nil {
+				return err
+			}
+			skillsFolder = config.ExpandPath(firstNonEmpty(skillsFolder, cfg.SkillsFolder))
+			if skillsFolder == "" {
+				return fmt.Errorf("no skills folder configured (use --skills-folder)")
+			}
+
+			if folderName != "" {
+				if err := pipeline.CheckPathName(folderName); err != nil {
+					return fmt.Errorf("invalid --folder-name: %w", err)
+				}
+			}
+
+			values := make(map[string]string)
+			for _, set := range sets {
+				name, value, ok := strings.Cut(set, "=")
+				if !ok {
+					return fmt.Errorf("invalid --set %q (expected NAME=VALUE)", set)
+				}
+				values[name] = value
+			}
+
+			pkg, err := pipeline.Unpack(args[0])
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(pkg.Dir)
+			manifest := pkg.Manifest
+			fmt.Printf("Installing %s %s\n", manifest.Name, manifest.Version)
+
+			if err := promptVariables(manifest, values, yes); err != nil {
+				return err
+			}
+			if err := confirmCommands(manifest, values, yes, allowCommands); err != nil {
+				return err
+			}
+
+			deployPath := filepath.Join(skillsFolder, firstNonEmpty(folderName, manifest.Name))
+			if _, err := pipeline.RemovalPaths(deployPath); err == nil && !yes && !confirm(deployPath+" already exists, overwrite?") {
+				fmt.Println("Aborted")
+				return nil
+			}
+
+			start := time.Now()
+			_, err = pipeline.Deploy(pipeline.DeployOptions{
+				Manifest:    manifest,
+				BinaryPath:  pkg.BinaryPath,
+				DeployPath:  deployPath,
+				Values:      values,
+				UseKeychain: cfg.UseKeychain(),
+				EncryptEnv:  cfg.EncryptEnv,
+				Docs:        pkg.Docs,
+				Backup:      settings.KeepBackupsDays > 0,
+			})
+			pipeline.RecordEvent(pipeline.NewEvent(pipeline.ActionDeploy, manifest, deployPath, time.Since(start), "", err))
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Installed %s to %s\n", manifest.Name, deployPath)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&skillsFolder, "skills-folder", "", "Skills folder (default: saved from TUI)")
+	cmd.Flags().StringVar(&folderName, "folder-name", "", "Skill folder name (default: skill name)")
+	cmd.Flags().StringArrayVar(&sets, "set", nil, "Variable value as NAME=VALUE (repeatable)")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not prompt, use defaults for unset variables")
+	cmd.Flags().BoolVar(&allowCommands, "allow-commands", false, "Run the source: command variables of the package without asking")
+	return cmd
+}
+
+// promptVariables asks for the manifest variables missing in values.
+// Without prompting, defaults are used and missing required values fail.
+// Variables with source "command" are resolved at deploy time instead.
+func promptVariables(manifest *skill.Manifest, values map[string]string, noPrompt bool) error {
+	for _, v := range manifest.Variables {
+		if _, ok := values[v.Name]; ok || v.FromCommand() {
+			continue
+		}
+
+		value := ""
+		if !noPrompt {
+			label := firstNonEmpty(v.Label, v.Name)
+			if v.Description != "" {
+				label += " (" + v.Description + ")"
+			}
+			if v.Default != "" {
+				label += " [" + v.Default + "]"
+			}
+			var err error
+			if v.Backend != "" {
+				label += " [" + pipeline.BackendPlaceholder(v.Backend) + "]"
+			}
+			if value, err = prompt(label, v.Type == "secret" && v.Backend == ""); err != nil {
+				return err
+			}
+		}
+		if value == "" {
+			value = v.Default
+		}
+		if v.Required && value == "" {
+			return fmt.Errorf("missing value for required variable %s (use --set %s=...)", v.Name, v.Name)
+		}
+		values[v.Name] = value
+	}
+	return nil
+}
+
+// confirmCommands lists the source: command variables without a value that
+// the deploy would run as shell commands. A package may come from anywhere,
+// so they only run with allowCommands or after confirmation.
+func confirmCommands(manifest *skill.Manifest, values map[string]string, noPrompt, allowCommands bool) error {
+	var commands []skill.Variable
+	for _, v := range manifest.Variables {
+		if v.FromCommand() && values[v.Name] == "" {
+			commands = append(commands, v)
+		}
+	}
+	if len(commands) == 0 || allowCommands {
+		return nil
+	}
+
+	fmt.Println("The package sets these variables from shell commands:")
+	for _, v := range commands {
+		fmt.Printf("  %s: %s\n", v.Name, v.Command)
+	}
+	if noPrompt {
+		return fmt.Errorf("refusing to run the commands of the package (use --allow-commands or --set the values)")
+	}
+	if !confirm("Run them?") {
+		return fmt.Errorf("commands not confirmed (use --set to give the values)")
+	}
+	return nil
+}
+
+// prompt reads a line from stdin, without echo for secrets on a terminal
+func prompt(label string, secret bool) (string, error) {
+	fmt.Printf("%s: ", label)
+	if secret && term.IsTerminal(os.Stdin.Fd()) {
+		value, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Println()
+		return strings.TrimSpace(string(value)), err
+	}
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read %s: %w", label, err)
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -6,7 +6,10 @@ import (
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/stats"
 	"github.com/petervogelmann/skillfactory/internal/tui"
+	"github.com/spf13/cobra"
 )
 
 // version is set via ldflags at build time
@@ -14,21 +17,77 @@ import (
 var version = "dev"
 
 func main() {
-	// Handle --version flag
-	if len(os.Args) > 1 && (os.Args[1] == "--version" || os.Args[1] == "-v") {
-		fmt.Println("SkillFactory", version)
-		os.Exit(0)
+	rootCmd := &cobra.Command{
+		Use:           "skillfactory",
+		Short:         "Build & deploy skills for Claude Code",
+		Long:          "SkillFactory builds and deploys skills for Claude Code.\nRun without arguments to start the interactive TUI.",
+		Version:       version,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTUI()
+		},
 	}
+	rootCmd.SetVersionTemplate("SkillFactory {{.Version}}\n")
 
+	// Global config file, validated before any command runs
+	var configPath string
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Global config file (default ~/.config/skillfactory/config.yaml)")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if configPath != "" {
+			os.Setenv(config.SettingsEnvVar, configPath)
+		}
+		_, err := config.LoadSettings()
+		return err
+	}
+
+	rootCmd.AddCommand(
+		newCleanCmd(),
+		newConfigCmd(),
+		newDeployCmd(),
+		newDeployedCmd(),
+		newDiffCmd(),
+		newDepsCmd(),
+		newDocsCmd(),
+		newDoctorCmd(),
+		newHistoryCmd(),
+		newInstallCmd(),
+		newMultiplexCmd(),
+		newPackageCmd(),
+		newRefreshDocsCmd(),
+		newRemoveCmd(),
+		newSearchCmd(),
+		newServeCmd(),
+		newStatsCmd(),
+		newStatusCmd(),
+		newValidateCmd(),
+		newVerifyCmd(),
+	)
+
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runTUI starts the interactive terminal UI
+func runTUI() error {
 	// Find project root
 	projectRoot := tui.GetProjectRoot()
+	stats.Record(stats.FeatureTUI)
+
+	// Fail early on an invalid config file, the TUI applies its theme
+	if _, err := config.LoadSettings(); err != nil {
+		return err
+	}
 
 	// Create and run TUI
 	model := tui.NewModel(projectRoot, version)
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
 	if _, err := p.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error running TUI: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("running TUI: %w", err)
 	}
+	return nil
 }
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/spf13/cobra"
+)
+
+// newMultiplexCmd creates the multiplex command
+func newMultiplexCmd() *cobra.Command {
+	var skillsFolder string
+	var target string
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "multiplex [skill...]",
+		Short: "Generate one entry point binary for the deployed skills",
+		Long: `Build a single binary dispatching to the deployed skills by name, with one
+SKILL.md combining their docs:
+
+  skills vikunja tasks list
+  skills habitwire today
+
+The entry point is written to <skills folder>/<name> unless --target names
+another folder. All skills with a binary in the skills folder are included
+unless skills are named. Each call runs the deployed binary of the skill
+with its own .env, so redeploying a skill needs no new entry point, adding,
+removing or moving one does.
+
+For one PATH entry and one SKILL.md, deploy the skills to a folder Claude
+does not read and write the entry point to the Claude skills folder.
+
+Examples:
+  skillfactory multiplex
+  skillfactory multiplex vikunja habitwire --name tools
+  skillfactory multiplex --skills-folder ~/.local/share/skills --target ~/.claude/skills/skills`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if skillsFolder == "" {
+				cfg, err := config.Load()
+				if err != nil {
+					return err
+				}
+				skillsFolder = cfg.SkillsFolder
+			}
+			skillsFolder = config.ExpandPath(skillsFolder)
+			if skillsFolder == "" {
+				return fmt.Errorf("no skills folder configured (use --skills-folder)")
+			}
+			if target == "" {
+				target = filepath.Join(skillsFolder, name)
+			}
+			target = config.ExpandPath(target)
+
+			deployed, err := pipeline.ScanDeployed(skillsFolder, nil)
+			if err != nil {
+				return err
+			}
+			var skills []pipeline.DeployedSkill
+			for _, d := range pipeline.MultiplexSkills(deployed) {
+				if len(args) == 0 || slices.Contains(args, d.Name) || slices.Contains(args, d.Folder) {
+					skills = append(skills, d)
+				}
+			}
+			for _, arg := range args {
+				if !slices.ContainsFunc(skills, func(d pipeline.DeployedSkill) bool { return d.Name == arg || d.Folder == arg }) {
+					return fmt.Errorf("%s is not deployed with a binary in %s", arg, skillsFolder)
+				}
+			}
+
+			if err := pipeline.Multiplex(pipeline.MultiplexOptions{Name: name, DeployPath: target, Skills: skills}); err != nil {
+				return err
+			}
+			binaryPath := filepath.Join(target, "bin", name)
+			for _, d := range skills {
+				fmt.Printf("%-20s %s %s\n", d.Folder, binaryPath, d.Name)
+			}
+			fmt.Printf("Entry point: %s (docs in %s)\n", binaryPath, filepath.Join(target, "SKILL.md"))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&skillsFolder, "skills-folder", "", "Skills folder (default: saved from TUI)")
+	cmd.Flags().StringVar(&target, "target", "", "Folder of the entry point (default: <skills folder>/<name>)")
+	cmd.Flags().StringVar(&name, "name", pipeline.DefaultMultiplexName, "Binary and skill name of the entry point")
+	return cmd
+}
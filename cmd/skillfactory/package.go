@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// newPackageCmd creates the package command
+func newPackageCmd() *cobra.Command {
+	var output string
+	var provenance bool
+
+	cmd := &cobra.Command{
+		Use:   "package [skill]",
+		Short: "Build a skill into a package for skillfactory install",
+		Long: `Build a skill and write a package (.tar.gz) with its binary, skill.yaml
+and generated SKILL.md. The package contains no variable values; they are
+asked for when the package is installed.
+
+The binary is built for the current platform, use GOOS/GOARCH to
+cross-compile. Skills with build.cgo cannot be cross-compiled; set
+build.remote in skill.yaml to an SSH host of the target platform to
+build them there (the project is synced with rsync, the SSH agent is
+forwarded for private modules).
+
+--provenance writes a signed SLSA provenance statement (builder, source
+commit, Go modules, timestamps) next to the package as
+<package>.intoto.jsonl. It is signed with an ed25519 key in the settings
+directory that is created on first use; hand out provenance.pub from
+there to verify packages with skillfactory verify --provenance.
+
+Examples:
+  skillfactory package vikunja
+  skillfactory package vikunja --provenance
+  GOOS=darwin GOARCH=arm64 skillfactory package vikunja -o dist/vikunja-mac.tar.gz`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manifest, err := skill.FindSkill(tui.GetProjectRoot(), args[0])
+			if err != nil {
+				return err
+			}
+
+			tmpDir, err := os.MkdirTemp("", "skillfactory-package-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(tmpDir)
+			binaryPath := filepath.Join(tmpDir, manifest.BinaryName())
+
+			started := time.Now()
+			if pipeline.RemoteBuildNeeded(manifest) {
+				fmt.Printf("Building %s on %s...\n", manifest.Name, manifest.Build.Remote)
+				if err := pipeline.BuildRemote(manifest, tui.GetProjectRoot(), binaryPath, os.Stderr); err != nil {
+					return err
+				}
+			} else {
+				fmt.Printf("Building %s...\n", manifest.Name)
+				if output, err := pipeline.Build(manifest, binaryPath); err != nil {
+					fmt.Fprint(os.Stderr, output)
+					printDiagnoses(os.Stderr, manifest, output)
+					return err
+				}
+			}
+
+			if output == "" {
+				output = pipeline.PackageName(manifest)
+			}
+			if err := pipeline.Package(manifest, binaryPath, output); err != nil {
+				return err
+			}
+			fmt.Printf("Packaged %s to %s\n", manifest.Name, output)
+
+			if provenance {
+				keyID, err := pipeline.WriteProvenance(pipeline.ProvenanceOptions{
+					Manifest:       manifest,
+					BinaryPath:     binaryPath,
+					PackagePath:    output,
+					BuilderVersion: version,
+					Started:        started,
+					Finished:       time.Now(),
+				})
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Signed provenance %s (key %s)\n", pipeline.ProvenancePath(output), keyID)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Package file (default: <skill>-<version>-<os>-<arch>.tar.gz)")
+	cmd.Flags().BoolVar(&provenance, "provenance", false, "Write a signed provenance statement next to the package")
+	return cmd
+}
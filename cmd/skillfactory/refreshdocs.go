@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// newRefreshDocsCmd creates the refresh-docs command
+func newRefreshDocsCmd() *cobra.Command {
+	var all bool
+	var skillsFolder string
+	var olderThan time.Duration
+	var docsMode string
+
+	cmd := &cobra.Command{
+		Use:   "refresh-docs [skill...]",
+		Short: "Update the live data in the SKILL.md of deployed skills",
+		Long: `Regenerate the SKILL.md of deployed skills to update the tables of their
+docs.data commands (e.g. the project IDs of the account), without building
+or replacing the binaries. Meant for a cron job.
+
+--all refreshes every skill in the skills folder with docs.data in its
+skill.yaml; otherwise the skills (or skill folders) are named. The variable
+values come from the profile deploying to the folder, or the deployed .env.
+If a data command fails, e.g. because the API is unreachable, the SKILL.md
+of that skill is left as it is and the command exits with an error after
+the other skills.
+
+A SKILL.md edited by hand is skipped unless --docs says how to handle it.
+
+Examples:
+  skillfactory refresh-docs --all
+  skillfactory refresh-docs vikunja
+  skillfactory refresh-docs --all --older-than 12h
+
+  # crontab: every morning at 6
+  0 6 * * * skillfactory refresh-docs --all --older-than 20h`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) > 0) {
+				return fmt.Errorf("name the skills to refresh or use --all")
+			}
+			if docsMode != "" && !slices.Contains(pipeline.DocsModes, docsMode) {
+				return fmt.Errorf("invalid --docs %q (expected %s)", docsMode, strings.Join(pipeline.DocsModes, ", "))
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			skillsFolder = config.ExpandPath(firstNonEmpty(skillsFolder, cfg.SkillsFolder))
+			if skillsFolder == "" {
+				return fmt.Errorf("no skills folder configured (use --skills-folder)")
+			}
+
+			manifests, _, err := skill.DiscoverSkills(tui.GetProjectRoot())
+			if err != nil {
+				return err
+			}
+			deployed, err := pipeline.ScanDeployed(skillsFolder, manifests)
+			if err != nil {
+				return err
+			}
+
+			var refreshed, failed int
+			found := make(map[string]bool)
+			for _, d := range deployed {
+				named := slices.Contains(args, d.Name) || slices.Contains(args, d.Folder)
+				if !all && !named {
+					continue
+				}
+				found[d.Name], found[d.Folder] = true, true
+				i := slices.IndexFunc(manifests, func(m *skill.Manifest) bool { return m.Name == d.Name })
+				if i < 0 {
+					if named {
+						fmt.Printf("%-20s skipped, source missing\n", d.Folder)
+					}
+					continue
+				}
+				manifest := manifests[i]
+				if len(manifest.Docs.Data) == 0 {
+					if named {
+						fmt.Printf("%-20s skipped, no docs.data in skill.yaml\n", d.Folder)
+					}
+					continue
+				}
+
+				docsPath := filepath.Join(d.Path, "SKILL.md")
+				if info, err := os.Stat(docsPath); err == nil && olderThan > 0 && time.Since(info.ModTime()) < olderThan {
+					fmt.Printf("%-20s up to date, generated %s\n", d.Folder, info.ModTime().Format("2006-01-02 15:04"))
+					continue
+				}
+				if lock, err := pipeline.ReadLock(d.Path); err == nil && pipeline.DocsEdited(d.Path, lock) && docsMode == "" {
+					fmt.Printf("%-20s skipped, SKILL.md was edited by hand (use --docs overwrite, keep or merge)\n", d.Folder)
+					continue
+				}
+
+				if err := refreshDocs(manifest, d.Path, skillsFolder, cfg, docsMode); err != nil {
+					fmt.Fprintf(os.Stderr, "%-20s failed: %v\n", d.Folder, err)
+					failed++
+					continue
+				}
+				fmt.Printf("%-20s refreshed\n", d.Folder)
+				refreshed++
+			}
+
+			for _, name := range args {
+				if !found[name] {
+					fmt.Fprintf(os.Stderr, "%-20s failed: not deployed in %s\n", name, skillsFolder)
+					failed++
+				}
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d skills failed", failed, failed+refreshed)
+			}
+			if refreshed == 0 && all {
+				fmt.Printf("No deployed skill in %s needs a refresh\n", skillsFolder)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "Refresh every deployed skill with docs.data")
+	cmd.Flags().StringVar(&skillsFolder, "skills-folder", "", "Skills folder (default: saved from TUI)")
+	cmd.Flags().DurationVar(&olderThan, "older-than", 0, "Skip skills whose SKILL.md is younger, e.g. 12h")
+	cmd.Flags().StringVar(&docsMode, "docs", "", "Hand-edited SKILL.md: overwrite, keep or merge")
+	return cmd
+}
+
+// refreshDocs regenerates the docs of a deployed skill with the values of
+// the profile deploying there, or the values of its deployed .env
+func refreshDocs(manifest *skill.Manifest, deployPath, skillsFolder string, cfg *config.Config, docsMode string) error {
+	var values map[string]string
+	name, err := pipeline.DeployedProfile(manifest, deployPath, skillsFolder)
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		profile, err := config.LoadProfile(manifest.Name, name)
+		if err != nil {
+			return err
+		}
+		if values, err = pipeline.ProfileValues(profile); err != nil {
+			return err
+		}
+	} else if values, err = pipeline.DeployedValues(manifest, deployPath); err != nil {
+		return err
+	}
+
+	return pipeline.RefreshDocs(pipeline.DeployOptions{
+		Manifest:    manifest,
+		DeployPath:  deployPath,
+		Values:      values,
+		UseKeychain: cfg.UseKeychain(),
+		DocsMode:    docsMode,
+	})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/spf13/cobra"
+)
+
+// newRemoveCmd creates the remove command
+func newRemoveCmd() *cobra.Command {
+	var skillsFolder string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "remove [skill]",
+		Short: "Remove a deployed skill from the skills folder",
+		Long: `Remove a deployed skill: its bin directory (binary and .env), SKILL.md
+and deploy.lock. The files are listed and must be confirmed before
+anything is deleted. Other files in the skill folder are kept.
+
+The skill is the folder name in the skills folder.
+
+Examples:
+  skillfactory remove vikunja
+  skillfactory remove vikunja-personal --skills-folder ~/.claude/skills --yes`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if skillsFolder == "" {
+				cfg, err := config.Load()
+				if err != nil {
+					return err
+				}
+				skillsFolder = cfg.SkillsFolder
+			}
+			skillsFolder = config.ExpandPath(skillsFolder)
+			if skillsFolder == "" {
+				return fmt.Errorf("no skills folder configured (use --skills-folder)")
+			}
+
+			deployPath := filepath.Join(skillsFolder, args[0])
+			paths, err := pipeline.RemovalPaths(deployPath)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("The following will be removed from %s:\n", deployPath)
+			for _, path := range paths {
+				fmt.Println("  " + path)
+			}
+			if !yes && !confirm("Remove?") {
+				fmt.Println("Aborted")
+				return nil
+			}
+
+			if err := pipeline.Remove(deployPath, paths); err != nil {
+				return err
+			}
+			fmt.Printf("Removed %s\n", args[0])
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&skillsFolder, "skills-folder", "", "Skills folder (default: saved from TUI)")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Remove without asking for confirmation")
+	return cmd
+}
+
+// stdin is shared by all prompts so buffered input is not lost between them
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question on stdin, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := stdin.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
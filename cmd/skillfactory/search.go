@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/spf13/cobra"
+)
+
+// newSearchCmd creates the search command
+func newSearchCmd() *cobra.Command {
+	var skillsFolder string
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search the SKILL.md and commands of all deployed skills",
+		Long: `Search the SKILL.md files and command schemas (commands.json or
+tools.json, see docs.formats) of every skill in the skills folder. A line
+or command matches if it contains all words of the query, ignoring case.
+Commands match on their path, description and flags.
+
+Examples:
+  skillfactory search labels
+  skillfactory search "due date"
+  skillfactory search labels --json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if skillsFolder == "" {
+				cfg, err := config.Load()
+				if err != nil {
+					return err
+				}
+				skillsFolder = cfg.SkillsFolder
+			}
+			skillsFolder = config.ExpandPath(skillsFolder)
+			if skillsFolder == "" {
+				return fmt.Errorf("no skills folder configured (use --skills-folder)")
+			}
+
+			docs, err := pipeline.LoadSearchIndex(skillsFolder)
+			if err != nil {
+				return err
+			}
+			matches := pipeline.Search(docs, strings.Join(args, " "))
+
+			if asJSON {
+				if matches == nil {
+					matches = []pipeline.SearchMatch{}
+				}
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(matches)
+			}
+			if len(matches) == 0 {
+				fmt.Printf("No matches in %d deployed skills\n", len(docs))
+				return nil
+			}
+
+			skill := ""
+			for _, m := range matches {
+				if m.Folder != skill {
+					if skill != "" {
+						fmt.Println()
+					}
+					skill = m.Folder
+					fmt.Println(m.Folder)
+				}
+				if m.Command != "" {
+					fmt.Printf("  %-24s %s\n", m.Command, m.Text)
+				} else {
+					fmt.Printf("  %-24s %s\n", fmt.Sprintf("%s:%d", m.File, m.Line), m.Text)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&skillsFolder, "skills-folder", "", "Skills folder to search (default: saved from TUI)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the matches as JSON")
+	return cmd
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/internal/stats"
+	"github.com/petervogelmann/skillfactory/internal/tui"
+	"github.com/petervogelmann/skillfactory/internal/web"
+	"github.com/spf13/cobra"
+)
+
+// newServeCmd creates the serve command
+func newServeCmd() *cobra.Command {
+	var addr string
+	var token string
+	var skillsFolder string
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a local web UI to configure, deploy and inspect skills",
+		Long: `Serve a web UI as an alternative to the TUI: list the skills with their
+deployed versions, edit the variables of their profiles, build and deploy
+them and browse the history of builds and deployments.
+
+Every request needs an access token: a random one is generated at start
+(or set with --token / SKILLFACTORY_SERVE_TOKEN) and the printed URL
+passes it to the browser. Secret values are never sent to the browser.
+
+The UI runs on a JSON API that scripts (or Claude) can call with the
+token as "Authorization: Bearer <token>":
+  GET  /api/skills                 skills with versions, variables, profiles
+  GET  /api/skills/{name}          one skill
+  POST /api/deploy                 {"skill": "...", "profile": "...", "docs": "..."}
+                                   starts a deploy, 202 with its id (409 while
+                                   another deploy runs)
+  GET  /api/deployments            deploys of this server, newest first
+                                   (?skill=, ?state=running|success|failed)
+  GET  /api/deployments/{id}       state and output, ?wait=5m blocks until done
+  GET  /api/history                recorded builds and deploys (?skill=)
+
+The UI listens on localhost by default. Use --addr :8797 to reach it from
+other machines, e.g. on a headless build host, preferably through an SSH
+tunnel since the connection is not encrypted.
+
+Examples:
+  skillfactory serve
+  skillfactory serve --addr :8797
+  skillfactory serve --token secret &
+  curl -H "Authorization: Bearer secret" -d '{"skill":"vikunja"}' localhost:8797/api/deploy
+  ssh -L 8797:localhost:8797 buildhost skillfactory serve`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			skillsFolder = config.ExpandPath(firstNonEmpty(skillsFolder, cfg.SkillsFolder))
+			token = firstNonEmpty(token, os.Getenv("SKILLFACTORY_SERVE_TOKEN"))
+			if token == "" {
+				if token, err = randomToken(); err != nil {
+					return err
+				}
+			}
+
+			server := web.New(web.Options{
+				ProjectRoot:  tui.GetProjectRoot(),
+				SkillsFolder: skillsFolder,
+				Token:        token,
+				Deploy: func(out io.Writer, manifest *skill.Manifest, profile, docsMode string) error {
+					return deployProfile(out, out, manifest, deployRequest{Profile: profile, Docs: docsMode})
+				},
+			})
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			httpServer := &http.Server{Handler: server.Handler(), ReadHeaderTimeout: 10 * time.Second}
+			stats.Record(stats.FeatureWeb)
+
+			host, port, _ := net.SplitHostPort(listener.Addr().String())
+			if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+				host = "localhost"
+			}
+			fmt.Printf("SkillFactory web UI: http://%s/?token=%s\n", net.JoinHostPort(host, port), token)
+			fmt.Println("Press Ctrl+C to stop")
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				httpServer.Shutdown(shutdownCtx)
+			}()
+
+			if err := httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&addr, "addr", "localhost:8797", "Address to listen on (:8797 for all interfaces)")
+	cmd.Flags().StringVar(&token, "token", "", "Access token (default: $SKILLFACTORY_SERVE_TOKEN or random)")
+	cmd.Flags().StringVar(&skillsFolder, "skills-folder", "", "Skills folder for the deployed versions (default: saved from TUI)")
+	return cmd
+}
+
+// randomToken returns 16 random bytes as hex
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
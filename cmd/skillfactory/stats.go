@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+// newStatsCmd creates the stats command
+func newStatsCmd() *cobra.Command {
+	var enable bool
+	var disable bool
+	var reset bool
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show local usage statistics",
+		Long: `Show how often SkillFactory features are used and how long builds take.
+
+Recording is opt-in and strictly local: stats are stored in
+~/.skillfactory/stats.json and never sent anywhere.
+
+Examples:
+  skillfactory stats --enable
+  skillfactory stats
+  skillfactory stats --reset`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if enable || disable {
+				cfg, err := config.Load()
+				if err != nil {
+					return err
+				}
+				cfg.Stats = enable
+				if err := cfg.Save(); err != nil {
+					return err
+				}
+				if enable {
+					fmt.Println("Local stats enabled")
+				} else {
+					fmt.Println("Local stats disabled")
+				}
+				return nil
+			}
+			if reset {
+				if err := stats.Reset(); err != nil {
+					return err
+				}
+				fmt.Println("Stats reset")
+				return nil
+			}
+
+			if !stats.Enabled() {
+				fmt.Println("Local stats are disabled. Enable with: skillfactory stats --enable")
+				return nil
+			}
+			s, err := stats.Load()
+			if err != nil {
+				return err
+			}
+			printStats(s)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&enable, "enable", false, "Opt in to recording local stats")
+	cmd.Flags().BoolVar(&disable, "disable", false, "Stop recording stats")
+	cmd.Flags().BoolVar(&reset, "reset", false, "Delete recorded stats")
+	cmd.MarkFlagsMutuallyExclusive("enable", "disable", "reset")
+	return cmd
+}
+
+// printStats prints feature usage and build durations
+func printStats(s *stats.Stats) {
+	fmt.Printf("Since %s\n\n", s.Since.Local().Format("2006-01-02"))
+
+	if len(s.Features) == 0 && len(s.Builds) == 0 {
+		fmt.Println("Nothing recorded yet")
+		return
+	}
+
+	if len(s.Features) > 0 {
+		features := make([]string, 0, len(s.Features))
+		for name := range s.Features {
+			features = append(features, name)
+		}
+		sort.Slice(features, func(i, j int) bool {
+			if s.Features[features[i]] != s.Features[features[j]] {
+				return s.Features[features[i]] > s.Features[features[j]]
+			}
+			return features[i] < features[j]
+		})
+
+		fmt.Println("Features:")
+		for _, name := range features {
+			fmt.Printf("  %-16s %d\n", name, s.Features[name])
+		}
+		fmt.Println()
+	}
+
+	if len(s.Builds) > 0 {
+		skills := make([]string, 0, len(s.Builds))
+		for name := range s.Builds {
+			skills = append(skills, name)
+		}
+		sort.Strings(skills)
+
+		fmt.Println("Builds:")
+		fmt.Printf("  %-16s %6s %8s %8s %8s\n", "SKILL", "COUNT", "AVG", "MAX", "LAST")
+		for _, name := range skills {
+			b := s.Builds[name]
+			fmt.Printf("  %-16s %6d %8s %8s %8s\n", name, b.Count,
+				pipeline.FormatDuration(b.Average()),
+				pipeline.FormatDuration(time.Duration(b.MaxMS)*time.Millisecond),
+				pipeline.FormatDuration(time.Duration(b.LastMS)*time.Millisecond))
+		}
+	}
+}
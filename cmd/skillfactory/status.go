@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// newStatusCmd creates the status command
+func newStatusCmd() *cobra.Command {
+	var skillsFolder string
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show source and deployed versions and build trends of all skills",
+		Long: `Show every skill with its source version, the deployed version in the
+skills folder and the durations of its last builds.
+
+Examples:
+  skillfactory status
+  skillfactory status --skills-folder ~/.claude/skills`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if skillsFolder == "" {
+				cfg, err := config.Load()
+				if err != nil {
+					return err
+				}
+				skillsFolder = cfg.SkillsFolder
+			}
+			skillsFolder = config.ExpandPath(skillsFolder)
+
+			manifests, skillErrors, err := skill.DiscoverSkills(tui.GetProjectRoot())
+			if err != nil {
+				return err
+			}
+			history, err := pipeline.LoadBuildHistory()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%-16s %-10s %-10s %-14s %s\n", "SKILL", "VERSION", "DEPLOYED", "STATUS", "BUILDS")
+			for _, manifest := range manifests {
+				deployed := ""
+				if skillsFolder != "" {
+					deployed = pipeline.DeployedVersion(filepath.Join(skillsFolder, manifest.Name), manifest.BinaryName())
+				}
+				fmt.Printf("%-16s %-10s %-10s %-14s %s\n",
+					manifest.Name,
+					orDash(manifest.Version),
+					orDash(deployed),
+					skill.VersionStatus(manifest.Version, deployed),
+					orDash(pipeline.BuildTrend(history[manifest.Name])))
+			}
+			for _, e := range skillErrors {
+				fmt.Printf("%-16s %s: %v\n", e.Name, e.Kind, e.Error)
+			}
+
+			if skillsFolder == "" {
+				fmt.Println("\nNo skills folder configured, deployed versions unknown (use --skills-folder)")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&skillsFolder, "skills-folder", "", "Skills folder to inspect (default: saved from TUI)")
+	return cmd
+}
+
+// orDash returns "-" for empty values
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
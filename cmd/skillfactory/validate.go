@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// validateResult is the validation outcome of one skill
+type validateResult struct {
+	Skill  string        `json:"skill"`
+	Path   string        `json:"path"`
+	Valid  bool          `json:"valid"`
+	Kind   string        `json:"kind,omitempty"`  // Error kind if invalid
+	Error  string        `json:"error,omitempty"` // Error if the manifest could not be checked field by field
+	Issues []skill.Issue `json:"issues,omitempty"`
+}
+
+// newValidateCmd creates the validate command
+func newValidateCmd() *cobra.Command {
+	var asJSON bool
+	var printSchema bool
+
+	cmd := &cobra.Command{
+		Use:   "validate [skill]",
+		Short: "Check skill.yaml files against the manifest schema",
+		Long: `Check the skill.yaml of one or all skills: required fields, variable
+types, the build section and unknown fields. The TUI lists skills with
+errors using the same checks.
+
+--json prints one result per skill for scripts and CI. --schema prints
+the JSON Schema of skill.yaml, e.g. for editor completion.
+
+Examples:
+  skillfactory validate
+  skillfactory validate vikunja --json
+  skillfactory validate --schema > skill.schema.json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if printSchema {
+				_, err := os.Stdout.Write(skill.Schema)
+				return err
+			}
+
+			manifests, skillErrors, err := skill.DiscoverSkills(tui.GetProjectRoot())
+			if err != nil {
+				return err
+			}
+
+			var results []validateResult
+			for _, m := range manifests {
+				results = append(results, validateResult{Skill: m.Name, Path: filepath.Join(m.Path, "skill.yaml"), Valid: true})
+			}
+			for _, e := range skillErrors {
+				r := validateResult{Skill: e.Name, Path: filepath.Join(e.Path, "skill.yaml"), Kind: string(e.Kind), Issues: e.Issues}
+				if len(e.Issues) == 0 {
+					r.Error = e.Error.Error()
+				}
+				results = append(results, r)
+			}
+			if len(args) == 1 {
+				results = filterResults(results, args[0])
+				if len(results) == 0 {
+					return fmt.Errorf("skill not found: %s", args[0])
+				}
+			}
+
+			invalid := 0
+			for _, r := range results {
+				if !r.Valid {
+					invalid++
+				}
+			}
+
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(results); err != nil {
+					return err
+				}
+			} else {
+				for _, r := range results {
+					if r.Valid {
+						fmt.Printf("%-16s ok\n", r.Skill)
+						continue
+					}
+					fmt.Printf("%-16s %s\n", r.Skill, r.Kind)
+					if r.Error != "" {
+						fmt.Printf("  %s\n", r.Error)
+					}
+					for _, issue := range r.Issues {
+						fmt.Printf("  %s\n", issue)
+						if issue.Hint != "" {
+							fmt.Printf("    quick fix: %s\n", issue.Hint)
+						}
+					}
+				}
+			}
+
+			if invalid > 0 {
+				return fmt.Errorf("%d of %d skills invalid", invalid, len(results))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print results as JSON")
+	cmd.Flags().BoolVar(&printSchema, "schema", false, "Print the JSON Schema of skill.yaml")
+	return cmd
+}
+
+// filterResults keeps the results of the skill with the given manifest or directory name
+func filterResults(results []validateResult, name string) []validateResult {
+	var filtered []validateResult
+	for _, r := range results {
+		if r.Skill == name || filepath.Base(filepath.Dir(r.Path)) == name {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/spf13/cobra"
+)
+
+// newVerifyCmd creates the verify command
+func newVerifyCmd() *cobra.Command {
+	var provenance bool
+	var attestation string
+	var publicKey string
+
+	cmd := &cobra.Command{
+		Use:   "verify [package]",
+		Short: "Verify the provenance of a skill package",
+		Long: `Verify a skill package against the provenance statement written by
+skillfactory package --provenance: the signature of the statement, the
+SHA-256 of the package and of the binary in it. On success the builder,
+source commit and build time from the statement are printed.
+
+The statement is read from <package>.intoto.jsonl, the public key from
+provenance.pub in the settings directory. Use --key with the public key
+of the team that built the package.
+
+Examples:
+  skillfactory verify vikunja-1.0.0-linux-amd64.tar.gz --provenance
+  skillfactory verify vikunja.tar.gz --provenance --key team.pub --attestation vikunja.intoto.jsonl`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !provenance {
+				return errors.New("nothing to verify, use --provenance")
+			}
+			packagePath := args[0]
+			if attestation == "" {
+				attestation = pipeline.ProvenancePath(packagePath)
+			}
+			if publicKey == "" {
+				path, err := pipeline.ProvenancePublicKeyPath()
+				if err != nil {
+					return err
+				}
+				publicKey = path
+			}
+
+			verified, err := pipeline.VerifyProvenance(packagePath, attestation, publicKey)
+			if err != nil {
+				return err
+			}
+
+			statement := verified.Statement
+			predicate := statement.Predicate
+			fmt.Printf("Verified %s (key %s)\n", packagePath, verified.KeyID)
+			for _, s := range statement.Subject {
+				fmt.Printf("  subject   %s sha256:%s\n", s.Name, s.Digest["sha256"])
+			}
+			fmt.Printf("  builder   %s\n", predicate.RunDetails.Builder.ID)
+			versions := predicate.RunDetails.Builder.Version
+			names := make([]string, 0, len(versions))
+			for name := range versions {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Printf("            %s %s\n", name, versions[name])
+			}
+			for _, dep := range predicate.BuildDefinition.ResolvedDependencies {
+				if commit := dep.Digest["gitCommit"]; commit != "" {
+					fmt.Printf("  source    %s %s (%s)\n", orDash(dep.URI), commit, dep.Name)
+				}
+			}
+			metadata := predicate.RunDetails.Metadata
+			fmt.Printf("  built     %s (%s)\n", metadata.FinishedOn.Local().Format("2006-01-02 15:04"),
+				metadata.FinishedOn.Sub(metadata.StartedOn).Round(time.Second))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&provenance, "provenance", false, "Verify the signed provenance statement of the package")
+	cmd.Flags().StringVar(&attestation, "attestation", "", "Provenance statement (default: <package>.intoto.jsonl)")
+	cmd.Flags().StringVar(&publicKey, "key", "", "Public key (PEM) of the signer (default: provenance.pub in the settings directory)")
+	return cmd
+}
@@ -12,22 +12,63 @@ const (
 	configFile = "config.json"
 )
 
+// Secret storage options for type: secret variables
+const (
+	SecretStorageEnv      = "env"      // Plaintext in the deployed .env (default)
+	SecretStorageKeychain = "keychain" // OS keychain, referenced from the .env
+)
+
 // Config holds persistent user settings
 type Config struct {
-	SkillsFolder string `json:"skills_folder,omitempty"`
+	Version       int    `json:"version"` // Format version, see migrate.go
+	SkillsFolder  string `json:"skills_folder,omitempty"`
+	SecretStorage string `json:"secret_storage,omitempty"`
+	EncryptEnv    bool   `json:"encrypt_env,omitempty"` // Deploy .env.enc instead of a plaintext .env
+	Stats         bool   `json:"stats,omitempty"`       // Opt-in: record local usage statistics
+}
+
+// UseKeychain reports whether secrets should be stored in the OS keychain
+func (c *Config) UseKeychain() bool {
+	return c.SecretStorage == SecretStorageKeychain
+}
+
+// Dir returns the SkillFactory settings directory (~/.skillfactory)
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, configDir), nil
 }
 
 // getConfigPath returns the path to the config file
 func getConfigPath() (string, error) {
-	home, err := os.UserHomeDir()
+	dir, err := Dir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, configDir, configFile), nil
+	return filepath.Join(dir, configFile), nil
 }
 
-// Load reads the config from disk, returns empty config if not found
+// Load reads the config from disk, returns empty config if not found. The
+// skills folder falls back to the one in the global config file, and
+// SKILLFACTORY_SKILLS_FOLDER overrides it; ~ and $VARS in it are expanded.
 func Load() (*Config, error) {
+	cfg, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if settings, err := LoadSettings(); err == nil {
+		if cfg.SkillsFolder == "" || os.Getenv(SkillsFolderEnvVar) != "" {
+			cfg.SkillsFolder = settings.SkillsFolder
+		}
+	}
+	cfg.SkillsFolder = ExpandPath(cfg.SkillsFolder)
+	return cfg, nil
+}
+
+// load reads the saved config file
+func load() (*Config, error) {
 	path, err := getConfigPath()
 	if err != nil {
 		return &Config{}, nil
@@ -41,6 +82,16 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	if !json.Valid(data) {
+		return &Config{}, nil
+	}
+
+	// Upgrade files written by older versions, see migrate.go
+	data, _, err = migrateData(path, data, configMigrations, 0644, false)
+	if data == nil {
+		return nil, err
+	}
+
 	var cfg Config
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return &Config{}, nil
@@ -62,6 +113,7 @@ func (c *Config) Save() error {
 		return err
 	}
 
+	c.Version = ConfigVersion
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
 		return err
@@ -0,0 +1,438 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of a config value, see Entry
+const (
+	SourceDefault  = "default"
+	SourceFile     = "config.yaml"
+	SourceEnv      = "env"
+	SourceProfiles = "profiles"
+)
+
+// settingKeys maps the keys of the global config file to the environment
+// variables overriding them, empty for keys without one
+var settingKeys = map[string]string{
+	"skills_folder":      SkillsFolderEnvVar,
+	"parallel_builds":    ParallelBuildsEnvVar,
+	"theme":              ThemeEnvVar,
+	"test_before_deploy": "",
+	"keep_dist":          "",
+	"keep_builds":        "",
+	"keep_backups_days":  "",
+}
+
+// SettingKeys returns the keys of the global config file, sorted
+func SettingKeys() []string {
+	keys := make([]string, 0, len(settingKeys))
+	for key := range settingKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Entry is a config key with its effective value
+type Entry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // default, config.yaml, env or profiles
+}
+
+// Keys address either a setting of the global config file ("theme") or a
+// field of a skill profile:
+//
+//	profiles.<skill>.<profile>.values.<VARIABLE>
+//	profiles.<skill>.<profile>.skills_folder
+//	profiles.<skill>.<profile>.skill_folder_name
+type profileKey struct {
+	skill, profile, field, variable string
+}
+
+// parseProfileKey splits a profiles.* key, ok is false for other keys
+func parseProfileKey(key string) (profileKey, bool, error) {
+	rest, ok := strings.CutPrefix(key, "profiles.")
+	if !ok {
+		return profileKey{}, false, nil
+	}
+	parts := strings.SplitN(rest, ".", 4)
+	if len(parts) < 3 || parts[0] == "" || parts[1] == "" {
+		return profileKey{}, true, fmt.Errorf("invalid key %q (expected profiles.<skill>.<profile>.<field>)", key)
+	}
+	k := profileKey{skill: parts[0], profile: parts[1], field: parts[2]}
+	switch k.field {
+	case "skills_folder", "skill_folder_name":
+		if len(parts) == 3 {
+			return k, true, nil
+		}
+	case "values":
+		if len(parts) == 4 && parts[3] != "" {
+			k.variable = parts[3]
+			return k, true, nil
+		}
+	}
+	return profileKey{}, true, fmt.Errorf("invalid key %q (expected values.<VARIABLE>, skills_folder or skill_folder_name after the profile)", key)
+}
+
+// get returns the field of a profile addressed by k
+func (k profileKey) get(p *Profile) (string, bool) {
+	switch k.field {
+	case "skills_folder":
+		return p.SkillsFolder, p.SkillsFolder != ""
+	case "skill_folder_name":
+		return p.SkillFolderName, p.SkillFolderName != ""
+	}
+	v, ok := p.Values[k.variable]
+	return v, ok
+}
+
+// set changes the field of a profile addressed by k, unset if value is nil
+func (k profileKey) set(p *Profile, value *string) {
+	v := ""
+	if value != nil {
+		v = *value
+	}
+	switch k.field {
+	case "skills_folder":
+		p.SkillsFolder = v
+	case "skill_folder_name":
+		p.SkillFolderName = v
+	default:
+		if value == nil {
+			delete(p.Values, k.variable)
+			return
+		}
+		if p.Values == nil {
+			p.Values = make(map[string]string)
+		}
+		p.Values[k.variable] = v
+	}
+}
+
+// Get returns the effective value of a key
+func Get(key string) (Entry, error) {
+	if k, ok, err := parseProfileKey(key); ok {
+		if err != nil {
+			return Entry{}, err
+		}
+		profile, err := LoadProfile(k.skill, k.profile)
+		if err != nil {
+			return Entry{}, err
+		}
+		value, ok := k.get(profile)
+		if !ok {
+			return Entry{}, fmt.Errorf("%s is not set", key)
+		}
+		return Entry{Key: key, Value: value, Source: SourceProfiles}, nil
+	}
+
+	entries, err := settingEntries()
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, e := range entries {
+		if e.Key == key {
+			return e, nil
+		}
+	}
+	return Entry{}, unknownKey(key)
+}
+
+// Set changes a key. Settings are written to the global config file after
+// validating the result; profile fields create the profile if needed.
+func Set(key, value string) error {
+	if k, ok, err := parseProfileKey(key); ok {
+		if err != nil {
+			return err
+		}
+		return updateProfile(k, &value)
+	}
+	if _, ok := settingKeys[key]; !ok {
+		return unknownKey(key)
+	}
+	return updateSettingsFile(map[string]*string{key: &value})
+}
+
+// Unset removes a key, so its default applies again
+func Unset(key string) error {
+	if k, ok, err := parseProfileKey(key); ok {
+		if err != nil {
+			return err
+		}
+		return updateProfile(k, nil)
+	}
+	if _, ok := settingKeys[key]; !ok {
+		return unknownKey(key)
+	}
+	return updateSettingsFile(map[string]*string{key: nil})
+}
+
+// List returns the settings followed by the fields of all saved profiles
+func List() ([]Entry, error) {
+	entries, err := settingEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	skills, err := ProfileSkills()
+	if err != nil {
+		return nil, err
+	}
+	for _, skill := range skills {
+		profiles, err := LoadProfiles(skill)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range ProfileNames(profiles) {
+			p := profiles[name]
+			prefix := "profiles." + skill + "." + name + "."
+			if p.SkillsFolder != "" {
+				entries = append(entries, Entry{Key: prefix + "skills_folder", Value: p.SkillsFolder, Source: SourceProfiles})
+			}
+			if p.SkillFolderName != "" {
+				entries = append(entries, Entry{Key: prefix + "skill_folder_name", Value: p.SkillFolderName, Source: SourceProfiles})
+			}
+			vars := make([]string, 0, len(p.Values))
+			for v := range p.Values {
+				vars = append(vars, v)
+			}
+			sort.Strings(vars)
+			for _, v := range vars {
+				entries = append(entries, Entry{Key: prefix + "values." + v, Value: p.Values[v], Source: SourceProfiles})
+			}
+		}
+	}
+	return entries, nil
+}
+
+// settingEntries returns the effective settings and where each comes from
+func settingEntries() ([]Entry, error) {
+	settings, err := LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	file, err := readSettingsFile()
+	if err != nil {
+		return nil, err
+	}
+
+	values := settings.values()
+	var entries []Entry
+	for _, key := range SettingKeys() {
+		source := SourceDefault
+		switch {
+		case settingKeys[key] != "" && os.Getenv(settingKeys[key]) != "":
+			source = SourceEnv
+		case mappingKey(file, key) != nil:
+			source = SourceFile
+		}
+		entries = append(entries, Entry{Key: key, Value: values[key], Source: source})
+	}
+	return entries, nil
+}
+
+// values returns the settings by key of the global config file
+func (s *Settings) values() map[string]string {
+	return map[string]string{
+		"skills_folder":      s.SkillsFolder,
+		"parallel_builds":    fmt.Sprint(s.ParallelBuilds),
+		"theme":              s.Theme,
+		"test_before_deploy": fmt.Sprint(s.TestBeforeDeploy),
+		"keep_dist":          fmt.Sprint(s.KeepDist),
+		"keep_builds":        fmt.Sprint(s.KeepBuilds),
+		"keep_backups_days":  fmt.Sprint(s.KeepBackupsDays),
+	}
+}
+
+// SaveSettings writes the settings that differ from the global config file
+// to it, as returned by ReadSettings. Settings changed back to their default
+// are removed; other keys and comments are kept.
+func SaveSettings(s *Settings) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	saved, err := ReadSettings()
+	if err != nil {
+		return err
+	}
+
+	defaults := (&Settings{Theme: ThemeAuto}).values()
+	before := saved.values()
+	changes := make(map[string]*string)
+	for key, value := range s.values() {
+		switch {
+		case value == before[key]:
+		case value == defaults[key]:
+			changes[key] = nil
+		default:
+			changes[key] = &value
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return updateSettingsFile(changes)
+}
+
+// readSettingsFile returns the root mapping of the global config file, an
+// empty mapping if the file does not exist
+func readSettingsFile() (*yaml.Node, error) {
+	empty := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	path, err := SettingsPath()
+	if err != nil {
+		return empty, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return empty, nil
+		}
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		// Only comments, which the decoder drops
+		empty.HeadComment = strings.TrimSpace(string(data))
+		return empty, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s must contain a mapping", path)
+	}
+	return doc.Content[0], nil
+}
+
+// updateSettingsFile sets the keys in the global config file to their
+// values, or removes a key if its value is nil. Other keys and comments are
+// kept. The file is only written if the resulting settings are valid.
+func updateSettingsFile(changes map[string]*string) error {
+	path, err := SettingsPath()
+	if err != nil {
+		return err
+	}
+	root, err := readSettingsFile()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	comment := "" // Comments above removed last keys
+	for _, key := range keys {
+		value := changes[key]
+		found := false
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value != key {
+				continue
+			}
+			found = true
+			if value == nil {
+				// Keep a comment above the removed key
+				if i+2 < len(root.Content) {
+					next := root.Content[i+2]
+					next.HeadComment = strings.TrimSpace(root.Content[i].HeadComment + "\n" + next.HeadComment)
+				} else {
+					comment = strings.TrimSpace(comment + "\n" + root.Content[i].HeadComment)
+				}
+				root.Content = append(root.Content[:i], root.Content[i+2:]...)
+			} else {
+				root.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Value: *value, LineComment: root.Content[i+1].LineComment}
+			}
+			break
+		}
+		if !found && value != nil {
+			root.Content = append(root.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+				&yaml.Node{Kind: yaml.ScalarNode, Value: *value},
+			)
+		}
+	}
+
+	var buf bytes.Buffer
+	if len(root.Content) == 0 {
+		// An empty mapping would be written as {}
+		if comment != "" {
+			buf.WriteString(comment + "\n")
+		}
+	} else {
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	}
+
+	var s Settings
+	if err := yaml.Unmarshal(buf.Bytes(), &s); err != nil {
+		for _, key := range keys {
+			if value := changes[key]; value != nil {
+				return fmt.Errorf("invalid %s %q", key, *value)
+			}
+		}
+		return err
+	}
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// updateProfile sets or removes a profile field. A profile left without
+// values and deploy target is deleted.
+func updateProfile(k profileKey, value *string) error {
+	profiles, err := LoadProfiles(k.skill)
+	if err != nil {
+		return err
+	}
+	profile, ok := profiles[k.profile]
+	if !ok {
+		if value == nil {
+			return fmt.Errorf("profile %q not found for skill %s", k.profile, k.skill)
+		}
+		profile = &Profile{Values: make(map[string]string)}
+	}
+
+	k.set(profile, value)
+	if len(profile.Values) == 0 && profile.SkillsFolder == "" && profile.SkillFolderName == "" {
+		return DeleteProfile(k.skill, k.profile)
+	}
+	return SaveProfile(k.skill, k.profile, profile)
+}
+
+// mappingKey returns the value node of key in a mapping
+func mappingKey(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// unknownKey is the error for keys that are neither settings nor profile fields
+func unknownKey(key string) error {
+	return fmt.Errorf("unknown key %q (expected %s or profiles.<skill>.<profile>.<field>)", key, strings.Join(SettingKeys(), ", "))
+}
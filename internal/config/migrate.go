@@ -0,0 +1,157 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Format versions written by this build. Files without a version field are
+// version 0. Bump a version together with a new step in its migration list.
+const (
+	ConfigVersion   = 1 // config.json
+	ProfilesVersion = 1 // profiles/<skill>.json
+)
+
+// migration upgrades a decoded file from the previous format version to version
+type migration struct {
+	version     int
+	description string
+	apply       func(doc map[string]any) map[string]any
+}
+
+// configMigrations upgrade config.json
+var configMigrations = []migration{
+	{1, "add format version", func(doc map[string]any) map[string]any {
+		return doc
+	}},
+}
+
+// profilesMigrations upgrade the profile files of skills
+var profilesMigrations = []migration{
+	{1, `move profiles below "profiles"`, func(doc map[string]any) map[string]any {
+		return map[string]any{"profiles": doc}
+	}},
+}
+
+// Migration describes the upgrade of one file
+type Migration struct {
+	Path   string   // Migrated file
+	Backup string   // Copy of the previous file, empty for dry runs
+	From   int      // Format version before
+	To     int      // Format version after
+	Steps  []string // Descriptions of the applied steps
+}
+
+// Migrate upgrades config.json and all profile files to the current format
+// versions, keeping a backup of every changed file next to it. With dryRun
+// the files are left untouched. Files already up to date are not returned.
+func Migrate(dryRun bool) ([]Migration, error) {
+	var migrations []Migration
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	m, err := migrateFile(configPath, configMigrations, 0644, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if m != nil {
+		migrations = append(migrations, *m)
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, profilesDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		m, err := migrateFile(path, profilesMigrations, 0600, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			migrations = append(migrations, *m)
+		}
+	}
+	return migrations, nil
+}
+
+// migrateFile upgrades the file at path. A missing file needs no migration.
+func migrateFile(path string, migrations []migration, perm os.FileMode, dryRun bool) (*Migration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	_, m, err := migrateData(path, data, migrations, perm, dryRun)
+	return m, err
+}
+
+// migrateData upgrades the content of the file at path and returns the
+// current content. Unless dryRun, an upgraded file is written back after
+// copying the previous content to <path>.v<version>.bak. A failed write
+// leaves the file as it was; the upgraded content is still returned, so a
+// read-only settings directory does not stop SkillFactory from loading it.
+func migrateData(path string, data []byte, migrations []migration, perm os.FileMode, dryRun bool) ([]byte, *Migration, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	from := docVersion(doc)
+	latest := migrations[len(migrations)-1].version
+	if from > latest {
+		return nil, nil, fmt.Errorf("%s has format version %d, this SkillFactory supports up to %d: update SkillFactory", path, from, latest)
+	}
+	if from == latest {
+		return data, nil, nil
+	}
+
+	m := &Migration{Path: path, From: from, To: latest}
+	for _, step := range migrations {
+		if step.version <= from {
+			continue
+		}
+		doc = step.apply(doc)
+		doc["version"] = step.version
+		m.Steps = append(m.Steps, step.description)
+	}
+	migrated, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	if dryRun {
+		return migrated, m, nil
+	}
+
+	backup := fmt.Sprintf("%s.v%d.bak", path, from)
+	if err := os.WriteFile(backup, data, perm); err != nil {
+		return migrated, m, fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	m.Backup = backup
+	if err := os.WriteFile(path, migrated, perm); err != nil {
+		return migrated, m, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return migrated, m, nil
+}
+
+// docVersion returns the format version of a decoded file, 0 if unversioned
+func docVersion(doc map[string]any) int {
+	if v, ok := doc["version"].(float64); ok {
+		return int(v)
+	}
+	return 0
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const profilesDir = "profiles"
+
+// DefaultProfile is used when no profile name is given
+const DefaultProfile = "default"
+
+// Profile holds the variable values and deploy target of a named skill configuration
+type Profile struct {
+	Values          map[string]string `json:"values"`
+	SkillsFolder    string            `json:"skills_folder,omitempty"`
+	SkillFolderName string            `json:"skill_folder_name,omitempty"`
+}
+
+// profilesFile is the content of a skill's profiles file
+type profilesFile struct {
+	Version  int                 `json:"version"` // Format version, see migrate.go
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+// profilesPath returns the profiles file of a skill
+func profilesPath(skill string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profilesDir, skill+".json"), nil
+}
+
+// LoadProfiles reads all profiles of a skill, returns none if not found
+func LoadProfiles(skill string) (map[string]*Profile, error) {
+	profiles := make(map[string]*Profile)
+
+	path, err := profilesPath(skill)
+	if err != nil {
+		return profiles, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return profiles, nil
+		}
+		return nil, err
+	}
+
+	// Upgrade files written by older versions, see migrate.go
+	data, _, err = migrateData(path, data, profilesMigrations, 0600, false)
+	if data == nil {
+		return nil, err
+	}
+
+	var file profilesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles of %s: %w", skill, err)
+	}
+	if file.Profiles != nil {
+		profiles = file.Profiles
+	}
+	for _, p := range profiles {
+		p.SkillsFolder = ExpandPath(p.SkillsFolder)
+	}
+	return profiles, nil
+}
+
+// LoadProfile reads a single profile of a skill
+func LoadProfile(skill, name string) (*Profile, error) {
+	profiles, err := LoadProfiles(skill)
+	if err != nil {
+		return nil, err
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found for skill %s", name, skill)
+	}
+	return profile, nil
+}
+
+// SaveProfile stores a profile of a skill, replacing one with the same name.
+// The file is only readable by the user since values may contain secrets.
+func SaveProfile(skill, name string, profile *Profile) error {
+	profiles, err := LoadProfiles(skill)
+	if err != nil {
+		return err
+	}
+	profiles[name] = profile
+
+	path, err := profilesPath(skill)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(profilesFile{Version: ProfilesVersion, Profiles: profiles}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// DeleteProfile removes a profile of a skill, and the profiles file with
+// its last profile
+func DeleteProfile(skill, name string) error {
+	profiles, err := LoadProfiles(skill)
+	if err != nil {
+		return err
+	}
+	if _, ok := profiles[name]; !ok {
+		return fmt.Errorf("profile %q not found for skill %s", name, skill)
+	}
+	delete(profiles, name)
+
+	path, err := profilesPath(skill)
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		return os.Remove(path)
+	}
+	data, err := json.MarshalIndent(profilesFile{Version: ProfilesVersion, Profiles: profiles}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// ProfileSkills returns the sorted names of the skills with saved profiles
+func ProfileSkills() ([]string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, profilesDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	skills := make([]string, 0, len(paths))
+	for _, path := range paths {
+		skills = append(skills, strings.TrimSuffix(filepath.Base(path), ".json"))
+	}
+	sort.Strings(skills)
+	return skills, nil
+}
+
+// ProfileNames returns the sorted profile names
+func ProfileNames(profiles map[string]*Profile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SettingsEnvVar points to a settings file other than ~/.config/skillfactory/config.yaml
+const SettingsEnvVar = "SKILLFACTORY_CONFIG"
+
+// Environment variables overriding single settings for one invocation
+const (
+	SkillsFolderEnvVar    = "SKILLFACTORY_SKILLS_FOLDER"
+	ClaudeSkillsDirEnvVar = "CLAUDE_SKILLS_DIR" // Default skills folder if none is configured
+	ParallelBuildsEnvVar  = "SKILLFACTORY_PARALLEL_BUILDS"
+	ThemeEnvVar           = "SKILLFACTORY_THEME"
+)
+
+// TUI themes
+const (
+	ThemeAuto         = "auto"          // Dark or light colors following the terminal background (default)
+	ThemeDark         = "dark"          // Colors for a dark background
+	ThemeLight        = "light"         // Colors for a light background
+	ThemeHighContrast = "high-contrast" // Few, strong colors on the terminal's own foreground
+	ThemeMono         = "mono"          // No colors
+)
+
+// Themes lists the TUI themes in the order the Settings view cycles them
+var Themes = []string{ThemeAuto, ThemeDark, ThemeLight, ThemeHighContrast, ThemeMono}
+
+// Settings are user defaults from the global config file, written by hand,
+// with skillfactory config set or from the Settings view of the TUI (see
+// keys.go)
+type Settings struct {
+	SkillsFolder     string `yaml:"skills_folder"`      // Used when no skills folder was saved or passed
+	ParallelBuilds   int    `yaml:"parallel_builds"`    // Passed to go build -p, 0 uses the Go default
+	Theme            string `yaml:"theme"`              // One of Themes
+	TestBeforeDeploy bool   `yaml:"test_before_deploy"` // Run go test for every skill before building
+	KeepDist         bool   `yaml:"keep_dist"`          // Keep the build in dist/ after a TUI deploy
+	KeepBuilds       int    `yaml:"keep_builds"`        // Deployed builds archived in dist/builds per skill, 0 keeps none
+	KeepBackupsDays  int    `yaml:"keep_backups_days"`  // Back up replaced deployments and keep them this many days, 0 disables backups
+
+	// TUI key bindings replacing the defaults, e.g. "skills.edit": "ctrl+e"
+	Keys map[string]string `yaml:"keys"`
+}
+
+// SettingsPath returns the global config file: SKILLFACTORY_CONFIG if set,
+// otherwise $XDG_CONFIG_HOME/skillfactory/config.yaml (~/.config by default)
+func SettingsPath() (string, error) {
+	if path := os.Getenv(SettingsEnvVar); path != "" {
+		return path, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "skillfactory", "config.yaml"), nil
+}
+
+// LoadSettings reads the global config file and applies the SKILLFACTORY_*
+// environment overrides. A missing file yields the defaults.
+func LoadSettings() (*Settings, error) {
+	s, err := ReadSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	if v := os.Getenv(SkillsFolderEnvVar); v != "" {
+		s.SkillsFolder = v
+	}
+	if s.SkillsFolder == "" {
+		s.SkillsFolder = os.Getenv(ClaudeSkillsDirEnvVar)
+	}
+	s.SkillsFolder = ExpandPath(s.SkillsFolder)
+	if v := os.Getenv(ParallelBuildsEnvVar); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", ParallelBuildsEnvVar, v, err)
+		}
+		s.ParallelBuilds = n
+	}
+	if v := os.Getenv(ThemeEnvVar); v != "" {
+		s.Theme = v
+	}
+
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ReadSettings reads the global config file as written, without environment
+// overrides and with paths unexpanded. A missing file yields the defaults.
+func ReadSettings() (*Settings, error) {
+	s := &Settings{Theme: ThemeAuto}
+
+	path, err := SettingsPath()
+	if err != nil {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, s); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// validate checks the values of the settings
+func (s *Settings) validate() error {
+	if s.ParallelBuilds < 0 {
+		return fmt.Errorf("parallel_builds must not be negative")
+	}
+	if s.KeepBuilds < 0 {
+		return fmt.Errorf("keep_builds must not be negative")
+	}
+	if s.KeepBackupsDays < 0 {
+		return fmt.Errorf("keep_backups_days must not be negative")
+	}
+	if s.Theme == "" {
+		s.Theme = ThemeAuto
+	}
+	if !slices.Contains(Themes, s.Theme) {
+		return fmt.Errorf("invalid theme %q (expected one of %s)", s.Theme, strings.Join(Themes, ", "))
+	}
+	return nil
+}
+
+// ExpandPath expands environment variables ($VAR, ${VAR}) and a leading ~
+// in a path. Unset variables are left as they are.
+func ExpandPath(path string) string {
+	path = os.Expand(path, func(name string) string {
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		return "$" + name
+	})
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// ContractPath replaces the home directory at the start of path with ~,
+// for display
+func ContractPath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, home+string(filepath.Separator)); ok {
+		return "~/" + filepath.ToSlash(rest)
+	}
+	return path
+}
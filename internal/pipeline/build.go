@@ -0,0 +1,158 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// BuildArgs returns the go build arguments for a skill manifest
+func BuildArgs(manifest *skill.Manifest, outputPath string) []string {
+	args := []string{"build", "-o", outputPath}
+
+	if settings, err := config.LoadSettings(); err == nil && settings.ParallelBuilds > 0 {
+		args = append(args, "-p", strconv.Itoa(settings.ParallelBuilds))
+	}
+	if manifest.Build.Trimpath {
+		args = append(args, "-trimpath")
+	}
+	if len(manifest.Build.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(manifest.Build.Tags, ","))
+	}
+	if ldflags := buildLDFlags(manifest); ldflags != "" {
+		args = append(args, "-ldflags", ldflags)
+	}
+
+	entry := manifest.Build.Entry
+	if entry == "" {
+		entry = "."
+	}
+	return append(args, entry)
+}
+
+// BuildEnv returns the environment for go build
+func BuildEnv(manifest *skill.Manifest) []string {
+	env := os.Environ()
+	if manifest.Build.CGO != nil {
+		if *manifest.Build.CGO {
+			env = append(env, "CGO_ENABLED=1")
+		} else {
+			env = append(env, "CGO_ENABLED=0")
+		}
+	}
+	return env
+}
+
+// Build compiles a skill to outputPath and returns the combined go build output
+func Build(manifest *skill.Manifest, outputPath string) (string, error) {
+	var output strings.Builder
+	err := BuildTo(manifest, outputPath, &output)
+	return output.String(), err
+}
+
+// BuildTo compiles a skill to outputPath, writing the combined go build
+// output to out as it is produced
+func BuildTo(manifest *skill.Manifest, outputPath string, out io.Writer) error {
+	return BuildContext(context.Background(), manifest, outputPath, out)
+}
+
+// BuildContext is BuildTo stopped by ctx: canceling it kills go build with
+// the compiler processes it started and returns an error wrapping
+// context.Canceled
+func BuildContext(ctx context.Context, manifest *skill.Manifest, outputPath string, out io.Writer) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "go", BuildArgs(manifest, outputPath)...)
+	cmd.Dir = manifest.Path
+	cmd.Env = BuildEnv(manifest)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cancelProcessGroup(cmd)
+	cmd.WaitDelay = 5 * time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("build canceled: %w", ctx.Err())
+		}
+		return fmt.Errorf("build failed: %w", err)
+	}
+	return nil
+}
+
+// buildLDFlags returns the manifest ldflags, embedding the skill version as
+// main.version unless the manifest already sets it
+func buildLDFlags(manifest *skill.Manifest) string {
+	ldflags := expandBuildPlaceholders(manifest, manifest.Build.LDFlags)
+	if manifest.Version != "" && !strings.Contains(ldflags, "main.version=") {
+		ldflags = strings.TrimSpace(ldflags + " -X main.version=" + manifest.Version)
+	}
+	return ldflags
+}
+
+// expandBuildPlaceholders replaces {{version}}, {{commit}}, {{name}} and {{binary}}
+func expandBuildPlaceholders(manifest *skill.Manifest, s string) string {
+	version := manifest.Version
+	if version == "" {
+		version = "dev"
+	}
+
+	commit := GitCommit(manifest.Path)
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+
+	r := strings.NewReplacer(
+		"{{version}}", version,
+		"{{commit}}", commit,
+		"{{name}}", manifest.Name,
+		"{{binary}}", manifest.BinaryName(),
+	)
+	return r.Replace(s)
+}
+
+// TestsEnabled reports whether tests must pass before a skill is built
+func TestsEnabled(manifest *skill.Manifest, always bool) bool {
+	return always || manifest.Build.Test
+}
+
+// RunTests runs go test ./... in the skill directory with the manifest's
+// build tags and returns the combined output
+func RunTests(manifest *skill.Manifest) (string, error) {
+	return RunTestsContext(context.Background(), manifest)
+}
+
+// RunTestsContext is RunTests stopped by ctx like BuildContext
+func RunTestsContext(ctx context.Context, manifest *skill.Manifest) (string, error) {
+	args := []string{"test"}
+	if len(manifest.Build.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(manifest.Build.Tags, ","))
+	}
+	args = append(args, "./...")
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = manifest.Path
+	cmd.Env = BuildEnv(manifest)
+	cancelProcessGroup(cmd)
+	cmd.WaitDelay = 5 * time.Second
+
+	output, err := cmd.CombinedOutput()
+	log := "$ go " + strings.Join(args, " ") + "\n" + string(output)
+	if err != nil {
+		if ctx.Err() != nil {
+			return log, fmt.Errorf("tests canceled: %w", ctx.Err())
+		}
+		return log, fmt.Errorf("tests failed: %w", err)
+	}
+	return log, nil
+}
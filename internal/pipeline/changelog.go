@@ -0,0 +1,122 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// changesHeading starts the section of SKILL.md listing the changes since
+// the previous deploy
+const changesHeading = "## Recent changes"
+
+// maxChangeCommits limits the commits listed in the Recent changes section
+const maxChangeCommits = 10
+
+// RecentChanges returns the Recent changes section of SKILL.md for a
+// redeploy: the commands added, removed and changed since the deployed
+// build and the commits to the skill source since the deployed commit. If
+// nothing changed, the section of the previous deploy is kept. It returns ""
+// for a first deploy.
+func RecentChanges(opts DeployOptions) string {
+	lock, err := ReadLock(opts.DeployPath)
+	if err != nil {
+		return ""
+	}
+
+	var changes []string
+	deployed := filepath.Join(opts.DeployPath, "bin", opts.Manifest.BinaryName())
+	if opts.BinaryPath != "" && opts.BinaryPath != deployed {
+		if diff, err := DiffDeployed(opts.Manifest, opts.BinaryPath, opts.DeployPath); err == nil {
+			changes = append(changes, commandChangeLines(diff)...)
+		}
+	}
+	commits := commitsSince(opts.Manifest.Path, lock.GitCommit)
+
+	if len(changes) == 0 && len(commits) == 0 {
+		previous, err := os.ReadFile(filepath.Join(opts.DeployPath, DocsBaseFile))
+		if err != nil {
+			return ""
+		}
+		return extractChanges(string(previous))
+	}
+
+	var b strings.Builder
+	b.WriteString(changesHeading + "\n\n")
+	since := "the previous deploy"
+	if lock.SkillVersion != "" {
+		since = "version " + lock.SkillVersion
+	}
+	b.WriteString(fmt.Sprintf("Changes since %s, deployed %s:\n\n", since, lock.BuiltAt.Local().Format("2006-01-02")))
+	for _, line := range changes {
+		b.WriteString("- " + line + "\n")
+	}
+	if len(commits) > 0 {
+		if len(changes) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("Commits:\n\n")
+		for _, commit := range commits {
+			b.WriteString("- " + commit + "\n")
+		}
+	}
+	return b.String()
+}
+
+// commandChangeLines describes a command diff as list items
+func commandChangeLines(diff CommandDiff) []string {
+	var lines []string
+	for _, path := range diff.Added {
+		lines = append(lines, "New command `"+path+"`")
+	}
+	for _, path := range diff.Removed {
+		lines = append(lines, "Removed command `"+path+"`")
+	}
+	for _, change := range diff.Changed {
+		lines = append(lines, "`"+change.Path+"`: "+strings.Join(change.Changes, ", "))
+	}
+	return lines
+}
+
+// commitsSince returns the subjects of the commits touching dir since
+// commit, newest first, e.g. "3f9c2a1 Add archive command". It returns nil
+// without a commit or if git does not know it.
+func commitsSince(dir, commit string) []string {
+	if commit == "" {
+		return nil
+	}
+	cmd := exec.Command("git", "log", "--no-merges", fmt.Sprintf("--max-count=%d", maxChangeCommits),
+		"--format=%h %s", commit+"..HEAD", "--", ".")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	var commits []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			commits = append(commits, line)
+		}
+	}
+	return commits
+}
+
+// extractChanges returns the Recent changes section of a SKILL.md, up to the
+// next heading or the footer
+func extractChanges(docs string) string {
+	_, section, ok := strings.Cut(docs, "\n"+changesHeading+"\n")
+	if !ok {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(changesHeading + "\n")
+	for _, line := range strings.Split(section, "\n") {
+		if strings.HasPrefix(line, "## ") || line == "---" {
+			break
+		}
+		b.WriteString(line + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
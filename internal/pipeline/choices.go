@@ -0,0 +1,124 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+)
+
+// Choice is a value of a variable with source: skill and its label
+type Choice struct {
+	Value string
+	Label string
+}
+
+// Choices lists the values of a variable with source: skill by running its
+// command with the values entered so far, e.g. the projects of the account
+// behind the entered token. The skill is built to a temporary folder for
+// this and kept there until its source changes.
+func Choices(manifest *skill.Manifest, v skill.Variable, values map[string]string) ([]Choice, error) {
+	if !v.HasChoices() {
+		return nil, fmt.Errorf("%s has no source: skill", v.Name)
+	}
+	args, err := splitCommand(v.Command)
+	if err != nil {
+		return nil, err
+	}
+	binaryPath, err := choicesBinary(manifest)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := ResolveValues(manifest, values)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, binaryPath, args...)
+	cmd.Env = append(os.Environ(), skillkit.NoEnvFileEnvVar+"=1")
+	for name, value := range resolved {
+		if value != "" {
+			cmd.Env = append(cmd.Env, name+"="+value)
+		}
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%s timed out after %s", v.Command, commandTimeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s", v.Command, firstLine(msg))
+		}
+		return nil, fmt.Errorf("%s: %w", v.Command, err)
+	}
+	return parseChoices(output, v)
+}
+
+// choicesBinary builds the skill for Choices, reusing the binary of an
+// earlier call while the source is unchanged
+func choicesBinary(manifest *skill.Manifest) (string, error) {
+	hash, err := SkillHash(manifest)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(os.TempDir(), "skillfactory-choices", manifest.Name, hash[:12])
+	binaryPath := filepath.Join(dir, manifest.BinaryName())
+	if _, err := os.Stat(binaryPath); err == nil {
+		return binaryPath, nil
+	}
+
+	// Binaries of older sources are no longer needed
+	os.RemoveAll(filepath.Dir(dir))
+	var output strings.Builder
+	if err := BuildTo(manifest, binaryPath, &output); err != nil {
+		if msg := strings.TrimSpace(output.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, firstLine(msg))
+		}
+		return "", err
+	}
+	return binaryPath, nil
+}
+
+// parseChoices reads the choices from a JSON array of objects, with the
+// fields option_value (default id) and option_label (default title or name)
+func parseChoices(output []byte, v skill.Variable) ([]Choice, error) {
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(output, &rows); err != nil {
+		return nil, fmt.Errorf("%s did not print a JSON list of objects", v.Command)
+	}
+	valueField := v.OptionValue
+	if valueField == "" {
+		valueField = "id"
+	}
+
+	choices := make([]Choice, 0, len(rows))
+	for _, row := range rows {
+		value := dataCell(row[valueField])
+		if value == "" {
+			continue
+		}
+		label := dataCell(row[v.OptionLabel])
+		if v.OptionLabel == "" {
+			label = dataCell(row["title"])
+			if label == "" {
+				label = dataCell(row["name"])
+			}
+		}
+		choices = append(choices, Choice{Value: value, Label: label})
+	}
+	if len(choices) == 0 && len(rows) > 0 {
+		return nil, fmt.Errorf("%s printed no field %q", v.Command, valueField)
+	}
+	return choices, nil
+}
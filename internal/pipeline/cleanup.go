@@ -0,0 +1,255 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// Retention is the cleanup policy of the global config file for archived
+// builds and backups of replaced deployments
+type Retention struct {
+	KeepBuilds      int // Deployed builds kept in dist/builds per skill, 0 keeps none
+	KeepBackupsDays int // Days a backup is kept, 0 disables backups
+}
+
+// RetentionFrom returns the cleanup policy of the settings
+func RetentionFrom(s *config.Settings) Retention {
+	return Retention{KeepBuilds: s.KeepBuilds, KeepBackupsDays: s.KeepBackupsDays}
+}
+
+// archiveTimeFormat names the directory of an archived build or a backup
+const archiveTimeFormat = "20060102-150405"
+
+// BuildsDir returns the archive of deployed builds, one directory per skill
+func BuildsDir(projectRoot string) string {
+	return filepath.Join(projectRoot, "dist", "builds")
+}
+
+// BackupsDir returns the directory holding the backups of replaced
+// deployments, one directory per skill folder
+func BackupsDir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "backups"), nil
+}
+
+// BackupDeployment copies an existing deployment to the backups directory
+// before it is replaced and returns the backup, empty if nothing is deployed
+// at deployPath. Backups hold the .env with its secrets, so they are only
+// accessible to the user.
+func BackupDeployment(deployPath string) (string, error) {
+	if _, err := os.Stat(filepath.Join(deployPath, LockFile)); err != nil {
+		return "", nil
+	}
+	dir, err := BackupsDir()
+	if err != nil {
+		return "", err
+	}
+	skillDir := filepath.Join(dir, filepath.Base(deployPath))
+	if err := os.MkdirAll(skillDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	// Directories created by earlier versions were readable by everyone
+	if err := os.Chmod(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	backup, err := newBackupDir(skillDir, time.Now().Format(archiveTimeFormat))
+	if err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if err := copyDir(deployPath, backup); err != nil {
+		os.RemoveAll(backup)
+		return "", fmt.Errorf("failed to back up %s: %w", deployPath, err)
+	}
+	return backup, nil
+}
+
+// newBackupDir creates the backup directory name in dir, adding a counter
+// if a backup of the same second exists
+func newBackupDir(dir, name string) (string, error) {
+	for i := 1; ; i++ {
+		path := filepath.Join(dir, name)
+		if i > 1 {
+			path = fmt.Sprintf("%s-%d", path, i)
+		}
+		err := os.Mkdir(path, 0700)
+		if err == nil {
+			return path, nil
+		}
+		if !os.IsExist(err) {
+			return "", err
+		}
+	}
+}
+
+// ArchiveBuild copies a deployed build to dist/builds/<skill>/<time>/ if
+// the policy keeps builds
+func ArchiveBuild(projectRoot string, manifest *skill.Manifest, binaryPath string, r Retention) error {
+	if r.KeepBuilds == 0 {
+		return nil
+	}
+	dir := filepath.Join(BuildsDir(projectRoot), manifest.Name, time.Now().Format(archiveTimeFormat))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := copyFile(binaryPath, filepath.Join(dir, manifest.BinaryName()), 0755); err != nil {
+		return fmt.Errorf("failed to archive build: %w", err)
+	}
+	return nil
+}
+
+// Clean removes the archived builds beyond the newest KeepBuilds per skill
+// and the backups older than KeepBackupsDays. With dryRun nothing is
+// removed. It returns the removed directories.
+func Clean(projectRoot string, r Retention, dryRun bool) ([]string, error) {
+	var removed []string
+	remove := func(path string) error {
+		removed = append(removed, path)
+		if dryRun {
+			return nil
+		}
+		return os.RemoveAll(path)
+	}
+
+	// Archives are named by time, so the newest sort last
+	err := forEachArchive(BuildsDir(projectRoot), func(archives []string) error {
+		for _, path := range archives[:max(len(archives)-r.KeepBuilds, 0)] {
+			if err := remove(path); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return removed, err
+	}
+
+	backups, err := BackupsDir()
+	if err != nil {
+		return removed, err
+	}
+	cutoff := time.Now().AddDate(0, 0, -r.KeepBackupsDays)
+	err = forEachArchive(backups, func(archives []string) error {
+		for _, path := range archives {
+			// Backups of the same second carry a counter after the time
+			name := filepath.Base(path)
+			created, err := time.ParseInLocation(archiveTimeFormat, name[:min(len(name), len(archiveTimeFormat))], time.Local)
+			if err != nil || !created.Before(cutoff) {
+				continue
+			}
+			if err := remove(path); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// CleanDist removes the loose builds in dist/, keeping the archive in
+// dist/builds
+func CleanDist(projectRoot string) error {
+	distDir := filepath.Join(projectRoot, "dist")
+	entries, err := os.ReadDir(distDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() == "builds" {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(distDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forEachArchive calls fn with the sorted archive directories of every
+// subdirectory of dir (<dir>/<name>/<time>)
+func forEachArchive(dir string, fn func(archives []string) error) error {
+	groups, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, group := range groups {
+		if !group.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(dir, group.Name()))
+		if err != nil {
+			return err
+		}
+		var archives []string
+		for _, entry := range entries {
+			if entry.IsDir() {
+				archives = append(archives, filepath.Join(dir, group.Name(), entry.Name()))
+			}
+		}
+		sort.Strings(archives)
+		if err := fn(archives); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyDir copies the regular files of src to dst for a backup. Directories
+// are created 0700 and files keep their mode without group and others.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0700)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return copyFile(path, target, info.Mode().Perm()&0700)
+	})
+}
+
+// copyFile copies a file, creating dst with perm
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,335 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+)
+
+// DeployOptions describes where and how a built skill is deployed
+type DeployOptions struct {
+	Manifest    *skill.Manifest
+	BinaryPath  string            // Built binary to deploy
+	DeployPath  string            // Skills folder + skill folder name
+	Values      map[string]string // Configured variable values
+	UseKeychain bool              // Store secret variables in the OS keychain
+	EncryptEnv  bool              // Deploy .env.enc instead of a plaintext .env
+	Docs        string            // Prebuilt SKILL.md ({{SKILL_PATH}} is replaced), generated if empty
+	DocsMode    string            // Handling of a hand-edited SKILL.md, see DocsModes; overwrite if empty
+	Backup      bool              // Copy the replaced deployment to BackupsDir first
+	Progress    func(DeployStep)  // Receives the start and outcome of every deploy step, optional
+
+	changes string            // Recent changes section of SKILL.md, see RecentChanges
+	data    map[string]string // Sections of docs.data already run, see RefreshDocs
+}
+
+// skillFolder returns the name of the deployed skill folder
+func (o DeployOptions) skillFolder() string {
+	return filepath.Base(o.DeployPath)
+}
+
+// Deploy copies the binary, writes the .env and SKILL.md and records
+// deploy.lock, reporting each step to opts.Progress
+func Deploy(opts DeployOptions) (*Lock, error) {
+	if opts.DeployPath == "" {
+		return nil, fmt.Errorf("deploy path not configured")
+	}
+
+	binaryName := opts.Manifest.BinaryName()
+	dstBinDir := filepath.Join(opts.DeployPath, "bin")
+	dstBinary := filepath.Join(dstBinDir, binaryName)
+
+	// Keep a copy of the deployment about to be replaced
+	if opts.Backup {
+		err := RunStep(opts.Progress, StepBackup, func() error {
+			_, err := BackupDeployment(opts.DeployPath)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Ensure destination directories exist
+	os.MkdirAll(dstBinDir, 0755)
+
+	// Compare with the deployed build before it is replaced
+	if opts.Docs == "" {
+		opts.changes = RecentChanges(opts)
+	}
+
+	// Copy binary (remove old one first to avoid issues with running processes)
+	var binaryData []byte
+	err := RunStep(opts.Progress, StepBinary, func() error {
+		var err error
+		if binaryData, err = os.ReadFile(opts.BinaryPath); err != nil {
+			return fmt.Errorf("failed to read binary: %w", err)
+		}
+
+		// Remove existing binary first to ensure clean overwrite
+		os.Remove(dstBinary)
+
+		if err := os.WriteFile(dstBinary, binaryData, 0755); err != nil {
+			return fmt.Errorf("failed to write binary: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate .env file with environment variables. Command variables and
+	// secret references are resolved for the .env only, SKILL.md and
+	// deploy.lock keep the saved values.
+	err = RunStep(opts.Progress, StepEnv, func() error {
+		values, err := ResolveValues(opts.Manifest, opts.Values)
+		if err != nil {
+			return err
+		}
+		envOpts := opts
+		envOpts.Values = values
+		envContent, err := deployEnvFile(envOpts)
+		if err != nil {
+			return err
+		}
+		return writeEnvFile(opts, dstBinDir, envContent)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate SKILL.md
+	err = RunStep(opts.Progress, StepDocs, func() error {
+		previous, _ := ReadLock(opts.DeployPath)
+		if err := GenerateDocs(opts, DocsEdited(opts.DeployPath, previous)); err != nil {
+			return fmt.Errorf("failed to generate docs: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Record checksum and build metadata in deploy.lock
+	lock := ExpectedLock(opts.Manifest, opts.Values)
+	err = RunStep(opts.Progress, StepLock, func() error {
+		lock.BinarySHA256 = HashBytes(binaryData)
+		lock.DocsSHA256, _ = HashFile(filepath.Join(opts.DeployPath, DocsBaseFile))
+		lock.BuiltAt = time.Now().UTC()
+		if err := lock.Write(opts.DeployPath); err != nil {
+			return fmt.Errorf("failed to write %s: %w", LockFile, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// ExpectedLock returns the lock describing the build inputs of a configuration
+func ExpectedLock(manifest *skill.Manifest, values map[string]string) *Lock {
+	sourceHash, _ := SkillHash(manifest)
+	envFile, _ := EnvFile(manifest, values)
+	git := GitStatus(manifest.Path)
+
+	return &Lock{
+		Skill:        manifest.Name,
+		SkillVersion: manifest.Version,
+		SourceSHA256: sourceHash,
+		ConfigSHA256: HashBytes([]byte(envFile)),
+		GoVersion:    GoVersion(manifest.Path),
+		GitCommit:    git.Commit,
+		GitBranch:    git.Branch,
+		GitDirty:     git.Dirty,
+	}
+}
+
+// EnvFile creates a .env file with environment variables
+func EnvFile(manifest *skill.Manifest, values map[string]string) (string, error) {
+	var b strings.Builder
+
+	b.WriteString("# Auto-generated environment file\n")
+
+	for _, v := range manifest.Variables {
+		if value, ok := values[v.Name]; ok && value != "" {
+			b.WriteString(fmt.Sprintf("%s=%s\n", v.Name, value))
+		}
+	}
+	redact, err := redactEnv(manifest)
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(redact)
+
+	return b.String(), nil
+}
+
+// redactEnv returns the SKILLKIT_REDACT line passing the redact rules of
+// skill.yaml to the skill, empty without rules. The JSON is double-quoted:
+// the fields may contain quotes, backslashes and $.
+func redactEnv(manifest *skill.Manifest) (string, error) {
+	if len(manifest.Redact) == 0 {
+		return "", nil
+	}
+	rules := make([]skillkit.RedactRule, len(manifest.Redact))
+	for i, rule := range manifest.Redact {
+		rules[i] = skillkit.RedactRule(rule)
+	}
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode redact rules: %w", err)
+	}
+	return fmt.Sprintf("%s=%s\n", skillkit.RedactEnvVar, envQuote(string(data))), nil
+}
+
+// envQuoter escapes the characters godotenv unescapes in double-quoted values
+var envQuoter = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`, "\r", `\r`)
+
+// envQuote double-quotes s for a .env file read by godotenv
+func envQuote(s string) string {
+	return `"` + envQuoter.Replace(s) + `"`
+}
+
+// ResolveValues returns values with the variables of source "command" set to
+// the output of their command, run in the skill directory, and secret backend
+// references replaced by the secrets. A value entered for a command variable
+// takes precedence over the command.
+func ResolveValues(manifest *skill.Manifest, values map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(values))
+	for name, value := range values {
+		resolved[name] = value
+	}
+
+	for _, v := range manifest.Variables {
+		if v.Backend != "" && resolved[v.Name] != "" {
+			secret, err := ResolveSecret(v.Backend, resolved[v.Name])
+			if err != nil {
+				return nil, fmt.Errorf("variable %s: %w", v.Name, err)
+			}
+			resolved[v.Name] = secret
+			continue
+		}
+		if !v.FromCommand() || resolved[v.Name] != "" {
+			continue
+		}
+		if v.Command == "" {
+			return nil, fmt.Errorf("variable %s: source command without command", v.Name)
+		}
+
+		cmd := shellCommand(v.Command)
+		cmd.Dir = manifest.Path
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf("variable %s: %s: %w: %s", v.Name, v.Command, err, msg)
+			}
+			return nil, fmt.Errorf("variable %s: %s: %w", v.Name, v.Command, err)
+		}
+
+		value := strings.TrimRight(string(output), "\r\n")
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("variable %s: %s printed more than one line", v.Name, v.Command)
+		}
+		if value == "" {
+			value = v.Default
+		}
+		if v.Required && value == "" {
+			return nil, fmt.Errorf("variable %s: %s printed nothing", v.Name, v.Command)
+		}
+		resolved[v.Name] = value
+	}
+	return resolved, nil
+}
+
+// deployEnvFile creates the deployed .env. With keychain storage enabled, secret
+// variables are written to the OS keychain and the .env only holds references.
+func deployEnvFile(opts DeployOptions) (string, error) {
+	if !opts.UseKeychain {
+		return EnvFile(opts.Manifest, opts.Values)
+	}
+
+	var b strings.Builder
+	b.WriteString("# Auto-generated environment file\n")
+
+	service := skillkit.KeychainService(opts.skillFolder())
+	for _, v := range opts.Manifest.Variables {
+		value, ok := opts.Values[v.Name]
+		if !ok || value == "" {
+			continue
+		}
+		if v.Type == "secret" {
+			if err := skillkit.SetSecret(service, v.Name, value); err != nil {
+				return "", fmt.Errorf("failed to store %s in keychain: %w", v.Name, err)
+			}
+			value = skillkit.KeychainRef(service, v.Name)
+		}
+		b.WriteString(fmt.Sprintf("%s=%s\n", v.Name, value))
+	}
+	redact, err := redactEnv(opts.Manifest)
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(redact)
+
+	return b.String(), nil
+}
+
+// writeEnvFile writes the .env, or the encrypted .env.enc if enabled, and
+// removes the other variant left over from a previous deploy
+func writeEnvFile(opts DeployOptions, binDir string, content string) error {
+	envPath := filepath.Join(binDir, ".env")
+	encPath := filepath.Join(binDir, skillkit.EncryptedEnvFile)
+
+	if !opts.EncryptEnv {
+		os.Remove(encPath)
+		if err := os.WriteFile(envPath, []byte(content), 0600); err != nil {
+			return fmt.Errorf("failed to write .env: %w", err)
+		}
+		return nil
+	}
+
+	passphrase, err := envPassphrase(opts.skillFolder())
+	if err != nil {
+		return err
+	}
+	data, err := skillkit.EncryptEnv([]byte(content), passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt .env: %w", err)
+	}
+	if err := os.WriteFile(encPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", skillkit.EncryptedEnvFile, err)
+	}
+	os.Remove(envPath)
+	return nil
+}
+
+// envPassphrase returns the passphrase for .env.enc: SKILL_ENV_PASSPHRASE if
+// set, otherwise the one in the keychain, generating it on first deploy
+func envPassphrase(skillFolder string) (string, error) {
+	if p := os.Getenv(skillkit.PassphraseEnvVar); p != "" {
+		return p, nil
+	}
+
+	service := skillkit.KeychainService(skillFolder)
+	if p, err := skillkit.GetSecret(service, skillkit.PassphraseAccount); err == nil && p != "" {
+		return p, nil
+	}
+
+	p, err := skillkit.GeneratePassphrase()
+	if err != nil {
+		return "", err
+	}
+	if err := skillkit.SetSecret(service, skillkit.PassphraseAccount, p); err != nil {
+		return "", fmt.Errorf("failed to store .env passphrase in keychain (set %s instead): %w", skillkit.PassphraseEnvVar, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,167 @@
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"gopkg.in/yaml.v3"
+)
+
+// DeployedSkill describes a skill found in a skills folder
+type DeployedSkill struct {
+	Folder       string // Folder name in the skills folder
+	Path         string
+	Name         string // From deploy.lock or SKILL.md, falls back to the folder name
+	Description  string // From the SKILL.md frontmatter
+	Version      string
+	Binary       string // Path of the deployed binary, empty if missing
+	BinarySize   int64
+	DeployedAt   time.Time // Build time from deploy.lock, binary mtime without lock
+	HasLock      bool
+	SourceExists bool // A skill with this name exists in the repository
+}
+
+// ScanDeployed lists the deployed skills in a skills folder. Folders without
+// bin/, SKILL.md or deploy.lock are ignored. sources are the skills of the
+// repository, used to check whether the source still exists.
+func ScanDeployed(skillsFolder string, sources []*skill.Manifest) ([]DeployedSkill, error) {
+	entries, err := os.ReadDir(skillsFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceNames := make(map[string]bool)
+	for _, manifest := range sources {
+		sourceNames[manifest.Name] = true
+	}
+
+	var skills []DeployedSkill
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		deployPath := filepath.Join(skillsFolder, entry.Name())
+		if _, err := RemovalPaths(deployPath); err != nil {
+			continue
+		}
+		d := inspectDeployed(deployPath)
+		d.SourceExists = sourceNames[d.Name]
+		skills = append(skills, d)
+	}
+
+	sort.Slice(skills, func(i, j int) bool { return skills[i].Folder < skills[j].Folder })
+	return skills, nil
+}
+
+// inspectDeployed reads deploy.lock, SKILL.md and the binary of a deployed skill
+func inspectDeployed(deployPath string) DeployedSkill {
+	d := DeployedSkill{
+		Folder: filepath.Base(deployPath),
+		Path:   deployPath,
+	}
+
+	name, description := readSkillFrontmatter(filepath.Join(deployPath, "SKILL.md"))
+	d.Description = description
+
+	lock, err := ReadLock(deployPath)
+	if err == nil {
+		d.HasLock = true
+		d.Name = lock.Skill
+		d.Version = lock.SkillVersion
+		d.DeployedAt = lock.BuiltAt
+	}
+	d.Name = firstNonEmpty(d.Name, name, d.Folder)
+
+	if binary, info := findDeployedBinary(filepath.Join(deployPath, "bin"), d.Name); binary != "" {
+		d.Binary = binary
+		d.BinarySize = info.Size()
+		if d.DeployedAt.IsZero() {
+			d.DeployedAt = info.ModTime()
+		}
+		if d.Version == "" {
+			d.Version = DeployedVersion(deployPath, filepath.Base(binary))
+		}
+	}
+	return d
+}
+
+// findDeployedBinary returns the binary in binDir: the file named after the
+// skill, or the only non-hidden file
+func findDeployedBinary(binDir, name string) (string, os.FileInfo) {
+	if info, err := os.Stat(filepath.Join(binDir, name)); err == nil && info.Mode().IsRegular() {
+		return filepath.Join(binDir, name), info
+	}
+
+	entries, err := os.ReadDir(binDir)
+	if err != nil {
+		return "", nil
+	}
+	var binary string
+	var binaryInfo os.FileInfo
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") || !entry.Type().IsRegular() {
+			continue
+		}
+		if binary != "" {
+			return "", nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return "", nil
+		}
+		binary, binaryInfo = filepath.Join(binDir, entry.Name()), info
+	}
+	return binary, binaryInfo
+}
+
+// readSkillFrontmatter returns name and description from the frontmatter of a SKILL.md
+func readSkillFrontmatter(path string) (name, description string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", ""
+	}
+	content, ok := strings.CutPrefix(string(data), "---\n")
+	if !ok {
+		return "", ""
+	}
+	end := strings.Index(content, "\n---")
+	if end < 0 {
+		return "", ""
+	}
+
+	var frontmatter struct {
+		Name        string `yaml:"name"`
+		Description string `yaml:"description"`
+	}
+	if err := yaml.Unmarshal([]byte(content[:end]), &frontmatter); err != nil {
+		return "", ""
+	}
+	return frontmatter.Name, frontmatter.Description
+}
+
+// FormatSize formats a byte count as KB or MB
+func FormatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return strconv.FormatFloat(float64(size)/(1<<20), 'f', 1, 64) + " MB"
+	case size >= 1<<10:
+		return strconv.FormatInt(size>>10, 10) + " KB"
+	default:
+		return strconv.FormatInt(size, 10) + " B"
+	}
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package pipeline
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Dependency update modes for UpdateDeps
+const (
+	UpdatePatch = "patch"
+	UpdateMinor = "minor"
+)
+
+// DepChange describes a dependency whose version changed
+type DepChange struct {
+	Path string
+	Old  string // Empty if the dependency was added
+	New  string // Empty if the dependency was removed
+}
+
+// ModuleFile returns the go.mod path of the module containing dir
+func ModuleFile(dir string) (string, error) {
+	output, err := runGo(dir, "env", "GOMOD")
+	if err != nil {
+		return "", err
+	}
+	gomod := strings.TrimSpace(output)
+	if gomod == "" || gomod == "/dev/null" || gomod == "NUL" {
+		return "", fmt.Errorf("no go.mod found for %s", dir)
+	}
+	return gomod, nil
+}
+
+// ListModules returns path → version for all dependencies of the module containing dir
+func ListModules(dir string) (map[string]string, error) {
+	output, err := runGo(dir, "list", "-m", "-f", "{{if not .Main}}{{.Path}} {{.Version}}{{end}}", "all")
+	if err != nil {
+		return nil, fmt.Errorf("go list failed: %w\n%s", err, output)
+	}
+
+	modules := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Fields(line)
+		if len(parts) == 2 {
+			modules[parts[0]] = parts[1]
+		}
+	}
+	return modules, nil
+}
+
+// DiffModules compares two ListModules results, sorted by module path
+func DiffModules(before, after map[string]string) []DepChange {
+	var changes []DepChange
+	for path, oldVersion := range before {
+		if newVersion := after[path]; newVersion != oldVersion {
+			changes = append(changes, DepChange{Path: path, Old: oldVersion, New: newVersion})
+		}
+	}
+	for path, newVersion := range after {
+		if _, ok := before[path]; !ok {
+			changes = append(changes, DepChange{Path: path, New: newVersion})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// UpdateDeps runs go get -u (minor) or go get -u=patch (patch) for the skill packages
+func UpdateDeps(dir string, mode string) (string, error) {
+	var flag string
+	switch mode {
+	case UpdateMinor:
+		flag = "-u"
+	case UpdatePatch:
+		flag = "-u=patch"
+	default:
+		return "", fmt.Errorf("invalid update mode %q (use %s or %s)", mode, UpdatePatch, UpdateMinor)
+	}
+
+	output, err := runGo(dir, "get", flag, "./...")
+	if err != nil {
+		return output, fmt.Errorf("go get failed: %w", err)
+	}
+	return output, nil
+}
+
+// Tidy runs go mod tidy in dir
+func Tidy(dir string) (string, error) {
+	output, err := runGo(dir, "mod", "tidy")
+	if err != nil {
+		return output, fmt.Errorf("go mod tidy failed: %w", err)
+	}
+	return output, nil
+}
+
+// Test runs go test ./... in dir
+func Test(dir string) (string, error) {
+	output, err := runGo(dir, "test", "./...")
+	if err != nil {
+		return output, fmt.Errorf("tests failed: %w", err)
+	}
+	return output, nil
+}
+
+// runGo executes the go tool in dir and returns the combined output
+func runGo(dir string, args ...string) (string, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
@@ -0,0 +1,212 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// Diagnosis is a recognized cause of a failed build with its remedy
+type Diagnosis struct {
+	Cause  string   // What went wrong
+	Advice string   // How to fix it
+	Fix    []string // go arguments fixing it, nil if it must be fixed by hand
+}
+
+// FixCommand returns the fix as a command line, e.g. "go mod tidy"
+func (d Diagnosis) FixCommand() string {
+	if len(d.Fix) == 0 {
+		return ""
+	}
+	return "go " + strings.Join(d.Fix, " ")
+}
+
+var (
+	// "missing go.sum entry for module providing package ...",
+	// "go: updates to go.mod needed; to update it: go mod tidy"
+	missingSumPattern = regexp.MustCompile(`missing go\.sum entry|updates to go\.mod needed|go\.mod file indicates replacement`)
+	// "no required module provides package example.com/x; to add it: ..."
+	missingModulePattern = regexp.MustCompile(`no required module provides package (\S+?);`)
+	// "package example.com/x/tasks is not in std (/usr/lib/go/src/...)",
+	// "module example.com/x/tasks: reading https://proxy.golang.org/...: 404 Not Found"
+	unknownPackagePattern = regexp.MustCompile(`package (\S+) is not in std|module (\S+): reading \S+: \d+|cannot find module providing package (\S+)`)
+	// "go: go.mod requires go >= 1.25.1 (running go 1.22.0; GOTOOLCHAIN=local)"
+	goVersionPattern = regexp.MustCompile(`go\.mod requires go >= (\S+) \(running go (\S+?);`)
+	// "undefined: tasks.NewService", "undefined: newServeCmd"
+	undefinedPattern = regexp.MustCompile(`undefined: (?:\w+\.)?(\w+)`)
+)
+
+// DiagnoseBuild recognizes common causes of a failed build of manifest in
+// its output: missing go.sum entries, a Go version the go.mod requires,
+// imports of a renamed module and symbols excluded by build tags. Each cause
+// is reported once.
+func DiagnoseBuild(output string, manifest *skill.Manifest) []Diagnosis {
+	var diagnoses []Diagnosis
+	seen := make(map[string]bool)
+	add := func(d Diagnosis) {
+		if !seen[d.Cause] {
+			seen[d.Cause] = true
+			diagnoses = append(diagnoses, d)
+		}
+	}
+
+	if match := goVersionPattern.FindStringSubmatch(output); match != nil {
+		add(toolchainDiagnosis(match[1], match[2]))
+	}
+
+	modulePath, moduleRoot := skillModule(manifest.Path)
+	var imports []string
+	missing := missingModulePattern.FindAllStringSubmatch(output, -1)
+	for _, m := range missing {
+		imports = append(imports, m[1])
+	}
+	for _, m := range unknownPackagePattern.FindAllStringSubmatch(output, -1) {
+		imports = append(imports, m[1]+m[2]+m[3])
+	}
+	renamed := false
+	for _, path := range imports {
+		if old := renamedModule(path, modulePath, moduleRoot); old != "" {
+			renamed = true
+			add(Diagnosis{
+				Cause:  fmt.Sprintf("Imports use the module path %s, but go.mod declares %s", old, modulePath),
+				Advice: fmt.Sprintf("Replace the %q import prefix with %q in the .go files (or rename the module back in go.mod)", old, modulePath),
+			})
+		}
+	}
+
+	if !renamed && (missingSumPattern.MatchString(output) || len(missing) > 0) {
+		add(Diagnosis{
+			Cause:  "go.mod or go.sum is out of date",
+			Advice: "Add the missing requirements and checksums with go mod tidy, then build again",
+			Fix:    []string{"mod", "tidy"},
+		})
+	}
+
+	for _, m := range undefinedPattern.FindAllStringSubmatch(output, -1) {
+		if file, tags := taggedDefinition(manifest.Path, m[1]); file != "" {
+			add(Diagnosis{
+				Cause:  fmt.Sprintf("%s is defined in %s, which needs the build constraint %q", m[1], file, tags),
+				Advice: "Add the tags to build.tags in skill.yaml, or move the definition to a file without constraint",
+			})
+		}
+	}
+	return diagnoses
+}
+
+// toolchainDiagnosis suggests getting the Go version a go.mod requires.
+// Go downloads it itself unless GOTOOLCHAIN forces the local toolchain.
+func toolchainDiagnosis(required, running string) Diagnosis {
+	d := Diagnosis{Cause: fmt.Sprintf("go.mod requires Go %s, the installed Go is %s", required, running)}
+	env := os.Getenv("GOTOOLCHAIN")
+	switch {
+	case strings.Contains(env, "auto"):
+		d.Advice = fmt.Sprintf("Go could not download Go %s, check the network or install it yourself", required)
+	case env != "":
+		d.Advice = fmt.Sprintf("GOTOOLCHAIN=%s is set in the environment, unset it or set it to go%s to let Go download the toolchain, or install Go %s", env, required, required)
+	default:
+		d.Advice = fmt.Sprintf("Let Go download Go %s when needed (GOTOOLCHAIN=auto in go env), or install it yourself", required)
+		d.Fix = []string{"env", "-w", "GOTOOLCHAIN=auto"}
+	}
+	return d
+}
+
+// skillModule returns the module path and root directory of the module
+// containing dir, empty if there is none
+func skillModule(dir string) (string, string) {
+	gomod, err := ModuleFile(dir)
+	if err != nil {
+		return "", ""
+	}
+	data, err := os.ReadFile(gomod)
+	if err != nil {
+		return "", ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if path, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(path), `"`), filepath.Dir(gomod)
+		}
+	}
+	return "", ""
+}
+
+// renamedModule returns the old module path of an import of a package of
+// this module under another module path, e.g. "github.com/old/skill" for
+// "github.com/old/skill/tasks" when the module root has a tasks directory
+func renamedModule(importPath, modulePath, moduleRoot string) string {
+	if modulePath == "" || importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/") {
+		return ""
+	}
+	parts := strings.Split(importPath, "/")
+	for i := 1; i < len(parts); i++ {
+		dir := filepath.Join(moduleRoot, filepath.Join(parts[i:]...))
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return strings.Join(parts[:i], "/")
+		}
+	}
+	return ""
+}
+
+// definitionPattern matches the declaration of a top-level identifier
+const definitionPattern = `(?m)^(?:func(?: \([^)]*\))?|type|var|const) %s\b`
+
+// taggedDefinition looks for the declaration of name in a .go file of dir
+// with a //go:build constraint and returns the file and the constraint
+func taggedDefinition(dir, name string) (string, string) {
+	definition := regexp.MustCompile(fmt.Sprintf(definitionPattern, regexp.QuoteMeta(name)))
+	var file, tags string
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if file != "" {
+			return filepath.SkipAll
+		}
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || !definition.Match(data) {
+			return nil
+		}
+		// The constraint is in the header before the package clause
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(line, "package ") {
+				break
+			}
+			if constraint, ok := strings.CutPrefix(line, "//go:build "); ok {
+				file, _ = filepath.Rel(dir, path)
+				tags = strings.TrimSpace(constraint)
+				break
+			}
+		}
+		return nil
+	})
+	return file, tags
+}
+
+// RunFix runs the fix of a diagnosis in the skill directory and returns
+// its output
+func RunFix(manifest *skill.Manifest, d Diagnosis) (string, error) {
+	if len(d.Fix) == 0 {
+		return "", fmt.Errorf("%s has no automatic fix", d.Cause)
+	}
+	cmd := exec.Command("go", d.Fix...)
+	cmd.Dir = manifest.Path
+	output, err := cmd.CombinedOutput()
+	log := "$ " + d.FixCommand() + "\n" + string(output)
+	if err != nil {
+		return log, fmt.Errorf("%s failed: %w", d.FixCommand(), err)
+	}
+	return log, nil
+}
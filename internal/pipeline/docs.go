@@ -0,0 +1,706 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+	"gopkg.in/yaml.v3"
+)
+
+// GenerateDocs writes the SKILL.md of a deployment and the other formats
+// listed in docs.formats. edited reports a deployed SKILL.md changed by hand,
+// which is handled according to opts.DocsMode.
+func GenerateDocs(opts DeployOptions, edited bool) error {
+	content := opts.Docs
+	if content == "" {
+		content = RenderDocs(opts)
+	} else {
+		content = strings.ReplaceAll(content, "{{SKILL_PATH}}", opts.DeployPath)
+	}
+
+	// Write SKILL.md
+	if err := writeSkillDocs(opts, content, edited); err != nil {
+		return err
+	}
+	return generateFormats(opts)
+}
+
+// RenderDocs returns the SKILL.md content generated from the manifest's docs template
+func RenderDocs(opts DeployOptions) string {
+	// Read template if exists
+	templatePath := filepath.Join(opts.Manifest.Path, opts.Manifest.Docs.Template)
+	var content string
+
+	templateData, err := os.ReadFile(templatePath)
+	if err != nil {
+		// No template, generate basic docs
+		content = generateBasicDocs(opts.Manifest)
+	} else {
+		content = string(templateData)
+		// Remove any existing frontmatter from template
+		content = stripFrontmatter(content)
+		// Replace placeholders
+		content = replacePlaceholders(opts, content)
+	}
+
+	// Examples and variable reference at their placeholders, appended if the
+	// template has none
+	content = insertSection(content, "{{EXAMPLES}}", generateExamples(opts))
+	content = insertSection(content, "{{VARIABLES}}", generateVariables(opts.Manifest))
+	content = insertSection(content, "{{REQUIREMENTS}}", generateRequirements(opts.Manifest))
+	content = insertSection(content, "{{CHANGES}}", opts.changes)
+
+	if secrets := generateSecrets(opts); secrets != "" {
+		content = strings.TrimRight(content, "\n") + "\n\n" + secrets
+	}
+
+	if footer := generateFooter(opts.Manifest); footer != "" {
+		content = strings.TrimRight(content, "\n") + "\n\n---\n\n" + footer
+	}
+
+	// Prepend generated frontmatter from skill.yaml
+	frontmatter := generateFrontmatter(opts.Manifest)
+	return frontmatter + content
+}
+
+// generateFrontmatter creates YAML frontmatter from skill manifest: name and
+// description followed by the frontmatter keys of skill.yaml
+func generateFrontmatter(manifest *skill.Manifest) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	b.WriteString(fmt.Sprintf("name: %s\n", manifest.Name))
+	b.WriteString(fmt.Sprintf("description: %s\n", manifest.GetSkillDescription()))
+	b.WriteString(extraFrontmatter(manifest))
+	b.WriteString("---\n\n")
+	return b.String()
+}
+
+// extraFrontmatter renders the frontmatter keys of skill.yaml as YAML,
+// except name and description
+func extraFrontmatter(manifest *skill.Manifest) string {
+	node := manifest.Frontmatter
+	if node.Kind != yaml.MappingNode || len(node.Content) == 0 {
+		return ""
+	}
+	extra := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i].Value; key != "name" && key != "description" {
+			extra.Content = append(extra.Content, node.Content[i], node.Content[i+1])
+		}
+	}
+	if len(extra.Content) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(extra); err != nil {
+		return ""
+	}
+	enc.Close()
+	return b.String()
+}
+
+// generateFooter records the git state of the skill source the docs were built from
+func generateFooter(manifest *skill.Manifest) string {
+	git := GitStatus(manifest.Path)
+	if git.Commit == "" {
+		return ""
+	}
+
+	footer := "Built from commit `" + git.ShortCommit() + "`"
+	if git.Branch != "" {
+		footer += " on branch `" + git.Branch + "`"
+	}
+	if git.Dirty {
+		footer += " with uncommitted changes"
+	}
+	return "_" + footer + "_\n"
+}
+
+// insertSection replaces placeholder in content with section, or appends the
+// section if content has no placeholder
+func insertSection(content, placeholder, section string) string {
+	if strings.Contains(content, placeholder) {
+		return strings.Replace(content, placeholder, section, 1)
+	}
+	if section == "" {
+		return content
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + section
+}
+
+// generateExamples renders the examples of skill.yaml with the deployed
+// binary path and their canned output
+func generateExamples(opts DeployOptions) string {
+	if len(opts.Manifest.Examples) == 0 {
+		return ""
+	}
+	binaryName := opts.Manifest.BinaryName()
+	binaryPath := filepath.Join(opts.DeployPath, "bin", binaryName)
+
+	var b strings.Builder
+	b.WriteString("## Examples\n\n")
+	for _, e := range opts.Manifest.Examples {
+		command := strings.TrimSpace(e.Command)
+		if rest, ok := strings.CutPrefix(command, binaryName+" "); ok {
+			command = rest
+		}
+		if e.Description != "" {
+			b.WriteString(e.Description + "\n\n")
+		}
+		b.WriteString("```bash\n" + binaryPath + " " + command + "\n```\n\n")
+		if output := strings.TrimSpace(e.Output); output != "" {
+			b.WriteString("Output:\n\n```json\n" + output + "\n```\n\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// generateVariables documents the variables of skill.yaml as a table. Examples
+// come from placeholder or default, configured values are never included.
+func generateVariables(manifest *skill.Manifest) string {
+	if len(manifest.Variables) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("## Configuration\n\n")
+	b.WriteString("Environment variables read from the skill's `.env`, set when deploying with SkillFactory:\n\n")
+	b.WriteString("| Variable | Label | Required | Description | Example |\n")
+	b.WriteString("|----------|-------|----------|-------------|---------|\n")
+	for _, v := range manifest.Variables {
+		required := "no"
+		if v.Required {
+			required = "yes"
+		}
+		example := v.Placeholder
+		if example == "" && v.Type != "secret" {
+			example = v.Default
+		}
+		if example != "" {
+			example = "`" + example + "`"
+		}
+		b.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s | %s |\n",
+			v.Name, tableCell(v.Label), required, tableCell(v.Description), tableCell(example)))
+	}
+	return b.String()
+}
+
+// generateRequirements lists the external tools of skill.yaml the skill runs
+func generateRequirements(manifest *skill.Manifest) string {
+	if len(manifest.Requires) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("## Requirements\n\n")
+	b.WriteString("The skill runs these tools, which must be installed and in PATH:\n\n")
+	for _, tool := range manifest.Requires {
+		b.WriteString("- `" + tool + "`\n")
+	}
+	return b.String()
+}
+
+// tableCell escapes a value for a markdown table cell
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// generateSecrets documents where variables resolved at deploy time come
+// from: the secret backend reference or the command, never the value
+func generateSecrets(opts DeployOptions) string {
+	var b strings.Builder
+	for _, v := range opts.Manifest.Variables {
+		switch {
+		case v.Backend != "" && opts.Values[v.Name] != "":
+			b.WriteString(fmt.Sprintf("- `%s`: %s `%s`\n", v.Name, BackendNames[v.Backend], opts.Values[v.Name]))
+		case v.FromCommand() && opts.Values[v.Name] == "":
+			b.WriteString(fmt.Sprintf("- `%s`: output of `%s`\n", v.Name, v.Command))
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "## Secrets\n\nResolved at deploy time, redeploy to pick up changed values:\n\n" + b.String()
+}
+
+// stripFrontmatter removes existing YAML frontmatter from content
+func stripFrontmatter(content string) string {
+	if !strings.HasPrefix(content, "---") {
+		return content
+	}
+	// Find the closing ---
+	rest := content[3:]
+	idx := strings.Index(rest, "---")
+	if idx == -1 {
+		return content
+	}
+	// Return everything after the closing --- and any leading newlines
+	result := strings.TrimLeft(rest[idx+3:], "\n")
+	return result
+}
+
+// generateBasicDocs generates basic documentation
+func generateBasicDocs(manifest *skill.Manifest) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("# %s\n\n", manifest.Name))
+	b.WriteString(manifest.Description)
+	b.WriteString("\n\n")
+
+	b.WriteString("## Commands\n\n")
+	b.WriteString("Run `" + manifest.BinaryName() + " --help` to see available commands.\n")
+
+	return b.String()
+}
+
+// replacePlaceholders replaces template placeholders
+func replacePlaceholders(opts DeployOptions, content string) string {
+	binaryName := opts.Manifest.BinaryName()
+
+	// Replace SKILL_PATH placeholder
+	content = strings.Replace(content, "{{SKILL_PATH}}", opts.DeployPath, -1)
+
+	// Replace PROJECT_IDS_TABLE if we have PROJECT_IDS configured
+	if projectIDs, ok := opts.Values[ProjectIDsVariable]; ok && projectIDs != "" {
+		table := generateProjectIDsTable(projectIDs)
+		content = strings.Replace(content, "{{PROJECT_IDS_TABLE}}", table, 1)
+	} else {
+		content = strings.Replace(content, "{{PROJECT_IDS_TABLE}}", "No project IDs configured.", 1)
+	}
+
+	// Extract commands from built binary
+	// Generate commands with binary path for SKILL.md (binary loads .env automatically)
+	deployedBinaryPath := filepath.Join(opts.DeployPath, "bin", binaryName)
+	commands := extractCommands(opts.BinaryPath, deployedBinaryPath, opts.Manifest.Docs.Depth())
+	content = strings.Replace(content, "{{COMMANDS}}", commands, 1)
+
+	// Live data of the docs.data commands, e.g. the user's project IDs
+	return replaceData(opts, content)
+}
+
+// extractCommands documents all commands of the built binary with their flags,
+// up to maxDepth levels of subcommands. Skills registering
+// skillkit.DocsCommand describe themselves as JSON; for others the --help
+// output is parsed recursively.
+// binaryPath is the built binary, displayPath is what to show in docs
+func extractCommands(binaryPath string, displayPath string, maxDepth int) string {
+	if docs, err := describeBinary(binaryPath); err == nil && len(docs.Commands) > 0 {
+		var b strings.Builder
+		for _, cmd := range docs.Commands {
+			if len(strings.Fields(cmd.Path)) <= maxDepth {
+				b.WriteString(formatCommandDoc(displayPath, cmd))
+			}
+		}
+		return b.String()
+	}
+
+	// Run binary --help to get top-level help
+	output, err := runHelp(binaryPath)
+	if err != nil {
+		return "Run `" + displayPath + " --help` to see available commands."
+	}
+
+	var b strings.Builder
+	seen := map[string]bool{output: true}
+	extractHelpCommands(&b, binaryPath, displayPath, nil, parseSubcommands(output), maxDepth, seen)
+
+	if b.Len() == 0 {
+		return "Run `" + displayPath + " --help` to see available commands."
+	}
+
+	return b.String()
+}
+
+// extractHelpCommands documents the subcommands below parent by parsing their
+// --help output. Leaf commands and commands at maxDepth are written to b.
+// seen holds the help texts already visited, so a binary printing the same
+// help for unknown arguments cannot recurse forever.
+func extractHelpCommands(b *strings.Builder, binaryPath, displayPath string, parent, subcommands []string, maxDepth int, seen map[string]bool) {
+	for _, sub := range subcommands {
+		path := append(slices.Clone(parent), sub)
+		output, err := runHelp(binaryPath, path...)
+		if err != nil || seen[output] {
+			continue
+		}
+		seen[output] = true
+
+		children := parseSubcommands(output)
+		if len(children) == 0 || len(path) >= maxDepth {
+			b.WriteString(formatCommand(displayPath, strings.Join(path, " "), output))
+			continue
+		}
+		extractHelpCommands(b, binaryPath, displayPath, path, children, maxDepth, seen)
+	}
+}
+
+// describeBinary runs the hidden skillkit docs command of a skill binary
+func describeBinary(binaryPath string) (*skillkit.Docs, error) {
+	cmd := exec.Command(binaryPath, skillkit.DocsCommandName, "--"+skillkit.NoEnvFileFlag)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var docs skillkit.Docs
+	if err := json.Unmarshal(output, &docs); err != nil {
+		return nil, err
+	}
+	return &docs, nil
+}
+
+// formatCommandDoc formats a command described by skillkit like formatCommand
+func formatCommandDoc(displayPath string, cmd skillkit.CommandDoc) string {
+	var b strings.Builder
+
+	b.WriteString("### " + cmd.Path + "\n\n")
+	if cmd.Description != "" {
+		b.WriteString(cmd.Description + "\n\n")
+	}
+	b.WriteString("**Usage:** `" + displayPath + " " + cmd.Usage + "`\n\n")
+
+	if len(cmd.Flags) > 0 {
+		b.WriteString("**Flags:**\n")
+		for _, f := range cmd.Flags {
+			name := "--" + f.Name
+			if f.Shorthand != "" {
+				name = "-" + f.Shorthand + ", " + name
+			}
+			line := "`" + name + "`"
+			if f.Type != "bool" {
+				line += " (" + f.Type + ")"
+			}
+			if f.Usage != "" {
+				line += ": " + f.Usage
+			}
+			if f.Default != "" {
+				line += " (default " + f.Default + ")"
+			}
+			b.WriteString("- " + line + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// runHelp executes a command with --help and returns the output
+func runHelp(binaryPath string, args ...string) (string, error) {
+	cmdArgs := append(args, "--help")
+	cmd := exec.Command(binaryPath, cmdArgs...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// formatCommand formats a leaf command with its description and flags
+func formatCommand(displayPath string, cmdPath string, helpOutput string) string {
+	var b strings.Builder
+
+	// Parse the help output
+	description := parseDescription(helpOutput)
+	usage := parseUsage(helpOutput)
+	flags := parseFlags(helpOutput)
+
+	b.WriteString("### " + cmdPath + "\n\n")
+
+	if description != "" {
+		b.WriteString(description + "\n\n")
+	}
+
+	if usage != "" {
+		b.WriteString("**Usage:** `" + displayPath + " " + usage + "`\n\n")
+	}
+
+	if len(flags) > 0 {
+		b.WriteString("**Flags:**\n")
+		for _, flag := range flags {
+			b.WriteString("- " + flag + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// parseDescription extracts the description from Cobra help output (first non-empty line)
+func parseDescription(helpOutput string) string {
+	lines := strings.Split(helpOutput, "\n")
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "Usage:") {
+			return trimmed
+		}
+		if strings.HasPrefix(trimmed, "Usage:") {
+			break
+		}
+	}
+	return ""
+}
+
+// parseUsage extracts the usage pattern from Cobra help output
+func parseUsage(helpOutput string) string {
+	lines := strings.Split(helpOutput, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "Usage:") {
+			// Get the next line or the rest of this line
+			if i+1 < len(lines) {
+				usage := strings.TrimSpace(lines[i+1])
+				// Remove the binary path prefix, keep just the command pattern
+				parts := strings.Fields(usage)
+				if len(parts) > 1 {
+					// Skip the binary name, return the rest
+					return strings.Join(parts[1:], " ")
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// parseFlags extracts flags from Cobra help output
+func parseFlags(helpOutput string) []string {
+	var flags []string
+	lines := strings.Split(helpOutput, "\n")
+	inFlagsSection := false
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "Flags:") {
+			inFlagsSection = true
+			continue
+		}
+
+		// End flags section at next section or empty line followed by non-flag
+		if inFlagsSection {
+			if trimmed == "" {
+				continue
+			}
+			if strings.HasSuffix(trimmed, ":") {
+				break
+			}
+
+			// Parse flag line: "  -t, --title string   Task title (required)"
+			if strings.HasPrefix(trimmed, "-") {
+				// Format: `-short, --long type   description`
+				flag := formatFlag(trimmed)
+				if flag != "" && !strings.Contains(flag, "--help") {
+					flags = append(flags, flag)
+				}
+			}
+		}
+	}
+
+	return flags
+}
+
+// formatFlag formats a Cobra flag line into a readable format
+func formatFlag(line string) string {
+	// Input: "  -t, --title string    Task title (required)"
+	// Output: "`-t, --title` (string): Task title (required)"
+
+	parts := strings.Fields(line)
+	if len(parts) < 2 {
+		return ""
+	}
+
+	var flagPart string
+	var typePart string
+	var descParts []string
+
+	i := 0
+	// Collect flag names (-t, --title)
+	for i < len(parts) && (strings.HasPrefix(parts[i], "-") || parts[i] == ",") {
+		if parts[i] != "," {
+			if flagPart != "" {
+				flagPart += ", "
+			}
+			flagPart += strings.TrimSuffix(parts[i], ",")
+		}
+		i++
+	}
+
+	// Next part might be type (string, int, etc.) or description
+	if i < len(parts) {
+		// Common types in Cobra
+		commonTypes := []string{"string", "int", "int64", "bool", "float64", "duration", "stringArray", "intSlice"}
+		isType := false
+		for _, t := range commonTypes {
+			if parts[i] == t {
+				isType = true
+				break
+			}
+		}
+		if isType {
+			typePart = parts[i]
+			i++
+		}
+	}
+
+	// Rest is description
+	if i < len(parts) {
+		descParts = parts[i:]
+	}
+
+	result := "`" + flagPart + "`"
+	if typePart != "" {
+		result += " (" + typePart + ")"
+	}
+	if len(descParts) > 0 {
+		result += ": " + strings.Join(descParts, " ")
+	}
+
+	return result
+}
+
+// parseSubcommands extracts subcommand names from Cobra help output
+func parseSubcommands(helpText string) []string {
+	var commands []string
+
+	lines := strings.Split(helpText, "\n")
+	inCommandsSection := false
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		// Start of commands section
+		if strings.HasPrefix(trimmed, "Available Commands:") {
+			inCommandsSection = true
+			continue
+		}
+
+		// End of commands section (empty line or new section)
+		if inCommandsSection {
+			if trimmed == "" || strings.HasSuffix(trimmed, ":") {
+				if strings.HasSuffix(trimmed, ":") && !strings.HasPrefix(trimmed, "Available") {
+					break
+				}
+				continue
+			}
+
+			// Parse command name (first word)
+			parts := strings.Fields(trimmed)
+			if len(parts) > 0 {
+				cmdName := parts[0]
+				// Skip help and completion commands
+				if cmdName != "help" && cmdName != "completion" {
+					commands = append(commands, cmdName)
+				}
+			}
+		}
+	}
+
+	return commands
+}
+
+// ProjectIDsVariable is rendered as a table at {{PROJECT_IDS_TABLE}}
+const ProjectIDsVariable = "PROJECT_IDS"
+
+// ProjectID is an entry of the PROJECT_IDS variable
+type ProjectID struct {
+	Name string
+	ID   string
+}
+
+// ParseProjectIDs parses the PROJECT_IDS variable, either a {"Name": id} map
+// or an array of objects with id and name (or title) fields, sorted by name
+func ParseProjectIDs(jsonStr string) ([]ProjectID, error) {
+	dec := json.NewDecoder(strings.NewReader(jsonStr))
+	dec.UseNumber()
+	var raw any
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after the value")
+	}
+
+	var entries []ProjectID
+	switch v := raw.(type) {
+	case map[string]any:
+		for name, id := range v {
+			s, ok := projectIDValue(id)
+			if !ok {
+				return nil, fmt.Errorf("invalid ID for %q (expected a number or string)", name)
+			}
+			entries = append(entries, ProjectID{Name: name, ID: s})
+		}
+	case []any:
+		for i, item := range v {
+			obj, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("entry %d is not an object", i+1)
+			}
+			var entry ProjectID
+			var hasID bool
+			for key, value := range obj {
+				switch strings.ToLower(key) {
+				case "id":
+					entry.ID, hasID = projectIDValue(value)
+				case "name", "title":
+					if s, ok := value.(string); ok && entry.Name == "" {
+						entry.Name = s
+					}
+				}
+			}
+			if !hasID || entry.Name == "" {
+				return nil, fmt.Errorf("entry %d needs an id and a name or title", i+1)
+			}
+			entries = append(entries, entry)
+		}
+	default:
+		return nil, fmt.Errorf(`expected {"Name": id} or [{"id": ..., "name": ...}]`)
+	}
+
+	slices.SortFunc(entries, func(a, b ProjectID) int {
+		if c := strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return entries, nil
+}
+
+// projectIDValue formats a JSON number or string ID
+func projectIDValue(v any) (string, bool) {
+	switch id := v.(type) {
+	case json.Number:
+		return id.String(), true
+	case string:
+		return id, id != ""
+	}
+	return "", false
+}
+
+// generateProjectIDsTable generates a markdown table from PROJECT_IDS JSON.
+// Malformed JSON is documented as is, with the parse error.
+func generateProjectIDsTable(jsonStr string) string {
+	entries, err := ParseProjectIDs(jsonStr)
+	if err != nil {
+		return fmt.Sprintf("Invalid PROJECT_IDS (%s): `%s`", err, jsonStr)
+	}
+	if len(entries) == 0 {
+		return "No project IDs configured."
+	}
+
+	var b strings.Builder
+	b.WriteString("| ID | Projekt |\n")
+	b.WriteString("|----|---------|\n")
+	for _, e := range entries {
+		b.WriteString(fmt.Sprintf("| %s | %s |\n", tableCell(e.ID), tableCell(e.Name)))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
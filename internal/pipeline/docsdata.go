@@ -0,0 +1,212 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// commandTimeout limits a docs.data or healthcheck command, which usually
+// calls an API
+const commandTimeout = 30 * time.Second
+
+// dataPlaceholder matches {{DATA:name}} in a docs template
+var dataPlaceholder = regexp.MustCompile(`\{\{DATA:([A-Za-z0-9_-]+)\}\}`)
+
+// replaceData fills the {{DATA:name}} placeholders with the output of the
+// docs.data commands of skill.yaml. Only these commands run, with the
+// deployed binary and its .env; a placeholder without an entry or a failed
+// command is documented with the reason instead of failing the deploy.
+func replaceData(opts DeployOptions, content string) string {
+	binaryPath := filepath.Join(opts.DeployPath, "bin", opts.Manifest.BinaryName())
+	return dataPlaceholder.ReplaceAllStringFunc(content, func(placeholder string) string {
+		name := dataPlaceholder.FindStringSubmatch(placeholder)[1]
+		if section, ok := opts.data[name]; ok {
+			return section
+		}
+		data, ok := opts.Manifest.Docs.DataCommand(name)
+		if !ok {
+			return fmt.Sprintf("_No docs.data entry %q in skill.yaml._", name)
+		}
+		section, err := dataSection(binaryPath, data)
+		if err != nil {
+			return fmt.Sprintf("_Not available at deploy time (%s), run `%s %s` to list them._",
+				err, binaryPath, data.Command)
+		}
+		return section
+	})
+}
+
+// dataSection runs a docs.data command and renders its output
+func dataSection(binaryPath string, data skill.DocsData) (string, error) {
+	output, err := runDeployedCommand(binaryPath, data.Command)
+	if err != nil {
+		return "", err
+	}
+	return dataTable(output, data.Columns)
+}
+
+// RefreshDocs regenerates the docs of a deployment like RegenerateDocs to
+// update the output of the docs.data commands. Nothing is written if one of
+// them fails, so an unreachable API keeps the tables of the last run.
+func RefreshDocs(opts DeployOptions) error {
+	binaryPath := filepath.Join(opts.DeployPath, "bin", opts.Manifest.BinaryName())
+	opts.data = make(map[string]string)
+	for _, data := range opts.Manifest.Docs.Data {
+		section, err := dataSection(binaryPath, data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", data.Command, err)
+		}
+		opts.data[data.Name] = section
+	}
+	return RegenerateDocs(opts)
+}
+
+// runDeployedCommand runs a command of the deployed binary, which reads its
+// .env, and returns the output
+func runDeployedCommand(binaryPath, command string) ([]byte, error) {
+	args, err := splitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(binaryPath); err != nil {
+		return nil, fmt.Errorf("skill not deployed yet")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, binaryPath, args...)
+	cmd.Dir = filepath.Dir(binaryPath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("timed out after %s", commandTimeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s", firstLine(msg))
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+// dataTable renders JSON output as a markdown table: an array of objects
+// with a column per field, other values as a JSON code block
+func dataTable(output []byte, columns []string) (string, error) {
+	var rows []json.RawMessage
+	err := json.Unmarshal(output, &rows)
+	if err == nil && len(rows) == 0 {
+		return "_None._", nil
+	}
+	if err != nil || rows[0][0] != '{' {
+		if !json.Valid(output) {
+			return "", fmt.Errorf("output is not JSON")
+		}
+		var b bytes.Buffer
+		json.Indent(&b, bytes.TrimSpace(output), "", "  ")
+		return "```json\n" + b.String() + "\n```", nil
+	}
+
+	if len(columns) == 0 {
+		columns = objectKeys(rows[0])
+	}
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(columns, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat("----|", len(columns)) + "\n")
+	for _, row := range rows {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(row, &fields); err != nil {
+			return "", fmt.Errorf("output is not a list of objects")
+		}
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = tableCell(dataCell(fields[column]))
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// dataCell formats a JSON value for a table cell: strings without quotes,
+// other values as JSON
+func dataCell(value json.RawMessage) string {
+	var s string
+	if json.Unmarshal(value, &s) == nil {
+		return s
+	}
+	if string(value) == "null" {
+		return ""
+	}
+	return string(value)
+}
+
+// objectKeys returns the keys of a JSON object in their order
+func objectKeys(object json.RawMessage) []string {
+	dec := json.NewDecoder(bytes.NewReader(object))
+	if _, err := dec.Token(); err != nil {
+		return nil
+	}
+	var keys []string
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return keys
+		}
+		keys = append(keys, key.(string))
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return keys
+		}
+	}
+	return keys
+}
+
+// splitCommand splits the arguments of a skill command at spaces, keeping
+// quoted arguments like --filter "done = false" together
+func splitCommand(command string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range command {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", command)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return args, nil
+}
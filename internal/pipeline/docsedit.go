@@ -0,0 +1,125 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// DocsBaseFile keeps the SKILL.md generated by the last deploy at the deploy
+// path. Its hash is recorded in deploy.lock; a SKILL.md differing from it was
+// edited by hand, and it is the base when merging those edits.
+const DocsBaseFile = ".SKILL.base.md"
+
+// How Deploy treats a SKILL.md edited by hand since the last deploy
+const (
+	DocsOverwrite = "overwrite" // Replace it with the generated SKILL.md (default)
+	DocsKeep      = "keep"      // Leave it as it is
+	DocsMerge     = "merge"     // Merge the edits into the generated SKILL.md
+)
+
+// DocsModes lists the values of DeployOptions.DocsMode
+var DocsModes = []string{DocsOverwrite, DocsKeep, DocsMerge}
+
+// DocsEdited reports whether the SKILL.md at deployPath was changed since
+// the deploy recorded in lock. Deployments without a recorded hash are
+// never reported.
+func DocsEdited(deployPath string, lock *Lock) bool {
+	if lock == nil || lock.DocsSHA256 == "" {
+		return false
+	}
+	hash, err := HashFile(filepath.Join(deployPath, "SKILL.md"))
+	return err == nil && hash != lock.DocsSHA256
+}
+
+// writeSkillDocs writes the generated SKILL.md content to the deploy path,
+// honoring opts.DocsMode if the deployed SKILL.md was edited by hand
+func writeSkillDocs(opts DeployOptions, content string, edited bool) error {
+	outputPath := filepath.Join(opts.DeployPath, "SKILL.md")
+	basePath := filepath.Join(opts.DeployPath, DocsBaseFile)
+
+	docs := []byte(content)
+	if edited {
+		switch opts.DocsMode {
+		case DocsKeep:
+			// The base stays, so the edits are still detected next time
+			return nil
+		case DocsMerge:
+			merged, err := mergeDocs(outputPath, basePath, docs)
+			if err != nil {
+				return err
+			}
+			docs = merged
+		}
+	}
+
+	if err := os.WriteFile(outputPath, docs, 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(basePath, []byte(content), 0644)
+}
+
+// mergeDocs merges the changes between the base file and the edited SKILL.md
+// into the generated content with git merge-file. Conflicting changes are an
+// error, leaving the edited SKILL.md untouched.
+func mergeDocs(editedPath, basePath string, generated []byte) ([]byte, error) {
+	if _, err := os.Stat(basePath); err != nil {
+		return nil, fmt.Errorf("cannot merge SKILL.md: %s of the last deploy is missing", DocsBaseFile)
+	}
+
+	tmp, err := os.CreateTemp("", "skillfactory-SKILL-*.md")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(generated); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("git", "merge-file", "-p",
+		"-L", "edited", "-L", "last deploy", "-L", "generated",
+		editedPath, basePath, tmp.Name())
+	merged, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+		return nil, fmt.Errorf("SKILL.md edits conflict with the generated docs in %d place(s), keep or overwrite them instead", exitErr.ExitCode())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge SKILL.md: %w", err)
+	}
+	return merged, nil
+}
+
+// RegenerateDocs rewrites the SKILL.md and docs.formats files of an existing
+// deployment without building: commands are extracted from the deployed
+// binary. The docs hash in deploy.lock is updated, the rest of the lock
+// still describes the deployed build.
+func RegenerateDocs(opts DeployOptions) error {
+	opts.BinaryPath = filepath.Join(opts.DeployPath, "bin", opts.Manifest.BinaryName())
+	if _, err := os.Stat(opts.BinaryPath); err != nil {
+		return fmt.Errorf("%s is not deployed to %s, deploy it first", opts.Manifest.Name, opts.DeployPath)
+	}
+
+	lock, err := ReadLock(opts.DeployPath)
+	if err != nil {
+		lock = nil
+	}
+	opts.changes = RecentChanges(opts)
+	if err := GenerateDocs(opts, DocsEdited(opts.DeployPath, lock)); err != nil {
+		return fmt.Errorf("failed to generate docs: %w", err)
+	}
+	if lock == nil {
+		return nil
+	}
+	lock.DocsSHA256, _ = HashFile(filepath.Join(opts.DeployPath, DocsBaseFile))
+	if err := lock.Write(opts.DeployPath); err != nil {
+		return fmt.Errorf("failed to write %s: %w", LockFile, err)
+	}
+	return nil
+}
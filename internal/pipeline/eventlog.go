@@ -0,0 +1,193 @@
+package pipeline
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// Event actions recorded in the event log
+const (
+	ActionBuild  = "build"
+	ActionDeploy = "deploy"
+)
+
+// Event outcomes
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailed  = "failed"
+)
+
+// eventLogFile is the append-only JSONL log of builds and deployments
+const eventLogFile = "history.jsonl"
+
+// buildLogDir holds build logs saved from the TUI, next to the event log
+const buildLogDir = "logs"
+
+// maxEventOutput limits the build output stored per event
+const maxEventOutput = 64 << 10
+
+// maxEventLine limits a single line read from the event log
+const maxEventLine = 1 << 20
+
+// Event is one build or deployment in the event log
+type Event struct {
+	At         time.Time `json:"at"`
+	Action     string    `json:"action"` // "build" or "deploy"
+	Skill      string    `json:"skill"`
+	Version    string    `json:"version,omitempty"`
+	Target     string    `json:"target,omitempty"` // Deploy path
+	DurationMS int64     `json:"duration_ms"`
+	Outcome    string    `json:"outcome"` // "success" or "failed"
+	Error      string    `json:"error,omitempty"`
+	GitCommit  string    `json:"git_commit,omitempty"`
+	Output     string    `json:"output,omitempty"`
+}
+
+// Duration returns the duration of the build or deployment
+func (e Event) Duration() time.Duration {
+	return time.Duration(e.DurationMS) * time.Millisecond
+}
+
+// NewEvent creates an event for a finished build or deployment. A non-nil
+// err marks it failed.
+func NewEvent(action string, manifest *skill.Manifest, target string, duration time.Duration, output string, err error) Event {
+	e := Event{
+		At:         time.Now().UTC(),
+		Action:     action,
+		Skill:      manifest.Name,
+		Version:    manifest.Version,
+		Target:     target,
+		DurationMS: duration.Milliseconds(),
+		Outcome:    OutcomeSuccess,
+		GitCommit:  GitCommit(manifest.Path),
+		Output:     output,
+	}
+	if err != nil {
+		e.Outcome = OutcomeFailed
+		e.Error = err.Error()
+	}
+	if len(e.Output) > maxEventOutput {
+		e.Output = "...\n" + e.Output[len(e.Output)-maxEventOutput:]
+	}
+	return e
+}
+
+// EventLogPath returns the event log location:
+// $XDG_STATE_HOME/skillfactory/history.jsonl, ~/.local/state by default
+func EventLogPath() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "skillfactory", eventLogFile), nil
+}
+
+// RecordEvent appends an event to the event log
+func RecordEvent(e Event) error {
+	path, err := EventLogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// SaveBuildLog writes the output of a build of skillName to a new file in
+// the logs directory next to the event log and returns its path
+func SaveBuildLog(skillName, output string) (string, error) {
+	path, err := EventLogPath()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(filepath.Dir(path), buildLogDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	file := filepath.Join(dir, fmt.Sprintf("%s-%s.log", skillName, time.Now().Format("20060102-150405")))
+	if err := os.WriteFile(file, []byte(output), 0644); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+// EventFilter selects events from the event log; zero values match everything
+type EventFilter struct {
+	Skill  string
+	Action string
+	Failed bool // Only failed events
+	Since  time.Time
+}
+
+// match reports whether an event passes the filter
+func (f EventFilter) match(e Event) bool {
+	switch {
+	case f.Skill != "" && e.Skill != f.Skill:
+		return false
+	case f.Action != "" && e.Action != f.Action:
+		return false
+	case f.Failed && e.Outcome != OutcomeFailed:
+		return false
+	case !f.Since.IsZero() && e.At.Before(f.Since):
+		return false
+	}
+	return true
+}
+
+// LoadEvents reads the events matching filter, oldest first. Unreadable
+// lines (e.g. from an interrupted write) are skipped.
+func LoadEvents(filter EventFilter) ([]Event, error) {
+	path, err := EventLogPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxEventLine)
+	for scanner.Scan() {
+		var e Event
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		if filter.match(e) {
+			events = append(events, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return events, fmt.Errorf("failed to read %s: %w", eventLogFile, err)
+	}
+	return events, nil
+}
@@ -0,0 +1,182 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+)
+
+// Files written for the docs.formats besides SKILL.md
+const (
+	CommandsFile = "commands.json" // docs.formats: json
+	ToolsFile    = "tools.json"    // docs.formats: mcp
+)
+
+// CommandCatalog is the machine-readable command documentation of a skill
+type CommandCatalog struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Version     string                `json:"version,omitempty"`
+	Binary      string                `json:"binary"` // Deployed binary
+	Commands    []skillkit.CommandDoc `json:"commands"`
+}
+
+// ToolManifest lists the commands of a skill as MCP-style tools
+type ToolManifest struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Binary  string `json:"binary"` // Deployed binary running the tools
+	Tools   []Tool `json:"tools"`
+}
+
+// Tool is a command in the shape of an MCP tools/list entry. Command is the
+// invocation after the binary; flags are passed as --<property>, the args
+// property as positional arguments.
+type Tool struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Command     string     `json:"command"`
+	InputSchema toolSchema `json:"inputSchema"`
+}
+
+// toolSchema is the JSON Schema of a tool's input
+type toolSchema struct {
+	Type        string                 `json:"type"`
+	Description string                 `json:"description,omitempty"`
+	Default     any                    `json:"default,omitempty"`
+	Items       *toolSchema            `json:"items,omitempty"`
+	Properties  map[string]*toolSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+}
+
+// toolNameInvalid matches characters not allowed in MCP tool names
+var toolNameInvalid = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// generateFormats writes the docs.formats other than SKILL.md to the deploy
+// path. They need the skill to register skillkit.DocsCommand.
+func generateFormats(opts DeployOptions) error {
+	docsConfig := opts.Manifest.Docs
+	if !docsConfig.HasFormat(skill.DocsFormatJSON) && !docsConfig.HasFormat(skill.DocsFormatMCP) {
+		return nil
+	}
+
+	docs, err := describeBinary(opts.BinaryPath)
+	if err != nil {
+		return fmt.Errorf("docs.formats needs the hidden %s command (skillkit.DocsCommand): %w", skillkit.DocsCommandName, err)
+	}
+	var commands []skillkit.CommandDoc
+	for _, cmd := range docs.Commands {
+		if len(strings.Fields(cmd.Path)) <= docsConfig.Depth() {
+			commands = append(commands, cmd)
+		}
+	}
+	binaryPath := filepath.Join(opts.DeployPath, "bin", opts.Manifest.BinaryName())
+
+	if docsConfig.HasFormat(skill.DocsFormatJSON) {
+		catalog := CommandCatalog{
+			Name:        opts.Manifest.Name,
+			Description: opts.Manifest.GetSkillDescription(),
+			Version:     opts.Manifest.Version,
+			Binary:      binaryPath,
+			Commands:    commands,
+		}
+		if err := writeJSONFile(filepath.Join(opts.DeployPath, CommandsFile), catalog); err != nil {
+			return err
+		}
+	}
+
+	if docsConfig.HasFormat(skill.DocsFormatMCP) {
+		manifest := ToolManifest{
+			Name:    opts.Manifest.Name,
+			Version: opts.Manifest.Version,
+			Binary:  binaryPath,
+			Tools:   []Tool{},
+		}
+		for _, cmd := range commands {
+			manifest.Tools = append(manifest.Tools, commandTool(opts.Manifest.Name, cmd))
+		}
+		if err := writeJSONFile(filepath.Join(opts.DeployPath, ToolsFile), manifest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commandTool describes a command as a tool named <skill>_<command path>
+func commandTool(skillName string, cmd skillkit.CommandDoc) Tool {
+	name := toolNameInvalid.ReplaceAllString(skillName+"_"+strings.ReplaceAll(cmd.Path, " ", "_"), "_")
+	input := toolSchema{Type: "object", Properties: make(map[string]*toolSchema)}
+
+	// Positional arguments from the usage line, e.g. "tasks get <id> [flags]"
+	var args []string
+	required := false
+	for _, arg := range strings.Fields(strings.TrimPrefix(cmd.Usage, cmd.Path)) {
+		if arg == "[flags]" {
+			continue
+		}
+		args = append(args, arg)
+		required = required || strings.HasPrefix(arg, "<")
+	}
+	if len(args) > 0 {
+		input.Properties["args"] = &toolSchema{
+			Type:        "array",
+			Description: "Positional arguments: " + strings.Join(args, " "),
+			Items:       &toolSchema{Type: "string"},
+		}
+		if required {
+			input.Required = append(input.Required, "args")
+		}
+	}
+
+	for _, f := range cmd.Flags {
+		input.Properties[f.Name] = flagSchema(f)
+	}
+	return Tool{
+		Name:        name,
+		Description: cmd.Description,
+		Command:     cmd.Path,
+		InputSchema: input,
+	}
+}
+
+// flagSchema maps a pflag type to a JSON Schema type
+func flagSchema(f skillkit.FlagDoc) *toolSchema {
+	s := &toolSchema{Type: "string", Description: f.Usage}
+	switch f.Type {
+	case "bool":
+		s.Type = "boolean"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "count":
+		s.Type = "integer"
+	case "float32", "float64":
+		s.Type = "number"
+	case "stringSlice", "stringArray":
+		s.Type = "array"
+		s.Items = &toolSchema{Type: "string"}
+	case "intSlice", "int32Slice", "int64Slice", "uintSlice":
+		s.Type = "array"
+		s.Items = &toolSchema{Type: "integer"}
+	}
+	if f.Default != "" {
+		var v any = f.Default
+		if s.Type != "string" && json.Unmarshal([]byte(f.Default), &v) != nil {
+			v = f.Default
+		}
+		s.Default = v
+	}
+	return s
+}
+
+// writeJSONFile writes v as indented JSON
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
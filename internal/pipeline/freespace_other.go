@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package pipeline
+
+// freeSpace is unknown on this platform, the check is skipped
+func freeSpace(dir string) (int64, bool) {
+	return 0, false
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package pipeline
+
+import "syscall"
+
+// freeSpace returns the bytes available to the user on the filesystem of dir
+func freeSpace(dir string) (int64, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, false
+	}
+	return int64(st.Bavail) * int64(st.Bsize), true
+}
@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// HealthcheckResult is the outcome of the healthcheck command of skill.yaml
+type HealthcheckResult struct {
+	Command string
+	Output  string // Output of the command, indented if JSON
+	Err     error
+}
+
+// Healthcheck runs the healthcheck command of skill.yaml with the deployed
+// binary after a deploy, showing whether its configuration works, e.g. that
+// the API credentials are accepted
+func Healthcheck(manifest *skill.Manifest, deployPath string) HealthcheckResult {
+	result := HealthcheckResult{Command: manifest.Healthcheck}
+	output, err := runDeployedCommand(filepath.Join(deployPath, "bin", manifest.BinaryName()), manifest.Healthcheck)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	var b bytes.Buffer
+	if json.Indent(&b, bytes.TrimSpace(output), "", "  ") == nil {
+		result.Output = b.String()
+	} else {
+		result.Output = strings.TrimSpace(string(output))
+	}
+	return result
+}
@@ -0,0 +1,148 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+)
+
+const (
+	buildHistoryFile = "builds.json"
+	buildHistoryKeep = 20 // Builds kept per skill
+)
+
+// TrendBuilds is the number of recent builds shown in a trend
+const TrendBuilds = 5
+
+// BuildRecord is one successful build of a skill
+type BuildRecord struct {
+	At         time.Time `json:"at"`
+	DurationMS int64     `json:"duration_ms"`
+	GoVersion  string    `json:"go_version,omitempty"`
+}
+
+// Duration returns the build duration
+func (r BuildRecord) Duration() time.Duration {
+	return time.Duration(r.DurationMS) * time.Millisecond
+}
+
+// buildHistoryPath returns the location of the build history file
+func buildHistoryPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, buildHistoryFile), nil
+}
+
+// LoadBuildHistory reads the build durations of all skills, oldest first
+func LoadBuildHistory() (map[string][]BuildRecord, error) {
+	history := make(map[string][]BuildRecord)
+
+	path, err := buildHistoryPath()
+	if err != nil {
+		return history, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return history, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", buildHistoryFile, err)
+	}
+	return history, nil
+}
+
+// RecordBuildDuration appends a build to the history of skill and returns the
+// updated records of that skill
+func RecordBuildDuration(skill string, record BuildRecord) ([]BuildRecord, error) {
+	history, err := LoadBuildHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	records := append(history[skill], record)
+	if len(records) > buildHistoryKeep {
+		records = records[len(records)-buildHistoryKeep:]
+	}
+	history[skill] = records
+
+	path, err := buildHistoryPath()
+	if err != nil {
+		return records, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return records, err
+	}
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return records, err
+	}
+	return records, os.WriteFile(path, data, 0644)
+}
+
+// sparkBars are the levels of a build trend sparkline
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// BuildTrend renders the last TrendBuilds durations as sparkline with the
+// change of the latest build against the average of the previous ones,
+// e.g. "▂▂▃▂█ 4.1s (+85%)"
+func BuildTrend(records []BuildRecord) string {
+	if len(records) == 0 {
+		return ""
+	}
+	if len(records) > TrendBuilds {
+		records = records[len(records)-TrendBuilds:]
+	}
+
+	var lo, hi int64
+	for i, r := range records {
+		if i == 0 || r.DurationMS < lo {
+			lo = r.DurationMS
+		}
+		if r.DurationMS > hi {
+			hi = r.DurationMS
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range records {
+		level := 0
+		if hi > lo {
+			level = int((r.DurationMS - lo) * int64(len(sparkBars)-1) / (hi - lo))
+		}
+		b.WriteRune(sparkBars[level])
+	}
+
+	last := records[len(records)-1]
+	b.WriteString(" " + FormatDuration(last.Duration()))
+
+	if len(records) > 1 {
+		var sum int64
+		for _, r := range records[:len(records)-1] {
+			sum += r.DurationMS
+		}
+		avg := sum / int64(len(records)-1)
+		if avg > 0 {
+			change := (last.DurationMS - avg) * 100 / avg
+			b.WriteString(fmt.Sprintf(" (%+d%%)", change))
+		}
+	}
+	return b.String()
+}
+
+// FormatDuration rounds a build duration for display
+func FormatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,79 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// Hook names in skill.yaml
+const (
+	HookPreBuild   = "pre_build"
+	HookPostBuild  = "post_build"
+	HookPostDeploy = "post_deploy"
+)
+
+// HookEnv describes the build and deploy state passed to hook commands
+type HookEnv struct {
+	BinaryPath string // Built binary (empty before the build)
+	DeployPath string // Deploy target (empty before the deploy)
+}
+
+// HookCommands returns the commands configured for a hook
+func HookCommands(manifest *skill.Manifest, hook string) []string {
+	switch hook {
+	case HookPreBuild:
+		return manifest.Hooks.PreBuild
+	case HookPostBuild:
+		return manifest.Hooks.PostBuild
+	case HookPostDeploy:
+		return manifest.Hooks.PostDeploy
+	}
+	return nil
+}
+
+// RunHook runs the commands of a hook in the skill directory. It returns the
+// combined output of all commands, each prefixed with "$ <command>", and stops
+// at the first failing command.
+func RunHook(manifest *skill.Manifest, hook string, env HookEnv) (string, error) {
+	var log strings.Builder
+	err := RunHookTo(manifest, hook, env, &log)
+	return log.String(), err
+}
+
+// RunHookTo runs the commands of a hook like RunHook, writing the output
+// to out as it is produced
+func RunHookTo(manifest *skill.Manifest, hook string, env HookEnv, out io.Writer) error {
+	for _, command := range HookCommands(manifest, hook) {
+		io.WriteString(out, "$ "+command+"\n")
+
+		cmd := shellCommand(command)
+		cmd.Dir = manifest.Path
+		cmd.Env = append(BuildEnv(manifest),
+			"SKILL_NAME="+manifest.Name,
+			"SKILL_VERSION="+manifest.Version,
+			"SKILL_DIR="+manifest.Path,
+			"SKILL_BINARY="+env.BinaryPath,
+			"SKILL_DEPLOY_PATH="+env.DeployPath,
+		)
+		cmd.Stdout = out
+		cmd.Stderr = out
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook failed: %s: %w", hook, command, err)
+		}
+	}
+	return nil
+}
+
+// shellCommand runs command through the platform shell
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
@@ -0,0 +1,250 @@
+// Package pipeline contains the build and deploy steps shared by the TUI and CLI
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// LockFile is the name of the deploy metadata file written at the deploy path
+const LockFile = "deploy.lock"
+
+// Lock describes a deployed skill artifact
+type Lock struct {
+	Skill        string    `json:"skill"`
+	SkillVersion string    `json:"skill_version,omitempty"`
+	BinarySHA256 string    `json:"binary_sha256"`
+	SourceSHA256 string    `json:"source_sha256"`
+	ConfigSHA256 string    `json:"config_sha256"`
+	DocsSHA256   string    `json:"docs_sha256,omitempty"` // SKILL.md as generated, see DocsBaseFile
+	GoVersion    string    `json:"go_version"`
+	GitCommit    string    `json:"git_commit,omitempty"`
+	GitBranch    string    `json:"git_branch,omitempty"`
+	GitDirty     bool      `json:"git_dirty,omitempty"`
+	BuiltAt      time.Time `json:"built_at"`
+}
+
+// ReadLock reads the deploy.lock from a deploy path
+func ReadLock(deployPath string) (*Lock, error) {
+	data, err := os.ReadFile(filepath.Join(deployPath, LockFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var lock Lock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", LockFile, err)
+	}
+	return &lock, nil
+}
+
+// Write stores the lock as deploy.lock in the deploy path
+func (l *Lock) Write(deployPath string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(deployPath, LockFile), append(data, '\n'), 0644)
+}
+
+// Changes compares the build inputs of two locks and returns the names of
+// the components that differ. An empty result means the builds are identical.
+func (l *Lock) Changes(other *Lock) []string {
+	var changes []string
+	if l.SourceSHA256 != other.SourceSHA256 {
+		changes = append(changes, "source")
+	}
+	if l.ConfigSHA256 != other.ConfigSHA256 {
+		changes = append(changes, "config")
+	}
+	if l.GoVersion != other.GoVersion {
+		changes = append(changes, "go version")
+	}
+	if l.SkillVersion != other.SkillVersion {
+		changes = append(changes, "skill version")
+	}
+	return changes
+}
+
+// HashBytes returns the hex encoded SHA-256 of data
+func HashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// HashFile returns the hex encoded SHA-256 of a file
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashSource returns a SHA-256 over all files of a skill directory.
+// Hidden files and a locally built binary (binaryName in the skill root) are ignored.
+func HashSource(skillDir string, binaryName string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(skillDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != skillDir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(skillDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == binaryName {
+			return nil
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, rel := range files {
+		fileHash, err := HashFile(filepath.Join(skillDir, rel))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %s\n", filepath.ToSlash(rel), fileHash)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// SkillHash returns the source hash of a skill including the libraries it
+// depends on and the defaults file it inherits, so a change to them marks
+// the skill outdated. Without either it is the HashSource of the skill
+// directory.
+func SkillHash(manifest *skill.Manifest) (string, error) {
+	hash, err := HashSource(manifest.Path, manifest.BinaryName())
+	if err != nil || (len(manifest.Libraries) == 0 && manifest.Defaults == "") {
+		return hash, err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, ". %s\n", hash)
+	if manifest.Defaults != "" {
+		defaultsHash, err := HashFile(manifest.Defaults)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %s\n", skill.DefaultsFile, defaultsHash)
+	}
+	for i, lib := range manifest.Libraries {
+		libHash, err := HashSource(lib, "")
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %s\n", filepath.ToSlash(manifest.Dependencies.Libraries[i]), libHash)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// GoVersion returns the Go toolchain version used for builds in dir
+func GoVersion(dir string) string {
+	cmd := exec.Command("go", "env", "GOVERSION")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// GitCommit returns the current commit of the repository containing dir
+func GitCommit(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// GitInfo describes the git state of a skill source directory
+type GitInfo struct {
+	Commit string
+	Branch string // Empty for a detached HEAD
+	Dirty  bool   // Uncommitted or untracked changes below the directory
+}
+
+// GitStatus returns the commit, branch and dirty state of dir. Commit is
+// empty if dir is not in a git repository.
+func GitStatus(dir string) GitInfo {
+	info := GitInfo{Commit: GitCommit(dir)}
+	if info.Commit == "" {
+		return info
+	}
+
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = dir
+	if output, err := cmd.Output(); err == nil {
+		if branch := strings.TrimSpace(string(output)); branch != "HEAD" {
+			info.Branch = branch
+		}
+	}
+
+	cmd = exec.Command("git", "status", "--porcelain", "--", ".")
+	cmd.Dir = dir
+	if output, err := cmd.Output(); err == nil {
+		info.Dirty = len(strings.TrimSpace(string(output))) > 0
+	}
+	return info
+}
+
+// ShortCommit returns the abbreviated commit hash
+func (g GitInfo) ShortCommit() string {
+	if len(g.Commit) > 12 {
+		return g.Commit[:12]
+	}
+	return g.Commit
+}
+
+// String formats the git state, e.g. "3f9c2a1b7d4e (main, dirty)"
+func (g GitInfo) String() string {
+	if g.Commit == "" {
+		return ""
+	}
+	var details []string
+	if g.Branch != "" {
+		details = append(details, g.Branch)
+	}
+	if g.Dirty {
+		details = append(details, "dirty")
+	}
+	if len(details) == 0 {
+		return g.ShortCommit()
+	}
+	return g.ShortCommit() + " (" + strings.Join(details, ", ") + ")"
+}
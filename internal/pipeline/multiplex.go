@@ -0,0 +1,231 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// DefaultMultiplexName is the binary and skill name of the combined entry
+// point unless another one is given
+const DefaultMultiplexName = "skills"
+
+// MultiplexFile lists the skills of a combined entry point at its deploy path
+const MultiplexFile = "multiplex.json"
+
+// MultiplexOptions configures the combined entry point of deployed skills
+type MultiplexOptions struct {
+	Name       string          // Binary and skill name, DefaultMultiplexName if empty
+	DeployPath string          // Folder of the entry point, e.g. <skills folder>/skills
+	Skills     []DeployedSkill // Skills dispatched to, see MultiplexSkills
+}
+
+// MultiplexSkill is a skill dispatched to by a combined entry point
+type MultiplexSkill struct {
+	Name   string `json:"name"`
+	Binary string `json:"binary"`
+}
+
+// MultiplexSkills returns the deployed skills a combined entry point can
+// dispatch to: skills with a binary, without other entry points
+func MultiplexSkills(deployed []DeployedSkill) []DeployedSkill {
+	var skills []DeployedSkill
+	for _, d := range deployed {
+		if _, err := os.Stat(filepath.Join(d.Path, MultiplexFile)); err == nil || d.Binary == "" {
+			continue
+		}
+		skills = append(skills, d)
+	}
+	return skills
+}
+
+// Multiplex builds a single binary dispatching to deployed skills by name
+// (skills vikunja tasks list) and writes one SKILL.md combining their docs,
+// with the calls rewritten to the entry point. The binary runs the deployed
+// binaries, which read their own .env, so it has to be generated again after
+// skills are added, removed or moved, but not after a redeploy.
+func Multiplex(opts MultiplexOptions) error {
+	if opts.Name == "" {
+		opts.Name = DefaultMultiplexName
+	}
+	if len(opts.Skills) == 0 {
+		return fmt.Errorf("no deployed skills to combine")
+	}
+	skills := make([]MultiplexSkill, 0, len(opts.Skills))
+	for _, d := range opts.Skills {
+		if d.Name == opts.Name || strings.HasPrefix(d.Name, "-") {
+			return fmt.Errorf("skill %s cannot be dispatched to by %s", d.Name, opts.Name)
+		}
+		skills = append(skills, MultiplexSkill{Name: d.Name, Binary: d.Binary})
+	}
+
+	binaryPath := filepath.Join(opts.DeployPath, "bin", opts.Name)
+	if err := buildMultiplexer(skills, binaryPath); err != nil {
+		return err
+	}
+	docs := multiplexDocs(opts.Name, binaryPath, opts.Skills)
+	if err := os.WriteFile(filepath.Join(opts.DeployPath, "SKILL.md"), []byte(docs), 0644); err != nil {
+		return fmt.Errorf("failed to write SKILL.md: %w", err)
+	}
+	data, err := json.MarshalIndent(skills, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(opts.DeployPath, MultiplexFile), append(data, '\n'), 0644)
+}
+
+// multiplexSource is the program of the entry point. It passes stdin,
+// stdout, stderr and the exit code through and leaves Ctrl+C to the skill.
+var multiplexSource = template.Must(template.New("main.go").Parse(`// Code generated by skillfactory multiplex. DO NOT EDIT.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+)
+
+var skills = map[string]string{
+{{- range .}}
+	{{printf "%q" .Name}}: {{printf "%q" .Binary}},
+{{- end}}
+}
+
+var names = []string{ {{- range $i, $s := .}}{{if $i}}, {{end}}{{printf "%q" $s.Name}}{{end -}} }
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		fmt.Printf("Usage: %s <skill> <command> [flags]\n\nSkills:\n", os.Args[0])
+		for _, name := range names {
+			fmt.Printf("  %s\n", name)
+		}
+		return
+	}
+	binary, ok := skills[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "{\"error\": %q}\n", "unknown skill "+os.Args[1])
+		os.Exit(1)
+	}
+
+	signal.Notify(make(chan os.Signal, 1), os.Interrupt)
+	cmd := exec.Command(binary, os.Args[2:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		fmt.Fprintf(os.Stderr, "{\"error\": %q}\n", err.Error())
+		os.Exit(1)
+	}
+}
+`))
+
+// buildMultiplexer generates the entry point in a temporary module and
+// builds it to binaryPath
+func buildMultiplexer(skills []MultiplexSkill, binaryPath string) error {
+	dir, err := os.MkdirTemp("", "skillfactory-multiplex-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	var source bytes.Buffer
+	if err := multiplexSource.Execute(&source, skills); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), source.Bytes(), 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module multiplex\n\ngo 1.21\n"), 0644); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(binaryPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Remove the old binary first, it may be running
+	os.Remove(binaryPath)
+	cmd := exec.Command("go", "build", "-trimpath", "-ldflags", "-s -w", "-o", binaryPath, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOFLAGS=-mod=mod")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("build failed: %s", firstLine(msg))
+		}
+		return fmt.Errorf("build failed: %w", err)
+	}
+	return nil
+}
+
+// markdownHeading matches a markdown heading line
+var markdownHeading = regexp.MustCompile(`^#{1,5} `)
+
+// multiplexDocs combines the SKILL.md files of the skills into one, each
+// below a heading of its own instead of its title, with the calls of its
+// binary going through the entry point
+func multiplexDocs(name, binaryPath string, skills []DeployedSkill) string {
+	names := make([]string, len(skills))
+	for i, d := range skills {
+		names[i] = d.Name
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	b.WriteString(fmt.Sprintf("name: %s\n", name))
+	b.WriteString(fmt.Sprintf("description: Single entry point for the skills %s\n", strings.Join(names, ", ")))
+	b.WriteString("---\n\n")
+	b.WriteString("# " + name + "\n\n")
+	b.WriteString("Run the skills through one binary: `" + binaryPath + " <skill> <command> [flags]`, ")
+	b.WriteString("e.g. `" + binaryPath + " " + names[0] + " --help`. ")
+	b.WriteString("Each skill reads its own configuration, output and exit codes are those of the skill.\n\n")
+	for _, d := range skills {
+		b.WriteString("- **" + d.Name + "**")
+		if d.Description != "" {
+			b.WriteString(": " + d.Description)
+		}
+		b.WriteString("\n")
+	}
+
+	for _, d := range skills {
+		b.WriteString("\n## " + d.Name + "\n\n")
+		data, err := os.ReadFile(filepath.Join(d.Path, "SKILL.md"))
+		if err != nil {
+			b.WriteString("No SKILL.md deployed, run `" + binaryPath + " " + d.Name + " --help` to list its commands.\n")
+			continue
+		}
+		body := strings.ReplaceAll(stripFrontmatter(string(data)), d.Binary, binaryPath+" "+d.Name)
+		body = strings.TrimSpace(body)
+		if strings.HasPrefix(body, "# ") {
+			// The title of the skill is replaced by its heading
+			_, body, _ = strings.Cut(body, "\n")
+			body = strings.TrimSpace(body)
+		}
+		b.WriteString(demoteHeadings(body) + "\n")
+	}
+	return b.String()
+}
+
+// demoteHeadings moves the markdown headings outside code blocks one level
+// down, so the docs of a skill fit below its own heading
+func demoteHeadings(content string) string {
+	lines := strings.Split(content, "\n")
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+		}
+		if !inCode && markdownHeading.MatchString(line) {
+			lines[i] = "#" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,280 @@
+package pipeline
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// PackageInfo is stored as package.json in a skill package
+type PackageInfo struct {
+	Skill     string    `json:"skill"`
+	Version   string    `json:"version,omitempty"`
+	Binary    string    `json:"binary"`
+	GOOS      string    `json:"goos"`
+	GOARCH    string    `json:"goarch"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// packageInfoFile is the metadata file of a skill package
+const packageInfoFile = "package.json"
+
+// maxPackageFileSize limits single files extracted from a package
+const maxPackageFileSize = 256 << 20
+
+// PackageName returns the default file name of a skill package
+func PackageName(manifest *skill.Manifest) string {
+	version := manifest.Version
+	if version == "" {
+		version = "dev"
+	}
+	goos, goarch := targetPlatform()
+	return fmt.Sprintf("%s-%s-%s-%s.tar.gz", manifest.Name, version, goos, goarch)
+}
+
+// targetPlatform returns the platform go build compiles for, honoring GOOS and GOARCH
+func targetPlatform() (goos, goarch string) {
+	goos, goarch = os.Getenv("GOOS"), os.Getenv("GOARCH")
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	return goos, goarch
+}
+
+// Package writes a skill package (.tar.gz) containing skill.yaml, the built
+// binary, the generated SKILL.md and package.json. The SKILL.md keeps the
+// {{SKILL_PATH}} placeholder, which is filled in on install.
+func Package(manifest *skill.Manifest, binaryPath string, outputPath string) error {
+	binary, err := os.ReadFile(binaryPath)
+	if err != nil {
+		return fmt.Errorf("failed to read binary: %w", err)
+	}
+	// The package carries the inherited defaults, they are not installed
+	manifestData, err := skill.EffectiveManifest(manifest.Path)
+	if err != nil {
+		return err
+	}
+	docs := RenderDocs(DeployOptions{
+		Manifest:   manifest,
+		BinaryPath: binaryPath,
+		DeployPath: "{{SKILL_PATH}}",
+	})
+	goos, goarch := targetPlatform()
+	info, err := json.MarshalIndent(PackageInfo{
+		Skill:     manifest.Name,
+		Version:   manifest.Version,
+		Binary:    manifest.BinaryName(),
+		GOOS:      goos,
+		GOARCH:    goarch,
+		CreatedAt: time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create package: %w", err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	files := []struct {
+		name string
+		data []byte
+		mode int64
+	}{
+		{packageInfoFile, append(info, '\n'), 0644},
+		{"skill.yaml", manifestData, 0644},
+		{"SKILL.md", []byte(docs), 0644},
+		{"bin/" + manifest.BinaryName(), binary, 0755},
+	}
+	for _, file := range files {
+		header := &tar.Header{
+			Name:    file.name,
+			Mode:    file.mode,
+			Size:    int64(len(file.data)),
+			ModTime: time.Now(),
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write package: %w", err)
+		}
+		if _, err := tw.Write(file.data); err != nil {
+			return fmt.Errorf("failed to write package: %w", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write package: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write package: %w", err)
+	}
+	return f.Close()
+}
+
+// Unpacked is a skill package extracted to a temporary directory
+type Unpacked struct {
+	Dir        string // Remove with os.RemoveAll when done
+	Info       PackageInfo
+	Manifest   *skill.Manifest
+	BinaryPath string
+	Docs       string // SKILL.md with the {{SKILL_PATH}} placeholder
+}
+
+// Unpack extracts a skill package from a file path or http(s) URL into a
+// temporary directory and checks that the binary matches this platform
+func Unpack(source string) (*Unpacked, error) {
+	dir, err := os.MkdirTemp("", "skillfactory-install-")
+	if err != nil {
+		return nil, err
+	}
+	u, err := unpack(source, dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return u, nil
+}
+
+// CheckPathName rejects names that cannot be used as a single path element,
+// e.g. a skill folder name: empty names, . and .. and names containing a
+// path separator
+func CheckPathName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("empty name")
+	case name == "." || name == "..":
+		return fmt.Errorf("invalid name %q", name)
+	case strings.ContainsAny(name, `/\`) || strings.ContainsRune(name, filepath.Separator):
+		return fmt.Errorf("invalid name %q: contains a path separator", name)
+	}
+	return nil
+}
+
+func unpack(source, dir string) (*Unpacked, error) {
+	r, err := openPackage(source)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid skill package: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid skill package: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name, ok := packageEntry(header.Name)
+		if !ok {
+			return nil, fmt.Errorf("invalid skill package: unexpected file %s", header.Name)
+		}
+		if header.Size > maxPackageFileSize {
+			return nil, fmt.Errorf("invalid skill package: %s is too large", header.Name)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, err
+		}
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode)&0755)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(f, io.LimitReader(tr, maxPackageFileSize))
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+	}
+
+	u := &Unpacked{Dir: dir}
+	data, err := os.ReadFile(filepath.Join(dir, packageInfoFile))
+	if err != nil {
+		return nil, fmt.Errorf("invalid skill package: missing %s", packageInfoFile)
+	}
+	if err := json.Unmarshal(data, &u.Info); err != nil {
+		return nil, fmt.Errorf("invalid skill package: %w", err)
+	}
+	if u.Info.GOOS != runtime.GOOS || u.Info.GOARCH != runtime.GOARCH {
+		return nil, fmt.Errorf("package is built for %s/%s, this system is %s/%s",
+			u.Info.GOOS, u.Info.GOARCH, runtime.GOOS, runtime.GOARCH)
+	}
+
+	if u.Manifest, err = skill.ReadManifest(filepath.Join(dir, "skill.yaml")); err != nil {
+		return nil, err
+	}
+	// Name and binary become paths below the skills folder
+	if err := CheckPathName(u.Manifest.Name); err != nil {
+		return nil, fmt.Errorf("invalid skill package: skill name: %w", err)
+	}
+	if err := CheckPathName(u.Manifest.BinaryName()); err != nil {
+		return nil, fmt.Errorf("invalid skill package: binary name: %w", err)
+	}
+	u.BinaryPath = filepath.Join(dir, "bin", u.Manifest.BinaryName())
+	if _, err := os.Stat(u.BinaryPath); err != nil {
+		return nil, fmt.Errorf("invalid skill package: missing bin/%s", u.Manifest.BinaryName())
+	}
+	if docs, err := os.ReadFile(filepath.Join(dir, "SKILL.md")); err == nil {
+		u.Docs = string(docs)
+	}
+	return u, nil
+}
+
+// openPackage opens a local package file or downloads it
+func openPackage(source string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return os.Open(source)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download package: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download package: %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// packageEntry validates a file name in a package archive. Only the known
+// top-level files and files directly in bin/ are accepted.
+func packageEntry(name string) (string, bool) {
+	name = path.Clean(strings.TrimPrefix(name, "./"))
+	switch name {
+	case packageInfoFile, "skill.yaml", "SKILL.md":
+		return name, true
+	}
+	dir, file := path.Split(name)
+	if dir != "bin/" || file == "" || strings.HasPrefix(file, ".") {
+		return "", false
+	}
+	return name, true
+}
@@ -0,0 +1,111 @@
+package pipeline
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// elfArchs maps ELF machine types to GOARCH
+var elfArchs = map[elf.Machine]string{
+	elf.EM_X86_64:    "amd64",
+	elf.EM_386:       "386",
+	elf.EM_AARCH64:   "arm64",
+	elf.EM_ARM:       "arm",
+	elf.EM_RISCV:     "riscv64",
+	elf.EM_S390:      "s390x",
+	elf.EM_LOONGARCH: "loong64",
+}
+
+// machoArchs maps Mach-O CPU types to GOARCH
+var machoArchs = map[macho.Cpu]string{
+	macho.CpuAmd64: "amd64",
+	macho.CpuArm64: "arm64",
+	macho.Cpu386:   "386",
+}
+
+// peArchs maps PE machine types to GOARCH
+var peArchs = map[uint16]string{
+	pe.IMAGE_FILE_MACHINE_AMD64: "amd64",
+	pe.IMAGE_FILE_MACHINE_ARM64: "arm64",
+	pe.IMAGE_FILE_MACHINE_I386:  "386",
+}
+
+// BinaryPlatform reads the GOOS/GOARCH of an executable from its ELF,
+// Mach-O or PE header, e.g. "linux/amd64"
+func BinaryPlatform(path string) (string, error) {
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		goos := "linux"
+		switch f.OSABI {
+		case elf.ELFOSABI_FREEBSD:
+			goos = "freebsd"
+		case elf.ELFOSABI_NETBSD:
+			goos = "netbsd"
+		case elf.ELFOSABI_OPENBSD:
+			goos = "openbsd"
+		}
+		arch := elfArchs[f.Machine]
+		if f.Machine == elf.EM_PPC64 {
+			arch = "ppc64"
+			if f.ByteOrder == binary.LittleEndian {
+				arch = "ppc64le"
+			}
+		}
+		return platformString(goos, arch, f.Machine.String())
+	}
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+		return platformString("darwin", machoArchs[f.Cpu], f.Cpu.String())
+	}
+	if f, err := pe.Open(path); err == nil {
+		defer f.Close()
+		return platformString("windows", peArchs[f.Machine], fmt.Sprintf("machine %#x", f.Machine))
+	}
+	return "", fmt.Errorf("%s is not an ELF, Mach-O or PE executable", filepath.Base(path))
+}
+
+// platformString formats goos/goarch, failing for an unknown architecture
+func platformString(goos, goarch, machine string) (string, error) {
+	if goarch == "" {
+		return "", fmt.Errorf("unknown %s architecture %s", goos, machine)
+	}
+	return goos + "/" + goarch, nil
+}
+
+// TargetPlatform returns the GOOS/GOARCH go build compiles for, e.g.
+// "darwin/arm64"
+func TargetPlatform() string {
+	goos, goarch := targetPlatform()
+	return goos + "/" + goarch
+}
+
+// PlatformMismatch compares the platform of the binary deployed at
+// deployPath with the platform the new build targets. It returns a warning
+// if they differ, e.g. a deploy from macOS to a skills folder used by a
+// Linux server, and "" if they match or nothing is deployed.
+func PlatformMismatch(manifest *skill.Manifest, deployPath string) string {
+	deployed, err := BinaryPlatform(filepath.Join(deployPath, "bin", manifest.BinaryName()))
+	if err != nil {
+		return ""
+	}
+	target := TargetPlatform()
+	if deployed == target {
+		return ""
+	}
+
+	goos, goarch, _ := strings.Cut(deployed, "/")
+	warning := fmt.Sprintf("The deployed binary is built for %s, the new build targets %s and would not run there; set GOOS=%s GOARCH=%s to build for it", deployed, target, goos, goarch)
+	cgo := manifest.Build.CGO != nil && *manifest.Build.CGO
+	if cgo && manifest.Build.Remote == "" && deployed != runtime.GOOS+"/"+runtime.GOARCH {
+		warning += fmt.Sprintf(", and build.remote to a %s host: cgo skills cannot be cross-compiled", deployed)
+	}
+	return warning
+}
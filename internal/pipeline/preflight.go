@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// defaultBinarySize is the expected size of a skill binary never deployed before
+const defaultBinarySize = 20 << 20
+
+// preflightMargin is the space needed besides the binary (SKILL.md, .env, deploy.lock)
+const preflightMargin = 1 << 20
+
+// PreflightDeploy checks before a build that the deploy path exists or can
+// be created, is writable and that its filesystem has room for the binary,
+// so a deploy does not fail after a long compile.
+func PreflightDeploy(manifest *skill.Manifest, deployPath string) error {
+	if deployPath == "" {
+		return fmt.Errorf("deploy path not configured")
+	}
+
+	// The deploy path, or the nearest parent it would be created in
+	dir := deployPath
+	var statErr error
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("cannot deploy to %s: %s is not a directory", deployPath, dir)
+			}
+			if statErr != nil {
+				return fmt.Errorf("cannot deploy to %s: %w", deployPath, statErr)
+			}
+			break
+		}
+		if !os.IsNotExist(err) && statErr == nil {
+			statErr = err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return fmt.Errorf("cannot deploy to %s: no existing parent directory", deployPath)
+		}
+		dir = parent
+	}
+
+	if err := checkWritable(dir); err != nil {
+		return fmt.Errorf("cannot deploy to %s: %s is not writable (%w)", deployPath, dir, err)
+	}
+	binDir := filepath.Join(deployPath, "bin")
+	if info, err := os.Stat(binDir); err == nil && info.IsDir() {
+		if err := checkWritable(binDir); err != nil {
+			return fmt.Errorf("cannot deploy to %s: %s is not writable (%w)", deployPath, binDir, err)
+		}
+	}
+
+	need := expectedBinarySize(manifest, deployPath) + preflightMargin
+	if free, ok := freeSpace(dir); ok && free < need {
+		return fmt.Errorf("cannot deploy to %s: only %s free, %s needs about %s", deployPath, FormatSize(free), manifest.Name, FormatSize(need))
+	}
+
+	// Tools can only be looked up if the skill runs on this machine
+	if TargetPlatform() == runtime.GOOS+"/"+runtime.GOARCH {
+		if missing := MissingTools(manifest); len(missing) > 0 {
+			return fmt.Errorf("%s requires %s, not found in PATH: install the missing tools or add them to PATH before deploying",
+				manifest.Name, strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
+
+// MissingTools returns the tools of requires in skill.yaml that are not
+// found in PATH
+func MissingTools(manifest *skill.Manifest) []string {
+	var missing []string
+	for _, tool := range manifest.Requires {
+		if _, err := exec.LookPath(tool); err != nil {
+			missing = append(missing, tool)
+		}
+	}
+	return missing
+}
+
+// checkWritable creates and removes a file in dir
+func checkWritable(dir string) error {
+	probe, err := os.CreateTemp(dir, ".skillfactory-preflight-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// expectedBinarySize estimates the size of the binary a deploy writes from
+// the one deployed before
+func expectedBinarySize(manifest *skill.Manifest, deployPath string) int64 {
+	if info, err := os.Stat(filepath.Join(deployPath, "bin", manifest.BinaryName())); err == nil {
+		return info.Size()
+	}
+	return defaultBinarySize
+}
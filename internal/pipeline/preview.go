@@ -0,0 +1,260 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+)
+
+// diffContext is the number of unchanged lines around a change in a diff
+const diffContext = 3
+
+// FileDiff compares a deployed file with the version a deploy would write
+type FileDiff struct {
+	Name    string // Path relative to the deploy path, e.g. "bin/.env"
+	Diff    string // Unified diff, empty if unchanged or Note is set
+	Added   int    // Added lines
+	Removed int    // Removed lines
+	Note    string // Why the file is not compared
+}
+
+// PreviewDeploy compares the SKILL.md and .env at the deploy path with the
+// files a deploy with opts would write. The commands in SKILL.md come from
+// opts.BinaryPath, or from the deployed binary if it was not built yet.
+// Secret values are masked; command variables and secret references are
+// compared unresolved.
+func PreviewDeploy(opts DeployOptions) []FileDiff {
+	binDir := filepath.Join(opts.DeployPath, "bin")
+	if _, err := os.Stat(opts.BinaryPath); err != nil {
+		opts.BinaryPath = filepath.Join(binDir, opts.Manifest.BinaryName())
+	}
+
+	docs := opts.Docs
+	if docs == "" {
+		opts.changes = RecentChanges(opts)
+		docs = RenderDocs(opts)
+	} else {
+		docs = strings.ReplaceAll(docs, "{{SKILL_PATH}}", opts.DeployPath)
+	}
+	diffs := []FileDiff{previewFile(opts.DeployPath, "SKILL.md", docs)}
+
+	envName := filepath.Join("bin", ".env")
+	if _, err := os.Stat(filepath.Join(binDir, skillkit.EncryptedEnvFile)); err == nil || opts.EncryptEnv {
+		diffs = append(diffs, FileDiff{Name: envName, Note: "encrypted, not compared"})
+		return diffs
+	}
+	deployed, err := os.ReadFile(filepath.Join(binDir, ".env"))
+	if err != nil {
+		deployed = nil
+	}
+	before, after := maskEnvFiles(opts, string(deployed), previewEnvFile(opts))
+	diffs = append(diffs, diffFiles(envName, before, after))
+	return diffs
+}
+
+// previewFile compares a deployed file with content
+func previewFile(deployPath, name, content string) FileDiff {
+	deployed, err := os.ReadFile(filepath.Join(deployPath, name))
+	if err != nil {
+		deployed = nil
+	}
+	return diffFiles(name, string(deployed), content)
+}
+
+// PreviewEnvFile returns the .env a deploy with opts would write, for the
+// confirm step. Secret values are masked unless showSecrets is set; command
+// variables and secret references are shown unresolved.
+func PreviewEnvFile(opts DeployOptions, showSecrets bool) string {
+	content := previewEnvFile(opts)
+	if !showSecrets {
+		_, content = maskEnvFiles(opts, "", content)
+	}
+	return content
+}
+
+// previewEnvFile returns the .env a deploy would write, without storing
+// secrets in the keychain. Errors are left to the deploy.
+func previewEnvFile(opts DeployOptions) string {
+	if !opts.UseKeychain {
+		content, _ := EnvFile(opts.Manifest, opts.Values)
+		return content
+	}
+	values := make(map[string]string, len(opts.Values))
+	service := skillkit.KeychainService(opts.skillFolder())
+	for _, v := range opts.Manifest.Variables {
+		value := opts.Values[v.Name]
+		if v.Type == "secret" && value != "" {
+			value = skillkit.KeychainRef(service, v.Name)
+		}
+		values[v.Name] = value
+	}
+	content, _ := EnvFile(opts.Manifest, values)
+	return content
+}
+
+// maskEnvFiles replaces the values of secret variables in the deployed and
+// the new .env, marking new values that differ from the deployed ones.
+// Keychain references are kept, they contain no secret.
+func maskEnvFiles(opts DeployOptions, deployed, next string) (string, string) {
+	secrets := make(map[string]bool)
+	for _, v := range opts.Manifest.Variables {
+		if v.Type == "secret" {
+			secrets[v.Name] = true
+		}
+	}
+
+	previous := make(map[string]string)
+	mask := func(content string, changed func(name, value string) bool) string {
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			name, value, ok := strings.Cut(line, "=")
+			if !ok || !secrets[name] || strings.HasPrefix(value, skillkit.KeychainPrefix) {
+				continue
+			}
+			lines[i] = name + "=********"
+			if changed(name, value) {
+				lines[i] += " (changed)"
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	deployed = mask(deployed, func(name, value string) bool {
+		previous[name] = value
+		return false
+	})
+	next = mask(next, func(name, value string) bool {
+		old, ok := previous[name]
+		return ok && old != value
+	})
+	return deployed, next
+}
+
+// diffFiles compares two versions of a file
+func diffFiles(name, before, after string) FileDiff {
+	d := FileDiff{Name: name}
+	if before == after {
+		return d
+	}
+	d.Diff, d.Added, d.Removed = UnifiedDiff("deployed/"+name, "new/"+name, before, after)
+	return d
+}
+
+// diffOp is a line of a diff: ' ' unchanged, '-' removed or '+' added
+type diffOp struct {
+	kind     byte
+	line     string
+	oldIndex int // Position in the old lines before this op
+	newIndex int // Position in the new lines before this op
+}
+
+// UnifiedDiff returns a unified diff between two texts with the number of
+// added and removed lines
+func UnifiedDiff(fromName, toName, before, after string) (string, int, int) {
+	a, b := splitLines(before), splitLines(after)
+	ops := diffLines(a, b)
+
+	var out strings.Builder
+	added, removed := 0, 0
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// Hunk from the first change with context, extended while the next
+		// change is within twice the context
+		start := max(i-diffContext, 0)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j
+			} else if j-end > 2*diffContext {
+				break
+			}
+		}
+		end = min(end+diffContext+1, len(ops))
+
+		oldCount, newCount := 0, 0
+		var hunk strings.Builder
+		for _, op := range ops[start:end] {
+			hunk.WriteString(string(op.kind) + op.line + "\n")
+			switch op.kind {
+			case ' ':
+				oldCount++
+				newCount++
+			case '-':
+				oldCount++
+				removed++
+			case '+':
+				newCount++
+				added++
+			}
+		}
+		if out.Len() == 0 {
+			out.WriteString("--- " + fromName + "\n+++ " + toName + "\n")
+		}
+		out.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(ops[start].oldIndex, oldCount), hunkRange(ops[start].newIndex, newCount)))
+		out.WriteString(hunk.String())
+		i = end
+	}
+	return out.String(), added, removed
+}
+
+// hunkRange formats the start line and length of a hunk side
+func hunkRange(index, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", index)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", index+1)
+	}
+	return fmt.Sprintf("%d,%d", index+1, count)
+}
+
+// splitLines splits text into lines without the trailing newline
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines computes the line operations turning a into b from their
+// longest common subsequence
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], i, j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i], i, j})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j], i, j})
+			j++
+		}
+	}
+	return ops
+}
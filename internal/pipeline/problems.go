@@ -0,0 +1,94 @@
+package pipeline
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Problem is a compiler error or vet finding with its source position
+type Problem struct {
+	File    string // Absolute path
+	Line    int
+	Column  int // 0 if not reported
+	Message string
+}
+
+// problemPattern matches "path/file.go:12:5: message" and "file.go:12: message"
+var problemPattern = regexp.MustCompile(`^(\S+\.go):(\d+)(?::(\d+))?: (.+)$`)
+
+// ParseProblems extracts the problems from go build or go vet output.
+// Relative file names are resolved against dir, the directory the command
+// ran in. Indented follow-up lines (e.g. "have/want") are appended to the
+// message of the problem before them.
+func ParseProblems(output string, dir string) []Problem {
+	var problems []Problem
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "\t") && len(problems) > 0 {
+			last := &problems[len(problems)-1]
+			last.Message += "\n" + strings.TrimSpace(line)
+			continue
+		}
+
+		match := problemPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		file := filepath.FromSlash(match[1])
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		lineNo, _ := strconv.Atoi(match[2])
+		column, _ := strconv.Atoi(match[3])
+		problems = append(problems, Problem{
+			File:    file,
+			Line:    lineNo,
+			Column:  column,
+			Message: match[4],
+		})
+	}
+	return problems
+}
+
+// Location formats the problem position relative to dir, e.g. "tasks/service.go:12:5"
+func (p Problem) Location(dir string) string {
+	file := p.File
+	if rel, err := filepath.Rel(dir, file); err == nil && !strings.HasPrefix(rel, "..") {
+		file = rel
+	}
+	location := filepath.ToSlash(file) + ":" + strconv.Itoa(p.Line)
+	if p.Column > 0 {
+		location += ":" + strconv.Itoa(p.Column)
+	}
+	return location
+}
+
+// EditorCommand returns the command opening file at line in $VISUAL or
+// $EDITOR (default vi). The line argument is passed in the form the editor
+// understands: "-g file:line" for VS Code, "file:line" for Sublime Text and
+// Zed, "+line file" otherwise.
+func EditorCommand(file string, line int) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	args := strings.Fields(editor)
+	position := file + ":" + strconv.Itoa(line)
+	switch strings.TrimSuffix(filepath.Base(args[0]), ".exe") {
+	case "code", "code-insiders", "codium", "cursor":
+		args = append(args, "-g", position)
+	case "subl", "zed":
+		args = append(args, position)
+	default:
+		args = append(args, "+"+strconv.Itoa(line), file)
+	}
+	return exec.Command(args[0], args[1:]...)
+}
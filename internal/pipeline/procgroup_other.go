@@ -0,0 +1,9 @@
+//go:build !unix
+
+package pipeline
+
+import "os/exec"
+
+// cancelProcessGroup keeps the default cancellation, which kills the go
+// command only
+func cancelProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package pipeline
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// cancelProcessGroup starts cmd in its own process group and makes the
+// cancellation of its context kill the whole group, so the compiler and
+// linker processes spawned by go build stop with it
+func cancelProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,49 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+)
+
+// SaveProfile stores a skill configuration as named profile. With useKeychain
+// secret values are kept in the OS keychain and only referenced in the profile.
+func SaveProfile(manifest *skill.Manifest, name string, profile *config.Profile, useKeychain bool) error {
+	stored := *profile
+	stored.Values = make(map[string]string, len(profile.Values))
+
+	service := "skillfactory.profile." + manifest.Name + "." + name
+	for _, v := range manifest.Variables {
+		value, ok := profile.Values[v.Name]
+		if !ok {
+			continue
+		}
+		if useKeychain && v.Type == "secret" && value != "" {
+			if err := skillkit.SetSecret(service, v.Name, value); err != nil {
+				return fmt.Errorf("failed to store %s in keychain: %w", v.Name, err)
+			}
+			value = skillkit.KeychainRef(service, v.Name)
+		}
+		stored.Values[v.Name] = value
+	}
+
+	return config.SaveProfile(manifest.Name, name, &stored)
+}
+
+// ProfileValues returns the values of a profile with keychain references resolved
+func ProfileValues(profile *config.Profile) (map[string]string, error) {
+	values := make(map[string]string, len(profile.Values))
+	for name, value := range profile.Values {
+		if service, account, ok := skillkit.ParseKeychainRef(value); ok {
+			secret, err := skillkit.GetSecret(service, account)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s from keychain: %w", name, err)
+			}
+			value = secret
+		}
+		values[name] = value
+	}
+	return values, nil
+}
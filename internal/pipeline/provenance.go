@@ -0,0 +1,443 @@
+package pipeline
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"debug/buildinfo"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// Provenance attestations are in-toto statements with a SLSA v1 provenance
+// predicate, signed as DSSE envelope with an ed25519 key of the user
+const (
+	ProvenanceSuffix      = ".intoto.jsonl" // Appended to the package file name
+	provenanceKeyFile     = "provenance.key"
+	ProvenancePublicFile  = "provenance.pub"
+	inTotoStatementType   = "https://in-toto.io/Statement/v1"
+	slsaProvenanceType    = "https://slsa.dev/provenance/v1"
+	dssePayloadType       = "application/vnd.in-toto+json"
+	provenanceBuildType   = "https://github.com/petervogelmann/skillfactory/package/v1"
+	provenanceBuilderBase = "https://github.com/petervogelmann/skillfactory"
+)
+
+// Statement is an in-toto statement about the artifacts in Subject
+type Statement struct {
+	Type          string               `json:"_type"`
+	Subject       []ResourceDescriptor `json:"subject"`
+	PredicateType string               `json:"predicateType"`
+	Predicate     Provenance           `json:"predicate"`
+}
+
+// Provenance is the SLSA v1 provenance predicate: how, from what and by
+// whom a package was built
+type Provenance struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+// BuildDefinition lists the inputs of a build
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   map[string]string    `json:"externalParameters"`
+	InternalParameters   map[string]string    `json:"internalParameters,omitempty"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies"`
+}
+
+// ResourceDescriptor is an artifact or material with its digests
+type ResourceDescriptor struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// RunDetails describes the builder and the time of a build
+type RunDetails struct {
+	Builder  ProvenanceBuilder  `json:"builder"`
+	Metadata ProvenanceMetadata `json:"metadata"`
+}
+
+// ProvenanceBuilder identifies what ran the build
+type ProvenanceBuilder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+// ProvenanceMetadata holds the build timestamps
+type ProvenanceMetadata struct {
+	StartedOn  time.Time `json:"startedOn"`
+	FinishedOn time.Time `json:"finishedOn"`
+}
+
+// dsseEnvelope is a signed statement; json encodes the byte slices as base64
+type dsseEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     []byte          `json:"payload"`
+	Signatures  []dsseSignature `json:"signatures"`
+}
+
+type dsseSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   []byte `json:"sig"`
+}
+
+// ProvenanceOptions describes a packaged build for WriteProvenance
+type ProvenanceOptions struct {
+	Manifest       *skill.Manifest
+	BinaryPath     string
+	PackagePath    string
+	BuilderVersion string // Version of skillfactory
+	Started        time.Time
+	Finished       time.Time
+}
+
+// ProvenancePath returns the attestation file written next to a package
+func ProvenancePath(packagePath string) string {
+	return packagePath + ProvenanceSuffix
+}
+
+// WriteProvenance signs a provenance statement for a package with the
+// provenance key (created on first use) and writes it next to the package.
+// It returns the key ID.
+func WriteProvenance(opts ProvenanceOptions) (string, error) {
+	statement, err := provenanceStatement(opts)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return "", err
+	}
+	key, err := ProvenanceKey()
+	if err != nil {
+		return "", err
+	}
+	keyID := provenanceKeyID(key.Public().(ed25519.PublicKey))
+	envelope := dsseEnvelope{
+		PayloadType: dssePayloadType,
+		Payload:     payload,
+		Signatures:  []dsseSignature{{KeyID: keyID, Sig: ed25519.Sign(key, dssePAE(dssePayloadType, payload))}},
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(ProvenancePath(opts.PackagePath), append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write provenance: %w", err)
+	}
+	return keyID, nil
+}
+
+// provenanceStatement describes the package and its binary with the source
+// commit, the source hash and the Go modules compiled into the binary
+func provenanceStatement(opts ProvenanceOptions) (*Statement, error) {
+	manifest := opts.Manifest
+	packageDigest, err := HashFile(opts.PackagePath)
+	if err != nil {
+		return nil, err
+	}
+	binaryDigest, err := HashFile(opts.BinaryPath)
+	if err != nil {
+		return nil, err
+	}
+	goos, goarch := targetPlatform()
+
+	external := map[string]string{
+		"skill":  manifest.Name,
+		"goos":   goos,
+		"goarch": goarch,
+	}
+	if manifest.Version != "" {
+		external["version"] = manifest.Version
+	}
+	if len(manifest.Build.Tags) > 0 {
+		external["tags"] = strings.Join(manifest.Build.Tags, ",")
+	}
+	internal := map[string]string{"ldflags": buildLDFlags(manifest)}
+	builderID := provenanceBuilderBase
+	if RemoteBuildNeeded(manifest) {
+		internal["remote"] = manifest.Build.Remote
+		builderID += "?remote=" + manifest.Build.Remote
+	}
+
+	var deps []ResourceDescriptor
+	git := GitStatus(manifest.Path)
+	if git.Commit != "" {
+		source := ResourceDescriptor{Name: "git", URI: gitRemote(manifest.Path), Digest: map[string]string{"gitCommit": git.Commit}}
+		if git.Dirty {
+			source.Name = "git (uncommitted changes)"
+		}
+		deps = append(deps, source)
+	}
+	if sourceHash, err := SkillHash(manifest); err == nil {
+		deps = append(deps, ResourceDescriptor{Name: "source", Digest: map[string]string{"sha256": sourceHash}})
+	}
+	goVersion := GoVersion(manifest.Path)
+	if info, err := buildinfo.ReadFile(opts.BinaryPath); err == nil {
+		goVersion = info.GoVersion
+		for _, dep := range info.Deps {
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			d := ResourceDescriptor{URI: "pkg:golang/" + dep.Path + "@" + dep.Version}
+			if dep.Sum != "" {
+				d.Digest = map[string]string{"goModuleH1": strings.TrimPrefix(dep.Sum, "h1:")}
+			}
+			deps = append(deps, d)
+		}
+	}
+
+	return &Statement{
+		Type: inTotoStatementType,
+		Subject: []ResourceDescriptor{
+			{Name: filepath.Base(opts.PackagePath), Digest: map[string]string{"sha256": packageDigest}},
+			{Name: "bin/" + manifest.BinaryName(), Digest: map[string]string{"sha256": binaryDigest}},
+		},
+		PredicateType: slsaProvenanceType,
+		Predicate: Provenance{
+			BuildDefinition: BuildDefinition{
+				BuildType:            provenanceBuildType,
+				ExternalParameters:   external,
+				InternalParameters:   internal,
+				ResolvedDependencies: deps,
+			},
+			RunDetails: RunDetails{
+				Builder: ProvenanceBuilder{
+					ID:      builderID,
+					Version: map[string]string{"skillfactory": opts.BuilderVersion, "go": goVersion},
+				},
+				Metadata: ProvenanceMetadata{StartedOn: opts.Started.UTC(), FinishedOn: opts.Finished.UTC()},
+			},
+		},
+	}, nil
+}
+
+// gitRemote returns the origin URL of the repository containing dir as
+// git+ URI, empty without one
+func gitRemote(dir string) string {
+	cmd := exec.Command("git", "remote", "get-url", "origin")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return "git+" + strings.TrimSpace(string(output))
+}
+
+// VerifiedProvenance is a provenance statement whose signature and package
+// digests were checked
+type VerifiedProvenance struct {
+	KeyID     string
+	Statement Statement
+}
+
+// VerifyProvenance checks the attestation of a package: the signature
+// against the public key (PEM), the package digest and the digests of the
+// files in the package named as subjects
+func VerifyProvenance(packagePath, attestationPath, publicKeyPath string) (*VerifiedProvenance, error) {
+	publicKey, err := readProvenancePublicKey(publicKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(attestationPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provenance: %w", err)
+	}
+	var envelope dsseEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid provenance: %w", err)
+	}
+	if envelope.PayloadType != dssePayloadType {
+		return nil, fmt.Errorf("invalid provenance: payload type %q", envelope.PayloadType)
+	}
+
+	keyID := provenanceKeyID(publicKey)
+	signed := false
+	for _, sig := range envelope.Signatures {
+		if ed25519.Verify(publicKey, dssePAE(envelope.PayloadType, envelope.Payload), sig.Sig) {
+			signed = true
+		}
+	}
+	if !signed {
+		return nil, fmt.Errorf("provenance is not signed by key %s", keyID)
+	}
+
+	var statement Statement
+	if err := json.Unmarshal(envelope.Payload, &statement); err != nil {
+		return nil, fmt.Errorf("invalid provenance: %w", err)
+	}
+	if statement.Type != inTotoStatementType || statement.PredicateType != slsaProvenanceType {
+		return nil, fmt.Errorf("invalid provenance: unexpected statement type %s", statement.PredicateType)
+	}
+	if err := verifySubjects(packagePath, statement.Subject); err != nil {
+		return nil, err
+	}
+	return &VerifiedProvenance{KeyID: keyID, Statement: statement}, nil
+}
+
+// verifySubjects compares the digests of the package and of the package
+// files named as subjects
+func verifySubjects(packagePath string, subjects []ResourceDescriptor) error {
+	expected := make(map[string]string)
+	for _, s := range subjects {
+		expected[s.Name] = s.Digest["sha256"]
+	}
+	packageName := filepath.Base(packagePath)
+	digest, err := HashFile(packagePath)
+	if err != nil {
+		return err
+	}
+	if want, ok := expected[packageName]; !ok {
+		return fmt.Errorf("provenance does not describe %s", packageName)
+	} else if want != digest {
+		return fmt.Errorf("%s does not match the provenance (sha256 %s, expected %s)", packageName, digest, want)
+	}
+	delete(expected, packageName)
+
+	f, err := os.Open(packagePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("invalid skill package: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid skill package: %w", err)
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		want, ok := expected[name]
+		if !ok {
+			continue
+		}
+		h := sha256.New()
+		if _, err := io.Copy(h, io.LimitReader(tr, maxPackageFileSize)); err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != want {
+			return fmt.Errorf("%s in the package does not match the provenance", name)
+		}
+		delete(expected, name)
+	}
+	for name := range expected {
+		return fmt.Errorf("%s of the provenance is missing in the package", name)
+	}
+	return nil
+}
+
+// dssePAE is the pre-authentication encoding of DSSE that is signed
+func dssePAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// provenanceKeyID identifies a public key by the start of its SHA-256
+func provenanceKeyID(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ProvenanceKey returns the signing key in the settings directory, creating
+// it with its public key on first use
+func ProvenanceKey() (ed25519.PrivateKey, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+	keyPath := filepath.Join(dir, provenanceKeyFile)
+	data, err := os.ReadFile(keyPath)
+	if err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("invalid provenance key %s", keyPath)
+		}
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid provenance key %s: %w", keyPath, err)
+		}
+		if key, ok := key.(ed25519.PrivateKey); ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("provenance key %s is no ed25519 key", keyPath)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	publicKey, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	private, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	public, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: private}), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write provenance key: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ProvenancePublicFile), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write provenance public key: %w", err)
+	}
+	return key, nil
+}
+
+// ProvenancePublicKeyPath returns the public key of ProvenanceKey, which
+// teams verifying packages need
+func ProvenancePublicKeyPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ProvenancePublicFile), nil
+}
+
+// readProvenancePublicKey reads a PEM encoded ed25519 public key
+func readProvenancePublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid public key %s", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key %s: %w", path, err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is no ed25519 key", path)
+	}
+	return publicKey, nil
+}
@@ -0,0 +1,95 @@
+package pipeline
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joho/godotenv"
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+)
+
+// DeployedValues reads the variable values of a deployed skill from its .env
+// or .env.enc, with keychain references replaced by the secrets. Variables
+// set from a command or a secret backend are left out: their deployed values
+// are resolved ones and would no longer follow the source.
+func DeployedValues(manifest *skill.Manifest, deployPath string) (map[string]string, error) {
+	binDir := filepath.Join(deployPath, "bin")
+	content, err := os.ReadFile(filepath.Join(binDir, ".env"))
+	if os.IsNotExist(err) {
+		data, encErr := os.ReadFile(filepath.Join(binDir, skillkit.EncryptedEnvFile))
+		if encErr != nil {
+			return nil, fmt.Errorf("no .env deployed to %s", deployPath)
+		}
+		passphrase, err := skillkit.EnvPassphrase(filepath.Base(deployPath))
+		if err != nil {
+			return nil, err
+		}
+		if content, err = skillkit.DecryptEnv(data, passphrase); err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", skillkit.EncryptedEnvFile, err)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	env, err := godotenv.Unmarshal(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the deployed .env: %w", err)
+	}
+	values := make(map[string]string)
+	for _, v := range manifest.Variables {
+		value, ok := env[v.Name]
+		if !ok || v.FromCommand() || v.Backend != "" {
+			continue
+		}
+		if service, account, ok := skillkit.ParseKeychainRef(value); ok {
+			if value, err = skillkit.GetSecret(service, account); err != nil {
+				return nil, fmt.Errorf("failed to read %s from keychain: %w", v.Name, err)
+			}
+		}
+		values[v.Name] = value
+	}
+	return values, nil
+}
+
+// DeployedProfile returns the name of the saved profile of a skill that
+// deploys to deployPath, empty if there is none. Profiles without a skills
+// folder deploy to skillsFolder.
+func DeployedProfile(manifest *skill.Manifest, deployPath, skillsFolder string) (string, error) {
+	profiles, err := config.LoadProfiles(manifest.Name)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range config.ProfileNames(profiles) {
+		profile := profiles[name]
+		folder := config.ExpandPath(cmp.Or(profile.SkillsFolder, skillsFolder))
+		if filepath.Join(folder, cmp.Or(profile.SkillFolderName, manifest.Name)) == filepath.Clean(deployPath) {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+// RedeployConfig deploys a new configuration with the binary already
+// deployed, for changes of the .env only. The caller checks that the source
+// is unchanged since that binary was built. deploy.lock keeps the build time
+// of the binary.
+func RedeployConfig(opts DeployOptions) (*Lock, error) {
+	opts.BinaryPath = filepath.Join(opts.DeployPath, "bin", opts.Manifest.BinaryName())
+	if _, err := os.Stat(opts.BinaryPath); err != nil {
+		return nil, fmt.Errorf("%s is not deployed to %s, deploy it first", opts.Manifest.Name, opts.DeployPath)
+	}
+	previous, _ := ReadLock(opts.DeployPath)
+	lock, err := Deploy(opts)
+	if err != nil || previous == nil {
+		return lock, err
+	}
+	lock.BuiltAt = previous.BuiltAt
+	if err := lock.Write(opts.DeployPath); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", LockFile, err)
+	}
+	return lock, nil
+}
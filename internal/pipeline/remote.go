@@ -0,0 +1,99 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// remoteBuildDir is the directory below the remote home the project is synced to
+const remoteBuildDir = ".cache/skillfactory/build"
+
+// RemoteBuildNeeded reports whether a skill must be built on its build.remote
+// host: cgo skills cannot be cross-compiled for another GOOS/GOARCH
+func RemoteBuildNeeded(manifest *skill.Manifest) bool {
+	if manifest.Build.Remote == "" || manifest.Build.CGO == nil || !*manifest.Build.CGO {
+		return false
+	}
+	goos, goarch := targetPlatform()
+	return goos != runtime.GOOS || goarch != runtime.GOARCH
+}
+
+// BuildRemote builds a skill on its build.remote host over SSH with agent
+// forwarding, so private modules can be fetched with the local keys. The
+// project is synced with rsync, go build runs in the skill directory and the
+// binary is copied back to outputPath. Build outputs and .env files are not
+// synced. Output is streamed to out.
+func BuildRemote(manifest *skill.Manifest, projectRoot, outputPath string, out io.Writer) error {
+	host := manifest.Build.Remote
+	rel, err := filepath.Rel(projectRoot, manifest.Path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("skill %s is outside the project %s", manifest.Name, projectRoot)
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// The remote host must be the target platform, it builds natively
+	goos, goarch := targetPlatform()
+	platform, err := exec.Command("ssh", host, "go env GOOS GOARCH").Output()
+	if err != nil {
+		return fmt.Errorf("failed to run go on %s: %w", host, err)
+	}
+	if fields := strings.Fields(string(platform)); len(fields) != 2 || fields[0] != goos || fields[1] != goarch {
+		return fmt.Errorf("%s builds for %s, not %s/%s", host, strings.Join(fields, "/"), goos, goarch)
+	}
+
+	remoteRoot := remoteBuildDir + "/" + filepath.Base(projectRoot)
+	remoteSkill := remoteRoot + "/" + filepath.ToSlash(rel)
+	binary := ".skillfactory-build/" + manifest.BinaryName()
+
+	// Only the build step needs the forwarded agent
+	sync := []string{"-az", "--delete", "--delete-excluded", "-e", "ssh"}
+	for _, pattern := range remoteSyncExcludes {
+		sync = append(sync, "--exclude", pattern)
+	}
+	sync = append(sync, projectRoot+"/", host+":"+remoteRoot+"/")
+
+	steps := []*exec.Cmd{
+		exec.Command("ssh", host, "mkdir -p "+shellQuote(remoteRoot)),
+		exec.Command("rsync", sync...),
+		exec.Command("ssh", "-A", host, remoteBuildCommand(manifest, remoteSkill, binary)),
+		exec.Command("rsync", "-az", "-e", "ssh", host+":"+remoteSkill+"/"+binary, outputPath),
+	}
+	for _, cmd := range steps {
+		fmt.Fprintf(out, "$ %s\n", strings.Join(cmd.Args, " "))
+		cmd.Stdout = out
+		cmd.Stderr = out
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("remote build on %s failed: %w", host, err)
+		}
+	}
+	return nil
+}
+
+// remoteSyncExcludes are the rsync patterns not synced to the build host:
+// the git history, build outputs and .env files with secrets. Excluded files
+// left from earlier syncs are deleted on the host.
+var remoteSyncExcludes = []string{".git", "/dist/", ".env", ".env.*"}
+
+// remoteBuildCommand returns the shell command running go build in dir
+func remoteBuildCommand(manifest *skill.Manifest, dir, outputPath string) string {
+	var b strings.Builder
+	b.WriteString("cd " + shellQuote(dir) + " && CGO_ENABLED=1 go")
+	for _, arg := range BuildArgs(manifest, outputPath) {
+		b.WriteString(" " + shellQuote(arg))
+	}
+	return b.String()
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
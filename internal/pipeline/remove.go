@@ -0,0 +1,42 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// deployedFiles lists the files and directories a deploy creates at the deploy path
+var deployedFiles = []string{"bin", "SKILL.md", DocsBaseFile, CommandsFile, ToolsFile, ".env", LockFile, MultiplexFile}
+
+// RemovalPaths returns the deployed files at deployPath that Remove deletes.
+// Other files in the skill folder are left alone.
+func RemovalPaths(deployPath string) ([]string, error) {
+	var paths []string
+	for _, name := range deployedFiles {
+		path := filepath.Join(deployPath, name)
+		if _, err := os.Lstat(path); err == nil {
+			paths = append(paths, path)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no deployed skill found at %s", deployPath)
+	}
+	return paths, nil
+}
+
+// Remove deletes the given deployed files and the deploy path itself if it
+// is empty afterwards
+func Remove(deployPath string, paths []string) error {
+	for _, path := range paths {
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	if entries, err := os.ReadDir(deployPath); err == nil && len(entries) == 0 {
+		os.Remove(deployPath)
+	}
+	return nil
+}
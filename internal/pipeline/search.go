@@ -0,0 +1,126 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SearchDoc is the searchable documentation of a deployed skill
+type SearchDoc struct {
+	Skill    string   // Skill name
+	Folder   string   // Folder name in the skills folder
+	Lines    []string // Lines of SKILL.md
+	Commands []Tool   // Nil without docs.formats json or mcp
+	Schema   string   // File the commands were read from
+}
+
+// SearchMatch is a command or a SKILL.md line matching a search
+type SearchMatch struct {
+	Skill   string `json:"skill"`
+	Folder  string `json:"folder"`
+	File    string `json:"file"`              // SKILL.md, commands.json or tools.json
+	Line    int    `json:"line,omitempty"`    // Line in SKILL.md
+	Command string `json:"command,omitempty"` // Command path for a command match
+	Text    string `json:"text"`              // Matching line or command description
+}
+
+// LoadSearchIndex reads the SKILL.md and command schemas of every skill
+// deployed in skillsFolder
+func LoadSearchIndex(skillsFolder string) ([]SearchDoc, error) {
+	deployed, err := ScanDeployed(skillsFolder, nil)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]SearchDoc, 0, len(deployed))
+	for _, d := range deployed {
+		doc := SearchDoc{Skill: d.Name, Folder: d.Folder}
+		if data, err := os.ReadFile(filepath.Join(d.Path, "SKILL.md")); err == nil {
+			doc.Lines = strings.Split(string(data), "\n")
+		}
+		doc.Commands, doc.Schema = readCommandSchemas(d.Path)
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// readCommandSchemas reads the commands of a deployed skill from
+// commands.json, or from tools.json if there is none, with the file name
+func readCommandSchemas(deployPath string) ([]Tool, string) {
+	var catalog CommandCatalog
+	if data, err := os.ReadFile(filepath.Join(deployPath, CommandsFile)); err == nil && json.Unmarshal(data, &catalog) == nil {
+		tools := make([]Tool, 0, len(catalog.Commands))
+		for _, cmd := range catalog.Commands {
+			tools = append(tools, commandTool(catalog.Name, cmd))
+		}
+		return tools, CommandsFile
+	}
+	var manifest ToolManifest
+	if data, err := os.ReadFile(filepath.Join(deployPath, ToolsFile)); err == nil && json.Unmarshal(data, &manifest) == nil {
+		return manifest.Tools, ToolsFile
+	}
+	return nil, ""
+}
+
+// Search finds the commands and SKILL.md lines containing all words of
+// query, ignoring case. Commands match on their path, description and flag
+// names and descriptions; they come before the SKILL.md lines of a skill.
+func Search(docs []SearchDoc, query string) []SearchMatch {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var matches []SearchMatch
+	for _, doc := range docs {
+		for _, tool := range doc.Commands {
+			if containsAll(commandText(tool), terms) {
+				matches = append(matches, SearchMatch{
+					Skill:   doc.Skill,
+					Folder:  doc.Folder,
+					File:    doc.Schema,
+					Command: tool.Command,
+					Text:    firstLine(tool.Description),
+				})
+			}
+		}
+		for i, line := range doc.Lines {
+			if containsAll(strings.ToLower(line), terms) {
+				matches = append(matches, SearchMatch{
+					Skill:  doc.Skill,
+					Folder: doc.Folder,
+					File:   "SKILL.md",
+					Line:   i + 1,
+					Text:   strings.TrimSpace(line),
+				})
+			}
+		}
+	}
+	return matches
+}
+
+// commandText returns the searchable text of a command in lower case
+func commandText(tool Tool) string {
+	parts := []string{tool.Command, tool.Description}
+	for name, prop := range tool.InputSchema.Properties {
+		parts = append(parts, name, prop.Description)
+	}
+	return strings.ToLower(strings.Join(parts, " "))
+}
+
+// containsAll reports whether s contains every term
+func containsAll(s string, terms []string) bool {
+	for _, term := range terms {
+		if !strings.Contains(s, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
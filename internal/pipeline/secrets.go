@@ -0,0 +1,137 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// BackendNames maps the secret backends to display names
+var BackendNames = map[string]string{
+	skill.BackendOnePassword: "1Password",
+	skill.BackendBitwarden:   "Bitwarden",
+	skill.BackendEnv:         "environment",
+}
+
+// BackendPlaceholder returns an example reference for a secret backend
+func BackendPlaceholder(backend string) string {
+	switch backend {
+	case skill.BackendOnePassword:
+		return "op://vault/item/field"
+	case skill.BackendBitwarden:
+		return "item/field"
+	case skill.BackendEnv:
+		return "VARIABLE_NAME"
+	}
+	return ""
+}
+
+// CheckSecretRef reports whether ref is a valid reference for backend
+func CheckSecretRef(backend, ref string) error {
+	switch backend {
+	case skill.BackendOnePassword:
+		parts := strings.Split(strings.TrimPrefix(ref, "op://"), "/")
+		if !strings.HasPrefix(ref, "op://") || len(parts) < 3 || parts[0] == "" || parts[1] == "" || parts[len(parts)-1] == "" {
+			return fmt.Errorf("expected op://vault/item/field, got %q", ref)
+		}
+	case skill.BackendBitwarden:
+		if item, _ := splitBitwardenRef(ref); item == "" {
+			return fmt.Errorf("expected item or item/field, got %q", ref)
+		}
+	case skill.BackendEnv:
+		if ref == "" || strings.ContainsAny(ref, "= \t") {
+			return fmt.Errorf("expected an environment variable name, got %q", ref)
+		}
+	default:
+		return fmt.Errorf("unknown secret backend %q", backend)
+	}
+	return nil
+}
+
+// ResolveSecret reads the secret a reference points to: with the 1Password
+// CLI (op read), the Bitwarden CLI (bw get, needs an unlocked vault via
+// BW_SESSION) or from the environment of SkillFactory
+func ResolveSecret(backend, ref string) (string, error) {
+	if err := CheckSecretRef(backend, ref); err != nil {
+		return "", err
+	}
+
+	switch backend {
+	case skill.BackendOnePassword:
+		return runSecretCLI("op", "read", "--no-newline", ref)
+	case skill.BackendBitwarden:
+		item, field := splitBitwardenRef(ref)
+		switch field {
+		case "password", "username", "totp", "notes", "uri":
+			return runSecretCLI("bw", "get", field, item)
+		}
+		return bitwardenField(item, field)
+	default:
+		value, ok := os.LookupEnv(ref)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", ref)
+		}
+		return value, nil
+	}
+}
+
+// splitBitwardenRef splits "item/field" at the last slash; the field
+// defaults to the password
+func splitBitwardenRef(ref string) (item, field string) {
+	i := strings.LastIndex(ref, "/")
+	if i < 0 {
+		return ref, "password"
+	}
+	item, field = ref[:i], ref[i+1:]
+	if field == "" {
+		field = "password"
+	}
+	return item, field
+}
+
+// bitwardenField reads a custom field of a Bitwarden item
+func bitwardenField(item, field string) (string, error) {
+	output, err := runSecretCLI("bw", "get", "item", item)
+	if err != nil {
+		return "", err
+	}
+	var data struct {
+		Fields []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(output), &data); err != nil {
+		return "", fmt.Errorf("failed to parse bw output: %w", err)
+	}
+	for _, f := range data.Fields {
+		if f.Name == field {
+			return f.Value, nil
+		}
+	}
+	return "", fmt.Errorf("bitwarden item %s has no field %s", item, field)
+}
+
+// runSecretCLI runs a password manager CLI and returns its output without
+// the trailing newline
+func runSecretCLI(name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%s not found in PATH", name)
+	}
+	cmd := exec.Command(name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s %s: %s", name, args[0], msg)
+		}
+		return "", fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
@@ -0,0 +1,95 @@
+package pipeline
+
+import "time"
+
+// Steps of a deploy. Deploy runs the steps up to StepLock, the callers run
+// the post_deploy hooks, the healthcheck and the cleanup of the build output.
+const (
+	StepBackup      = "Back up deployment"
+	StepBinary      = "Copy binary"
+	StepEnv         = "Write .env"
+	StepDocs        = "Generate docs"
+	StepLock        = "Write deploy.lock"
+	StepHooks       = "Run post_deploy hooks"
+	StepHealthcheck = "Smoke test"
+	StepCleanup     = "Clean up"
+)
+
+// States of a deploy step
+const (
+	StepPending = "pending"
+	StepRunning = "running"
+	StepDone    = "done"
+	StepFailed  = "failed"
+)
+
+// DeployStep is a step of a deploy with its outcome
+type DeployStep struct {
+	Name     string
+	State    string
+	Duration time.Duration
+	Err      error
+}
+
+// DeploySteps returns the pending steps of a deploy in the order they run.
+// The backup is listed if enabled in opts, the hooks and the smoke test if
+// skill.yaml declares them, the cleanup with cleanup set.
+func DeploySteps(opts DeployOptions, cleanup bool) []DeployStep {
+	manifest := opts.Manifest
+	var names []string
+	if opts.Backup {
+		names = append(names, StepBackup)
+	}
+	names = append(names, StepBinary, StepEnv, StepDocs, StepLock)
+	if len(manifest.Hooks.PostDeploy) > 0 {
+		names = append(names, StepHooks)
+	}
+	if manifest.Healthcheck != "" {
+		names = append(names, StepHealthcheck)
+	}
+	if cleanup {
+		names = append(names, StepCleanup)
+	}
+	steps := make([]DeployStep, len(names))
+	for i, name := range names {
+		steps[i] = DeployStep{Name: name, State: StepPending}
+	}
+	return steps
+}
+
+// UpdateStep replaces the step of the same name in steps
+func UpdateStep(steps []DeployStep, step DeployStep) {
+	for i := range steps {
+		if steps[i].Name == step.Name {
+			steps[i] = step
+		}
+	}
+}
+
+// FailedStep returns the first failed step, nil if none failed
+func FailedStep(steps []DeployStep) *DeployStep {
+	for i := range steps {
+		if steps[i].State == StepFailed {
+			return &steps[i]
+		}
+	}
+	return nil
+}
+
+// RunStep runs fn as the step name, reporting its start and outcome to
+// progress if set
+func RunStep(progress func(DeployStep), name string, fn func() error) error {
+	if progress == nil {
+		return fn()
+	}
+	progress(DeployStep{Name: name, State: StepRunning})
+	start := time.Now()
+	err := fn()
+	step := DeployStep{Name: name, State: StepDone, Duration: time.Since(start)}
+	if err != nil {
+		step.State = StepFailed
+		step.Err = err
+	}
+	progress(step)
+	return err
+}
@@ -0,0 +1,193 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+)
+
+// CommandDiff compares the commands of two builds of a skill
+type CommandDiff struct {
+	Added   []string        `json:"added"`   // Command paths only in the new build
+	Removed []string        `json:"removed"` // Command paths only in the old build
+	Changed []CommandChange `json:"changed"`
+}
+
+// CommandChange lists the changes of a command in both builds
+type CommandChange struct {
+	Path    string   `json:"path"`
+	Changes []string `json:"changes"` // e.g. "flag --due added"
+}
+
+// Empty reports whether both builds have the same commands
+func (d CommandDiff) Empty() bool {
+	return len(d.Added)+len(d.Removed)+len(d.Changed) == 0
+}
+
+// CommandSurface returns the commands of a skill binary with their flags, up
+// to maxDepth levels. Skills registering skillkit.DocsCommand describe
+// themselves; for others the --help output is parsed.
+func CommandSurface(binaryPath string, maxDepth int) ([]skillkit.CommandDoc, error) {
+	if docs, err := describeBinary(binaryPath); err == nil && len(docs.Commands) > 0 {
+		var commands []skillkit.CommandDoc
+		for _, cmd := range docs.Commands {
+			if len(strings.Fields(cmd.Path)) <= maxDepth {
+				commands = append(commands, cmd)
+			}
+		}
+		return commands, nil
+	}
+
+	output, err := runHelp(binaryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s --help: %w", binaryPath, err)
+	}
+	seen := map[string]bool{output: true}
+	return helpCommands(binaryPath, nil, parseSubcommands(output), maxDepth, seen), nil
+}
+
+// helpCommands describes the subcommands below parent from their --help
+// output, like extractHelpCommands
+func helpCommands(binaryPath string, parent, subcommands []string, maxDepth int, seen map[string]bool) []skillkit.CommandDoc {
+	var commands []skillkit.CommandDoc
+	for _, sub := range subcommands {
+		path := append(slices.Clone(parent), sub)
+		output, err := runHelp(binaryPath, path...)
+		if err != nil || seen[output] {
+			continue
+		}
+		seen[output] = true
+
+		commands = append(commands, skillkit.CommandDoc{
+			Path:        strings.Join(path, " "),
+			Usage:       parseUsage(output),
+			Description: parseDescription(output),
+			Flags:       parseFlagDocs(output),
+		})
+		if children := parseSubcommands(output); len(children) > 0 && len(path) < maxDepth {
+			commands = append(commands, helpCommands(binaryPath, path, children, maxDepth, seen)...)
+		}
+	}
+	return commands
+}
+
+// parseFlagDocs extracts the flag names, shorthands and types from Cobra
+// help output
+func parseFlagDocs(helpOutput string) []skillkit.FlagDoc {
+	var flags []skillkit.FlagDoc
+	for _, line := range parseFlags(helpOutput) {
+		// "`-t, --title` (string): Task title" as formatted by formatFlag
+		names, rest, _ := strings.Cut(strings.TrimPrefix(line, "`"), "`")
+		var f skillkit.FlagDoc
+		for _, name := range strings.Split(names, ", ") {
+			if long, ok := strings.CutPrefix(name, "--"); ok {
+				f.Name = long
+			} else {
+				f.Shorthand = strings.TrimPrefix(name, "-")
+			}
+		}
+		f.Type = "bool"
+		if typ, ok := strings.CutPrefix(rest, " ("); ok {
+			f.Type, _, _ = strings.Cut(typ, ")")
+		}
+		if f.Name != "" {
+			flags = append(flags, f)
+		}
+	}
+	return flags
+}
+
+// DiffDeployed compares the commands of the binary at binaryPath with the
+// binary deployed at deployPath, up to the docs.max_depth of the manifest
+func DiffDeployed(manifest *skill.Manifest, binaryPath, deployPath string) (CommandDiff, error) {
+	deployed := filepath.Join(deployPath, "bin", manifest.BinaryName())
+	if _, err := os.Stat(deployed); err != nil {
+		return CommandDiff{}, fmt.Errorf("no deployed binary at %s", deployed)
+	}
+	old, err := CommandSurface(deployed, manifest.Docs.Depth())
+	if err != nil {
+		return CommandDiff{}, err
+	}
+	current, err := CommandSurface(binaryPath, manifest.Docs.Depth())
+	if err != nil {
+		return CommandDiff{}, err
+	}
+	return DiffCommands(old, current), nil
+}
+
+// DiffCommands compares the commands of an old and a new build: added and
+// removed commands, and for commands in both, added and removed flags,
+// changed flag types, shorthands and usage
+func DiffCommands(old, current []skillkit.CommandDoc) CommandDiff {
+	diff := CommandDiff{Added: []string{}, Removed: []string{}, Changed: []CommandChange{}}
+	oldByPath := make(map[string]skillkit.CommandDoc, len(old))
+	for _, cmd := range old {
+		oldByPath[cmd.Path] = cmd
+	}
+	newByPath := make(map[string]skillkit.CommandDoc, len(current))
+	for _, cmd := range current {
+		newByPath[cmd.Path] = cmd
+	}
+
+	for _, cmd := range old {
+		if _, ok := newByPath[cmd.Path]; !ok {
+			diff.Removed = append(diff.Removed, cmd.Path)
+		}
+	}
+	for _, cmd := range current {
+		before, ok := oldByPath[cmd.Path]
+		if !ok {
+			diff.Added = append(diff.Added, cmd.Path)
+			continue
+		}
+		if changes := commandChanges(before, cmd); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, CommandChange{Path: cmd.Path, Changes: changes})
+		}
+	}
+	return diff
+}
+
+// commandChanges describes the differences of a command in two builds
+func commandChanges(old, current skillkit.CommandDoc) []string {
+	var changes []string
+	if old.Usage != current.Usage {
+		changes = append(changes, fmt.Sprintf("usage %q → %q", old.Usage, current.Usage))
+	}
+
+	oldFlags := make(map[string]skillkit.FlagDoc, len(old.Flags))
+	for _, f := range old.Flags {
+		oldFlags[f.Name] = f
+	}
+	newFlags := make(map[string]bool, len(current.Flags))
+	for _, f := range current.Flags {
+		newFlags[f.Name] = true
+		before, ok := oldFlags[f.Name]
+		switch {
+		case !ok:
+			changes = append(changes, "flag --"+f.Name+" added")
+		case before.Type != f.Type:
+			changes = append(changes, fmt.Sprintf("flag --%s: %s → %s", f.Name, before.Type, f.Type))
+		case before.Shorthand != f.Shorthand:
+			changes = append(changes, fmt.Sprintf("flag --%s: shorthand %s → %s", f.Name, orNone(before.Shorthand), orNone(f.Shorthand)))
+		}
+	}
+	for _, f := range old.Flags {
+		if !newFlags[f.Name] {
+			changes = append(changes, "flag --"+f.Name+" removed")
+		}
+	}
+	return changes
+}
+
+// orNone returns "-s" for a flag shorthand, "none" if there is none
+func orNone(shorthand string) string {
+	if shorthand == "" {
+		return "none"
+	}
+	return "-" + shorthand
+}
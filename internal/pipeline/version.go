@@ -0,0 +1,28 @@
+package pipeline
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DeployedVersion returns the version of the skill deployed at deployPath.
+// It prefers deploy.lock and falls back to running the binary with --version.
+func DeployedVersion(deployPath string, binaryName string) string {
+	if lock, err := ReadLock(deployPath); err == nil && lock.SkillVersion != "" {
+		return lock.SkillVersion
+	}
+
+	binaryPath := filepath.Join(deployPath, "bin", binaryName)
+	output, err := exec.Command(binaryPath, "--version").Output()
+	if err != nil {
+		return ""
+	}
+
+	// Cobra prints "<name> version <version>"
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"errors"
+	"os/exec"
+	"regexp"
+
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+// Vulncheck modes for build.vulncheck in skill.yaml
+const (
+	VulncheckOff   = "off"
+	VulncheckWarn  = "warn"
+	VulncheckBlock = "block"
+)
+
+// vulnIDPattern matches Go vulnerability database IDs in govulncheck output
+var vulnIDPattern = regexp.MustCompile(`GO-\d{4}-\d+`)
+
+// VulncheckResult holds the outcome of a govulncheck scan
+type VulncheckResult struct {
+	Ran        bool     // govulncheck was available and executed
+	Vulnerable bool     // known vulnerabilities affect the skill
+	IDs        []string // Vulnerability IDs found in the output
+	Output     string   // Raw govulncheck output
+}
+
+// VulncheckEnabled reports whether the manifest requests a vulnerability scan
+func VulncheckEnabled(manifest *skill.Manifest) bool {
+	mode := manifest.Build.Vulncheck
+	return mode == VulncheckWarn || mode == VulncheckBlock
+}
+
+// Vulncheck scans the skill module with govulncheck
+func Vulncheck(manifest *skill.Manifest) (VulncheckResult, error) {
+	path, err := exec.LookPath("govulncheck")
+	if err != nil {
+		return VulncheckResult{
+			Output: "govulncheck not found (install: go install golang.org/x/vuln/cmd/govulncheck@latest)",
+		}, nil
+	}
+
+	cmd := exec.Command(path, "./...")
+	cmd.Dir = manifest.Path
+	cmd.Env = BuildEnv(manifest)
+
+	output, err := cmd.CombinedOutput()
+	result := VulncheckResult{Ran: true, Output: string(output)}
+
+	// govulncheck exits with code 3 if vulnerabilities were found
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 3 {
+		result.Vulnerable = true
+		result.IDs = uniqueStrings(vulnIDPattern.FindAllString(result.Output, -1))
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// uniqueStrings returns values without duplicates, preserving order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
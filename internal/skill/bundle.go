@@ -0,0 +1,110 @@
+package skill
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BundleFile holds the bundle definitions, in the project root
+const BundleFile = "bundle.yaml"
+
+// Bundle is a named set of skills deployed together
+type Bundle struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	Skills      []string `yaml:"skills"`
+	Shared      []string `yaml:"shared,omitempty"` // Variables entered once for all skills declaring them
+	Target      string   `yaml:"target,omitempty"` // Default skills folder
+}
+
+// bundleFile is the content of bundle.yaml
+type bundleFile struct {
+	Bundles []Bundle `yaml:"bundles"`
+}
+
+// SharedVariable is a variable offered for sharing in a bundle
+type SharedVariable struct {
+	Name   string
+	Skills []string // Skills of the bundle declaring the variable
+}
+
+// LoadBundles reads the bundles of bundle.yaml, none if the file does not exist
+func LoadBundles(projectRoot string) ([]Bundle, error) {
+	data, err := os.ReadFile(filepath.Join(projectRoot, BundleFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var file bundleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", BundleFile, err)
+	}
+	return file.Bundles, nil
+}
+
+// SaveBundle validates b and writes it to bundle.yaml, replacing the bundle
+// named previous (empty for a new bundle)
+func SaveBundle(projectRoot string, b Bundle, previous string) error {
+	if b.Name == "" {
+		return fmt.Errorf("bundle name is required")
+	}
+	if len(b.Skills) == 0 {
+		return fmt.Errorf("select at least one skill")
+	}
+
+	bundles, err := LoadBundles(projectRoot)
+	if err != nil {
+		return err
+	}
+	index := -1
+	for i, existing := range bundles {
+		switch {
+		case previous != "" && existing.Name == previous:
+			index = i
+		case existing.Name == b.Name:
+			return fmt.Errorf("bundle %q already exists", b.Name)
+		}
+	}
+	if index >= 0 {
+		bundles[index] = b
+	} else {
+		bundles = append(bundles, b)
+	}
+
+	data, err := yaml.Marshal(bundleFile{Bundles: bundles})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(projectRoot, BundleFile), data, 0644)
+}
+
+// SharedVariables lists the variables of the given skills in manifest order.
+// Variables declared by several skills come first since sharing them saves
+// entering the same value twice.
+func SharedVariables(manifests []*Manifest, skills []string) []SharedVariable {
+	var vars []SharedVariable
+	index := make(map[string]int)
+	for _, m := range manifests {
+		if !slices.Contains(skills, m.Name) {
+			continue
+		}
+		for _, v := range m.Variables {
+			if i, ok := index[v.Name]; ok {
+				vars[i].Skills = append(vars[i].Skills, m.Name)
+				continue
+			}
+			index[v.Name] = len(vars)
+			vars = append(vars, SharedVariable{Name: v.Name, Skills: []string{m.Name}})
+		}
+	}
+	slices.SortStableFunc(vars, func(a, b SharedVariable) int {
+		return min(len(b.Skills), 2) - min(len(a.Skills), 2)
+	})
+	return vars
+}
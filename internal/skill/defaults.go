@@ -0,0 +1,176 @@
+package skill
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultsFile holds settings inherited by every skill.yaml of the skills
+// directory, e.g. skills/_defaults.yaml
+const DefaultsFile = "_defaults.yaml"
+
+// DefaultsFields lists the skill.yaml fields a defaults file may set
+var DefaultsFields = []string{"variables", "build", "docs", "hooks", "requires", "redact"}
+
+// DefaultsPath returns the defaults file a skill in skillDir inherits from
+func DefaultsPath(skillDir string) string {
+	return filepath.Join(filepath.Dir(skillDir), DefaultsFile)
+}
+
+// loadDefaults parses and validates the defaults file at path, nil if it
+// does not exist. Line numbers of the returned mapping are cleared, so issues
+// about inherited values carry no line of skill.yaml.
+func loadDefaults(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", DefaultsFile, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", DefaultsFile, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s must contain a mapping", DefaultsFile)
+	}
+
+	var issues []Issue
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if !slices.Contains(DefaultsFields, key.Value) {
+			issues = append(issues, Issue{Field: key.Value, Line: key.Line, Message: "cannot be inherited"})
+			continue
+		}
+		manifestSchema.Properties[key.Value].validate(value, key.Value, &issues)
+	}
+	if len(issues) > 0 {
+		return nil, fmt.Errorf("invalid %s: %s", DefaultsFile, issues[0])
+	}
+	var manifest Manifest
+	if err := root.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", DefaultsFile, err)
+	}
+
+	clearLines(root)
+	return root, nil
+}
+
+// inheritDefaults merges the defaults mapping into the skill.yaml mapping
+// root. Values of skill.yaml win: mappings are merged field by field, lists
+// and scalars replace the default. Variables are merged by name, inherited
+// variables the skill does not declare follow its own.
+func inheritDefaults(root, defaults *yaml.Node) {
+	for i := 0; i+1 < len(defaults.Content); i += 2 {
+		key, value := defaults.Content[i], defaults.Content[i+1]
+		current := mappingValue(root, key.Value)
+		switch {
+		case current == nil || (current.Kind == yaml.ScalarNode && current.Tag == "!!null"):
+			setMappingValue(root, key, value)
+		case key.Value == "variables" && current.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode:
+			inheritVariables(current, value)
+		default:
+			mergeMapping(current, value)
+		}
+	}
+}
+
+// inheritVariables merges the default variables into the variables of a skill
+func inheritVariables(vars, defaults *yaml.Node) {
+	for _, def := range defaults.Content {
+		name := mappingValue(def, "name")
+		var declared *yaml.Node
+		for _, v := range vars.Content {
+			if n := mappingValue(v, "name"); n != nil && name != nil && n.Value == name.Value {
+				declared = v
+				break
+			}
+		}
+		if declared == nil {
+			vars.Content = append(vars.Content, def)
+			continue
+		}
+		mergeMapping(declared, def)
+	}
+}
+
+// mergeMapping adds the fields of defaults missing in node and merges nested
+// mappings. Other values of node are kept.
+func mergeMapping(node, defaults *yaml.Node) {
+	if node.Kind != yaml.MappingNode || defaults.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(defaults.Content); i += 2 {
+		key, value := defaults.Content[i], defaults.Content[i+1]
+		current := mappingValue(node, key.Value)
+		if current == nil || (current.Kind == yaml.ScalarNode && current.Tag == "!!null") {
+			setMappingValue(node, key, value)
+			continue
+		}
+		mergeMapping(current, value)
+	}
+}
+
+// setMappingValue sets key to value in a mapping node, replacing an
+// existing value
+func setMappingValue(node, key, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key.Value {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, key, value)
+}
+
+// clearLines sets the line and column of node and its children to 0
+func clearLines(node *yaml.Node) {
+	node.Line, node.Column = 0, 0
+	for _, child := range node.Content {
+		clearLines(child)
+	}
+}
+
+// manifestDocument parses the skill.yaml in skillDir with the defaults it
+// inherits merged in
+func manifestDocument(skillDir string) (*yaml.Node, error) {
+	data, err := os.ReadFile(filepath.Join(skillDir, "skill.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skill.yaml: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse skill.yaml: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("skill.yaml must contain a mapping")
+	}
+	defaults, err := loadDefaults(DefaultsPath(skillDir))
+	if err != nil {
+		return nil, err
+	}
+	if defaults != nil {
+		inheritDefaults(doc.Content[0], defaults)
+	}
+	return &doc, nil
+}
+
+// EffectiveManifest returns the skill.yaml of skillDir with the inherited
+// defaults merged in, e.g. for a package that is installed without them
+func EffectiveManifest(skillDir string) ([]byte, error) {
+	doc, err := manifestDocument(skillDir)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc)
+}
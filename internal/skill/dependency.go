@@ -0,0 +1,157 @@
+package skill
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Dependencies declares what a skill builds on in the repository
+type Dependencies struct {
+	Skills    []string `yaml:"skills"`    // Skills deployed before this one, by name
+	Libraries []string `yaml:"libraries"` // Shared Go packages relative to the project root, e.g. pkg/skillkit
+}
+
+// resolveDependencies checks the dependencies of the loaded skills and sets
+// their library paths. Skills with a missing library, an unknown or failed
+// dependency or a dependency cycle are moved to the errors, and with them
+// the skills depending on them.
+func resolveDependencies(baseDir string, manifests []*Manifest, errors []SkillError) ([]*Manifest, []SkillError) {
+	failed := make(map[string]error)
+	for _, e := range errors {
+		failed[e.Name] = e.Error
+	}
+	byName := make(map[string]*Manifest, len(manifests))
+	for _, m := range manifests {
+		byName[m.Name] = m
+	}
+
+	reasons := make(map[string]error)
+	for _, m := range manifests {
+		m.Libraries = nil
+		for _, lib := range m.Dependencies.Libraries {
+			path := filepath.Join(baseDir, lib)
+			if info, err := os.Stat(path); err != nil || !info.IsDir() {
+				reasons[m.Name] = fmt.Errorf("library %s not found in the project", lib)
+				break
+			}
+			m.Libraries = append(m.Libraries, path)
+		}
+		for _, dep := range m.Dependencies.Skills {
+			if reasons[m.Name] != nil {
+				break
+			}
+			switch {
+			case failed[dep] != nil:
+				reasons[m.Name] = fmt.Errorf("depends on %s, which failed to load", dep)
+			case byName[dep] == nil:
+				reasons[m.Name] = fmt.Errorf("depends on unknown skill %s", dep)
+			}
+		}
+	}
+
+	for _, cycle := range dependencyCycles(manifests, byName) {
+		err := fmt.Errorf("dependency cycle %s", strings.Join(cycle, " → "))
+		for _, name := range cycle {
+			if reasons[name] == nil {
+				reasons[name] = err
+			}
+		}
+	}
+
+	// Skills depending on a skill that failed fail as well
+	for changed := true; changed; {
+		changed = false
+		for _, m := range manifests {
+			if reasons[m.Name] != nil {
+				continue
+			}
+			for _, dep := range m.Dependencies.Skills {
+				if reasons[dep] != nil {
+					reasons[m.Name] = fmt.Errorf("depends on %s: %w", dep, reasons[dep])
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var valid []*Manifest
+	for _, m := range manifests {
+		if err := reasons[m.Name]; err != nil {
+			errors = append(errors, SkillError{Name: m.Name, Path: m.Path, Kind: ErrDependency, Error: err})
+			continue
+		}
+		valid = append(valid, m)
+	}
+	return valid, errors
+}
+
+// dependencyCycles returns the dependency cycles between skills, each as the
+// skill names along the cycle with the first one repeated at the end
+func dependencyCycles(manifests []*Manifest, byName map[string]*Manifest) [][]string {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var cycles [][]string
+	var path []string
+
+	var visit func(name string)
+	visit = func(name string) {
+		switch state[name] {
+		case visiting:
+			start := slices.Index(path, name)
+			cycles = append(cycles, append(slices.Clone(path[start:]), name))
+			return
+		case done:
+			return
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range byName[name].Dependencies.Skills {
+			if byName[dep] != nil {
+				visit(dep)
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+	}
+	for _, m := range manifests {
+		visit(m.Name)
+	}
+	return cycles
+}
+
+// DependencyOrder returns the named skills together with the skills they
+// depend on, each after its dependencies and otherwise in the given order.
+// Names of unknown skills are kept in place.
+func DependencyOrder(manifests []*Manifest, names []string) []string {
+	byName := make(map[string]*Manifest, len(manifests))
+	for _, m := range manifests {
+		byName[m.Name] = m
+	}
+
+	var order []string
+	added := make(map[string]bool)
+	var add func(name string)
+	add = func(name string) {
+		if added[name] {
+			return
+		}
+		added[name] = true
+		if m := byName[name]; m != nil {
+			for _, dep := range m.Dependencies.Skills {
+				add(dep)
+			}
+		}
+		order = append(order, name)
+	}
+	for _, name := range names {
+		add(name)
+	}
+	return order
+}
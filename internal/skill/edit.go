@@ -0,0 +1,272 @@
+package skill
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestEdit holds the skill.yaml fields changed by the TUI manifest editor
+type ManifestEdit struct {
+	Name        string
+	Description string
+	Version     string
+	Variables   []VariableEdit
+
+	// Build settings
+	Binary    string
+	LDFlags   string
+	Tags      []string
+	Trimpath  bool
+	Test      bool
+	Vulncheck string
+}
+
+// VariableEdit is a variable in the editor
+type VariableEdit struct {
+	Variable
+	Index int // Position in skill.yaml, -1 for a new variable
+}
+
+// NewManifestEdit returns the editable fields of a manifest
+func NewManifestEdit(m *Manifest) *ManifestEdit {
+	e := &ManifestEdit{
+		Name:        m.Name,
+		Description: m.Description,
+		Version:     m.Version,
+		Binary:      m.Build.Binary,
+		LDFlags:     m.Build.LDFlags,
+		Tags:        append([]string{}, m.Build.Tags...),
+		Trimpath:    m.Build.Trimpath,
+		Test:        m.Build.Test,
+		Vulncheck:   m.Build.Vulncheck,
+	}
+	for i, v := range m.Variables {
+		e.Variables = append(e.Variables, VariableEdit{Variable: v, Index: i})
+	}
+	return e
+}
+
+// Save writes the edited fields to the skill.yaml at manifestPath. Only
+// changed values are touched; comments, key order and other fields are kept.
+// Empty values remove their key.
+func (e *ManifestEdit) Save(manifestPath string) error {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read skill.yaml: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse skill.yaml: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("skill.yaml must contain a mapping")
+	}
+
+	setString(root, "name", e.Name)
+	setString(root, "description", e.Description)
+	setString(root, "version", e.Version)
+	if err := e.saveVariables(root); err != nil {
+		return err
+	}
+
+	build := mappingValue(root, "build")
+	if build == nil || build.Kind != yaml.MappingNode {
+		build = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	setString(build, "binary", e.Binary)
+	setString(build, "ldflags", e.LDFlags)
+	setStrings(build, "tags", e.Tags)
+	setBool(build, "trimpath", e.Trimpath)
+	setBool(build, "test", e.Test)
+	setString(build, "vulncheck", e.Vulncheck)
+	if len(build.Content) > 0 && mappingValue(root, "build") != build {
+		setNode(root, "build", build)
+	}
+
+	out, err := encodeNode(&doc)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath, restoreBlankLines(data, out), 0644)
+}
+
+// restoreBlankLines re-inserts the blank lines of the original file, which
+// the YAML encoder drops, before the lines they preceded
+func restoreBlankLines(original, encoded []byte) []byte {
+	var anchors []string
+	blank := false
+	for _, line := range strings.Split(string(original), "\n") {
+		if strings.TrimSpace(line) == "" {
+			blank = true
+			continue
+		}
+		if blank {
+			anchors = append(anchors, strings.TrimRight(line, " \t"))
+		}
+		blank = false
+	}
+
+	var b strings.Builder
+	next := 0
+	for _, line := range strings.Split(string(encoded), "\n") {
+		for i := next; i < len(anchors); i++ {
+			if anchors[i] == line {
+				b.WriteString("\n")
+				next = i + 1
+				break
+			}
+		}
+		b.WriteString(line + "\n")
+	}
+	return []byte(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// saveVariables rebuilds the variables sequence, reusing the nodes (and
+// comments) of existing variables
+func (e *ManifestEdit) saveVariables(root *yaml.Node) error {
+	existing := mappingValue(root, "variables")
+	var old []*yaml.Node
+	if existing != nil && existing.Kind == yaml.SequenceNode {
+		old = existing.Content
+	}
+
+	var content []*yaml.Node
+	for _, v := range e.Variables {
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if v.Index >= 0 {
+			if v.Index >= len(old) || old[v.Index].Kind != yaml.MappingNode {
+				return fmt.Errorf("variables.%d: not found in skill.yaml", v.Index)
+			}
+			node = old[v.Index]
+		}
+		setString(node, "name", v.Name)
+		setString(node, "label", v.Label)
+		setString(node, "description", v.Description)
+		setBool(node, "required", v.Required)
+		setString(node, "placeholder", v.Placeholder)
+		setString(node, "default", v.Default)
+		setString(node, "type", v.Type)
+		content = append(content, node)
+	}
+
+	switch {
+	case existing != nil && existing.Kind == yaml.SequenceNode:
+		existing.Content = content
+		if len(content) == 0 {
+			deleteKey(root, "variables")
+		}
+	case len(content) > 0:
+		setNode(root, "variables", &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: content})
+	}
+	return nil
+}
+
+// setString sets a scalar in a mapping if it changed; an empty value removes the key
+func setString(mapping *yaml.Node, key, value string) {
+	existing := mappingValue(mapping, key)
+	if value == "" {
+		deleteKey(mapping, key)
+		return
+	}
+	if existing != nil && existing.Kind == yaml.ScalarNode && existing.Value == value {
+		return
+	}
+	// Tagged as string, the encoder quotes values like "true" or "123"
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if existing != nil {
+		// Keep comments and quoting of the old value
+		node.HeadComment, node.LineComment, node.FootComment = existing.HeadComment, existing.LineComment, existing.FootComment
+		if existing.Kind == yaml.ScalarNode && existing.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+			node.Style = existing.Style
+		}
+	}
+	setNode(mapping, key, node)
+}
+
+// setBool sets a bool in a mapping if it changed. A missing key stays
+// missing for false, an existing one is set to false.
+func setBool(mapping *yaml.Node, key string, value bool) {
+	existing := mappingValue(mapping, key)
+	if existing == nil && !value {
+		return
+	}
+	if existing != nil && existing.Kind == yaml.ScalarNode {
+		var current bool
+		if existing.Decode(&current) == nil && current == value {
+			return
+		}
+	}
+	node := &yaml.Node{}
+	setScalar(node, strconv.FormatBool(value))
+	if existing != nil {
+		node.HeadComment, node.LineComment, node.FootComment = existing.HeadComment, existing.LineComment, existing.FootComment
+	}
+	setNode(mapping, key, node)
+}
+
+// setStrings sets a list of strings as flow sequence if it changed; an empty list removes the key
+func setStrings(mapping *yaml.Node, key string, values []string) {
+	existing := mappingValue(mapping, key)
+	if len(values) == 0 {
+		deleteKey(mapping, key)
+		return
+	}
+	if existing != nil {
+		var current []string
+		if existing.Decode(&current) == nil && equalStrings(current, values) {
+			return
+		}
+	}
+	node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+	if existing != nil && existing.Kind == yaml.SequenceNode {
+		node.Style = existing.Style
+		node.HeadComment, node.LineComment, node.FootComment = existing.HeadComment, existing.LineComment, existing.FootComment
+	}
+	for _, v := range values {
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v})
+	}
+	setNode(mapping, key, node)
+}
+
+// setNode replaces the value of key in a mapping or appends the key
+func setNode(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// deleteKey removes key and its value from a mapping
+func deleteKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
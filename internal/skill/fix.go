@@ -0,0 +1,246 @@
+package skill
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetField sets a dotted field path (e.g. "variables.1.type") in a skill.yaml
+// to value and writes the file back. Existing scalars are replaced in place and
+// missing keys are inserted as new lines, so comments and blank lines survive.
+func SetField(manifestPath string, field string, value string) error {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read skill.yaml: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse skill.yaml: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+
+	path := strings.Split(field, ".")
+	parent, err := lookup(doc.Content[0], path[:len(path)-1])
+	if err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	key := path[len(path)-1]
+	lines := strings.Split(string(data), "\n")
+
+	// Replace an existing single-line scalar in place
+	if existing := childNode(parent, key); existing != nil {
+		if existing.Kind == yaml.ScalarNode && existing.Line > 0 && !strings.Contains(existing.Value, "\n") {
+			if edited, ok := replaceScalar(lines, existing, value); ok {
+				return os.WriteFile(manifestPath, []byte(strings.Join(edited, "\n")), 0644)
+			}
+		}
+	} else if parent.Kind == yaml.MappingNode {
+		// Insert a missing key next to the first key of the mapping
+		if edited, ok := insertKey(lines, parent, key, value); ok {
+			return os.WriteFile(manifestPath, []byte(strings.Join(edited, "\n")), 0644)
+		}
+	}
+
+	// Fall back to re-encoding the document
+	node, err := lookupOrCreate(parent, []string{key})
+	if err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	setScalar(node, value)
+	return writeNode(manifestPath, &doc)
+}
+
+// lookup walks a path through mappings and sequences, creating missing mappings
+func lookup(node *yaml.Node, path []string) (*yaml.Node, error) {
+	for _, key := range path {
+		next, err := lookupOrCreate(node, []string{key})
+		if err != nil {
+			return nil, err
+		}
+		if next.Kind == yaml.ScalarNode && next.Value == "" {
+			next.Kind = yaml.MappingNode
+			next.Tag = "!!map"
+		}
+		node = next
+	}
+	return node, nil
+}
+
+// childNode returns the child for key in a mapping (by name) or sequence (by index)
+func childNode(node *yaml.Node, key string) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return mappingValue(node, key)
+	case yaml.SequenceNode:
+		if idx, err := strconv.Atoi(key); err == nil && idx >= 0 && idx < len(node.Content) {
+			return node.Content[idx]
+		}
+	}
+	return nil
+}
+
+// lookupOrCreate walks a path through mappings and sequences, creating missing mapping keys
+func lookupOrCreate(node *yaml.Node, path []string) (*yaml.Node, error) {
+	if len(path) == 0 {
+		return node, nil
+	}
+	key := path[0]
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		if value := mappingValue(node, key); value != nil {
+			return lookupOrCreate(value, path[1:])
+		}
+		value := &yaml.Node{Kind: yaml.ScalarNode}
+		if len(path) > 1 {
+			value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+		return lookupOrCreate(value, path[1:])
+	case yaml.SequenceNode:
+		idx, err := strconv.Atoi(key)
+		if err != nil || idx < 0 || idx >= len(node.Content) {
+			return nil, fmt.Errorf("invalid index %q", key)
+		}
+		return lookupOrCreate(node.Content[idx], path[1:])
+	default:
+		return nil, fmt.Errorf("cannot descend into scalar at %q", key)
+	}
+}
+
+// replaceScalar swaps the scalar token at node's position, keeping trailing comments
+func replaceScalar(lines []string, node *yaml.Node, value string) ([]string, bool) {
+	idx := node.Line - 1
+	if idx >= len(lines) || node.Column < 1 || node.Column-1 > len(lines[idx]) {
+		return nil, false
+	}
+	line := lines[idx]
+	start := node.Column - 1
+	rest := line[start:]
+
+	// Determine where the old token ends
+	var end int
+	switch {
+	case strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, "'"):
+		closing := strings.Index(rest[1:], rest[:1])
+		if closing < 0 {
+			return nil, false
+		}
+		end = closing + 2
+	default:
+		end = len(rest)
+		if i := strings.Index(rest, " #"); i >= 0 {
+			end = i
+		}
+		end = len(strings.TrimRight(rest[:end], " \t"))
+	}
+
+	edited := append([]string{}, lines...)
+	edited[idx] = line[:start] + formatScalar(value) + rest[end:]
+	return edited, true
+}
+
+// insertKey adds "key: value" as a new line next to the first key of a mapping
+func insertKey(lines []string, mapping *yaml.Node, key, value string) ([]string, bool) {
+	newLine := key + ": " + formatScalar(value)
+
+	// Empty mapping or empty file: only handle the top-level case
+	if len(mapping.Content) < 2 {
+		if mapping.Line <= 1 {
+			return append([]string{newLine}, lines...), true
+		}
+		return nil, false
+	}
+
+	first := mapping.Content[0]
+	firstValue := mapping.Content[1]
+	idx := first.Line - 1
+	if idx >= len(lines) {
+		return nil, false
+	}
+	indent := strings.Repeat(" ", first.Column-1)
+	line := lines[idx]
+
+	var edited []string
+	if strings.TrimSpace(line[:first.Column-1]) == "" {
+		// Plain mapping: insert before the first key
+		edited = append(edited, lines[:idx]...)
+		edited = append(edited, indent+newLine)
+		edited = append(edited, lines[idx:]...)
+		return edited, true
+	}
+
+	// Sequence item ("- key: value"): insert after the first key if its value is a one-line scalar
+	if firstValue.Kind != yaml.ScalarNode || firstValue.Line != first.Line {
+		return nil, false
+	}
+	edited = append(edited, lines[:idx+1]...)
+	edited = append(edited, indent+newLine)
+	edited = append(edited, lines[idx+1:]...)
+	return edited, true
+}
+
+// formatScalar renders a value as YAML scalar, quoting only when required
+func formatScalar(value string) string {
+	node := &yaml.Node{}
+	setScalar(node, value)
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return strconv.Quote(value)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// setScalar replaces a node with a scalar, tagged as bool or int when the value looks like one
+func setScalar(node *yaml.Node, value string) {
+	node.Kind = yaml.ScalarNode
+	node.Content = nil
+	node.Value = value
+	node.Style = 0
+
+	switch {
+	case value == "true" || value == "false":
+		node.Tag = "!!bool"
+	case isInt(value):
+		node.Tag = "!!int"
+	default:
+		node.Tag = "!!str"
+	}
+}
+
+// writeNode encodes a YAML document with two-space indentation
+func writeNode(path string, doc *yaml.Node) error {
+	data, err := encodeNode(doc)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// encodeNode encodes a YAML document with two-space indentation
+func encodeNode(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode skill.yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func isInt(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
@@ -5,6 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
@@ -18,12 +23,67 @@ type Variable struct {
 	Placeholder string `yaml:"placeholder"`
 	Default     string `yaml:"default"`
 	Type        string `yaml:"type"` // string, secret, json
+
+	// Source "command" takes the value from the output of Command at deploy
+	// time (e.g. "op read op://vault/item/token") instead of the TUI. Source
+	// "skill" keeps the value in the TUI but offers the output of the skill
+	// command Command (e.g. "projects list") as choices there.
+	Source  string `yaml:"source"`
+	Command string `yaml:"command"`
+
+	// Fields of the objects listed by a skill source: the value to set and
+	// the label shown (default "id", and "title" or "name")
+	OptionValue string `yaml:"option_value"`
+	OptionLabel string `yaml:"option_label"`
+
+	// Backend reads a secret from a password manager at deploy time; the
+	// configured value is then a reference like "op://vault/item/field"
+	Backend string `yaml:"backend"` // 1password, bitwarden, env
+}
+
+// Secret backends resolving variable references at deploy time
+const (
+	BackendOnePassword = "1password" // op://vault/item/field via the 1Password CLI
+	BackendBitwarden   = "bitwarden" // item/field via the Bitwarden CLI
+	BackendEnv         = "env"       // Name of an environment variable
+)
+
+// SecretBackends lists the supported secret backends
+var SecretBackends = []string{BackendOnePassword, BackendBitwarden, BackendEnv}
+
+// SourceCommand marks a variable whose value is the output of a command
+const SourceCommand = "command"
+
+// SourceSkill marks a variable chosen from the output of a skill command
+const SourceSkill = "skill"
+
+// FromCommand reports whether the variable value comes from a command
+func (v Variable) FromCommand() bool {
+	return v.Source == SourceCommand
+}
+
+// HasChoices reports whether the value can be chosen from the output of a
+// skill command
+func (v Variable) HasChoices() bool {
+	return v.Source == SourceSkill
 }
 
 // BuildConfig holds build configuration
 type BuildConfig struct {
-	Entry  string `yaml:"entry"`
-	Binary string `yaml:"binary"`
+	Entry    string   `yaml:"entry"`
+	Binary   string   `yaml:"binary"`
+	LDFlags  string   `yaml:"ldflags"`  // Passed to -ldflags, supports {{version}}, {{commit}}, {{name}}, {{binary}}
+	Tags     []string `yaml:"tags"`     // Build tags
+	CGO      *bool    `yaml:"cgo"`      // Sets CGO_ENABLED if specified
+	Trimpath bool     `yaml:"trimpath"` // Build with -trimpath
+	Test     bool     `yaml:"test"`     // Run go test ./... before building, refuse deploy on failure
+
+	// Vulncheck runs govulncheck before deploy: "off" (default), "warn" or "block"
+	Vulncheck string `yaml:"vulncheck"`
+
+	// Remote is an SSH host (user@host) building cgo skills for another
+	// GOOS/GOARCH, which cannot be cross-compiled
+	Remote string `yaml:"remote"`
 }
 
 // DeployFile represents a file to deploy
@@ -38,10 +98,105 @@ type DeployConfig struct {
 	Wrapper bool         `yaml:"wrapper"`
 }
 
+// Commands is a list of shell commands. In YAML it may be a single string or a list.
+type Commands []string
+
+// UnmarshalYAML accepts a scalar or a sequence of strings
+func (c *Commands) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		if value.Value != "" {
+			*c = Commands{value.Value}
+		}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*c = list
+	return nil
+}
+
+// HooksConfig holds shell commands run around build and deploy
+type HooksConfig struct {
+	PreBuild   Commands `yaml:"pre_build"`
+	PostBuild  Commands `yaml:"post_build"`
+	PostDeploy Commands `yaml:"post_deploy"`
+}
+
+// Any reports whether any hook is configured
+func (h HooksConfig) Any() bool {
+	return len(h.PreBuild)+len(h.PostBuild)+len(h.PostDeploy) > 0
+}
+
+// DefaultDocsDepth is the command nesting documented in SKILL.md unless
+// docs.max_depth is set
+const DefaultDocsDepth = 5
+
 // DocsConfig holds documentation configuration
 type DocsConfig struct {
-	Template string `yaml:"template"`
-	Output   string `yaml:"output"`
+	Template string     `yaml:"template"`
+	Output   string     `yaml:"output"`
+	MaxDepth int        `yaml:"max_depth"` // Levels of subcommands documented, DefaultDocsDepth if 0
+	Formats  []string   `yaml:"formats"`   // Generated formats, see DocsFormats; SKILL.md is always written
+	Data     []DocsData `yaml:"data"`      // Commands whose output is embedded at {{DATA:name}}
+}
+
+// DocsData is a read-only command of the skill run at deploy time. Its JSON
+// output is embedded into SKILL.md at {{DATA:name}}, e.g. the projects of the
+// user's account with their IDs.
+type DocsData struct {
+	Name    string   `yaml:"name"`
+	Command string   `yaml:"command"` // Arguments after the binary, e.g. "projects list"
+	Columns []string `yaml:"columns"` // Fields shown of each entry, all fields of the first if empty
+}
+
+// DataCommand returns the docs.data entry called name
+func (d DocsConfig) DataCommand(name string) (DocsData, bool) {
+	for _, data := range d.Data {
+		if data.Name == name {
+			return data, true
+		}
+	}
+	return DocsData{}, false
+}
+
+// Documentation formats of docs.formats
+const (
+	DocsFormatMarkdown = "markdown" // SKILL.md
+	DocsFormatJSON     = "json"     // Machine-readable command catalog
+	DocsFormatMCP      = "mcp"      // MCP-style tool manifest
+)
+
+// DocsFormats lists the supported documentation formats
+var DocsFormats = []string{DocsFormatMarkdown, DocsFormatJSON, DocsFormatMCP}
+
+// HasFormat reports whether format is listed in docs.formats
+func (d DocsConfig) HasFormat(format string) bool {
+	return slices.Contains(d.Formats, format)
+}
+
+// Depth returns the levels of subcommands documented in SKILL.md
+func (d DocsConfig) Depth() int {
+	if d.MaxDepth <= 0 {
+		return DefaultDocsDepth
+	}
+	return d.MaxDepth
+}
+
+// Example is a sample invocation rendered into SKILL.md
+type Example struct {
+	Command     string `yaml:"command"`     // Arguments after the binary, e.g. "tasks list --project 2"
+	Description string `yaml:"description"` // When to use it
+	Output      string `yaml:"output"`      // Optional canned JSON output
+}
+
+// RedactRule declares fields removed from the JSON output of a skill before
+// it reaches the agent, see skillkit.RedactRule
+type RedactRule struct {
+	Command string   `yaml:"command"` // Command below the root, e.g. "tasks list"; all commands if empty
+	Fields  []string `yaml:"fields"`  // Field names matched at any depth, or dotted paths like "owner.email"
+	Hash    bool     `yaml:"hash"`    // Replace values with a short hash instead of "[redacted]"
 }
 
 // Manifest represents a skill.yaml file
@@ -53,10 +208,19 @@ type Manifest struct {
 	Variables        []Variable   `yaml:"variables"`
 	Build            BuildConfig  `yaml:"build"`
 	Deploy           DeployConfig `yaml:"deploy"`
+	Hooks            HooksConfig  `yaml:"hooks"`
 	Docs             DocsConfig   `yaml:"docs"`
+	Examples         []Example    `yaml:"examples"`
+	Redact           []RedactRule `yaml:"redact"`
+	Requires         []string     `yaml:"requires"`     // External tools the skill runs, e.g. git, checked in PATH before a deploy
+	Dependencies     Dependencies `yaml:"dependencies"` // Skills deployed first and shared libraries of the repository
+	Healthcheck      string       `yaml:"healthcheck"`  // Read-only command run after a deploy, e.g. "projects list"
+	Frontmatter      yaml.Node    `yaml:"frontmatter"`  // Extra SKILL.md frontmatter keys, in file order
 
 	// Runtime fields (not from YAML)
-	Path string `yaml:"-"` // Path to skill directory
+	Path      string   `yaml:"-"` // Path to skill directory
+	Libraries []string `yaml:"-"` // Paths of the libraries in dependencies, set by DiscoverSkills
+	Defaults  string   `yaml:"-"` // Path of the DefaultsFile the manifest inherits, empty if none
 }
 
 // GetSkillDescription returns SkillDescription if set, otherwise Description
@@ -67,17 +231,81 @@ func (m *Manifest) GetSkillDescription() string {
 	return m.Description
 }
 
+// BinaryName returns the configured binary name, falling back to the skill name
+func (m *Manifest) BinaryName() string {
+	if m.Build.Binary != "" {
+		return m.Build.Binary
+	}
+	return m.Name
+}
+
 // SkillError represents a skill that failed to load
 type SkillError struct {
-	Name  string
-	Path  string
-	Error error
+	Name   string
+	Path   string
+	Kind   ErrorKind
+	Error  error
+	Issues []Issue // Validation issues, some of which may be fixable
+}
+
+// errorLinePattern matches the line of a YAML error like "yaml: line 4: did not find expected key"
+var errorLinePattern = regexp.MustCompile(`line (\d+):`)
+
+// File returns the skill.yaml of the skill
+func (e SkillError) File() string {
+	return filepath.Join(e.Path, "skill.yaml")
+}
+
+// Line returns the line of skill.yaml the error refers to: the line of a YAML
+// syntax error or of the first issue with one, 0 if unknown
+func (e SkillError) Line() int {
+	for _, issue := range e.Issues {
+		if issue.Line > 0 {
+			return issue.Line
+		}
+	}
+	if match := errorLinePattern.FindStringSubmatch(e.Error.Error()); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		return line
+	}
+	return 0
 }
 
-// LoadManifest loads a skill manifest from a directory
+// ErrorKind categorizes why a skill failed to load
+type ErrorKind string
+
+// Skill error kinds
+const (
+	ErrMissingManifest ErrorKind = "missing manifest" // Go module without skill.yaml
+	ErrParse           ErrorKind = "parse error"      // skill.yaml is no valid YAML
+	ErrSchema          ErrorKind = "schema violation" // Missing fields or wrong values, see Issues
+	ErrMissingGoMod    ErrorKind = "missing go.mod"   // Build entry is not inside a Go module
+	ErrDependency      ErrorKind = "dependency error" // Unknown, failed or cyclic dependency
+)
+
+// LoadManifest loads a skill manifest from a directory, inheriting the
+// DefaultsFile of the skills directory
 func LoadManifest(skillDir string) (*Manifest, error) {
-	manifestPath := filepath.Join(skillDir, "skill.yaml")
+	doc, err := manifestDocument(skillDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := doc.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse skill.yaml: %w", err)
+	}
 
+	manifest.Path = skillDir
+	if _, err := os.Stat(DefaultsPath(skillDir)); err == nil {
+		manifest.Defaults = DefaultsPath(skillDir)
+	}
+	return &manifest, nil
+}
+
+// ReadManifest loads a skill.yaml file as is, without inherited defaults,
+// e.g. the effective manifest of an unpacked package
+func ReadManifest(manifestPath string) (*Manifest, error) {
 	data, err := os.ReadFile(manifestPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read skill.yaml: %w", err)
@@ -88,11 +316,12 @@ func LoadManifest(skillDir string) (*Manifest, error) {
 		return nil, fmt.Errorf("failed to parse skill.yaml: %w", err)
 	}
 
-	manifest.Path = skillDir
+	manifest.Path = filepath.Dir(manifestPath)
 	return &manifest, nil
 }
 
-// DiscoverSkills finds all skills in a directory
+// DiscoverSkills finds all skills in a directory. Skill directories are
+// loaded concurrently; the results keep the directory order.
 // Returns valid manifests and a list of skills that failed to load
 func DiscoverSkills(baseDir string) ([]*Manifest, []SkillError, error) {
 	skillsDir := filepath.Join(baseDir, "skills")
@@ -102,36 +331,117 @@ func DiscoverSkills(baseDir string) ([]*Manifest, []SkillError, error) {
 		return nil, nil, fmt.Errorf("failed to read skills directory: %w", err)
 	}
 
-	var manifests []*Manifest
-	var errors []SkillError
+	type result struct {
+		manifest *Manifest
+		err      *SkillError
+	}
+	results := make([]result, len(entries))
 
-	for _, entry := range entries {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.NumCPU())
+	for i, entry := range entries {
 		if !entry.IsDir() {
 			continue
 		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i].manifest, results[i].err = loadSkill(filepath.Join(skillsDir, entry.Name()))
+		}()
+	}
+	wg.Wait()
 
-		skillDir := filepath.Join(skillsDir, entry.Name())
-		manifestPath := filepath.Join(skillDir, "skill.yaml")
+	var manifests []*Manifest
+	var errors []SkillError
+	for _, r := range results {
+		switch {
+		case r.err != nil:
+			errors = append(errors, *r.err)
+		case r.manifest != nil:
+			manifests = append(manifests, r.manifest)
+		}
+	}
+	manifests, errors = resolveDependencies(baseDir, manifests, errors)
+	return manifests, errors, nil
+}
 
-		// Check if skill.yaml exists
-		if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
-			continue
+// loadSkill validates and loads the skill in skillDir. It returns neither a
+// manifest nor an error for directories that are no skill.
+func loadSkill(skillDir string) (*Manifest, *SkillError) {
+	name := filepath.Base(skillDir)
+	manifestPath := filepath.Join(skillDir, "skill.yaml")
+	skillErr := func(kind ErrorKind, err error, issues []Issue) *SkillError {
+		return &SkillError{Name: name, Path: skillDir, Kind: kind, Error: err, Issues: issues}
+	}
+
+	// Check if skill.yaml exists; a Go module without one is a skill missing its manifest
+	if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
+		if _, err := os.Stat(filepath.Join(skillDir, "go.mod")); err == nil {
+			return nil, skillErr(ErrMissingManifest, fmt.Errorf("go.mod without skill.yaml"), nil)
 		}
+		return nil, nil
+	}
 
-		manifest, err := LoadManifest(skillDir)
-		if err != nil {
-			errors = append(errors, SkillError{
-				Name:  entry.Name(),
-				Path:  skillDir,
-				Error: err,
-			})
-			continue
+	// Validate before loading to report fixable issues
+	issues, err := ValidateFile(manifestPath)
+	if err != nil {
+		return nil, skillErr(ErrParse, err, nil)
+	}
+	if len(issues) > 0 {
+		return nil, skillErr(ErrSchema, fmt.Errorf("invalid skill.yaml: %s", issues[0]), issues)
+	}
+
+	manifest, err := LoadManifest(skillDir)
+	if err != nil {
+		return nil, skillErr(ErrParse, err, nil)
+	}
+
+	entry := filepath.Join(skillDir, manifest.Build.Entry)
+	if !hasGoMod(entry) {
+		return nil, skillErr(ErrMissingGoMod, fmt.Errorf("no go.mod in %s or a parent directory", entry), nil)
+	}
+	return manifest, nil
+}
+
+// hasGoMod reports whether dir or one of its parents contains a go.mod
+func hasGoMod(dir string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return true
 		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
 
-		manifests = append(manifests, manifest)
+// FindSkill discovers skills in baseDir and returns the one matching name
+// (manifest name or skill directory name)
+func FindSkill(baseDir string, name string) (*Manifest, error) {
+	manifests, skillErrors, err := DiscoverSkills(baseDir)
+	if err != nil {
+		return nil, err
 	}
 
-	return manifests, errors, nil
+	for _, m := range manifests {
+		if m.Name == name || filepath.Base(m.Path) == name {
+			return m, nil
+		}
+	}
+	for _, e := range skillErrors {
+		if e.Name == name {
+			return nil, fmt.Errorf("skill %s failed to load (%s): %w", name, e.Kind, e.Error)
+		}
+	}
+	return nil, fmt.Errorf("skill not found: %s", name)
 }
 
 // GetRequiredVariables returns only required variables
@@ -0,0 +1,201 @@
+package skill
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema is the JSON Schema of skill.yaml. Editors with YAML language
+// support can use it for completion and inline errors.
+//
+//go:embed skill.schema.json
+var Schema []byte
+
+// schemaNode is the subset of JSON Schema used by skill.schema.json
+type schemaNode struct {
+	Type                 schemaTypes            `json:"type"`
+	Required             []string               `json:"required"`
+	Enum                 []string               `json:"enum"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *schemaNode            `json:"items"`
+}
+
+// schemaTypes holds the allowed types of a value: "type" is a string or a list
+type schemaTypes []string
+
+// UnmarshalJSON accepts a single type or a list of types
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if json.Unmarshal(data, &single) == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*t = list
+	return nil
+}
+
+// manifestSchema is the parsed Schema
+var manifestSchema = func() *schemaNode {
+	var s schemaNode
+	if err := json.Unmarshal(Schema, &s); err != nil {
+		panic("invalid skill.schema.json: " + err.Error())
+	}
+	return &s
+}()
+
+// validateSchema checks a skill.yaml document root against the schema
+func validateSchema(root *yaml.Node) []Issue {
+	var issues []Issue
+	manifestSchema.validate(root, "", &issues)
+	return issues
+}
+
+// validate checks node against s and appends the issues found below path
+func (s *schemaNode) validate(node *yaml.Node, path string, issues *[]Issue) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	// Empty values count as missing, reported by the parent's required list
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	if actual := yamlType(node); !s.allows(actual) {
+		issue := Issue{
+			Field:   path,
+			Line:    node.Line,
+			Message: fmt.Sprintf("expected %s, got %s", s.typeNames(), describe(node, actual)),
+		}
+		if node.Kind == yaml.ScalarNode {
+			switch {
+			case s.allows("boolean"):
+				issue.Hint, issue.Fixable = suggestBool(node.Value), true
+			case s.allows("integer"):
+				issue.Hint, issue.Fixable = "0", true
+			}
+		}
+		*issues = append(*issues, issue)
+		return
+	}
+
+	if len(s.Enum) > 0 && node.Kind == yaml.ScalarNode && node.Value != "" && !contains(s.Enum, node.Value) {
+		*issues = append(*issues, Issue{
+			Field:   path,
+			Line:    node.Line,
+			Message: fmt.Sprintf("invalid %s %q (expected %s)", lastField(path), node.Value, strings.Join(s.Enum, ", ")),
+			Hint:    s.Enum[0],
+			Fixable: true,
+		})
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for _, key := range s.Required {
+			if isBlank(mappingValue(node, key)) {
+				line := node.Line
+				if path == "" {
+					line = 0
+				}
+				*issues = append(*issues, Issue{Field: joinPath(path, key), Line: line, Message: "required field is missing", Fixable: true})
+			}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			child, ok := s.Properties[key.Value]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*issues = append(*issues, Issue{Field: joinPath(path, key.Value), Line: key.Line, Message: "unknown field"})
+				}
+				continue
+			}
+			child.validate(value, joinPath(path, key.Value), issues)
+		}
+	case yaml.SequenceNode:
+		if s.Items != nil {
+			for i, item := range node.Content {
+				s.Items.validate(item, joinPath(path, strconv.Itoa(i)), issues)
+			}
+		}
+	}
+}
+
+// allows reports whether the schema accepts values of type t. Like the YAML
+// decoder, strings accept any scalar (e.g. version: 1.0).
+func (s *schemaNode) allows(t string) bool {
+	if len(s.Type) == 0 {
+		return true
+	}
+	for _, allowed := range s.Type {
+		if allowed == t || (allowed == "string" && isScalarType(t)) || (allowed == "number" && t == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeNames formats the allowed types for messages, e.g. "string or list"
+func (s *schemaNode) typeNames() string {
+	names := make([]string, len(s.Type))
+	for i, t := range s.Type {
+		switch t {
+		case "array":
+			names[i] = "list"
+		case "object":
+			names[i] = "mapping"
+		default:
+			names[i] = t
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+// yamlType returns the JSON Schema type of a YAML node
+func yamlType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.Tag {
+	case "!!bool":
+		return "boolean"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!null":
+		return "null"
+	}
+	return "string"
+}
+
+func isScalarType(t string) bool {
+	return t == "string" || t == "boolean" || t == "integer" || t == "number"
+}
+
+// describe formats a value for a type mismatch message
+func describe(node *yaml.Node, t string) string {
+	switch t {
+	case "array":
+		return "a list"
+	case "object":
+		return "a mapping"
+	}
+	return strconv.Quote(node.Value)
+}
+
+// lastField returns the last element of a dotted field path
+func lastField(path string) string {
+	return path[strings.LastIndex(path, ".")+1:]
+}
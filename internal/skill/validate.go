@@ -0,0 +1,253 @@
+package skill
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VariableTypes lists the supported variable types
+var VariableTypes = []string{"string", "secret", "json"}
+
+// Issue describes a problem in a skill.yaml
+type Issue struct {
+	Field   string `json:"field"`          // Dotted field path, e.g. "name" or "variables.1.type"
+	Line    int    `json:"line,omitempty"` // Line in skill.yaml (0 if the field is missing)
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"` // Suggested value for a quick fix
+	Fixable bool   `json:"fixable"`        // Issue can be fixed by setting Field to a new value
+}
+
+// String formats the issue for display
+func (i Issue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s (line %d): %s", i.Field, i.Line, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.Field, i.Message)
+}
+
+// typeErrorPattern matches yaml.v3 type errors like "line 12: cannot unmarshal !!str `yes` into bool"
+var typeErrorPattern = regexp.MustCompile("line (\\d+): cannot unmarshal (\\S+) `([^`]*)` into (\\S+)")
+
+// ValidateFile checks a skill.yaml with the defaults it inherits against
+// Schema (missing required fields, wrong value types, invalid values, unknown
+// fields) and the rules the schema cannot express. Issues about inherited
+// values have no line.
+// A YAML syntax error and an invalid DefaultsFile are returned as error since
+// they cannot be fixed field by field.
+func ValidateFile(manifestPath string) ([]Issue, error) {
+	doc, err := manifestDocument(filepath.Dir(manifestPath))
+	if err != nil {
+		return nil, err
+	}
+	root := doc.Content[0]
+
+	// Required fields, value types, allowed values and unknown fields
+	issues := validateSchema(root)
+	dirName := filepath.Base(filepath.Dir(manifestPath))
+	for i := range issues {
+		if issues[i].Field == "name" && issues[i].Hint == "" {
+			issues[i].Hint = dirName
+		}
+	}
+
+	// Wrong value types reported by the decoder and not by the schema
+	reported := make(map[int]bool)
+	for _, issue := range issues {
+		reported[issue.Line] = true
+	}
+	var manifest Manifest
+	var typeErr *yaml.TypeError
+	if err := doc.Decode(&manifest); errors.As(err, &typeErr) {
+		for _, msg := range typeErr.Errors {
+			if issue := typeIssue(root, msg); !reported[issue.Line] {
+				issues = append(issues, issue)
+			}
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to parse skill.yaml: %w", err)
+	}
+
+	// Rules across fields of a variable
+	if vars := mappingValue(root, "variables"); vars != nil && vars.Kind == yaml.SequenceNode {
+		for i, v := range vars.Content {
+			source := mappingValue(v, "source")
+			prefix := "variables." + strconv.Itoa(i)
+			if source == nil || source.Value != SourceSkill {
+				for _, field := range []string{"option_value", "option_label"} {
+					if o := mappingValue(v, field); !isBlank(o) {
+						issues = append(issues, Issue{Field: prefix + "." + field, Line: o.Line, Message: "only used with source: skill"})
+					}
+				}
+			}
+			if source == nil || (source.Value != SourceCommand && source.Value != SourceSkill) {
+				continue
+			}
+			if isBlank(mappingValue(v, "command")) {
+				issues = append(issues, Issue{Field: prefix + ".command", Line: v.Line, Message: "required for source: " + source.Value})
+			}
+			if b := mappingValue(v, "backend"); !isBlank(b) {
+				issues = append(issues, Issue{Field: prefix + ".backend", Line: b.Line, Message: "cannot be combined with source: " + source.Value})
+			}
+		}
+	}
+
+	// name and description of the frontmatter come from the manifest
+	if frontmatter := mappingValue(root, "frontmatter"); frontmatter != nil && frontmatter.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(frontmatter.Content); i += 2 {
+			key := frontmatter.Content[i]
+			switch key.Value {
+			case "name":
+				issues = append(issues, Issue{Field: "frontmatter.name", Line: key.Line, Message: "set from the skill's name, remove it here"})
+			case "description":
+				issues = append(issues, Issue{Field: "frontmatter.description", Line: key.Line, Message: "set from skill_description (or description), remove it here"})
+			}
+		}
+	}
+
+	// Canned example output must be JSON like the real output
+	if examples := mappingValue(root, "examples"); examples != nil && examples.Kind == yaml.SequenceNode {
+		for i, e := range examples.Content {
+			output := mappingValue(e, "output")
+			if isBlank(output) || output.Kind != yaml.ScalarNode {
+				continue
+			}
+			if !json.Valid([]byte(output.Value)) {
+				issues = append(issues, Issue{Field: "examples." + strconv.Itoa(i) + ".output", Line: output.Line, Message: "must be valid JSON"})
+			}
+		}
+	}
+
+	// Names of docs.data are placeholders, {{DATA:name}}
+	if docs := mappingValue(root, "docs"); docs != nil {
+		if data := mappingValue(docs, "data"); data != nil && data.Kind == yaml.SequenceNode {
+			seen := make(map[string]bool)
+			for i, d := range data.Content {
+				name := mappingValue(d, "name")
+				if isBlank(name) || name.Kind != yaml.ScalarNode {
+					continue
+				}
+				field := "docs.data." + strconv.Itoa(i) + ".name"
+				switch {
+				case !docsDataName.MatchString(name.Value):
+					issues = append(issues, Issue{Field: field, Line: name.Line, Message: "must contain only letters, digits, - and _"})
+				case seen[name.Value]:
+					issues = append(issues, Issue{Field: field, Line: name.Line, Message: fmt.Sprintf("duplicate name %q", name.Value)})
+				}
+				seen[name.Value] = true
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// docsDataName matches the names of docs.data entries
+var docsDataName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// typeIssue converts a yaml.v3 type error message into an Issue
+func typeIssue(root *yaml.Node, msg string) Issue {
+	match := typeErrorPattern.FindStringSubmatch(msg)
+	if match == nil {
+		return Issue{Field: "?", Message: msg}
+	}
+
+	line, _ := strconv.Atoi(match[1])
+	value, target := match[3], match[4]
+	issue := Issue{
+		Line:    line,
+		Message: fmt.Sprintf("expected %s, got %q", target, value),
+	}
+
+	if field, node := findByLine(root, "", line); node != nil && node.Kind == yaml.ScalarNode {
+		issue.Field = field
+		issue.Fixable = true
+	} else {
+		issue.Field = "?"
+	}
+
+	switch target {
+	case "bool":
+		issue.Hint = suggestBool(value)
+	case "int", "int64", "float64":
+		issue.Hint = "0"
+	}
+	return issue
+}
+
+// findByLine returns the dotted path and node of the value located on line
+func findByLine(node *yaml.Node, path string, line int) (string, *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			childPath := joinPath(path, key.Value)
+			if value.Line == line && value.Kind == yaml.ScalarNode {
+				return childPath, value
+			}
+			if p, n := findByLine(value, childPath, line); n != nil {
+				return p, n
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			childPath := joinPath(path, strconv.Itoa(i))
+			if item.Line == line && item.Kind == yaml.ScalarNode {
+				return childPath, item
+			}
+			if p, n := findByLine(item, childPath, line); n != nil {
+				return p, n
+			}
+		}
+	}
+	return "", nil
+}
+
+// mappingValue returns the value node for key in a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// suggestBool maps common yes/no spellings to a YAML bool
+func suggestBool(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "y", "on", "1", "ja", "required":
+		return "true"
+	default:
+		return "false"
+	}
+}
+
+func isBlank(node *yaml.Node) bool {
+	return node == nil || (node.Kind == yaml.ScalarNode && strings.TrimSpace(node.Value) == "")
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package skill
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares two semantic version strings (with optional "v" prefix).
+// It returns -1 if a < b, 0 if a == b and 1 if a > b. A pre-release suffix
+// (e.g. 1.2.0-beta) sorts before the release it precedes.
+func CompareVersions(a, b string) int {
+	aCore, aPre := splitVersion(a)
+	bCore, bPre := splitVersion(b)
+
+	for i := 0; i < len(aCore) || i < len(bCore); i++ {
+		var x, y int
+		if i < len(aCore) {
+			x = aCore[i]
+		}
+		if i < len(bCore) {
+			y = bCore[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	case aPre < bPre:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// splitVersion splits "v1.2.3-beta+build" into [1 2 3] and "beta"
+func splitVersion(v string) ([]int, string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+
+	var pre string
+	if i := strings.Index(v, "-"); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(p)
+		parts = append(parts, n)
+	}
+	return parts, pre
+}
+
+// VersionStatus describes a deployed version relative to the source version
+func VersionStatus(source, deployed string) string {
+	if deployed == "" {
+		return "not deployed"
+	}
+	if source == "" || deployed == "dev" {
+		return "unknown"
+	}
+	switch CompareVersions(deployed, source) {
+	case -1:
+		return "outdated"
+	case 1:
+		return "newer"
+	default:
+		return "up to date"
+	}
+}
@@ -0,0 +1,163 @@
+// Package stats records local usage statistics. Recording is opt-in and the
+// data never leaves the machine: it is only shown by `skillfactory stats`.
+package stats
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+)
+
+const statsFile = "stats.json"
+
+// Feature names recorded by SkillFactory
+const (
+	FeatureTUI           = "tui"
+	FeatureDeploy        = "deploy"
+	FeatureSkipUnchanged = "skip-unchanged"
+	FeatureQuickFix      = "quick-fix"
+	FeatureKeychain      = "keychain"
+	FeatureVulncheck     = "vulncheck"
+	FeatureDeps          = "deps"
+	FeatureEditManifest  = "edit-manifest"
+	FeatureWeb           = "web"
+)
+
+// BuildStats aggregates build durations of a skill
+type BuildStats struct {
+	Count   int   `json:"count"`
+	TotalMS int64 `json:"total_ms"`
+	MaxMS   int64 `json:"max_ms"`
+	LastMS  int64 `json:"last_ms"`
+}
+
+// Average returns the mean build duration
+func (b *BuildStats) Average() time.Duration {
+	if b.Count == 0 {
+		return 0
+	}
+	return time.Duration(b.TotalMS/int64(b.Count)) * time.Millisecond
+}
+
+// Stats holds feature usage counts and build durations
+type Stats struct {
+	Since    time.Time              `json:"since"`
+	Features map[string]int         `json:"features"`
+	Builds   map[string]*BuildStats `json:"builds"`
+}
+
+// Path returns the location of the stats file
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, statsFile), nil
+}
+
+// Load reads the stats from disk, returns empty stats if not found
+func Load() (*Stats, error) {
+	s := &Stats{
+		Since:    time.Now().UTC(),
+		Features: make(map[string]int),
+		Builds:   make(map[string]*BuildStats),
+	}
+
+	path, err := Path()
+	if err != nil {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Features == nil {
+		s.Features = make(map[string]int)
+	}
+	if s.Builds == nil {
+		s.Builds = make(map[string]*BuildStats)
+	}
+	return s, nil
+}
+
+// Save writes the stats to disk
+func (s *Stats) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Reset removes all recorded stats
+func Reset() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Enabled reports whether the user opted in to local stats
+func Enabled() bool {
+	cfg, err := config.Load()
+	return err == nil && cfg.Stats
+}
+
+// Record counts one use of a feature. Errors are ignored, stats must never
+// get in the way of the actual work.
+func Record(feature string) {
+	update(func(s *Stats) {
+		s.Features[feature]++
+	})
+}
+
+// RecordBuild stores the duration of a build of skill
+func RecordBuild(skill string, d time.Duration) {
+	update(func(s *Stats) {
+		b := s.Builds[skill]
+		if b == nil {
+			b = &BuildStats{}
+			s.Builds[skill] = b
+		}
+		ms := d.Milliseconds()
+		b.Count++
+		b.TotalMS += ms
+		b.LastMS = ms
+		if ms > b.MaxMS {
+			b.MaxMS = ms
+		}
+	})
+}
+
+// update applies fn to the stored stats if recording is enabled
+func update(fn func(s *Stats)) {
+	if !Enabled() {
+		return
+	}
+	s, err := Load()
+	if err != nil {
+		return
+	}
+	fn(s)
+	_ = s.Save()
+}
@@ -2,513 +2,474 @@
 package tui
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/stats"
 )
 
+// testCompleteMsg is sent when the test gate completes
+type testCompleteMsg struct {
+	output   string
+	duration time.Duration
+	err      error
+}
+
 // buildCompleteMsg is sent when a build completes
 type buildCompleteMsg struct {
-	output string
+	output   string
+	duration time.Duration
+	err      error
+}
+
+// vulncheckCompleteMsg is sent when the govulncheck scan completes
+type vulncheckCompleteMsg struct {
+	result pipeline.VulncheckResult
 	err    error
 }
 
 // deployCompleteMsg is sent when a deploy completes
 type deployCompleteMsg struct {
-	err error
+	output      string // Output of post_deploy hooks
+	duration    time.Duration
+	err         error
+	healthcheck *pipeline.HealthcheckResult // Set if skill.yaml declares a healthcheck
+	steps       []pipeline.DeployStep       // Outcome of the deploy steps
 }
 
-// startBuild starts the build process for the selected skill
-func (m Model) startBuild() tea.Cmd {
-	return func() tea.Msg {
-		if m.selectedSkill == nil {
-			return buildCompleteMsg{err: fmt.Errorf("no skill selected")}
-		}
-
-		// Get skill source path
-		skillPath := m.selectedSkill.Path
-		binaryName := m.selectedSkill.Build.Binary
-		if binaryName == "" {
-			binaryName = m.selectedSkill.Name
-		}
+// buildLineMsg is a line of build or hook output streamed while building
+type buildLineMsg struct {
+	line string
+	msgs <-chan tea.Msg // Further lines and the closing buildCompleteMsg
+}
 
-		// Build to dist directory
-		distDir := filepath.Join(m.projectRoot, "dist")
-		os.MkdirAll(distDir, 0755)
-		outputPath := filepath.Join(distDir, binaryName)
+// fixCompleteMsg is sent when the fix of a failed build completes
+type fixCompleteMsg struct {
+	output string
+	err    error
+}
 
-		// Run go build
-		cmd := exec.Command("go", "build", "-o", outputPath, ".")
-		cmd.Dir = skillPath
+// choicesLoadedMsg is sent when the choices of config input index are listed
+type choicesLoadedMsg struct {
+	index   int
+	choices []pipeline.Choice
+	err     error
+}
 
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return buildCompleteMsg{
-				output: string(output),
-				err:    fmt.Errorf("build failed: %w", err),
-			}
-		}
+// editorClosedMsg is sent when the editor opened from the Done view exits
+type editorClosedMsg struct {
+	err error
+}
 
-		return buildCompleteMsg{
-			output: fmt.Sprintf("Built: %s", outputPath),
-		}
+// loadChoices lists the choices of the variable of config input index with
+// the values entered so far
+func (m Model) loadChoices(index int) tea.Cmd {
+	manifest := m.selectedSkill
+	v := manifest.Variables[index]
+	values := make(map[string]string, len(manifest.Variables))
+	for i, variable := range manifest.Variables {
+		values[variable.Name] = strings.TrimSpace(m.configInputs[i].Value())
+	}
+	return func() tea.Msg {
+		choices, err := pipeline.Choices(manifest, v, values)
+		return choicesLoadedMsg{index: index, choices: choices, err: err}
 	}
 }
 
-// deploySkill deploys the built skill to the configured path
-func (m Model) deploySkill() tea.Cmd {
+// runTestGate runs the skill's tests before it is built
+func (m Model) runTestGate() tea.Cmd {
 	return func() tea.Msg {
-		if m.selectedSkill == nil {
-			return deployCompleteMsg{err: fmt.Errorf("no skill selected")}
-		}
-
-		deployPath := m.getDeployPath()
-		if deployPath == "" {
-			return deployCompleteMsg{err: fmt.Errorf("deploy path not configured")}
-		}
-
-		binaryName := m.selectedSkill.Build.Binary
-		if binaryName == "" {
-			binaryName = m.selectedSkill.Name
-		}
-
-		// Source paths
-		distDir := filepath.Join(m.projectRoot, "dist")
-		srcBinary := filepath.Join(distDir, binaryName)
-
-		// Destination paths
-		dstBinDir := filepath.Join(deployPath, "bin")
-		dstBinary := filepath.Join(dstBinDir, binaryName)
-
-		// Ensure destination directories exist
-		os.MkdirAll(dstBinDir, 0755)
-
-		// Copy binary (remove old one first to avoid issues with running processes)
-		binaryData, err := os.ReadFile(srcBinary)
-		if err != nil {
-			return deployCompleteMsg{err: fmt.Errorf("failed to read binary: %w", err)}
-		}
-
-		// Remove existing binary first to ensure clean overwrite
-		os.Remove(dstBinary)
-
-		if err := os.WriteFile(dstBinary, binaryData, 0755); err != nil {
-			return deployCompleteMsg{err: fmt.Errorf("failed to write binary: %w", err)}
-		}
-
-		// Generate .env file with environment variables
-		envPath := filepath.Join(dstBinDir, ".env")
-		envContent := m.generateEnvFile()
-		if err := os.WriteFile(envPath, []byte(envContent), 0600); err != nil {
-			return deployCompleteMsg{err: fmt.Errorf("failed to write .env: %w", err)}
-		}
-
-		// Generate SKILL.md
-		if err := m.generateSkillDocs(); err != nil {
-			return deployCompleteMsg{err: fmt.Errorf("failed to generate docs: %w", err)}
-		}
-
-		// Cleanup: remove dist directory
-		os.RemoveAll(distDir)
-
-		return deployCompleteMsg{}
+		start := time.Now()
+		output, err := pipeline.RunTestsContext(m.buildCtx, m.selectedSkill)
+		return testCompleteMsg{output: output, duration: time.Since(start), err: err}
 	}
 }
 
-// generateEnvFile creates a .env file with environment variables
-func (m Model) generateEnvFile() string {
-	var b strings.Builder
-
-	b.WriteString("# Auto-generated environment file\n")
-
-	for _, v := range m.selectedSkill.Variables {
-		if value, ok := m.configValues[v.Name]; ok && value != "" {
-			b.WriteString(fmt.Sprintf("%s=%s\n", v.Name, value))
-		}
+// startBuild runs the pre_build hooks, go build and the post_build hooks,
+// streaming their output line by line as buildLineMsg before the closing
+// buildCompleteMsg
+func (m Model) startBuild() tea.Cmd {
+	msgs := make(chan tea.Msg, 64)
+	return func() tea.Msg {
+		go func() {
+			out := &lineWriter{msgs: msgs}
+			msg := m.build(out)
+			out.Flush()
+			msgs <- msg
+			close(msgs)
+		}()
+		return <-msgs
 	}
-
-	return b.String()
 }
 
-// generateSkillDocs generates the SKILL.md file
-func (m Model) generateSkillDocs() error {
+// build compiles the selected skill, writing the output to out
+func (m Model) build(out *lineWriter) buildCompleteMsg {
 	if m.selectedSkill == nil {
-		return fmt.Errorf("no skill selected")
+		return buildCompleteMsg{err: fmt.Errorf("no skill selected")}
 	}
 
-	// Read template if exists
-	templatePath := filepath.Join(m.selectedSkill.Path, m.selectedSkill.Docs.Template)
-	var content string
-
-	templateData, err := os.ReadFile(templatePath)
-	if err != nil {
-		// No template, generate basic docs
-		content = m.generateBasicDocs()
-	} else {
-		content = string(templateData)
-		// Remove any existing frontmatter from template
-		content = stripFrontmatter(content)
-		// Replace placeholders
-		content = m.replacePlaceholders(content)
-	}
-
-	// Prepend generated frontmatter from skill.yaml
-	frontmatter := m.generateFrontmatter()
-	content = frontmatter + content
-
-	// Write SKILL.md
-	outputPath := filepath.Join(m.getDeployPath(), "SKILL.md")
-	return os.WriteFile(outputPath, []byte(content), 0644)
-}
+	// Build to dist directory
+	distDir := filepath.Join(m.projectRoot, "dist")
+	outputPath := filepath.Join(distDir, m.selectedSkill.BinaryName())
 
-// generateFrontmatter creates YAML frontmatter from skill manifest
-func (m Model) generateFrontmatter() string {
-	var b strings.Builder
-	b.WriteString("---\n")
-	b.WriteString(fmt.Sprintf("name: %s\n", m.selectedSkill.Name))
-	b.WriteString(fmt.Sprintf("description: %s\n", m.selectedSkill.GetSkillDescription()))
-	b.WriteString("---\n\n")
-	return b.String()
-}
+	var log strings.Builder
+	w := io.MultiWriter(&log, out)
+	hookEnv := pipeline.HookEnv{BinaryPath: outputPath}
 
-// stripFrontmatter removes existing YAML frontmatter from content
-func stripFrontmatter(content string) string {
-	if !strings.HasPrefix(content, "---") {
-		return content
+	// Run pre_build hooks (e.g. tests, code generation)
+	if err := pipeline.RunHookTo(m.selectedSkill, pipeline.HookPreBuild, hookEnv, w); err != nil {
+		return buildCompleteMsg{output: log.String(), err: err}
 	}
-	// Find the closing ---
-	rest := content[3:]
-	idx := strings.Index(rest, "---")
-	if idx == -1 {
-		return content
-	}
-	// Return everything after the closing --- and any leading newlines
-	result := strings.TrimLeft(rest[idx+3:], "\n")
-	return result
-}
 
-// generateBasicDocs generates basic documentation
-func (m Model) generateBasicDocs() string {
-	var b strings.Builder
-
-	b.WriteString(fmt.Sprintf("# %s\n\n", m.selectedSkill.Name))
-	b.WriteString(m.selectedSkill.Description)
-	b.WriteString("\n\n")
+	// Run go build with the manifest's build options
+	start := time.Now()
+	err := pipeline.BuildContext(m.buildCtx, m.selectedSkill, outputPath, w)
+	duration := time.Since(start)
+	if err != nil {
+		return buildCompleteMsg{
+			output:   log.String(),
+			duration: duration,
+			err:      err,
+		}
+	}
+	fmt.Fprintf(w, "Built: %s\n", outputPath)
 
-	b.WriteString("## Commands\n\n")
-	b.WriteString("Run `" + m.selectedSkill.Build.Binary + " --help` to see available commands.\n")
+	if err := pipeline.RunHookTo(m.selectedSkill, pipeline.HookPostBuild, hookEnv, w); err != nil {
+		return buildCompleteMsg{output: log.String(), err: err}
+	}
 
-	return b.String()
+	return buildCompleteMsg{
+		output:   log.String(),
+		duration: duration,
+	}
 }
 
-// replacePlaceholders replaces template placeholders
-func (m Model) replacePlaceholders(content string) string {
-	binaryName := m.selectedSkill.Build.Binary
-	if binaryName == "" {
-		binaryName = m.selectedSkill.Name
+// waitForBuildMsg waits for the next message of a running build
+func waitForBuildMsg(msgs <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-msgs
 	}
+}
 
-	// Replace SKILL_PATH placeholder
-	content = strings.Replace(content, "{{SKILL_PATH}}", m.getDeployPath(), -1)
+// lineWriter sends the lines written to it as buildLineMsg
+type lineWriter struct {
+	msgs    chan tea.Msg
+	partial []byte // Last line until its newline is written
+}
 
-	// Replace PROJECT_IDS_TABLE if we have PROJECT_IDS configured
-	if projectIDs, ok := m.configValues["PROJECT_IDS"]; ok && projectIDs != "" {
-		table := m.generateProjectIDsTable(projectIDs)
-		content = strings.Replace(content, "{{PROJECT_IDS_TABLE}}", table, 1)
-	} else {
-		content = strings.Replace(content, "{{PROJECT_IDS_TABLE}}", "No project IDs configured.", 1)
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.msgs <- buildLineMsg{line: string(w.partial[:i]), msgs: w.msgs}
+		w.partial = w.partial[i+1:]
 	}
+	return len(p), nil
+}
 
-	// Extract commands from built binary
-	distDir := filepath.Join(m.projectRoot, "dist")
-	binaryPath := filepath.Join(distDir, binaryName)
-
-	// Generate commands with binary path for SKILL.md (binary loads .env automatically)
-	deployedBinaryPath := filepath.Join(m.getDeployPath(), "bin", binaryName)
-	commands := m.extractCommands(binaryPath, deployedBinaryPath)
-	content = strings.Replace(content, "{{COMMANDS}}", commands, 1)
-
-	return content
+// Flush sends the last line if it has no trailing newline
+func (w *lineWriter) Flush() {
+	if len(w.partial) > 0 {
+		w.msgs <- buildLineMsg{line: string(w.partial), msgs: w.msgs}
+		w.partial = nil
+	}
 }
 
-// extractCommands runs the binary with --help recursively and documents all leaf commands with flags
-// binaryPath is the built binary, displayPath is what to show in docs
-func (m Model) extractCommands(binaryPath string, displayPath string) string {
-	// Run binary --help to get top-level help
-	output, err := runHelp(binaryPath)
-	if err != nil {
-		return "Run `" + displayPath + " --help` to see available commands."
+// runFix runs the automatic fix of a build failure diagnosis
+func (m Model) runFix(d pipeline.Diagnosis) tea.Cmd {
+	return func() tea.Msg {
+		output, err := pipeline.RunFix(m.selectedSkill, d)
+		return fixCompleteMsg{output: output, err: err}
 	}
+}
 
-	// Extract top-level subcommands
-	topLevel := parseSubcommands(output)
-	if len(topLevel) == 0 {
-		return "Run `" + displayPath + " --help` to see available commands."
+// runVulncheck scans the selected skill for known vulnerabilities
+func (m Model) runVulncheck() tea.Cmd {
+	return func() tea.Msg {
+		stats.Record(stats.FeatureVulncheck)
+		result, err := pipeline.Vulncheck(m.selectedSkill)
+		return vulncheckCompleteMsg{result: result, err: err}
 	}
+}
 
-	var b strings.Builder
+// deployCheckMsg is sent when the existing deployment is compared with the
+// current build inputs
+type deployCheckMsg struct {
+	deployPath      string
+	version         string
+	platformWarning string
+	lock            *pipeline.Lock // Nil if the deployment has no deploy.lock
+	lockChanges     []string
+	docsEdited      bool
+	diffs           []pipeline.FileDiff
+}
 
-	for _, cmd := range topLevel {
-		// Get second-level subcommands
-		cmdOutput, err := runHelp(binaryPath, cmd)
+// checkDeployment compares the existing deployment with the current build
+// inputs for the Overwrite view: deploy.lock, version and platform of the
+// deployed binary, a hand-edited SKILL.md and the files the deploy would write
+func (m Model) checkDeployment() tea.Cmd {
+	return func() tea.Msg {
+		deployPath := m.getDeployPath()
+		msg := deployCheckMsg{
+			deployPath:      deployPath,
+			version:         pipeline.DeployedVersion(deployPath, m.selectedSkill.BinaryName()),
+			platformWarning: pipeline.PlatformMismatch(m.selectedSkill, deployPath),
+			diffs:           pipeline.PreviewDeploy(m.deployOptions()),
+		}
+		lock, err := pipeline.ReadLock(deployPath)
 		if err != nil {
-			continue
+			return msg
 		}
-
-		secondLevel := parseSubcommands(cmdOutput)
-
-		if len(secondLevel) == 0 {
-			// This is a leaf command - document it with flags
-			b.WriteString(formatCommand(displayPath, cmd, cmdOutput))
-		} else {
-			// Has subcommands - recurse one more level
-			for _, sub := range secondLevel {
-				subOutput, err := runHelp(binaryPath, cmd, sub)
-				if err != nil {
-					continue
-				}
-				fullCmd := cmd + " " + sub
-				b.WriteString(formatCommand(displayPath, fullCmd, subOutput))
-			}
+		msg.lock = lock
+		msg.lockChanges = lock.Changes(m.expectedLock())
+		msg.docsEdited = pipeline.DocsEdited(deployPath, lock)
+
+		// Detect binaries that were replaced outside of SkillFactory
+		binaryHash, err := pipeline.HashFile(filepath.Join(deployPath, "bin", m.selectedSkill.BinaryName()))
+		if err != nil || binaryHash != lock.BinarySHA256 {
+			msg.lockChanges = append(msg.lockChanges, "binary")
 		}
+		return msg
 	}
+}
 
-	if b.Len() == 0 {
-		return "Run `" + displayPath + " --help` to see available commands."
-	}
-
-	return b.String()
+// deployStepMsg is sent when a deploy step starts or ends
+type deployStepMsg struct {
+	steps []pipeline.DeployStep // All steps of the deploy with their current state
+	msgs  <-chan tea.Msg        // Further steps and the closing deployCompleteMsg
 }
 
-// runHelp executes a command with --help and returns the output
-func runHelp(binaryPath string, args ...string) (string, error) {
-	cmdArgs := append(args, "--help")
-	cmd := exec.Command(binaryPath, cmdArgs...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", err
+// deploySkill deploys the built skill to the configured path, sending a
+// deployStepMsg for every step before the closing deployCompleteMsg
+func (m Model) deploySkill() tea.Cmd {
+	msgs := make(chan tea.Msg, 16)
+	return func() tea.Msg {
+		go func() {
+			msgs <- m.deploy(msgs)
+			close(msgs)
+		}()
+		return <-msgs
 	}
-	return string(output), nil
 }
 
-// formatCommand formats a leaf command with its description and flags
-func formatCommand(displayPath string, cmdPath string, helpOutput string) string {
-	var b strings.Builder
-
-	// Parse the help output
-	description := parseDescription(helpOutput)
-	usage := parseUsage(helpOutput)
-	flags := parseFlags(helpOutput)
-
-	b.WriteString("### " + cmdPath + "\n\n")
-
-	if description != "" {
-		b.WriteString(description + "\n\n")
+// deploy runs the deploy steps of the selected skill, the post_deploy hooks,
+// the healthcheck and the cleanup, reporting their progress to msgs
+func (m Model) deploy(msgs chan tea.Msg) deployCompleteMsg {
+	if m.selectedSkill == nil {
+		return deployCompleteMsg{err: fmt.Errorf("no skill selected")}
 	}
 
-	if usage != "" {
-		b.WriteString("**Usage:** `" + displayPath + " " + usage + "`\n\n")
+	opts := m.deployOptions()
+	steps := pipeline.DeploySteps(opts, true)
+	progress := func(step pipeline.DeployStep) {
+		pipeline.UpdateStep(steps, step)
+		msgs <- deployStepMsg{steps: slices.Clone(steps), msgs: msgs}
+	}
+	opts.Progress = progress
+	start := time.Now()
+	deploy := pipeline.Deploy
+	if m.configOnly {
+		deploy = pipeline.RedeployConfig
+	}
+	if _, err := deploy(opts); err != nil {
+		return deployCompleteMsg{duration: time.Since(start), err: err, steps: steps}
 	}
 
-	if len(flags) > 0 {
-		b.WriteString("**Flags:**\n")
-		for _, flag := range flags {
-			b.WriteString("- " + flag + "\n")
+	// Run post_deploy hooks before the built binary is cleaned up
+	var output string
+	if len(m.selectedSkill.Hooks.PostDeploy) > 0 {
+		err := pipeline.RunStep(progress, pipeline.StepHooks, func() error {
+			var err error
+			output, err = pipeline.RunHook(m.selectedSkill, pipeline.HookPostDeploy, pipeline.HookEnv{
+				BinaryPath: filepath.Join(opts.DeployPath, "bin", m.selectedSkill.BinaryName()),
+				DeployPath: opts.DeployPath,
+			})
+			return err
+		})
+		if err != nil {
+			return deployCompleteMsg{output: output, duration: time.Since(start), err: err, steps: steps}
 		}
-		b.WriteString("\n")
 	}
 
-	return b.String()
-}
+	msg := deployCompleteMsg{output: output}
 
-// parseDescription extracts the description from Cobra help output (first non-empty line)
-func parseDescription(helpOutput string) string {
-	lines := strings.Split(helpOutput, "\n")
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed != "" && !strings.HasPrefix(trimmed, "Usage:") {
-			return trimmed
-		}
-		if strings.HasPrefix(trimmed, "Usage:") {
-			break
-		}
+	// Check the deployed configuration, a failure does not fail the deploy
+	if m.selectedSkill.Healthcheck != "" {
+		pipeline.RunStep(progress, pipeline.StepHealthcheck, func() error {
+			result := pipeline.Healthcheck(m.selectedSkill, opts.DeployPath)
+			msg.healthcheck = &result
+			return result.Err
+		})
 	}
-	return ""
-}
 
-// parseUsage extracts the usage pattern from Cobra help output
-func parseUsage(helpOutput string) string {
-	lines := strings.Split(helpOutput, "\n")
-	for i, line := range lines {
-		if strings.HasPrefix(strings.TrimSpace(line), "Usage:") {
-			// Get the next line or the rest of this line
-			if i+1 < len(lines) {
-				usage := strings.TrimSpace(lines[i+1])
-				// Remove the binary path prefix, keep just the command pattern
-				parts := strings.Fields(usage)
-				if len(parts) > 1 {
-					// Skip the binary name, return the rest
-					return strings.Join(parts[1:], " ")
+	// Cleanup: archive the build and empty dist/ as configured, then prune
+	// the archived builds and backups
+	retention := pipeline.RetentionFrom(m.settings)
+	pipeline.RunStep(progress, pipeline.StepCleanup, func() error {
+		if !m.configOnly {
+			if err := pipeline.ArchiveBuild(m.projectRoot, m.selectedSkill, opts.BinaryPath, retention); err != nil {
+				return err
+			}
+			if !m.settings.KeepDist {
+				if err := pipeline.CleanDist(m.projectRoot); err != nil {
+					return err
 				}
 			}
 		}
+		_, err := pipeline.Clean(m.projectRoot, retention, false)
+		return err
+	})
+
+	stats.Record(stats.FeatureDeploy)
+	if m.useKeychain() && m.hasSecrets() {
+		stats.Record(stats.FeatureKeychain)
 	}
-	return ""
+	msg.duration = time.Since(start)
+	msg.steps = steps
+	return msg
 }
 
-// parseFlags extracts flags from Cobra help output
-func parseFlags(helpOutput string) []string {
-	var flags []string
-	lines := strings.Split(helpOutput, "\n")
-	inFlagsSection := false
-
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-
-		if strings.HasPrefix(trimmed, "Flags:") {
-			inFlagsSection = true
-			continue
-		}
+// deployOptions returns the pipeline deploy options for the current configuration
+func (m Model) deployOptions() pipeline.DeployOptions {
+	return pipeline.DeployOptions{
+		Manifest:    m.selectedSkill,
+		BinaryPath:  filepath.Join(m.projectRoot, "dist", m.selectedSkill.BinaryName()),
+		DeployPath:  m.getDeployPath(),
+		Values:      m.configValues,
+		UseKeychain: m.useKeychain(),
+		EncryptEnv:  m.encryptEnv(),
+		DocsMode:    m.docsMode,
+		Backup:      m.settings.KeepBackupsDays > 0,
+	}
+}
 
-		// End flags section at next section or empty line followed by non-flag
-		if inFlagsSection {
-			if trimmed == "" {
-				continue
-			}
-			if strings.HasSuffix(trimmed, ":") {
-				break
-			}
+// Deploy queue states of a skill (Queue view)
+const (
+	queuePending   = "pending"
+	queueBuilding  = "building"
+	queueDeploying = "deploying"
+	queueDone      = "done"
+	queueFailed    = "failed"
+	queueCanceled  = "canceled"
+)
 
-			// Parse flag line: "  -t, --title string   Task title (required)"
-			if strings.HasPrefix(trimmed, "-") {
-				// Format: `-short, --long type   description`
-				flag := formatFlag(trimmed)
-				if flag != "" && !strings.Contains(flag, "--help") {
-					flags = append(flags, flag)
-				}
-			}
-		}
-	}
+// queueItem is a skill of a multi-skill deploy
+type queueItem struct {
+	name     string
+	opts     pipeline.DeployOptions // Manifest is nil if the skill was not found
+	state    string
+	started  time.Time
+	finished time.Time
+	output   string // Build and hook output of a failed skill
+	err      error
+}
 
-	return flags
+// queueStageMsg is sent when a skill of the deploy queue moves on to a
+// further step
+type queueStageMsg struct {
+	index int
+	state string
+	msgs  <-chan tea.Msg // The closing queueItemMsg
 }
 
-// formatFlag formats a Cobra flag line into a readable format
-func formatFlag(line string) string {
-	// Input: "  -t, --title string    Task title (required)"
-	// Output: "`-t, --title` (string): Task title (required)"
+// queueItemMsg is sent when a skill of the deploy queue is deployed or failed
+type queueItemMsg struct {
+	index  int
+	output string
+	err    error
+}
 
-	parts := strings.Fields(line)
-	if len(parts) < 2 {
-		return ""
+// runQueueItem builds and deploys a skill of the queue into its own
+// temporary directory, sending queueStageMsg before the deploy and the
+// closing queueItemMsg
+func (m Model) runQueueItem(index int) tea.Cmd {
+	msgs := make(chan tea.Msg, 2)
+	item := m.queue[index]
+	runTests := pipeline.TestsEnabled(item.opts.Manifest, m.settings.TestBeforeDeploy)
+	archive := func(binaryPath string) error {
+		return pipeline.ArchiveBuild(m.projectRoot, item.opts.Manifest, binaryPath, pipeline.RetentionFrom(m.settings))
+	}
+	return func() tea.Msg {
+		go func() {
+			output, err := deployQueueItem(item.opts, runTests, archive, func() {
+				msgs <- queueStageMsg{index: index, state: queueDeploying, msgs: msgs}
+			})
+			msgs <- queueItemMsg{index: index, output: output, err: err}
+			close(msgs)
+		}()
+		return <-msgs
 	}
+}
 
-	var flagPart string
-	var typePart string
-	var descParts []string
+// deployQueueItem runs the pipeline for one skill of the queue, calling
+// deploying once the build succeeded and archive with the build once it is
+// deployed. It returns the output of the build and the hooks.
+func deployQueueItem(opts pipeline.DeployOptions, runTests bool, archive func(binaryPath string) error, deploying func()) (string, error) {
+	manifest := opts.Manifest
+	tmpDir, err := os.MkdirTemp("", "skillfactory-queue-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+	opts.BinaryPath = filepath.Join(tmpDir, manifest.BinaryName())
 
-	i := 0
-	// Collect flag names (-t, --title)
-	for i < len(parts) && (strings.HasPrefix(parts[i], "-") || parts[i] == ",") {
-		if parts[i] != "," {
-			if flagPart != "" {
-				flagPart += ", "
-			}
-			flagPart += strings.TrimSuffix(parts[i], ",")
-		}
-		i++
-	}
-
-	// Next part might be type (string, int, etc.) or description
-	if i < len(parts) {
-		// Common types in Cobra
-		commonTypes := []string{"string", "int", "int64", "bool", "float64", "duration", "stringArray", "intSlice"}
-		isType := false
-		for _, t := range commonTypes {
-			if parts[i] == t {
-				isType = true
-				break
-			}
-		}
-		if isType {
-			typePart = parts[i]
-			i++
+	if err := pipeline.PreflightDeploy(manifest, opts.DeployPath); err != nil {
+		return "", err
+	}
+	if runTests {
+		start := time.Now()
+		output, err := pipeline.RunTests(manifest)
+		if err != nil {
+			pipeline.RecordEvent(pipeline.NewEvent(pipeline.ActionBuild, manifest, opts.DeployPath, time.Since(start), output, err))
+			return output, fmt.Errorf("%w, deploy refused", err)
 		}
 	}
 
-	// Rest is description
-	if i < len(parts) {
-		descParts = parts[i:]
+	var log strings.Builder
+	hookEnv := pipeline.HookEnv{BinaryPath: opts.BinaryPath}
+	if err := pipeline.RunHookTo(manifest, pipeline.HookPreBuild, hookEnv, &log); err != nil {
+		return log.String(), err
 	}
-
-	result := "`" + flagPart + "`"
-	if typePart != "" {
-		result += " (" + typePart + ")"
+	start := time.Now()
+	err = pipeline.BuildTo(manifest, opts.BinaryPath, &log)
+	duration := time.Since(start)
+	pipeline.RecordEvent(pipeline.NewEvent(pipeline.ActionBuild, manifest, opts.DeployPath, duration, log.String(), err))
+	if err != nil {
+		return log.String(), err
 	}
-	if len(descParts) > 0 {
-		result += ": " + strings.Join(descParts, " ")
+	stats.RecordBuild(manifest.Name, duration)
+	pipeline.RecordBuildDuration(manifest.Name, pipeline.BuildRecord{
+		At:         time.Now().UTC(),
+		DurationMS: duration.Milliseconds(),
+		GoVersion:  pipeline.GoVersion(manifest.Path),
+	})
+	if err := pipeline.RunHookTo(manifest, pipeline.HookPostBuild, hookEnv, &log); err != nil {
+		return log.String(), err
 	}
 
-	return result
-}
-
-// parseSubcommands extracts subcommand names from Cobra help output
-func parseSubcommands(helpText string) []string {
-	var commands []string
-
-	lines := strings.Split(helpText, "\n")
-	inCommandsSection := false
-
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-
-		// Start of commands section
-		if strings.HasPrefix(trimmed, "Available Commands:") {
-			inCommandsSection = true
-			continue
-		}
-
-		// End of commands section (empty line or new section)
-		if inCommandsSection {
-			if trimmed == "" || strings.HasSuffix(trimmed, ":") {
-				if strings.HasSuffix(trimmed, ":") && !strings.HasPrefix(trimmed, "Available") {
-					break
-				}
-				continue
-			}
-
-			// Parse command name (first word)
-			parts := strings.Fields(trimmed)
-			if len(parts) > 0 {
-				cmdName := parts[0]
-				// Skip help and completion commands
-				if cmdName != "help" && cmdName != "completion" {
-					commands = append(commands, cmdName)
-				}
-			}
-		}
+	deploying()
+	start = time.Now()
+	_, err = pipeline.Deploy(opts)
+	if err == nil {
+		hookEnv.BinaryPath = filepath.Join(opts.DeployPath, "bin", manifest.BinaryName())
+		hookEnv.DeployPath = opts.DeployPath
+		err = pipeline.RunHookTo(manifest, pipeline.HookPostDeploy, hookEnv, &log)
 	}
-
-	return commands
-}
-
-// generateProjectIDsTable generates a markdown table from PROJECT_IDS JSON
-func (m Model) generateProjectIDsTable(jsonStr string) string {
-	// Simple JSON parsing for {"Name": ID} format
-	// For now, just return the raw JSON prettified
-	var b strings.Builder
-	b.WriteString("| ID | Projekt |\n")
-	b.WriteString("|----|---------|")
-
-	// TODO: Parse JSON properly and generate table
-	// For now, include raw config
-	b.WriteString("\n\nConfig: `")
-	b.WriteString(jsonStr)
-	b.WriteString("`")
-
-	return b.String()
+	pipeline.RecordEvent(pipeline.NewEvent(pipeline.ActionDeploy, manifest, opts.DeployPath, time.Since(start), "", err))
+	if err != nil {
+		return log.String(), err
+	}
+	stats.Record(stats.FeatureDeploy)
+	return log.String(), archive(opts.BinaryPath)
 }
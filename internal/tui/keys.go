@@ -0,0 +1,428 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyDef is a key binding of a key scope. The view handlers match on the
+// default keys; keys configured in the keys setting of the config file
+// ("<scope>.<action>: ctrl+e") replace them and are translated to the first
+// default key before the handlers see them.
+type keyDef struct {
+	action string
+	keys   []string // Default keys
+	label  string   // Default keys as shown in the help
+	desc   string
+}
+
+// keyScope holds the key bindings of a view, or of a mode of a view such as
+// the filter of the skill list
+type keyScope struct {
+	name   string
+	title  string // Heading of the help overlay
+	typing bool   // Printable keys go to a text input
+	defs   []keyDef
+}
+
+// globalScope holds the bindings active in every scope
+const globalScope = "global"
+
+// keyScopes lists the key bindings of every scope in the order of the help
+var keyScopes = []keyScope{
+	{name: globalScope, title: "Everywhere", defs: []keyDef{
+		{"help", []string{"?", "f1"}, "?", "Help"},
+	}},
+	{name: "skills", title: "Skill list", defs: []keyDef{
+		{"up", []string{"up", "k"}, "↑/K", "Up"},
+		{"down", []string{"down", "j"}, "↓/J", "Down"},
+		{"select", []string{"enter"}, "Enter", "Select"},
+		{"filter", []string{"/"}, "/", "Filter"},
+		{"edit", []string{"e"}, "E", "Edit skill.yaml"},
+		{"bundles", []string{"b"}, "B", "Bundles"},
+		{"settings", []string{"s"}, "S", "Settings"},
+		{"deployed", []string{"tab"}, "Tab", "Deployed"},
+		{"remove", []string{"x"}, "X", "Remove deployed"},
+		{"clear", []string{"esc"}, "Esc", "Clear filter"},
+		{"quit", []string{"q"}, "q", "Quit"},
+	}},
+	{name: "filter", title: "Skill filter", typing: true, defs: []keyDef{
+		{"up", []string{"up"}, "↑", "Up"},
+		{"down", []string{"down"}, "↓", "Down"},
+		{"apply", []string{"enter"}, "Enter", "Apply"},
+		{"clear", []string{"esc"}, "Esc", "Clear"},
+	}},
+	{name: "deployed", title: "Deployed skills", defs: []keyDef{
+		{"up", []string{"up", "k"}, "↑/K", "Up"},
+		{"down", []string{"down", "j"}, "↓/J", "Down"},
+		{"search", []string{"/"}, "/", "Search"},
+		{"skills", []string{"tab", "esc"}, "Tab/Esc", "Skills"},
+		{"edit", []string{"e"}, "E", "Edit configuration"},
+		{"remove", []string{"x"}, "X", "Remove"},
+		{"quit", []string{"q"}, "q", "Quit"},
+	}},
+	{name: "search", title: "Search", typing: true, defs: []keyDef{
+		{"up", []string{"up", "ctrl+p"}, "↑", "Up"},
+		{"down", []string{"down", "ctrl+n"}, "↓", "Down"},
+		{"show", []string{"enter"}, "Enter", "Show skill"},
+		{"back", []string{"esc"}, "Esc", "Back"},
+	}},
+	{name: "config", title: "Skill environment", typing: true, defs: []keyDef{
+		{"next", []string{"tab", "down"}, "Tab/↓", "Next field"},
+		{"prev", []string{"shift+tab", "up"}, "Shift+Tab/↑", "Previous field"},
+		{"reveal", []string{"ctrl+r"}, "Ctrl+R", "Reveal"},
+		{"paste", []string{"ctrl+v"}, "Ctrl+V", "Paste"},
+		{"choices", []string{"ctrl+l"}, "Ctrl+L", "List choices"},
+		{"continue", []string{"enter", "ctrl+d"}, "Enter", "Next Step"},
+		{"back", []string{"esc"}, "Esc", "Back"},
+	}},
+	{name: "choices", title: "Choices", defs: []keyDef{
+		{"up", []string{"up", "k"}, "↑/K", "Up"},
+		{"down", []string{"down", "j"}, "↓/J", "Down"},
+		{"choose", []string{"enter"}, "Enter", "Choose"},
+		{"close", []string{"esc"}, "Esc", "Close"},
+	}},
+	{name: "deploy", title: "Deploy settings", typing: true, defs: []keyDef{
+		{"next", []string{"tab", "down"}, "Tab/↓", "Next field"},
+		{"prev", []string{"shift+tab", "up"}, "Shift+Tab/↑", "Previous field"},
+		{"browse", []string{"ctrl+o"}, "Ctrl+O", "Browse"},
+		{"continue", []string{"enter", "ctrl+d"}, "Enter", "Next Step"},
+		{"back", []string{"esc"}, "Esc", "Back"},
+	}},
+	{name: "picker", title: "Folder browser", defs: []keyDef{
+		{"up", []string{"up", "k"}, "↑/K", "Up"},
+		{"down", []string{"down", "j"}, "↓/J", "Down"},
+		{"open", []string{"right", "l", "enter"}, "→/Enter", "Open"},
+		{"parent", []string{"left", "h", "backspace"}, "←", "Parent"},
+		{"use", []string{"s", " "}, "S/Space", "Use this folder"},
+		{"new", []string{"n"}, "N", "New directory"},
+		{"home", []string{"~"}, "~", "Home"},
+		{"back", []string{"esc"}, "Esc", "Type path"},
+	}},
+	{name: "newdir", title: "New directory", typing: true, defs: []keyDef{
+		{"create", []string{"enter"}, "Enter", "Create"},
+		{"cancel", []string{"esc"}, "Esc", "Cancel"},
+	}},
+	{name: "confirm", title: "Confirm", defs: []keyDef{
+		{"deploy", []string{"y", "enter"}, "Y/Enter", "Build & Deploy"},
+		{"keychain", []string{"k"}, "K", "Secret storage"},
+		{"encrypt", []string{"e"}, "E", "Encrypt .env"},
+		{"tests", []string{"t"}, "T", "Tests"},
+		{"env", []string{"v"}, "V", ".env preview"},
+		{"reveal", []string{"r"}, "R", "Reveal secrets"},
+		{"back", []string{"n", "esc"}, "N/Esc", "Back"},
+	}},
+	{name: "overwrite", title: "Overwrite", defs: []keyDef{
+		{"overwrite", []string{"y"}, "Y", "Overwrite"},
+		{"keep", []string{"k"}, "K", "Keep SKILL.md"},
+		{"merge", []string{"m"}, "M", "Merge SKILL.md"},
+		{"docs", []string{"d"}, "D", "Docs only"},
+		{"config", []string{"c"}, "C", "Config only"},
+		{"skip", []string{"s"}, "S", "Skip"},
+		{"up", []string{"up", "pgup"}, "↑", "Scroll up"},
+		{"down", []string{"down", "pgdown"}, "↓", "Scroll down"},
+		{"cancel", []string{"n", "esc"}, "N/Esc", "Cancel"},
+	}},
+	{name: "building", title: "Building", defs: []keyDef{
+		{"up", []string{"up"}, "↑", "Scroll up"},
+		{"down", []string{"down"}, "↓", "Scroll down"},
+		{"cancel", []string{"esc"}, "Esc", "Cancel build"},
+	}},
+	{name: "done", title: "Result", defs: []keyDef{
+		{"up", []string{"up", "k"}, "↑", "Up"},
+		{"down", []string{"down", "j"}, "↓", "Down"},
+		{"fix", []string{"f"}, "F", "Run fix"},
+		{"open", []string{"o"}, "O", "Open in editor"},
+		{"output", []string{"v"}, "V", "Show output"},
+		{"copy", []string{"c"}, "C", "Copy log"},
+		{"save", []string{"w"}, "W", "Save log"},
+		{"restart", []string{"r"}, "R", "Configure another skill"},
+		{"quit", []string{"enter", "q", "esc"}, "Enter/q", "Quit"},
+	}},
+	{name: "error", title: "Skill error", defs: []keyDef{
+		{"fix", []string{"f", "enter"}, "F", "Quick fix"},
+		{"open", []string{"o"}, "O", "Open in editor"},
+		{"reload", []string{"r"}, "R", "Reload skills"},
+		{"back", []string{"esc"}, "Esc", "Back"},
+		{"quit", []string{"q"}, "q", "Quit"},
+	}},
+	{name: "quickfix", title: "Quick fix", typing: true, defs: []keyDef{
+		{"apply", []string{"enter"}, "Enter", "Apply fix"},
+		{"skip", []string{"tab"}, "Tab", "Skip"},
+		{"back", []string{"esc"}, "Esc", "Back"},
+	}},
+	{name: "edit", title: "Edit skill.yaml", typing: true, defs: []keyDef{
+		{"next", []string{"tab", "down", "enter"}, "Tab/↓", "Next field"},
+		{"prev", []string{"shift+tab", "up"}, "Shift+Tab/↑", "Previous field"},
+		{"add", []string{"ctrl+n"}, "Ctrl+N", "Add variable"},
+		{"delete", []string{"ctrl+x"}, "Ctrl+X", "Remove variable"},
+		{"save", []string{"ctrl+s"}, "Ctrl+S", "Save"},
+		{"cancel", []string{"esc"}, "Esc", "Cancel"},
+	}},
+	{name: "profile", title: "Profiles", defs: []keyDef{
+		{"up", []string{"up", "k"}, "↑/K", "Up"},
+		{"down", []string{"down", "j"}, "↓/J", "Down"},
+		{"select", []string{"enter"}, "Enter", "Select"},
+		{"back", []string{"esc"}, "Esc", "Back"},
+	}},
+	{name: "remove", title: "Remove", defs: []keyDef{
+		{"remove", []string{"y"}, "Y", "Remove"},
+		{"cancel", []string{"n", "esc"}, "N/Esc", "Cancel"},
+	}},
+	{name: "bundles", title: "Bundles", defs: []keyDef{
+		{"up", []string{"up", "k"}, "↑/K", "Up"},
+		{"down", []string{"down", "j"}, "↓/J", "Down"},
+		{"edit", []string{"enter"}, "Enter", "Edit"},
+		{"deploy", []string{"d"}, "D", "Deploy all skills"},
+		{"back", []string{"esc"}, "Esc", "Back"},
+		{"quit", []string{"q"}, "q", "Quit"},
+	}},
+	{name: "queue", title: "Deploy queue", defs: []keyDef{
+		{"cancel", []string{"x"}, "X", "Cancel remaining"},
+		{"back", []string{"esc"}, "Esc", "Back to bundles"},
+		{"quit", []string{"q"}, "q", "Quit"},
+	}},
+	{name: "bundle", title: "Bundle", typing: true, defs: []keyDef{
+		{"next", []string{"tab", "down"}, "Tab/↓", "Next row"},
+		{"prev", []string{"shift+tab", "up"}, "Shift+Tab/↑", "Previous row"},
+		{"toggle", []string{" ", "enter"}, "Space", "Toggle"},
+		{"save", []string{"ctrl+s"}, "Ctrl+S", "Save"},
+		{"cancel", []string{"esc"}, "Esc", "Cancel"},
+	}},
+	{name: "settings", title: "Settings", typing: true, defs: []keyDef{
+		{"next", []string{"tab", "down"}, "Tab/↓", "Next row"},
+		{"prev", []string{"shift+tab", "up"}, "Shift+Tab/↑", "Previous row"},
+		{"toggle", []string{" ", "left", "right", "enter"}, "Space", "Toggle"},
+		{"save", []string{"ctrl+s"}, "Ctrl+S", "Save"},
+		{"cancel", []string{"esc"}, "Esc", "Cancel"},
+	}},
+}
+
+// keyMap holds the key bindings of all scopes with the configured keys
+type keyMap struct {
+	scopes map[string]*scopeKeys
+}
+
+// scopeKeys are the bindings of one scope
+type scopeKeys struct {
+	keyScope
+	bindings map[string]key.Binding // By action
+	remap    map[string]string      // Configured key → default key of its action
+	unbound  map[string]bool        // Default keys replaced by configured ones
+}
+
+// newKeyMap creates the key map, with the keys of custom replacing the
+// defaults of their "<scope>.<action>", e.g. "skills.edit": "ctrl+e,e"
+func newKeyMap(custom map[string]string) (keyMap, error) {
+	k := keyMap{scopes: make(map[string]*scopeKeys, len(keyScopes))}
+	for _, scope := range keyScopes {
+		s := &scopeKeys{
+			keyScope: scope,
+			bindings: make(map[string]key.Binding, len(scope.defs)),
+			remap:    make(map[string]string),
+			unbound:  make(map[string]bool),
+		}
+		for _, def := range scope.defs {
+			keys, label := def.keys, def.label
+			if value, ok := custom[scope.name+"."+def.action]; ok {
+				keys = parseKeys(value)
+				if len(keys) == 0 {
+					return keyMap{}, fmt.Errorf("keys: no key for %s.%s", scope.name, def.action)
+				}
+				label = keyLabel(keys)
+				for _, d := range def.keys {
+					s.unbound[d] = true
+				}
+				for _, c := range keys {
+					s.remap[c] = def.keys[0]
+				}
+			}
+			s.bindings[def.action] = key.NewBinding(key.WithKeys(keys...), key.WithHelp(label, def.desc))
+		}
+		for c := range s.remap {
+			delete(s.unbound, c)
+		}
+		k.scopes[scope.name] = s
+	}
+
+	for name := range custom {
+		scope, action, _ := strings.Cut(name, ".")
+		s, ok := k.scopes[scope]
+		if ok {
+			_, ok = s.bindings[action]
+		}
+		if !ok {
+			return keyMap{}, fmt.Errorf("keys: unknown action %q", name)
+		}
+	}
+	for _, s := range k.scopes {
+		if err := k.checkConflicts(s); err != nil {
+			return keyMap{}, err
+		}
+	}
+	return k, nil
+}
+
+// checkConflicts reports a key bound to two actions of a scope, or to an
+// action of the scope and a global one
+func (k keyMap) checkConflicts(s *scopeKeys) error {
+	owner := make(map[string]string)
+	scopes := []*scopeKeys{s}
+	if s.name != globalScope {
+		scopes = append(scopes, k.scopes[globalScope])
+	}
+	for _, scope := range scopes {
+		for _, def := range scope.defs {
+			for _, c := range scope.bindings[def.action].Keys() {
+				name := scope.name + "." + def.action
+				if other, ok := owner[c]; ok && other != name {
+					return fmt.Errorf("keys: %q is bound to both %s and %s", c, other, name)
+				}
+				owner[c] = name
+			}
+		}
+	}
+	return nil
+}
+
+// translate maps a configured key to the default key of its action in
+// scope. ok is false for a default key that was replaced, except for
+// printable keys typed into an input.
+func (k keyMap) translate(scope string, msg tea.KeyMsg) (tea.KeyMsg, bool) {
+	for _, name := range []string{scope, globalScope} {
+		s, found := k.scopes[name]
+		if !found {
+			continue
+		}
+		if target, ok := s.remap[msg.String()]; ok {
+			return keyMsg(target), true
+		}
+		printable := msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace
+		if s.unbound[msg.String()] && (!printable || !k.scopes[scope].typing) {
+			return msg, false
+		}
+	}
+	return msg, true
+}
+
+// isHelp reports whether msg opens the help overlay in scope: the help keys,
+// except printable ones while typing
+func (k keyMap) isHelp(scope string, msg tea.KeyMsg) bool {
+	if !key.Matches(msg, k.scopes[globalScope].bindings["help"]) {
+		return false
+	}
+	printable := msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace
+	return !printable || !k.scopes[scope].typing
+}
+
+// hint formats the help of actions of scope for the help line, e.g.
+// "E: Edit skill.yaml • B: Bundles"
+func (k keyMap) hint(scope string, actions ...string) string {
+	hints := make([]string, 0, len(actions))
+	for _, action := range actions {
+		help := k.scopes[scope].bindings[action].Help()
+		hints = append(hints, help.Key+": "+help.Desc)
+	}
+	return strings.Join(hints, " • ")
+}
+
+// label returns the keys of an action as shown in the help, e.g. "Ctrl+O"
+func (k keyMap) label(scope, action string) string {
+	return k.scopes[scope].bindings[action].Help().Key
+}
+
+// helpKey returns the label of the help key usable in scope
+func (k keyMap) helpKey(scope string) string {
+	binding := k.scopes[globalScope].bindings["help"]
+	if !k.scopes[scope].typing {
+		return binding.Help().Key
+	}
+	for _, c := range binding.Keys() {
+		if len([]rune(c)) > 1 {
+			return keyLabel([]string{c})
+		}
+	}
+	return binding.Help().Key
+}
+
+// columns returns the bindings of scope in columns of at most rows
+// bindings for the help overlay, with the action names of the keys setting
+func (k keyMap) columns(scope string, rows int) [][]key.Binding {
+	s := k.scopes[scope]
+	var columns [][]key.Binding
+	for i, def := range s.defs {
+		if i%rows == 0 {
+			columns = append(columns, nil)
+		}
+		b := s.bindings[def.action]
+		help := b.Help()
+		b.SetHelp(help.Key, help.Desc+" "+mutedStyle.Render(def.action))
+		columns[len(columns)-1] = append(columns[len(columns)-1], b)
+	}
+	return columns
+}
+
+// parseKeys splits a configured key list, "space" stands for " "
+func parseKeys(value string) []string {
+	var keys []string
+	for _, c := range strings.Split(value, ",") {
+		c = strings.TrimSpace(c)
+		switch c {
+		case "":
+			continue
+		case "space":
+			c = " "
+		}
+		keys = append(keys, c)
+	}
+	return keys
+}
+
+// keyNames maps the names of non-printable keys to their type
+var keyNames = func() map[string]tea.KeyType {
+	names := make(map[string]tea.KeyType)
+	for t := tea.KeyType(-100); t <= 127; t++ {
+		if name := t.String(); name != "" && t != tea.KeyRunes {
+			if _, ok := names[name]; !ok {
+				names[name] = t
+			}
+		}
+	}
+	return names
+}()
+
+// keyMsg returns the key message tea reports for a key name, e.g. "ctrl+s"
+func keyMsg(name string) tea.KeyMsg {
+	rest, alt := strings.CutPrefix(name, "alt+")
+	if t, ok := keyNames[rest]; ok {
+		msg := tea.KeyMsg{Type: t, Alt: alt}
+		if t == tea.KeySpace {
+			msg.Runes = []rune{' '}
+		}
+		return msg
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(rest), Alt: alt}
+}
+
+// keyLabel formats configured keys for the help, e.g. "Ctrl+E/E"
+func keyLabel(keys []string) string {
+	symbols := map[string]string{"up": "↑", "down": "↓", "left": "←", "right": "→", " ": "Space"}
+	labels := make([]string, 0, len(keys))
+	for _, c := range keys {
+		if symbol, ok := symbols[c]; ok {
+			labels = append(labels, symbol)
+			continue
+		}
+		parts := strings.Split(c, "+")
+		for i, part := range parts {
+			if part != "" {
+				parts[i] = strings.ToUpper(part[:1]) + part[1:]
+			}
+		}
+		labels = append(labels, strings.Join(parts, "+"))
+	}
+	return strings.Join(labels, "/")
+}
@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// compactWidth is the terminal width below which the compact layout is used:
+// no subtitle, less padding and fewer table columns
+const compactWidth = 80
+
+// Default widths of the text inputs, reduced on narrow terminals by fitWidth
+const (
+	inputWidth      = 50
+	shortInputWidth = 40
+)
+
+// Columns left of a text input: indent, prompt and cursor in the forms, the
+// label columns of the bundle editor and the Settings view
+const (
+	formIndent     = 5
+	bundleIndent   = 20
+	settingsIndent = 29
+)
+
+// compact reports whether the terminal is narrower than compactWidth
+func (m Model) compact() bool {
+	return m.width > 0 && m.width < compactWidth
+}
+
+// contentWidth returns the width available inside a box, 0 while the
+// terminal size is unknown
+func (m Model) contentWidth() int {
+	if m.width == 0 {
+		return 0
+	}
+	padding := 2
+	if m.compact() {
+		padding = 1
+	}
+	return max(m.width-2-2*padding, 20)
+}
+
+// box renders content in the bordered box of the views. Lines wider than
+// the terminal are wrapped, see wrapLine.
+func (m Model) box(content string) string {
+	style := boxStyle
+	if m.compact() {
+		style = style.Padding(0, 1)
+	}
+	if width := m.contentWidth(); width > 0 {
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			lines[i] = wrapLine(line, width)
+		}
+		content = strings.Join(lines, "\n")
+	}
+	return style.Render(content)
+}
+
+// wrapLine wraps a line wider than width at spaces and slashes, indenting
+// the continuation lines like the line itself
+func wrapLine(line string, width int) string {
+	if lipgloss.Width(line) <= width {
+		return line
+	}
+	plain := ansi.Strip(line)
+	indent := len(plain) - len(strings.TrimLeft(plain, " "))
+	if indent > width/2 {
+		indent = 0
+	}
+	wrapped := ansi.Wrap(line, width-indent, "/")
+	return strings.ReplaceAll(wrapped, "\n", "\n"+strings.Repeat(" ", indent))
+}
+
+// wrapIndent wraps text to the box width minus indent columns, indenting the
+// continuation lines to line up with the first one
+func (m Model) wrapIndent(text string, indent int) string {
+	width := m.contentWidth() - indent
+	if width <= 0 || lipgloss.Width(text) <= width {
+		return text
+	}
+	return strings.ReplaceAll(ansi.Wrap(text, width, "/"), "\n", "\n"+strings.Repeat(" ", indent))
+}
+
+// detail renders a "label value" row of a summary; a long value wraps below
+// itself instead of below the label
+func (m Model) detail(label, value string) string {
+	return mutedStyle.Render(label) + m.wrapIndent(value, lipgloss.Width(label)) + "\n"
+}
+
+// fitWidth returns the width of a text input with indent columns left of it:
+// width, or less if the box would not fit in the terminal otherwise
+func (m Model) fitWidth(width, indent int) int {
+	if m.width == 0 {
+		return width
+	}
+	return max(min(width, m.contentWidth()-indent), 10)
+}
+
+// fitInputs adapts the widths of the text inputs to the terminal width
+func (m *Model) fitInputs() {
+	for i := range m.configInputs {
+		m.configInputs[i].Width = m.fitWidth(inputWidth, formIndent)
+	}
+	for i := range m.deployInputs {
+		m.deployInputs[i].Width = m.fitWidth(inputWidth, formIndent)
+	}
+	for i := range m.bundleInputs {
+		m.bundleInputs[i].Width = m.fitWidth(inputWidth, bundleIndent)
+	}
+	if len(m.settingsInputs) > 0 {
+		m.settingsInputs[0].Width = m.fitWidth(inputWidth, settingsIndent)
+	}
+	m.fixInput.Width = m.fitWidth(inputWidth, formIndent)
+	m.searchInput.Width = m.fitWidth(shortInputWidth, formIndent)
+	m.pickerInput.Width = m.fitWidth(shortInputWidth, formIndent)
+}
+
+// bodyHeight returns the number of lines available inside the box of the
+// current view, 0 while the terminal size is unknown
+func (m Model) bodyHeight() int {
+	if m.height == 0 {
+		return 0
+	}
+	chrome := 3 + 4 // Header, box border and padding
+	if m.compact() {
+		chrome = 2 + 2
+	}
+	chrome += 2 + lipgloss.Height(m.renderHelp())
+	if m.errorMsg != "" || m.statusMsg != "" {
+		chrome += 2
+	}
+	return max(m.height-chrome, 5)
+}
+
+// window crops the lines of a long form to the terminal height, keeping its
+// title (the first two lines). The focus line and span lines after it stay
+// visible; the number of lines hidden above and below is shown instead.
+func (m Model) window(content string, focus, span int) string {
+	height := m.bodyHeight()
+	if height == 0 {
+		return content
+	}
+	// Lines wrapped by box count as several
+	var lines []string
+	for i, line := range strings.Split(content, "\n") {
+		if i == focus {
+			focus = len(lines)
+		}
+		lines = append(lines, strings.Split(wrapLine(line, m.contentWidth()), "\n")...)
+	}
+	if len(lines) <= height || len(lines) < 3 {
+		return content
+	}
+
+	title, lines := lines[:2], lines[2:]
+	focus = max(focus-2, 0)
+	rows := max(height-4, 1) // Room for the title and both indicators
+	start := min(max(focus+span+1-rows, 0), len(lines)-rows)
+	end := start + rows
+
+	var b strings.Builder
+	b.WriteString(strings.Join(title, "\n"))
+	b.WriteString("\n")
+	if start > 0 {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  ↑ %d more", start)))
+		b.WriteString("\n")
+	}
+	b.WriteString(strings.Join(lines[start:end], "\n"))
+	if end < len(lines) {
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  ↓ %d more", len(lines)-end)))
+	}
+	return b.String()
+}
+
+// fitLine wraps a message below the box to the terminal width
+func (m Model) fitLine(s string) string {
+	if m.width == 0 {
+		return s
+	}
+	return ansi.Wrap(s, m.width, "/")
+}
+
+// lineCount returns the number of the line b is writing, to pass the line of
+// the focused input to window
+func lineCount(b *strings.Builder) int {
+	return strings.Count(b.String(), "\n")
+}
+
+// wrapHints wraps a help line of "key: action" hints joined by " • " to
+// width, breaking between hints
+func wrapHints(hints string, width int) string {
+	if width <= 0 || lipgloss.Width(hints) <= width {
+		return hints
+	}
+	var lines []string
+	line := ""
+	for _, hint := range strings.Split(hints, " • ") {
+		switch {
+		case line == "":
+			line = hint
+		case lipgloss.Width(line+" • "+hint) <= width:
+			line += " • " + hint
+		default:
+			lines = append(lines, line)
+			line = hint
+		}
+	}
+	return strings.Join(append(lines, line), "\n")
+}
@@ -2,13 +2,25 @@
 package tui
 
 import (
+	"context"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
 	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
 	"github.com/petervogelmann/skillfactory/internal/skill"
+	"github.com/petervogelmann/skillfactory/internal/stats"
 )
 
 // View represents different screens in the TUI
@@ -22,6 +34,18 @@ const (
 	ViewOverwrite             // Warning: skill already exists
 	ViewBuilding              // Building in progress
 	ViewDone                  // Success/Error result
+	ViewQuickFix              // Guided fix for manifest errors
+	ViewProfile               // Select a saved configuration profile
+	ViewRemove                // Confirm removal of a deployed skill
+	ViewDeployed              // Skills deployed in the skills folder (tab next to the skill list)
+	ViewEditManifest          // Edit skill.yaml in a form
+	ViewBundles               // Bundle definitions of bundle.yaml
+	ViewBundleEdit            // Compose a bundle: skills, shared variables, target
+	ViewFolderPicker          // Browse for the skills folder (from the Deploy view)
+	ViewSearch                // Full-text search of the deployed skills (from the Deployed view)
+	ViewSettings              // Global options of the config file
+	ViewErrorDetail           // Full error of a skill that failed to load
+	ViewQueue                 // Progress of a multi-skill deploy (from the Bundles view)
 )
 
 // Model represents the application state
@@ -35,33 +59,152 @@ type Model struct {
 	selectedSkill *skill.Manifest
 	selectedError *skill.SkillError // Selected error skill (for viewing errors)
 
+	// Fuzzy filter of the skill list, opened with "/"
+	skillFilter textinput.Model
+	filtering   bool // The filter input has focus
+
 	// Skill environment variable inputs
 	configInputs      []textinput.Model
 	configLabels      []string
 	configFocus       int
 
+	// Choices of a variable with source: skill, listed under its input
+	choices        []pipeline.Choice
+	choiceCursor   int
+	choicesLoading bool
+	choicesNote    string // Why no choices are listed
+
 	// Deploy settings inputs
 	deployInputs      []textinput.Model
 	deployLabels      []string
 	deployFocus       int
 
+	// Directory browser for the skills folder (FolderPicker view)
+	pickerDir      string
+	pickerEntries  []string // Subdirectories of pickerDir
+	pickerCursor   int
+	pickerCreating bool            // The new directory input has focus
+	pickerInput    textinput.Model // Name of a new directory
+	pickerNote     string          // Why the browser was opened, or the last error
+
 	// Deploy configuration (saved values)
 	skillsFolder    string // Base folder for skills (e.g., /path/to/.claude/skills/)
 	skillFolderName string // Subfolder name for this skill (default: skill name)
 
 	// Persistent config
-	config *config.Config
+	config   *config.Config
+	settings *config.Settings // Global config file with environment overrides
+
+	// Settings view: skills folder and parallel builds inputs, then the
+	// theme and the toggles of settingsEdit
+	settingsInputs []textinput.Model
+	settingsEdit   config.Settings
+	settingsFocus  int
 
 	// Configured values
 	configValues map[string]string
 
+	// Configuration profiles of the selected skill
+	profiles      map[string]*config.Profile
+	profileNames  []string
+	profileCursor int
+	profileName   string // Profile the configuration is saved as
+
 	// Status messages
 	statusMsg string
 	errorMsg  string
 
+	// Key bindings with the keys setting applied, see keys.go
+	keys     keyMap
+	showHelp bool // Help overlay with all key bindings of the view
+
 	// Build state
 	building    bool
+	buildStage  string // Current pipeline step shown in the Building view
 	buildOutput string
+	buildLog    string         // Output streamed by the running build
+	buildTrend  string         // Sparkline of the recent build durations
+	outputView  viewport.Model // Scrollable build output in the Building and Done views
+	spinner     spinner.Model  // Activity indicator of the Building view
+	logNote     string         // Result of copying or saving the build log (Done view)
+	vulnResult  *pipeline.VulncheckResult
+	healthcheck *pipeline.HealthcheckResult // Result of the healthcheck command after the deploy
+	deploySteps []pipeline.DeployStep       // Steps of the running or finished deploy
+
+	// Context of the running tests and go build, canceled by Esc in the
+	// Building view
+	buildCtx    context.Context
+	cancelBuild context.CancelFunc
+
+	// Compiler errors parsed from a failed build (Done view)
+	problems      []pipeline.Problem
+	problemCursor int
+	showOutput    bool   // Show the raw build output instead of the problems
+	editorErr     string // Error of the last "open in editor"
+
+	// Recognized causes of a failed build with remedies (Done view)
+	diagnoses []pipeline.Diagnosis
+
+	// Git state of the selected skill source (Confirm view)
+	gitInfo pipeline.GitInfo
+
+	// Preview of the .env to deploy (Confirm view)
+	showEnvPreview bool
+	revealEnv      bool // Show secret values in the preview
+
+	// Existing deployment (Overwrite view)
+	deployedLock    *pipeline.Lock
+	lockChanges     []string
+	deployedVersion string
+	platformWarning string              // Deployed binary targets another GOOS/GOARCH
+	docsEdited      bool                // Deployed SKILL.md was edited by hand
+	docsMode        string              // pipeline.DocsModes choice for the edited SKILL.md
+	configOnly      bool                // Redeploy the configuration with the deployed binary
+	deployDiffs     []pipeline.FileDiff // Deployed SKILL.md and .env vs. the new versions
+	checkingDeploy  bool                // Overwrite view waits for the deployCheckMsg
+
+	// Quick fix state for skills with manifest errors
+	fixIssues []skill.Issue
+	fixIndex  int
+	fixInput  textinput.Model
+
+	// Deployed versions by skill name (from the saved skills folder)
+	deployedVersions map[string]string
+	lastDeploys      map[string]deployRecord // Last successful deploy by skill name
+	versionsStale    bool                    // Look up the deployed versions again after this update
+
+	// Manifest editor state
+	editSkill  *skill.Manifest
+	edit       *skill.ManifestEdit
+	editFields []editField
+	editFocus  int
+
+	// Skills found in the skills folder (Deployed view)
+	deployedSkills []pipeline.DeployedSkill
+	deployedCursor int
+
+	// Search across the deployed SKILL.md files and commands (Search view)
+	searchInput   textinput.Model
+	searchIndex   []pipeline.SearchDoc
+	searchResults []pipeline.SearchMatch
+	searchCursor  int
+
+	// Bundle composer (Bundles and BundleEdit views)
+	bundles      []skill.Bundle
+	bundleCursor int
+	bundleEdit   skill.Bundle      // Bundle being composed
+	bundleOrig   string            // Name of the edited bundle, empty for a new one
+	bundleInputs []textinput.Model // Name, description, target
+	bundleFocus  int               // Inputs, then skills, then shared variables
+
+	// Multi-skill deploy of a bundle, one skill after another (Queue view)
+	queue       []queueItem
+	queueBundle string
+
+	// Removal of a deployed skill (Remove view)
+	removePath   string
+	removePaths  []string
+	removeReturn View // View to return to after removal
 
 	width    int
 	height   int
@@ -80,8 +223,13 @@ func NewModel(projectRoot string, version string) Model {
 
 	// Load persistent config
 	cfg, _ := config.Load()
+	settings, err := config.LoadSettings()
+	if err != nil {
+		settings = &config.Settings{Theme: config.ThemeAuto}
+	}
+	applyTheme(settings.Theme)
 
-	return Model{
+	m := Model{
 		projectRoot:  projectRoot,
 		version:      version,
 		manifests:    manifests,
@@ -89,17 +237,91 @@ func NewModel(projectRoot string, version string) Model {
 		currentView:  ViewSkillList,
 		configValues: make(map[string]string),
 		config:       cfg,
+		settings:     settings,
 		skillsFolder: cfg.SkillsFolder, // Pre-fill from saved config
+		skillFilter:  newSkillFilter(),
+		spinner:      spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(inputLabelStyle)),
+	}
+	if m.keys, err = newKeyMap(settings.Keys); err != nil {
+		m.keys, _ = newKeyMap(nil)
+		m.errorMsg = err.Error()
+	}
+	return m
+}
+
+// deployRecord is the last deploy of a skill shown in the skill list
+type deployRecord struct {
+	At     time.Time
+	Target string
+}
+
+// deployedVersionsMsg is sent when the deployed versions are looked up
+type deployedVersionsMsg struct {
+	skillsFolder string
+	versions     map[string]string
+	lastDeploys  map[string]deployRecord
+}
+
+// refreshDeployedVersions requests a new lookup of the deployed versions,
+// run by Update as loadDeployedVersions once the current message is handled
+func (m *Model) refreshDeployedVersions() {
+	m.versionsStale = true
+}
+
+// loadDeployedVersions looks up the deployed version of every skill in the
+// skills folder, and the last deploy of every skill in the event log (or the
+// deploy.lock in the skills folder). The versions come from running the
+// deployed binaries, so this runs as a command.
+func (m Model) loadDeployedVersions() tea.Cmd {
+	skillsFolder := m.skillsFolder
+	manifests := m.manifests
+	return func() tea.Msg {
+		msg := deployedVersionsMsg{
+			skillsFolder: skillsFolder,
+			versions:     make(map[string]string),
+			lastDeploys:  make(map[string]deployRecord),
+		}
+		if events, err := pipeline.LoadEvents(pipeline.EventFilter{Action: pipeline.ActionDeploy}); err == nil {
+			for _, e := range events {
+				if e.Outcome == pipeline.OutcomeSuccess {
+					msg.lastDeploys[e.Skill] = deployRecord{At: e.At, Target: e.Target}
+				}
+			}
+		}
+		if skillsFolder == "" {
+			return msg
+		}
+		for _, manifest := range manifests {
+			deployPath := filepath.Join(skillsFolder, manifest.Name)
+			if v := pipeline.DeployedVersion(deployPath, manifest.BinaryName()); v != "" {
+				msg.versions[manifest.Name] = v
+			}
+			if _, ok := msg.lastDeploys[manifest.Name]; !ok {
+				if lock, err := pipeline.ReadLock(deployPath); err == nil {
+					msg.lastDeploys[manifest.Name] = deployRecord{At: lock.BuiltAt, Target: deployPath}
+				}
+			}
+		}
+		return msg
 	}
 }
 
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
-	return nil
+	return m.loadDeployedVersions()
 }
 
 // Update handles messages and updates the model
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	if next, ok := model.(Model); ok && next.versionsStale {
+		next.versionsStale = false
+		return next, tea.Batch(cmd, next.loadDeployedVersions())
+	}
+	return model, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		// Global quit
@@ -108,7 +330,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 
+		// The help overlay is closed by any key
+		if m.showHelp {
+			m.showHelp = false
+			return m, nil
+		}
+
+		// Configured keys act like the default keys of their action
+		scope := m.keyScope()
+		msg, ok := m.keys.translate(scope, msg)
+		if !ok {
+			return m, nil
+		}
+		if m.keys.isHelp(scope, msg) {
+			m.showHelp = true
+			return m, nil
+		}
+
 		// Config view: handle skill variable inputs
+		if m.currentView == ViewConfig && m.choices != nil {
+			return m.handleChoices(msg)
+		}
 		if m.currentView == ViewConfig {
 			switch msg.String() {
 			case "esc":
@@ -116,11 +358,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.errorMsg = ""
 				return m, nil
 			case "tab", "down":
+				m.hideSecret()
+				m.closeChoices()
 				m.configInputs[m.configFocus].Blur()
 				m.configFocus = (m.configFocus + 1) % len(m.configInputs)
 				m.configInputs[m.configFocus].Focus()
 				return m, textinput.Blink
 			case "shift+tab", "up":
+				m.hideSecret()
+				m.closeChoices()
 				m.configInputs[m.configFocus].Blur()
 				m.configFocus--
 				if m.configFocus < 0 {
@@ -128,8 +374,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				m.configInputs[m.configFocus].Focus()
 				return m, textinput.Blink
+			case "ctrl+r":
+				// Reveal the focused secret until the focus moves on
+				if m.isSecretInput(m.configFocus) {
+					input := &m.configInputs[m.configFocus]
+					if input.EchoMode == textinput.EchoPassword {
+						input.EchoMode = textinput.EchoNormal
+					} else {
+						input.EchoMode = textinput.EchoPassword
+					}
+				}
+				return m, nil
+			case "ctrl+l":
+				// List the values of a source: skill variable to choose from
+				if m.hasChoices(m.configFocus) && !m.choicesLoading {
+					m.choicesLoading = true
+					m.choicesNote = ""
+					return m, m.loadChoices(m.configFocus)
+				}
+				return m, nil
 			case "ctrl+d", "enter":
 				// Validate and continue to deploy settings
+				m.hideSecret()
+				m.closeChoices()
 				if m.validateConfigInputs() {
 					m.saveConfigInputs()
 					m.setupDeployInputs()
@@ -142,6 +409,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		// Search of the deployed skills
+		if m.currentView == ViewSearch {
+			return m.handleSearchView(msg)
+		}
+
+		// Directory browser for the skills folder
+		if m.currentView == ViewFolderPicker {
+			return m.handleFolderPicker(msg)
+		}
+
 		// Deploy view: handle deploy settings inputs
 		if m.currentView == ViewDeploy {
 			switch msg.String() {
@@ -157,7 +434,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.configInputs[0].Focus()
 				}
 				return m, textinput.Blink
+			case "ctrl+o":
+				// Browse for the skills folder
+				m.openFolderPicker(config.ExpandPath(m.deployInputs[0].Value()), "")
+				return m, nil
 			case "tab", "down":
+				// Tab completes the skills folder path, or moves to the next field
+				if msg.String() == "tab" && m.deployFocus == 0 {
+					if completed, ok := completeDir(m.deployInputs[0].Value()); ok {
+						m.deployInputs[0].SetValue(completed)
+						m.deployInputs[0].CursorEnd()
+						return m, nil
+					}
+				}
 				m.deployInputs[m.deployFocus].Blur()
 				m.deployFocus = (m.deployFocus + 1) % len(m.deployInputs)
 				m.deployInputs[m.deployFocus].Focus()
@@ -173,7 +462,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "ctrl+d", "enter":
 				// Validate and continue to confirm
 				if m.validateDeployInputs() {
+					// A mistyped skills folder would be created silently, pick an existing one
+					folder := config.ExpandPath(m.deployInputs[0].Value())
+					if info, err := os.Stat(folder); err != nil || !info.IsDir() {
+						m.openFolderPicker(folder, folder+" does not exist, choose a folder or create it with N")
+						return m, nil
+					}
 					m.saveDeployInputs()
+					m.gitInfo = pipeline.GitStatus(m.selectedSkill.Path)
 					m.currentView = ViewConfirm
 				}
 				return m, nil
@@ -183,38 +479,293 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		// Profile view: pick a saved configuration
+		if m.currentView == ViewProfile {
+			return m.handleProfileView(msg)
+		}
+
+		// Quick fix view: edit the offending skill.yaml field
+		if m.currentView == ViewQuickFix {
+			return m.handleQuickFixView(msg)
+		}
+
+		// Manifest editor: form fields for skill.yaml
+		if m.currentView == ViewEditManifest {
+			return m.handleEditManifestView(msg)
+		}
+
+		// Bundle composer: inputs and checklists
+		if m.currentView == ViewBundleEdit {
+			return m.handleBundleEditView(msg)
+		}
+
+		// Settings: inputs and toggles of the global config file
+		if m.currentView == ViewSettings {
+			return m.handleSettingsView(msg)
+		}
+
+		// Skill list filter input
+		if m.currentView == ViewSkillList && m.filtering {
+			return m.handleSkillFilter(msg)
+		}
+
 		return m.handleKeyPress(msg)
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.fitInputs()
+		if m.currentView == ViewDone || m.currentView == ViewOverwrite || m.currentView == ViewBuilding {
+			m.outputView.Width = m.outputWidth()
+			m.outputView.Height = m.outputHeight()
+		}
 		return m, nil
 
+	case testCompleteMsg:
+		if m.buildCanceled() {
+			return m, nil
+		}
+		m.buildOutput = msg.output
+		if msg.err != nil {
+			m.recordEvent(pipeline.ActionBuild, msg.duration, msg.output, msg.err)
+			m.building = false
+			m.errorMsg = msg.err.Error() + ", deploy refused"
+			m.enterDone()
+			return m, nil
+		}
+		m.buildStage = ""
+		m.updateBuildView()
+		return m, m.startBuild()
+
+	case buildLineMsg:
+		m.buildLog += msg.line + "\n"
+		m.updateBuildView()
+		return m, waitForBuildMsg(msg.msgs)
+
+	case spinner.TickMsg:
+		if !m.building {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
 	case buildCompleteMsg:
+		if m.buildCanceled() {
+			return m, nil
+		}
 		m.building = false
+		m.buildLog = ""
+		m.buildOutput += msg.output
+		m.recordEvent(pipeline.ActionBuild, msg.duration, msg.output, msg.err)
+		if msg.err != nil {
+			m.errorMsg = msg.err.Error()
+			m.enterDone()
+			return m, nil
+		}
+		record := m.recordBuild(msg.duration)
+		if pipeline.VulncheckEnabled(m.selectedSkill) {
+			// Build succeeded, scan for vulnerabilities before deploying
+			m.buildStage = "Scanning for vulnerabilities"
+			return m, tea.Batch(record, m.runVulncheck())
+		}
+		// Build succeeded, now deploy
+		return m, tea.Batch(record, m.deploySkill())
+
+	case vulncheckCompleteMsg:
+		m.vulnResult = &msg.result
+		if msg.err != nil {
+			m.errorMsg = "vulnerability scan failed: " + msg.err.Error()
+			m.enterDone()
+			return m, nil
+		}
+		if msg.result.Vulnerable && m.selectedSkill.Build.Vulncheck == pipeline.VulncheckBlock {
+			m.errorMsg = "deploy blocked: known vulnerabilities found"
+			m.enterDone()
+			return m, nil
+		}
+		m.buildStage = "Deploying"
+		return m, m.deploySkill()
+
+	case fixCompleteMsg:
+		if m.buildCanceled() {
+			return m, nil
+		}
 		m.buildOutput = msg.output
 		if msg.err != nil {
+			m.building = false
 			m.errorMsg = msg.err.Error()
-			m.currentView = ViewDone
-		} else {
-			// Build succeeded, now deploy
-			return m, m.deploySkill()
+			m.enterDone()
+			return m, nil
+		}
+		// Fixed, build again
+		m.buildStage = ""
+		m.updateBuildView()
+		if m.runTests() {
+			m.buildStage = "Running tests"
+			return m, m.runTestGate()
+		}
+		return m, m.startBuild()
+
+	case choicesLoadedMsg:
+		if m.currentView != ViewConfig || msg.index != m.configFocus || !m.choicesLoading {
+			return m, nil
+		}
+		m.choicesLoading = false
+		switch {
+		case msg.err != nil:
+			m.choicesNote = msg.err.Error()
+		case len(msg.choices) == 0:
+			m.choicesNote = "no choices listed"
+		default:
+			m.choices = msg.choices
+			m.choiceCursor = 0
+			current := strings.TrimSpace(m.configInputs[msg.index].Value())
+			for i, c := range msg.choices {
+				if c.Value == current {
+					m.choiceCursor = i
+				}
+			}
+		}
+		return m, nil
+
+	case editorClosedMsg:
+		m.editorErr = ""
+		if msg.err != nil {
+			m.editorErr = "failed to open editor: " + msg.err.Error()
+		} else if m.currentView == ViewErrorDetail {
+			// Pick up the edited skill.yaml
+			m.reloadErrorSkill()
+		}
+		return m, nil
+
+	case queueStageMsg:
+		m.queue[msg.index].state = msg.state
+		return m, waitForBuildMsg(msg.msgs)
+
+	case queueItemMsg:
+		item := &m.queue[msg.index]
+		item.finished = time.Now()
+		item.state = queueDone
+		if msg.err != nil {
+			item.state = queueFailed
+			item.output = msg.output
+			item.err = msg.err
+		}
+		return m, m.runNextQueueItem()
+
+	case deployStepMsg:
+		m.deploySteps = msg.steps
+		for _, step := range msg.steps {
+			if step.State == pipeline.StepRunning {
+				m.buildStage = step.Name
+			}
+		}
+		return m, waitForBuildMsg(msg.msgs)
+
+	case deployCheckMsg:
+		if m.checkingDeploy && msg.deployPath == m.getDeployPath() {
+			m.applyDeployCheck(msg)
+		}
+		return m, nil
+
+	case buildRecordedMsg:
+		m.buildTrend = msg.trend
+		return m, nil
+
+	case deployedVersionsMsg:
+		// Skip a lookup for a skills folder changed in the meantime
+		if msg.skillsFolder == m.skillsFolder {
+			m.deployedVersions = msg.versions
+			m.lastDeploys = msg.lastDeploys
 		}
 		return m, nil
 
 	case deployCompleteMsg:
-		m.currentView = ViewDone
+		m.buildOutput += msg.output
+		m.healthcheck = msg.healthcheck
+		m.deploySteps = msg.steps
+		m.recordEvent(pipeline.ActionDeploy, msg.duration, msg.output, msg.err)
+		m.enterDone()
 		if msg.err != nil {
 			m.errorMsg = msg.err.Error()
 		} else {
 			m.statusMsg = "Skill deployed successfully!"
+			if m.configOnly {
+				m.statusMsg = "Configuration redeployed, binary unchanged"
+			}
+			m.refreshDeployedVersions()
+			if err := m.saveProfile(); err != nil {
+				m.errorMsg = "failed to save profile: " + err.Error()
+			}
 		}
 		return m, nil
 	}
 
+	// Other messages of the inputs, e.g. the clipboard content after Ctrl+V
+	switch m.currentView {
+	case ViewConfig:
+		return m.updateConfigInputs(msg)
+	case ViewDeploy:
+		return m.updateDeployInputs(msg)
+	}
 	return m, nil
 }
 
+// keyScope returns the key scope of the current view, see keys.go
+func (m Model) keyScope() string {
+	switch m.currentView {
+	case ViewSkillList:
+		if m.filtering {
+			return "filter"
+		}
+		return "skills"
+	case ViewConfig:
+		if m.choices != nil {
+			return "choices"
+		}
+		return "config"
+	case ViewDeploy:
+		return "deploy"
+	case ViewFolderPicker:
+		if m.pickerCreating {
+			return "newdir"
+		}
+		return "picker"
+	case ViewConfirm:
+		return "confirm"
+	case ViewOverwrite:
+		return "overwrite"
+	case ViewBuilding:
+		return "building"
+	case ViewDone:
+		return "done"
+	case ViewQuickFix:
+		return "quickfix"
+	case ViewEditManifest:
+		return "edit"
+	case ViewProfile:
+		return "profile"
+	case ViewRemove:
+		return "remove"
+	case ViewDeployed:
+		return "deployed"
+	case ViewSearch:
+		return "search"
+	case ViewBundles:
+		return "bundles"
+	case ViewBundleEdit:
+		return "bundle"
+	case ViewSettings:
+		return "settings"
+	case ViewErrorDetail:
+		return "error"
+	case ViewQueue:
+		return "queue"
+	}
+	return globalScope
+}
+
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch m.currentView {
 	case ViewSkillList:
@@ -223,8 +774,20 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleConfirmView(msg)
 	case ViewOverwrite:
 		return m.handleOverwriteView(msg)
+	case ViewBuilding:
+		return m.handleBuildingView(msg)
 	case ViewDone:
 		return m.handleDoneView(msg)
+	case ViewRemove:
+		return m.handleRemoveView(msg)
+	case ViewDeployed:
+		return m.handleDeployedView(msg)
+	case ViewBundles:
+		return m.handleBundlesView(msg)
+	case ViewErrorDetail:
+		return m.handleErrorDetailView(msg)
+	case ViewQueue:
+		return m.handleQueueView(msg)
 	}
 	return m, nil
 }
@@ -234,21 +797,35 @@ func (m Model) handleSkillListView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch msg.String() {
 	case "q", "esc":
+		// Esc clears an applied filter first
+		if msg.String() == "esc" && m.skillFilter.Value() != "" {
+			m.skillFilter.SetValue("")
+			return m, nil
+		}
 		m.quitting = true
 		return m, tea.Quit
+	case "/":
+		m.filtering = true
+		m.skillFilter.Focus()
+		return m, textinput.Blink
 	case "up", "k":
-		if m.skillCursor > 0 {
-			m.skillCursor--
-		}
+		m.moveSkillCursor(-1)
 	case "down", "j":
-		if m.skillCursor < totalItems-1 {
-			m.skillCursor++
-		}
+		m.moveSkillCursor(1)
 	case "enter":
+		if !m.skillMatches(m.skillCursor) {
+			return m, nil
+		}
 		if m.skillCursor < len(m.manifests) {
 			// Valid skill selected
 			m.selectedSkill = m.manifests[m.skillCursor]
 			m.selectedError = nil
+
+			// Offer saved profiles before configuring
+			if m.loadProfiles() {
+				m.currentView = ViewProfile
+				return m, nil
+			}
 			m.currentView = ViewConfig
 			m.setupInputsFromManifest()
 			return m, textinput.Blink
@@ -257,113 +834,1850 @@ func (m Model) handleSkillListView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			errorIdx := m.skillCursor - len(m.manifests)
 			m.selectedError = &m.skillErrors[errorIdx]
 			m.selectedSkill = nil
-			m.errorMsg = m.selectedError.Error.Error()
+			m.errorMsg = ""
+			m.statusMsg = ""
+			m.editorErr = ""
+			m.currentView = ViewErrorDetail
 		}
-	}
-	return m, nil
-}
-
-func (m Model) handleConfirmView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc", "n":
-		m.currentView = ViewDeploy
-		m.deployFocus = 0
-		for i := range m.deployInputs {
-			m.deployInputs[i].Blur()
-		}
-		if len(m.deployInputs) > 0 {
-			m.deployInputs[0].Focus()
+	case "tab":
+		// Switch to the Deployed tab
+		m.statusMsg = ""
+		m.loadDeployed()
+		m.currentView = ViewDeployed
+	case "e":
+		// Edit the skill.yaml in a form
+		if m.skillCursor < len(m.manifests) && m.skillMatches(m.skillCursor) {
+			m.statusMsg = ""
+			m.errorMsg = ""
+			m.setupEditManifest(m.manifests[m.skillCursor])
+			return m, textinput.Blink
 		}
-		return m, textinput.Blink
-	case "enter", "y":
-		// Check if skill already exists
-		if m.skillExists() {
-			m.currentView = ViewOverwrite
-			return m, nil
+	case "x":
+		// Remove the deployed skill from the skills folder
+		if m.skillCursor < len(m.manifests) && m.skillMatches(m.skillCursor) && m.skillsFolder != "" {
+			m.statusMsg = ""
+			m.setupRemove(filepath.Join(m.skillsFolder, m.manifests[m.skillCursor].Name), ViewSkillList)
 		}
-		// Start build
-		m.currentView = ViewBuilding
-		m.building = true
+	case "b":
+		// Compose bundles of skills
+		m.statusMsg = ""
 		m.errorMsg = ""
+		m.loadBundles()
+		m.currentView = ViewBundles
+	case "s":
+		// Edit the global settings
 		m.statusMsg = ""
-		return m, m.startBuild()
+		m.errorMsg = ""
+		if err := m.setupSettings(); err != nil {
+			m.errorMsg = err.Error()
+			return m, nil
+		}
+		return m, textinput.Blink
 	}
 	return m, nil
 }
 
-func (m Model) handleOverwriteView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+// newSkillFilter creates the filter input of the skill list
+func newSkillFilter() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "/ "
+	input.Placeholder = "filter skills"
+	input.CharLimit = 50
+	input.Width = 30
+	return input
+}
+
+// handleSkillFilter edits the skill list filter. Enter keeps the filter and
+// returns to the list, Esc clears it.
+func (m Model) handleSkillFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "esc", "n":
-		// Go back to confirm view
-		m.currentView = ViewConfirm
+	case "esc":
+		m.skillFilter.SetValue("")
+		m.filtering = false
+		m.skillFilter.Blur()
+		return m, nil
+	case "enter":
+		m.filtering = false
+		m.skillFilter.Blur()
+		return m, nil
+	case "up":
+		m.moveSkillCursor(-1)
+		return m, nil
+	case "down":
+		m.moveSkillCursor(1)
 		return m, nil
-	case "y":
-		// Proceed with build (overwrite)
-		m.currentView = ViewBuilding
-		m.building = true
-		m.errorMsg = ""
-		m.statusMsg = ""
-		return m, m.startBuild()
 	}
-	return m, nil
+
+	previous := m.skillFilter.Value()
+	var cmd tea.Cmd
+	m.skillFilter, cmd = m.skillFilter.Update(msg)
+	if m.skillFilter.Value() != previous {
+		m.selectBestSkill()
+	}
+	return m, cmd
 }
 
-// skillExists checks if the deploy path already contains a skill
-func (m Model) skillExists() bool {
-	deployPath := m.getDeployPath()
-	if deployPath == "" {
-		return false
+// skillMatches reports whether item i of the skill list (manifests, then
+// skill errors) matches the filter
+func (m Model) skillMatches(i int) bool {
+	return m.skillScore(i) >= 0
+}
+
+// skillScore rates how well item i of the skill list matches the filter,
+// -1 if it does not match. Matches in the name rank above the description.
+func (m Model) skillScore(i int) int {
+	pattern := strings.TrimSpace(m.skillFilter.Value())
+	if pattern == "" {
+		return 0
+	}
+	if i >= len(m.manifests) {
+		return fuzzyScore(pattern, m.skillErrors[i-len(m.manifests)].Name)
+	}
+	manifest := m.manifests[i]
+	score := fuzzyScore(pattern, manifest.Name)
+	if score >= 0 {
+		score += 100
 	}
+	return max(score, fuzzyScore(pattern, manifest.Description))
+}
 
-	// Check if the bin directory with binary exists
-	binaryName := m.selectedSkill.Build.Binary
-	if binaryName == "" {
-		binaryName = m.selectedSkill.Name
+// selectBestSkill moves the cursor to the best match of the filter
+func (m *Model) selectBestSkill() {
+	best, bestScore := m.skillCursor, -1
+	for i := 0; i < len(m.manifests)+len(m.skillErrors); i++ {
+		if score := m.skillScore(i); score > bestScore {
+			best, bestScore = i, score
+		}
 	}
-	binaryPath := filepath.Join(deployPath, "bin", binaryName)
+	m.skillCursor = best
+}
 
-	_, err := os.Stat(binaryPath)
-	return err == nil
+// moveSkillCursor moves the cursor to the previous (-1) or next (1) skill
+// matching the filter
+func (m *Model) moveSkillCursor(delta int) {
+	for i := m.skillCursor + delta; i >= 0 && i < len(m.manifests)+len(m.skillErrors); i += delta {
+		if m.skillMatches(i) {
+			m.skillCursor = i
+			return
+		}
+	}
 }
 
-func (m Model) handleDoneView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "enter", "q", "esc":
-		m.quitting = true
-		return m, tea.Quit
-	case "r":
-		// Restart - go back to skill list
-		m.currentView = ViewSkillList
-		m.errorMsg = ""
-		m.statusMsg = ""
-		m.buildOutput = ""
-		return m, nil
+// fuzzyScore matches the characters of pattern in order within text, ignoring
+// case, and returns -1 if they are not all found. Consecutive characters and
+// characters at the start of a word score higher.
+func fuzzyScore(pattern, text string) int {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+	score, last, j := 0, -2, 0
+	for i := 0; i < len(t) && j < len(p); i++ {
+		if t[i] != p[j] {
+			continue
+		}
+		score++
+		if i == last+1 {
+			score += 3
+		}
+		if i == 0 || strings.ContainsRune(" -_/.", t[i-1]) {
+			score += 2
+		}
+		last = i
+		j++
 	}
-	return m, nil
+	if j < len(p) {
+		return -1
+	}
+	return score
 }
 
-// setupInputsFromManifest creates input fields for skill environment variables
-func (m *Model) setupInputsFromManifest() {
-	if m.selectedSkill == nil {
+// loadDeployed scans the skills folder for the Deployed view
+func (m *Model) loadDeployed() {
+	m.deployedSkills = nil
+	m.errorMsg = ""
+	if m.skillsFolder == "" {
 		return
 	}
+	deployed, err := pipeline.ScanDeployed(m.skillsFolder, m.manifests)
+	if err != nil {
+		m.errorMsg = err.Error()
+		return
+	}
+	m.deployedSkills = deployed
+	if m.deployedCursor >= len(deployed) {
+		m.deployedCursor = max(len(deployed)-1, 0)
+	}
+}
 
-	// Create inputs for skill variables only
-	numInputs := len(m.selectedSkill.Variables)
-	m.configInputs = make([]textinput.Model, numInputs)
-	m.configLabels = make([]string, numInputs)
-
-	for i, v := range m.selectedSkill.Variables {
+func (m Model) handleDeployedView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		m.quitting = true
+		return m, tea.Quit
+	case "tab", "esc":
+		m.errorMsg = ""
+		m.currentView = ViewSkillList
+	case "up", "k":
+		if m.deployedCursor > 0 {
+			m.deployedCursor--
+		}
+	case "down", "j":
+		if m.deployedCursor < len(m.deployedSkills)-1 {
+			m.deployedCursor++
+		}
+	case "x":
+		if m.deployedCursor < len(m.deployedSkills) {
+			m.statusMsg = ""
+			m.setupRemove(m.deployedSkills[m.deployedCursor].Path, ViewDeployed)
+		}
+	case "e":
+		// Edit the configuration of the deployed skill
+		if m.deployedCursor < len(m.deployedSkills) {
+			if err := m.editDeployed(m.deployedSkills[m.deployedCursor]); err != nil {
+				m.errorMsg = err.Error()
+				return m, nil
+			}
+			return m, textinput.Blink
+		}
+	case "/":
+		if len(m.deployedSkills) > 0 {
+			m.openSearch()
+			return m, textinput.Blink
+		}
+	}
+	return m, nil
+}
+
+// editDeployed opens the Config view with the values deployed in the .env of
+// a skill. The saved profile deploying to the same folder supplies the values
+// the .env does not keep, e.g. variables set from a command.
+func (m *Model) editDeployed(d pipeline.DeployedSkill) error {
+	if !d.SourceExists {
+		return fmt.Errorf("the source of %s is missing, cannot redeploy it", d.Name)
+	}
+	var manifest *skill.Manifest
+	for _, mf := range m.manifests {
+		if mf.Name == d.Name {
+			manifest = mf
+		}
+	}
+	if manifest == nil {
+		return fmt.Errorf("%s has manifest errors, fix them first", d.Name)
+	}
+	values, err := pipeline.DeployedValues(manifest, d.Path)
+	if err != nil {
+		return err
+	}
+
+	m.selectedSkill = manifest
+	m.selectedError = nil
+	m.configValues = make(map[string]string)
+	m.loadProfiles()
+	name, err := pipeline.DeployedProfile(manifest, d.Path, m.skillsFolder)
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		if err := m.applyProfile(name); err != nil {
+			return err
+		}
+	}
+	maps.Copy(m.configValues, values)
+	m.skillFolderName = d.Folder
+	m.errorMsg = ""
+	m.statusMsg = ""
+	m.currentView = ViewConfig
+	m.setupInputsFromManifest()
+	return nil
+}
+
+// openSearch reads the docs of the deployed skills and opens the Search view
+func (m *Model) openSearch() {
+	index, err := pipeline.LoadSearchIndex(m.skillsFolder)
+	if err != nil {
+		m.errorMsg = err.Error()
+		return
+	}
+	m.searchIndex = index
+	m.searchResults = nil
+	m.searchCursor = 0
+	m.searchInput = textinput.New()
+	m.searchInput.Placeholder = "e.g. labels"
+	m.searchInput.CharLimit = 100
+	m.searchInput.Width = m.fitWidth(shortInputWidth, formIndent)
+	m.searchInput.Focus()
+	m.statusMsg = ""
+	m.currentView = ViewSearch
+}
+
+// handleSearchView searches while typing; Enter selects the skill of the
+// selected match in the Deployed view
+func (m Model) handleSearchView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.currentView = ViewDeployed
+		return m, nil
+	case "up", "ctrl+p":
+		if m.searchCursor > 0 {
+			m.searchCursor--
+		}
+		return m, nil
+	case "down", "ctrl+n":
+		if m.searchCursor < len(m.searchResults)-1 {
+			m.searchCursor++
+		}
+		return m, nil
+	case "enter":
+		if m.searchCursor < len(m.searchResults) {
+			folder := m.searchResults[m.searchCursor].Folder
+			for i, d := range m.deployedSkills {
+				if d.Folder == folder {
+					m.deployedCursor = i
+				}
+			}
+			m.currentView = ViewDeployed
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	m.searchResults = pipeline.Search(m.searchIndex, m.searchInput.Value())
+	m.searchCursor = min(m.searchCursor, max(len(m.searchResults)-1, 0))
+	return m, cmd
+}
+
+// setupRemove lists the deployed files at deployPath and opens the Remove view
+func (m *Model) setupRemove(deployPath string, returnTo View) {
+	paths, err := pipeline.RemovalPaths(deployPath)
+	if err != nil {
+		m.errorMsg = err.Error()
+		return
+	}
+	m.errorMsg = ""
+	m.removePath = deployPath
+	m.removePaths = paths
+	m.removeReturn = returnTo
+	m.currentView = ViewRemove
+}
+
+func (m Model) handleRemoveView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "n":
+		m.currentView = m.removeReturn
+	case "y":
+		err := pipeline.Remove(m.removePath, m.removePaths)
+		m.refreshDeployedVersions()
+		if m.removeReturn == ViewDeployed {
+			m.loadDeployed()
+		}
+		if err != nil {
+			m.errorMsg = err.Error()
+		} else {
+			m.statusMsg = "Removed " + m.removePath
+		}
+		m.currentView = m.removeReturn
+	}
+	return m, nil
+}
+
+// loadProfiles reads the saved profiles of the selected skill
+func (m *Model) loadProfiles() bool {
+	m.profiles = nil
+	m.profileNames = nil
+	m.profileCursor = 0
+	m.profileName = ""
+
+	profiles, err := config.LoadProfiles(m.selectedSkill.Name)
+	if err != nil {
+		m.errorMsg = err.Error()
+		return false
+	}
+	m.profiles = profiles
+	m.profileNames = config.ProfileNames(profiles)
+	return len(m.profileNames) > 0
+}
+
+func (m Model) handleProfileView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Last entry creates a new profile
+	totalItems := len(m.profileNames) + 1
+
+	switch msg.String() {
+	case "esc":
+		m.currentView = ViewSkillList
+		m.errorMsg = ""
+		return m, nil
+	case "up", "k":
+		if m.profileCursor > 0 {
+			m.profileCursor--
+		}
+	case "down", "j":
+		if m.profileCursor < totalItems-1 {
+			m.profileCursor++
+		}
+	case "enter":
+		if m.profileCursor < len(m.profileNames) {
+			if err := m.applyProfile(m.profileNames[m.profileCursor]); err != nil {
+				m.errorMsg = err.Error()
+				return m, nil
+			}
+		} else {
+			// New profile: start with empty values
+			m.profileName = ""
+			m.configValues = make(map[string]string)
+		}
+		m.errorMsg = ""
+		m.currentView = ViewConfig
+		m.setupInputsFromManifest()
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
+// applyProfile loads the values and deploy target of a saved profile
+func (m *Model) applyProfile(name string) error {
+	profile := m.profiles[name]
+	values, err := pipeline.ProfileValues(profile)
+	if err != nil {
+		return err
+	}
+
+	m.profileName = name
+	m.configValues = values
+	if profile.SkillsFolder != "" {
+		m.skillsFolder = profile.SkillsFolder
+	}
+	m.skillFolderName = profile.SkillFolderName
+	return nil
+}
+
+// saveProfile stores the deployed configuration under the current profile name
+func (m Model) saveProfile() error {
+	profile := &config.Profile{
+		Values:          m.configValues,
+		SkillsFolder:    m.skillsFolder,
+		SkillFolderName: m.skillFolderName,
+	}
+	return pipeline.SaveProfile(m.selectedSkill, m.profileName, profile, m.useKeychain())
+}
+
+// loadBundles reads bundle.yaml for the Bundles view
+func (m *Model) loadBundles() {
+	bundles, err := skill.LoadBundles(m.projectRoot)
+	if err != nil {
+		m.errorMsg = err.Error()
+	}
+	m.bundles = bundles
+	if m.bundleCursor > len(bundles) {
+		m.bundleCursor = len(bundles)
+	}
+}
+
+func (m Model) handleBundlesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Last entry creates a new bundle
+	totalItems := len(m.bundles) + 1
+
+	switch msg.String() {
+	case "q":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.errorMsg = ""
+		m.currentView = ViewSkillList
+	case "up", "k":
+		if m.bundleCursor > 0 {
+			m.bundleCursor--
+		}
+	case "down", "j":
+		if m.bundleCursor < totalItems-1 {
+			m.bundleCursor++
+		}
+	case "enter":
+		bundle := skill.Bundle{Target: m.skillsFolder}
+		if m.bundleCursor < len(m.bundles) {
+			bundle = m.bundles[m.bundleCursor]
+		}
+		m.errorMsg = ""
+		m.setupBundleEdit(bundle)
+		return m, textinput.Blink
+	case "d":
+		if m.bundleCursor < len(m.bundles) {
+			return m, m.startQueue(m.bundles[m.bundleCursor])
+		}
+	}
+	return m, nil
+}
+
+// startQueue deploys the skills of a bundle one after another with their
+// default profiles, after the skills they depend on. Skills that cannot be
+// deployed as configured fail right away, the others are queued.
+func (m *Model) startQueue(bundle skill.Bundle) tea.Cmd {
+	names := skill.DependencyOrder(m.manifests, bundle.Skills)
+	profiles := make(map[string]*config.Profile, len(names))
+	for _, name := range names {
+		if profile, err := config.LoadProfile(name, config.DefaultProfile); err == nil {
+			profiles[name] = profile
+		}
+	}
+
+	// Shared variables entered for one skill of the bundle apply to all
+	shared := make(map[string]string)
+	for _, name := range names {
+		if profile, ok := profiles[name]; ok {
+			values, err := pipeline.ProfileValues(profile)
+			if err != nil {
+				continue
+			}
+			for _, v := range bundle.Shared {
+				if shared[v] == "" {
+					shared[v] = values[v]
+				}
+			}
+		}
+	}
+
+	m.queue = make([]queueItem, 0, len(names))
+	m.queueBundle = bundle.Name
+	for _, name := range names {
+		item := queueItem{name: name, state: queuePending}
+		opts, err := m.queueOptions(name, bundle, profiles[name], shared)
+		if err != nil {
+			item.state = queueFailed
+			item.err = err
+		}
+		item.opts = opts
+		m.queue = append(m.queue, item)
+	}
+
+	m.currentView = ViewQueue
+	m.errorMsg = ""
+	m.statusMsg = ""
+	m.building = true
+	return tea.Batch(m.spinner.Tick, m.runNextQueueItem())
+}
+
+// queueOptions returns the deploy options of a bundle skill from its
+// profile, with the target folder of the bundle taking precedence
+func (m Model) queueOptions(name string, bundle skill.Bundle, profile *config.Profile, shared map[string]string) (pipeline.DeployOptions, error) {
+	var manifest *skill.Manifest
+	for _, mf := range m.manifests {
+		if mf.Name == name {
+			manifest = mf
+		}
+	}
+	if manifest == nil {
+		return pipeline.DeployOptions{}, fmt.Errorf("skill not found")
+	}
+	if profile == nil {
+		return pipeline.DeployOptions{}, fmt.Errorf("no %s profile saved, deploy the skill on its own first", config.DefaultProfile)
+	}
+	values, err := pipeline.ProfileValues(profile)
+	if err != nil {
+		return pipeline.DeployOptions{}, err
+	}
+	for _, v := range bundle.Shared {
+		if values[v] == "" && shared[v] != "" {
+			values[v] = shared[v]
+		}
+	}
+	for _, v := range manifest.Variables {
+		if v.Required && !v.FromCommand() && values[v.Name] == "" {
+			return pipeline.DeployOptions{}, fmt.Errorf("no value for required variable %s", v.Name)
+		}
+	}
+
+	folder := bundle.Target
+	if folder == "" {
+		folder = profile.SkillsFolder
+	}
+	if folder == "" {
+		folder = m.skillsFolder
+	}
+	if folder == "" {
+		return pipeline.DeployOptions{}, fmt.Errorf("no skills folder configured")
+	}
+	folderName := profile.SkillFolderName
+	if folderName == "" {
+		folderName = manifest.Name
+	}
+	deployPath := filepath.Join(config.ExpandPath(folder), folderName)
+	if lock, err := pipeline.ReadLock(deployPath); err == nil && pipeline.DocsEdited(deployPath, lock) {
+		return pipeline.DeployOptions{}, fmt.Errorf("SKILL.md was edited by hand, deploy the skill on its own to keep or merge it")
+	}
+
+	return pipeline.DeployOptions{
+		Manifest:    manifest,
+		DeployPath:  deployPath,
+		Values:      values,
+		UseKeychain: m.useKeychain(),
+		EncryptEnv:  m.encryptEnv(),
+		Backup:      m.settings.KeepBackupsDays > 0,
+	}, nil
+}
+
+// runNextQueueItem starts the next pending skill of the queue, or ends the
+// queue when none is left. Skills whose dependencies were not deployed fail.
+func (m *Model) runNextQueueItem() tea.Cmd {
+	for i := range m.queue {
+		if m.queue[i].state != queuePending {
+			continue
+		}
+		if dep := m.undeployedDependency(i); dep != "" {
+			m.queue[i].state = queueFailed
+			m.queue[i].err = fmt.Errorf("dependency %s was not deployed", dep)
+			continue
+		}
+		m.queue[i].state = queueBuilding
+		m.queue[i].started = time.Now()
+		return m.runQueueItem(i)
+	}
+
+	m.building = false
+	m.refreshDeployedVersions()
+	// Prune the builds archived and the backups made by the queue
+	pipeline.Clean(m.projectRoot, pipeline.RetentionFrom(m.settings), false)
+	return nil
+}
+
+// undeployedDependency returns a skill the queue item depends on that failed
+// or was canceled earlier in the queue, empty if there is none
+func (m Model) undeployedDependency(index int) string {
+	manifest := m.queue[index].opts.Manifest
+	if manifest == nil {
+		return ""
+	}
+	for _, item := range m.queue[:index] {
+		if slices.Contains(manifest.Dependencies.Skills, item.name) && item.state != queueDone {
+			return item.name
+		}
+	}
+	return ""
+}
+
+// queueRunning reports whether a skill of the queue is being deployed
+func (m Model) queueRunning() bool {
+	for _, item := range m.queue {
+		if item.state == queueBuilding || item.state == queueDeploying {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Model) handleQueueView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "x":
+		// The running skill finishes, the remaining ones are skipped
+		for i := range m.queue {
+			if m.queue[i].state == queuePending {
+				m.queue[i].state = queueCanceled
+			}
+		}
+	case "esc":
+		if !m.queueRunning() {
+			m.loadBundles()
+			m.currentView = ViewBundles
+		}
+	case "q":
+		if !m.queueRunning() {
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// bundleInputLabels are the labels of the bundle composer inputs
+var bundleInputLabels = []string{"Name", "Description", "Target folder"}
+
+// setupBundleEdit opens the bundle composer for a bundle
+func (m *Model) setupBundleEdit(bundle skill.Bundle) {
+	m.bundleEdit = bundle
+	m.bundleEdit.Skills = slices.Clone(bundle.Skills)
+	m.bundleEdit.Shared = slices.Clone(bundle.Shared)
+	m.bundleOrig = bundle.Name
+
+	m.bundleInputs = make([]textinput.Model, len(bundleInputLabels))
+	for i, value := range []string{bundle.Name, bundle.Description, bundle.Target} {
+		input := textinput.New()
+		input.CharLimit = 200
+		input.Width = m.fitWidth(inputWidth, bundleIndent)
+		input.SetValue(value)
+		m.bundleInputs[i] = input
+	}
+	m.bundleInputs[1].Placeholder = "Optional"
+	m.bundleInputs[2].Placeholder = "~/.claude/skills"
+	m.bundleFocus = 0
+	m.bundleInputs[0].Focus()
+	m.currentView = ViewBundleEdit
+}
+
+// bundleVariables returns the variables offered for sharing in the composed bundle
+func (m Model) bundleVariables() []skill.SharedVariable {
+	return skill.SharedVariables(m.manifests, m.bundleEdit.Skills)
+}
+
+// bundleItems is the number of focusable rows of the bundle composer
+func (m Model) bundleItems() int {
+	return len(m.bundleInputs) + len(m.manifests) + len(m.bundleVariables())
+}
+
+func (m Model) handleBundleEditView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.errorMsg = ""
+		m.currentView = ViewBundles
+		return m, nil
+	case "tab", "down":
+		m.focusBundleItem((m.bundleFocus + 1) % m.bundleItems())
+		return m, textinput.Blink
+	case "shift+tab", "up":
+		m.focusBundleItem((m.bundleFocus - 1 + m.bundleItems()) % m.bundleItems())
+		return m, textinput.Blink
+	case "ctrl+s":
+		return m.saveBundle()
+	case " ", "enter":
+		if m.bundleFocus >= len(m.bundleInputs) {
+			m.toggleBundleItem()
+			return m, nil
+		}
+		if msg.String() == "enter" {
+			m.focusBundleItem((m.bundleFocus + 1) % m.bundleItems())
+			return m, textinput.Blink
+		}
+	}
+
+	if m.bundleFocus < len(m.bundleInputs) {
+		var cmd tea.Cmd
+		m.bundleInputs[m.bundleFocus], cmd = m.bundleInputs[m.bundleFocus].Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// focusBundleItem moves the focus of the bundle composer to row i
+func (m *Model) focusBundleItem(i int) {
+	for j := range m.bundleInputs {
+		m.bundleInputs[j].Blur()
+	}
+	m.bundleFocus = i
+	if i < len(m.bundleInputs) {
+		m.bundleInputs[i].Focus()
+	}
+}
+
+// toggleBundleItem selects or deselects the focused skill or shared variable
+func (m *Model) toggleBundleItem() {
+	row := m.bundleFocus - len(m.bundleInputs)
+	if row < len(m.manifests) {
+		name := m.manifests[row].Name
+		if i := slices.Index(m.bundleEdit.Skills, name); i >= 0 {
+			m.bundleEdit.Skills = slices.Delete(m.bundleEdit.Skills, i, i+1)
+		} else {
+			m.bundleEdit.Skills = append(m.bundleEdit.Skills, name)
+		}
+
+		// Drop shared variables no selected skill declares anymore
+		vars := m.bundleVariables()
+		m.bundleEdit.Shared = slices.DeleteFunc(m.bundleEdit.Shared, func(shared string) bool {
+			return !slices.ContainsFunc(vars, func(v skill.SharedVariable) bool { return v.Name == shared })
+		})
+		if m.bundleFocus >= m.bundleItems() {
+			m.focusBundleItem(m.bundleItems() - 1)
+		}
+		return
+	}
+
+	name := m.bundleVariables()[row-len(m.manifests)].Name
+	if i := slices.Index(m.bundleEdit.Shared, name); i >= 0 {
+		m.bundleEdit.Shared = slices.Delete(m.bundleEdit.Shared, i, i+1)
+	} else {
+		m.bundleEdit.Shared = append(m.bundleEdit.Shared, name)
+	}
+}
+
+// saveBundle writes the composed bundle to bundle.yaml
+func (m Model) saveBundle() (tea.Model, tea.Cmd) {
+	bundle := m.bundleEdit
+	bundle.Name = strings.TrimSpace(m.bundleInputs[0].Value())
+	bundle.Description = strings.TrimSpace(m.bundleInputs[1].Value())
+	bundle.Target = strings.TrimSpace(m.bundleInputs[2].Value())
+
+	// Keep skills in the order of the skill list
+	var skills []string
+	for _, manifest := range m.manifests {
+		if slices.Contains(bundle.Skills, manifest.Name) {
+			skills = append(skills, manifest.Name)
+		}
+	}
+	bundle.Skills = skills
+
+	if err := skill.SaveBundle(m.projectRoot, bundle, m.bundleOrig); err != nil {
+		m.errorMsg = err.Error()
+		return m, nil
+	}
+	m.errorMsg = ""
+	m.loadBundles()
+	m.currentView = ViewBundles
+	m.statusMsg = "Saved bundle " + bundle.Name + " to " + skill.BundleFile
+	return m, nil
+}
+
+// settingsInputLabels are the labels of the Settings view inputs
+var settingsInputLabels = []string{"Skills folder", "Parallel builds", "Keep builds", "Keep backups (days)"}
+
+// settingsToggles is the number of choice rows below the inputs of the
+// Settings view: theme, tests before deploy and keep dist/
+const settingsToggles = 3
+
+// setupSettings opens the Settings view with the values of the global
+// config file, without environment overrides
+func (m *Model) setupSettings() error {
+	saved, err := config.ReadSettings()
+	if err != nil {
+		return err
+	}
+	m.settingsEdit = *saved
+
+	parallel := ""
+	if saved.ParallelBuilds > 0 {
+		parallel = strconv.Itoa(saved.ParallelBuilds)
+	}
+	keepBuilds, keepBackups := "", ""
+	if saved.KeepBuilds > 0 {
+		keepBuilds = strconv.Itoa(saved.KeepBuilds)
+	}
+	if saved.KeepBackupsDays > 0 {
+		keepBackups = strconv.Itoa(saved.KeepBackupsDays)
+	}
+	m.settingsInputs = make([]textinput.Model, len(settingsInputLabels))
+	for i, value := range []string{saved.SkillsFolder, parallel, keepBuilds, keepBackups} {
+		input := textinput.New()
+		input.CharLimit = 500
+		input.Width = m.fitWidth(inputWidth, settingsIndent)
+		input.SetValue(value)
+		m.settingsInputs[i] = input
+	}
+	m.settingsInputs[0].Placeholder = "~/.claude/skills"
+	m.settingsInputs[1].Placeholder = "Go default"
+	m.settingsInputs[2].Placeholder = "none"
+	m.settingsInputs[3].Placeholder = "no backups"
+	for i := 1; i < len(m.settingsInputs); i++ {
+		m.settingsInputs[i].CharLimit = 3
+		m.settingsInputs[i].Width = 10
+	}
+	m.focusSettingsItem(0)
+	m.currentView = ViewSettings
+	return nil
+}
+
+func (m Model) handleSettingsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	items := len(m.settingsInputs) + settingsToggles
+	switch msg.String() {
+	case "esc":
+		m.errorMsg = ""
+		m.currentView = ViewSkillList
+		return m, nil
+	case "tab", "down":
+		m.focusSettingsItem((m.settingsFocus + 1) % items)
+		return m, textinput.Blink
+	case "shift+tab", "up":
+		m.focusSettingsItem((m.settingsFocus - 1 + items) % items)
+		return m, textinput.Blink
+	case "ctrl+s":
+		return m.saveSettings()
+	case " ", "enter", "left", "right":
+		if m.settingsFocus >= len(m.settingsInputs) {
+			m.toggleSetting()
+			return m, nil
+		}
+		if msg.String() == "enter" {
+			m.focusSettingsItem(m.settingsFocus + 1)
+			return m, textinput.Blink
+		}
+	}
+
+	if m.settingsFocus < len(m.settingsInputs) {
+		var cmd tea.Cmd
+		m.settingsInputs[m.settingsFocus], cmd = m.settingsInputs[m.settingsFocus].Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// focusSettingsItem moves the focus of the Settings view to row i
+func (m *Model) focusSettingsItem(i int) {
+	for j := range m.settingsInputs {
+		m.settingsInputs[j].Blur()
+	}
+	m.settingsFocus = i
+	if i < len(m.settingsInputs) {
+		m.settingsInputs[i].Focus()
+	}
+}
+
+// toggleSetting switches the focused theme or toggle of the Settings view
+func (m *Model) toggleSetting() {
+	switch m.settingsFocus - len(m.settingsInputs) {
+	case 0:
+		i := slices.Index(config.Themes, m.settingsEdit.Theme)
+		m.settingsEdit.Theme = config.Themes[(i+1)%len(config.Themes)]
+	case 1:
+		m.settingsEdit.TestBeforeDeploy = !m.settingsEdit.TestBeforeDeploy
+	case 2:
+		m.settingsEdit.KeepDist = !m.settingsEdit.KeepDist
+	}
+}
+
+// saveSettings writes the Settings view to the global config file and
+// applies the new values
+func (m Model) saveSettings() (tea.Model, tea.Cmd) {
+	edit := m.settingsEdit
+	edit.SkillsFolder = strings.TrimSpace(m.settingsInputs[0].Value())
+	for i, field := range []*int{&edit.ParallelBuilds, &edit.KeepBuilds, &edit.KeepBackupsDays} {
+		*field = 0
+		if value := strings.TrimSpace(m.settingsInputs[i+1].Value()); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				m.errorMsg = fmt.Sprintf("%s must be a number, got %q", strings.ToLower(settingsInputLabels[i+1]), value)
+				return m, nil
+			}
+			*field = n
+		}
+	}
+
+	if err := config.SaveSettings(&edit); err != nil {
+		m.errorMsg = "save failed: " + err.Error()
+		return m, nil
+	}
+	settings, err := config.LoadSettings()
+	if err != nil {
+		m.errorMsg = err.Error()
+		return m, nil
+	}
+	m.settings = settings
+	applyTheme(settings.Theme)
+	m.spinner.Style = inputLabelStyle
+
+	// The default skills folder applies if none was saved yet
+	if m.skillsFolder == "" && settings.SkillsFolder != "" {
+		m.skillsFolder = settings.SkillsFolder
+		m.refreshDeployedVersions()
+	}
+
+	path, _ := config.SettingsPath()
+	m.errorMsg = ""
+	m.currentView = ViewSkillList
+	m.statusMsg = "Saved settings to " + config.ContractPath(path)
+	return m, nil
+}
+
+// setupQuickFix collects the fixable issues of the selected error skill
+func (m *Model) setupQuickFix() bool {
+	m.fixIssues = nil
+	m.fixIndex = 0
+	for _, issue := range m.selectedError.Issues {
+		if issue.Fixable {
+			m.fixIssues = append(m.fixIssues, issue)
+		}
+	}
+	if len(m.fixIssues) == 0 {
+		return false
+	}
+	m.setupFixInput()
+	return true
+}
+
+// setupFixInput prepares the input for the current quick fix issue
+func (m *Model) setupFixInput() {
+	issue := m.fixIssues[m.fixIndex]
+	input := textinput.New()
+	input.Placeholder = issue.Hint
+	input.SetValue(issue.Hint)
+	input.CharLimit = 200
+	input.Width = m.fitWidth(inputWidth, formIndent)
+	input.Focus()
+	m.fixInput = input
+}
+
+func (m Model) handleQuickFixView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.currentView = ViewErrorDetail
+		m.errorMsg = ""
+		return m, nil
+	case "tab":
+		// Skip this issue
+		return m.nextQuickFix()
+	case "enter":
+		issue := m.fixIssues[m.fixIndex]
+		manifestPath := filepath.Join(m.selectedError.Path, "skill.yaml")
+		if err := skill.SetField(manifestPath, issue.Field, m.fixInput.Value()); err != nil {
+			m.errorMsg = "fix failed: " + err.Error()
+			return m, nil
+		}
+		stats.Record(stats.FeatureQuickFix)
+		m.errorMsg = ""
+		return m.nextQuickFix()
+	}
+
+	var cmd tea.Cmd
+	m.fixInput, cmd = m.fixInput.Update(msg)
+	return m, cmd
+}
+
+// nextQuickFix advances to the next issue or re-runs discovery when done
+func (m Model) nextQuickFix() (tea.Model, tea.Cmd) {
+	m.fixIndex++
+	if m.fixIndex < len(m.fixIssues) {
+		m.setupFixInput()
+		return m, textinput.Blink
+	}
+
+	name := m.selectedError.Name
+	m.reloadErrorSkill()
+	if m.currentView == ViewSkillList && m.errorMsg == "" {
+		m.statusMsg = "Updated skill.yaml of " + name
+	}
+	return m, nil
+}
+
+func (m Model) handleErrorDetailView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.currentView = ViewSkillList
+		m.editorErr = ""
+	case "q":
+		m.quitting = true
+		return m, tea.Quit
+	case "f", "enter":
+		// Guided fix for the fixable issues
+		if m.setupQuickFix() {
+			m.statusMsg = ""
+			m.currentView = ViewQuickFix
+			return m, textinput.Blink
+		}
+	case "o":
+		// Open skill.yaml at the error in $EDITOR; discovery re-runs when it exits
+		cmd := pipeline.EditorCommand(m.selectedError.File(), max(m.selectedError.Line(), 1))
+		return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return editorClosedMsg{err: err}
+		})
+	case "r":
+		// Re-run discovery after editing skill.yaml outside the TUI
+		m.reloadErrorSkill()
+	}
+	return m, nil
+}
+
+// reloadErrorSkill re-runs discovery and selects the skill of the error
+// detail view again. The view stays open while the skill still fails to
+// load; otherwise the skill list shows the skill.
+func (m *Model) reloadErrorSkill() {
+	name, path := m.selectedError.Name, m.selectedError.Path
+	m.errorMsg = ""
+	m.statusMsg = ""
+	m.rediscoverSkills()
+	if m.errorMsg != "" {
+		return
+	}
+	for i := range m.skillErrors {
+		if m.skillErrors[i].Path == path {
+			m.selectedError = &m.skillErrors[i]
+			m.skillCursor = len(m.manifests) + i
+			m.currentView = ViewErrorDetail
+			m.statusMsg = name + " still has errors after reloading"
+			return
+		}
+	}
+	for i, manifest := range m.manifests {
+		if manifest.Path == path {
+			m.skillCursor = i
+		}
+	}
+	m.currentView = ViewSkillList
+	m.statusMsg = name + " loads now"
+}
+
+// editField is an input of the manifest editor. variable is the index into
+// edit.Variables for variable columns, -1 for skill and build fields.
+type editField struct {
+	key      string
+	label    string
+	variable int
+	input    textinput.Model
+}
+
+// editSkillFields is the number of skill fields (name, description, version)
+// before the variable columns in the editor
+const editSkillFields = 3
+
+// editVariableColumns are the variable fields shown as table columns
+var editVariableColumns = []struct {
+	key   string
+	label string
+	width int
+}{
+	{"name", "Name", 26},
+	{"label", "Label", 18},
+	{"type", "Type", 9},
+	{"required", "Req.", 6},
+	{"default", "Default", 14},
+}
+
+// setupEditManifest opens the manifest editor for a skill
+func (m *Model) setupEditManifest(manifest *skill.Manifest) {
+	m.editSkill = manifest
+	m.edit = skill.NewManifestEdit(manifest)
+	m.editFocus = 0
+	m.setupEditFields()
+	m.currentView = ViewEditManifest
+}
+
+// setupEditFields creates the editor inputs from m.edit
+func (m *Model) setupEditFields() {
+	e := m.edit
+	newInput := func(value string, width int) textinput.Model {
+		input := textinput.New()
+		input.Prompt = ""
+		input.CharLimit = 500
+		input.Width = width
+		input.SetValue(value)
+		return input
+	}
+
+	m.editFields = []editField{
+		{key: "name", label: "Name", variable: -1, input: newInput(e.Name, 40)},
+		{key: "description", label: "Description", variable: -1, input: newInput(e.Description, 60)},
+		{key: "version", label: "Version", variable: -1, input: newInput(e.Version, 20)},
+	}
+	for i, v := range e.Variables {
+		values := map[string]string{
+			"name":     v.Name,
+			"label":    v.Label,
+			"type":     v.Type,
+			"required": formatYesNo(v.Required),
+			"default":  v.Default,
+		}
+		for _, col := range editVariableColumns {
+			m.editFields = append(m.editFields, editField{
+				key:      col.key,
+				label:    col.label,
+				variable: i,
+				input:    newInput(values[col.key], col.width-2),
+			})
+		}
+	}
+	m.editFields = append(m.editFields,
+		editField{key: "binary", label: "Binary", variable: -1, input: newInput(e.Binary, 30)},
+		editField{key: "ldflags", label: "LD Flags", variable: -1, input: newInput(e.LDFlags, 60)},
+		editField{key: "tags", label: "Tags", variable: -1, input: newInput(strings.Join(e.Tags, ", "), 40)},
+		editField{key: "trimpath", label: "Trimpath", variable: -1, input: newInput(formatYesNo(e.Trimpath), 5)},
+		editField{key: "test", label: "Run Tests", variable: -1, input: newInput(formatYesNo(e.Test), 5)},
+		editField{key: "vulncheck", label: "Vulncheck", variable: -1, input: newInput(e.Vulncheck, 10)},
+	)
+
+	m.editFocus = min(m.editFocus, len(m.editFields)-1)
+	m.editFields[m.editFocus].input.Focus()
+}
+
+// syncEdit copies the editor inputs into m.edit, validating enum and bool fields
+func (m *Model) syncEdit() error {
+	e := m.edit
+	e.Tags = nil
+	for _, f := range m.editFields {
+		value := strings.TrimSpace(f.input.Value())
+		if f.variable >= 0 {
+			v := &e.Variables[f.variable]
+			switch f.key {
+			case "name":
+				v.Name = value
+			case "label":
+				v.Label = value
+			case "type":
+				if value != "" && !slices.Contains(skill.VariableTypes, value) {
+					return fmt.Errorf("variable %d: invalid type %q (expected %s)", f.variable+1, value, strings.Join(skill.VariableTypes, ", "))
+				}
+				v.Type = value
+			case "required":
+				required, err := parseYesNo(value)
+				if err != nil {
+					return fmt.Errorf("variable %d: required: %w", f.variable+1, err)
+				}
+				v.Required = required
+			case "default":
+				v.Default = value
+			}
+			continue
+		}
+
+		var err error
+		switch f.key {
+		case "name":
+			e.Name = value
+		case "description":
+			e.Description = value
+		case "version":
+			e.Version = value
+		case "binary":
+			e.Binary = value
+		case "ldflags":
+			e.LDFlags = value
+		case "tags":
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					e.Tags = append(e.Tags, tag)
+				}
+			}
+		case "trimpath":
+			e.Trimpath, err = parseYesNo(value)
+		case "test":
+			e.Test, err = parseYesNo(value)
+		case "vulncheck":
+			modes := []string{pipeline.VulncheckOff, pipeline.VulncheckWarn, pipeline.VulncheckBlock}
+			if value != "" && !slices.Contains(modes, value) {
+				err = fmt.Errorf("expected %s", strings.Join(modes, ", "))
+			}
+			e.Vulncheck = value
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.label, err)
+		}
+	}
+	return nil
+}
+
+func (m Model) handleEditManifestView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.currentView = ViewSkillList
+		m.errorMsg = ""
+		return m, nil
+	case "tab", "down", "enter":
+		m.editFields[m.editFocus].input.Blur()
+		m.editFocus = (m.editFocus + 1) % len(m.editFields)
+		m.editFields[m.editFocus].input.Focus()
+		return m, textinput.Blink
+	case "shift+tab", "up":
+		m.editFields[m.editFocus].input.Blur()
+		m.editFocus--
+		if m.editFocus < 0 {
+			m.editFocus = len(m.editFields) - 1
+		}
+		m.editFields[m.editFocus].input.Focus()
+		return m, textinput.Blink
+	case "ctrl+n":
+		// Add a variable row and focus its name
+		if err := m.syncEdit(); err != nil {
+			m.errorMsg = err.Error()
+			return m, nil
+		}
+		m.edit.Variables = append(m.edit.Variables, skill.VariableEdit{
+			Variable: skill.Variable{Type: "string"},
+			Index:    -1,
+		})
+		m.editFields[m.editFocus].input.Blur()
+		m.editFocus = editSkillFields + (len(m.edit.Variables)-1)*len(editVariableColumns)
+		m.setupEditFields()
+		m.errorMsg = ""
+		return m, textinput.Blink
+	case "ctrl+x":
+		// Remove the focused variable row
+		variable := m.editFields[m.editFocus].variable
+		if variable < 0 {
+			return m, nil
+		}
+		if err := m.syncEdit(); err != nil {
+			m.errorMsg = err.Error()
+			return m, nil
+		}
+		m.edit.Variables = append(m.edit.Variables[:variable], m.edit.Variables[variable+1:]...)
+		m.editFields[m.editFocus].input.Blur()
+		m.editFocus = editSkillFields + variable*len(editVariableColumns)
+		m.setupEditFields()
+		m.errorMsg = ""
+		return m, textinput.Blink
+	case "ctrl+s":
+		return m.saveEditManifest()
+	}
+
+	var cmd tea.Cmd
+	m.editFields[m.editFocus].input, cmd = m.editFields[m.editFocus].input.Update(msg)
+	return m, cmd
+}
+
+// saveEditManifest writes the editor fields to skill.yaml and re-runs discovery
+func (m Model) saveEditManifest() (tea.Model, tea.Cmd) {
+	if err := m.syncEdit(); err != nil {
+		m.errorMsg = err.Error()
+		return m, nil
+	}
+	if m.edit.Name == "" {
+		m.errorMsg = "name is required"
+		return m, nil
+	}
+	for i, v := range m.edit.Variables {
+		if v.Name == "" {
+			m.errorMsg = fmt.Sprintf("variable %d: name is required", i+1)
+			return m, nil
+		}
+	}
+
+	if err := m.edit.Save(filepath.Join(m.editSkill.Path, "skill.yaml")); err != nil {
+		m.errorMsg = "save failed: " + err.Error()
+		return m, nil
+	}
+	stats.Record(stats.FeatureEditManifest)
+
+	m.rediscoverSkills()
+	m.currentView = ViewSkillList
+	m.errorMsg = ""
+	m.statusMsg = "Saved skill.yaml of " + m.edit.Name
+	for _, e := range m.skillErrors {
+		if e.Path == m.editSkill.Path {
+			m.statusMsg = ""
+			m.errorMsg = e.Name + " has errors: " + e.Error.Error()
+		}
+	}
+	return m, nil
+}
+
+// formatYesNo formats a bool for a yes/no input
+func formatYesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// parseYesNo parses a yes/no input, also accepting true/false
+func parseYesNo(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "y", "true":
+		return true, nil
+	case "no", "n", "false", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected yes or no, got %q", value)
+}
+
+// rediscoverSkills re-scans the skills directory
+func (m *Model) rediscoverSkills() {
+	manifests, skillErrors, err := skill.DiscoverSkills(m.projectRoot)
+	if err != nil {
+		m.errorMsg = err.Error()
+		return
+	}
+	m.manifests = manifests
+	m.skillErrors = skillErrors
+	m.selectedError = nil
+	if total := len(m.manifests) + len(m.skillErrors); m.skillCursor >= total {
+		m.skillCursor = max(total-1, 0)
+	}
+	m.refreshDeployedVersions()
+}
+
+func (m Model) handleConfirmView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "n":
+		m.revealEnv = false
+		m.currentView = ViewDeploy
+		m.deployFocus = 0
+		for i := range m.deployInputs {
+			m.deployInputs[i].Blur()
+		}
+		if len(m.deployInputs) > 0 {
+			m.deployInputs[0].Focus()
+		}
+		return m, textinput.Blink
+	case "enter", "y":
+		m.revealEnv = false
+		// Check if skill already exists
+		if m.skillExists() {
+			cmd := m.checkDeployedLock()
+			m.currentView = ViewOverwrite
+			return m, cmd
+		}
+		// Start build
+		return m, m.beginBuild()
+	case "k":
+		// Toggle secret storage between .env and OS keychain
+		if m.config != nil && m.hasSecrets() {
+			if m.config.UseKeychain() {
+				m.config.SecretStorage = config.SecretStorageEnv
+			} else {
+				m.config.SecretStorage = config.SecretStorageKeychain
+			}
+			_ = m.config.Save()
+		}
+	case "t":
+		// Toggle running tests before the build
+		if !m.selectedSkill.Build.Test {
+			if saved, err := config.ReadSettings(); err == nil {
+				saved.TestBeforeDeploy = !m.settings.TestBeforeDeploy
+				if config.SaveSettings(saved) == nil {
+					m.settings.TestBeforeDeploy = saved.TestBeforeDeploy
+				}
+			}
+		}
+	case "e":
+		// Toggle encrypted .env output
+		if m.config != nil {
+			m.config.EncryptEnv = !m.config.EncryptEnv
+			_ = m.config.Save()
+		}
+	case "v":
+		// Expand or collapse the .env preview
+		m.showEnvPreview = !m.showEnvPreview
+		m.revealEnv = false
+	case "r":
+		// Show or mask the secret values in the preview
+		if m.showEnvPreview {
+			m.revealEnv = !m.revealEnv
+		}
+	}
+	return m, nil
+}
+
+// encryptEnv reports whether the deployed .env is encrypted
+func (m Model) encryptEnv() bool {
+	return m.config != nil && m.config.EncryptEnv
+}
+
+// hasSecrets reports whether the selected skill has secret variables
+func (m Model) hasSecrets() bool {
+	for _, v := range m.selectedSkill.Variables {
+		if v.Type == "secret" {
+			return true
+		}
+	}
+	return false
+}
+
+// useKeychain reports whether secrets are stored in the OS keychain
+func (m Model) useKeychain() bool {
+	return m.config != nil && m.config.UseKeychain()
+}
+
+func (m Model) handleOverwriteView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "n":
+		// Go back to confirm view
+		m.currentView = ViewConfirm
+		m.checkingDeploy = false
+		return m, nil
+	}
+	// The choices depend on the comparison with the deployment
+	if m.checkingDeploy {
+		return m, nil
+	}
+	switch msg.String() {
+	case "y":
+		// Proceed with build (overwrite)
+		m.docsMode = pipeline.DocsOverwrite
+		return m, m.beginBuild()
+	case "k", "m":
+		// Deploy but keep or merge the hand-edited SKILL.md
+		if m.docsEdited {
+			m.docsMode = pipeline.DocsKeep
+			if msg.String() == "m" {
+				m.docsMode = pipeline.DocsMerge
+			}
+			return m, m.beginBuild()
+		}
+	case "d":
+		// Regenerate SKILL.md from the deployed binary, without building
+		m.regenerateDocs()
+		return m, nil
+	case "c":
+		// Redeploy the changed configuration without rebuilding the binary
+		if m.configOnlyChange() {
+			if err := pipeline.PreflightDeploy(m.selectedSkill, m.getDeployPath()); err != nil {
+				m.errorMsg = err.Error()
+				return m, nil
+			}
+			m.docsMode = pipeline.DocsKeep
+			if !m.docsEdited {
+				m.docsMode = pipeline.DocsOverwrite
+			}
+			m.startBuildState()
+			m.configOnly = true
+			m.buildStage = "Redeploying configuration"
+			return m, tea.Batch(m.spinner.Tick, m.deploySkill())
+		}
+	case "s":
+		// Skip deploy if the existing deployment is identical
+		if m.deploymentUnchanged() {
+			m.currentView = ViewDone
+			m.errorMsg = ""
+			m.statusMsg = "Skill is up to date, deploy skipped"
+			stats.Record(stats.FeatureSkipUnchanged)
+		}
+	case "up", "down", "pgup", "pgdown":
+		// Scroll the diff of the deployed files
+		var cmd tea.Cmd
+		m.outputView, cmd = m.outputView.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// regenerateDocs rewrites the SKILL.md of the existing deployment and shows
+// the result in the Done view
+func (m *Model) regenerateDocs() {
+	opts := m.deployOptions()
+	opts.DocsMode = pipeline.DocsOverwrite
+	m.buildTrend = ""
+	m.vulnResult = nil
+	m.healthcheck = nil
+	m.deploySteps = nil
+	if err := pipeline.RegenerateDocs(opts); err != nil {
+		m.statusMsg = ""
+		m.errorMsg = err.Error()
+		m.buildOutput = err.Error()
+		m.enterDone()
+		return
+	}
+	m.currentView = ViewDone
+	m.errorMsg = ""
+	m.buildOutput = ""
+	m.statusMsg = "SKILL.md regenerated, binary unchanged"
+}
+
+// beginBuild starts the pipeline, running the test gate first if enabled.
+// It stays in the current view if the deploy target fails the preflight.
+func (m *Model) beginBuild() tea.Cmd {
+	if err := pipeline.PreflightDeploy(m.selectedSkill, m.getDeployPath()); err != nil {
+		m.errorMsg = err.Error()
+		return nil
+	}
+	m.startBuildState()
+	if m.runTests() {
+		m.buildStage = "Running tests"
+		return tea.Batch(m.spinner.Tick, m.runTestGate())
+	}
+	return tea.Batch(m.spinner.Tick, m.startBuild())
+}
+
+// runTests reports whether tests must pass before the skill is built
+func (m Model) runTests() bool {
+	return pipeline.TestsEnabled(m.selectedSkill, m.settings.TestBeforeDeploy)
+}
+
+// startBuildState resets the build state and switches to the Building view
+func (m *Model) startBuildState() {
+	m.currentView = ViewBuilding
+	m.building = true
+	m.configOnly = false
+	if m.cancelBuild != nil {
+		m.cancelBuild()
+	}
+	m.buildCtx, m.cancelBuild = context.WithCancel(context.Background())
+	m.buildStage = ""
+	m.buildOutput = ""
+	m.buildLog = ""
+	m.buildTrend = ""
+	m.vulnResult = nil
+	m.healthcheck = nil
+	m.deploySteps = nil
+	m.errorMsg = ""
+	m.statusMsg = ""
+	m.outputView = viewport.New(m.outputWidth(), 0)
+}
+
+// updateBuildView shows the output of the running pipeline in the Building
+// view, following new lines unless scrolled up
+func (m *Model) updateBuildView() {
+	follow := m.outputView.AtBottom()
+	m.outputView.SetContent(strings.TrimRight(m.buildOutput+m.buildLog, "\n"))
+	m.outputView.Height = m.outputHeight()
+	if follow {
+		m.outputView.GotoBottom()
+	}
+}
+
+// handleBuildingView scrolls the output of the running pipeline and cancels
+// the tests or build on Esc
+func (m Model) handleBuildingView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "esc" {
+		if m.building && m.cancelBuild != nil {
+			m.cancelBuild()
+			m.buildStage = "Canceling"
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.outputView, cmd = m.outputView.Update(msg)
+	return m, cmd
+}
+
+// buildCanceled reports whether Esc canceled the running build. The canceled
+// pipeline returns to the Confirm view without recording a build.
+func (m *Model) buildCanceled() bool {
+	if m.buildCtx == nil || m.buildCtx.Err() == nil {
+		return false
+	}
+	m.building = false
+	m.buildStage = ""
+	m.buildLog = ""
+	m.buildOutput = ""
+	m.statusMsg = ""
+	m.errorMsg = "Build canceled"
+	m.currentView = ViewConfirm
+	return true
+}
+
+// enterDone switches to the Done view with the build output in a scrollable viewport
+func (m *Model) enterDone() {
+	m.currentView = ViewDone
+	m.problems = nil
+	m.problemCursor = 0
+	m.showOutput = false
+	m.editorErr = ""
+	m.logNote = ""
+	m.diagnoses = nil
+	if m.errorMsg != "" && m.selectedSkill != nil {
+		m.problems = pipeline.ParseProblems(m.buildOutput, m.selectedSkill.Path)
+		m.diagnoses = pipeline.DiagnoseBuild(m.buildOutput, m.selectedSkill)
+	}
+	content := strings.TrimRight(m.buildOutput, "\n")
+	m.outputView = viewport.New(m.outputWidth(), 0)
+	m.outputView.SetContent(content)
+	m.outputView.Height = m.outputHeight()
+}
+
+// outputWidth returns the width of the build output viewport
+func (m Model) outputWidth() int {
+	if m.width > 30 {
+		return m.width - 10
+	}
+	return 80
+}
+
+// outputHeight returns the height of the build output viewport, at most the
+// number of output lines
+func (m Model) outputHeight() int {
+	height := 15
+	if m.height > 0 {
+		height = max(m.height-20, 5)
+	}
+	return min(height, m.outputView.TotalLineCount())
+}
+
+// buildRecordedMsg is sent when the build duration is stored
+type buildRecordedMsg struct {
+	trend string // Sparkline of the recent build durations
+}
+
+// recordBuild stores the build duration and sends the updated build trend
+func (m Model) recordBuild(duration time.Duration) tea.Cmd {
+	manifest := m.selectedSkill
+	return func() tea.Msg {
+		stats.RecordBuild(manifest.Name, duration)
+		records, err := pipeline.RecordBuildDuration(manifest.Name, pipeline.BuildRecord{
+			At:         time.Now().UTC(),
+			DurationMS: duration.Milliseconds(),
+			GoVersion:  pipeline.GoVersion(manifest.Path),
+		})
+		if err != nil {
+			return buildRecordedMsg{trend: pipeline.FormatDuration(duration)}
+		}
+		return buildRecordedMsg{trend: pipeline.BuildTrend(records)}
+	}
+}
+
+// recordEvent appends a build or deploy of the selected skill to the event log
+func (m Model) recordEvent(action string, duration time.Duration, output string, err error) {
+	pipeline.RecordEvent(pipeline.NewEvent(action, m.selectedSkill, m.getDeployPath(), duration, output, err))
+}
+
+// checkDeployedLock resets the comparison with the existing deployment and
+// returns the command running it, the Overwrite view waits for its result
+func (m *Model) checkDeployedLock() tea.Cmd {
+	m.deployedLock = nil
+	m.lockChanges = nil
+	m.docsEdited = false
+	m.docsMode = pipeline.DocsOverwrite
+	m.deployedVersion = ""
+	m.platformWarning = ""
+	m.deployDiffs = nil
+	m.outputView = viewport.New(m.outputWidth(), 0)
+	m.checkingDeploy = true
+	return m.checkDeployment()
+}
+
+// applyDeployCheck shows the result of checkDeployment, the diffs of the
+// deployed SKILL.md and .env in a scrollable viewport
+func (m *Model) applyDeployCheck(msg deployCheckMsg) {
+	m.checkingDeploy = false
+	m.deployedVersion = msg.version
+	m.platformWarning = msg.platformWarning
+	m.deployedLock = msg.lock
+	m.lockChanges = msg.lockChanges
+	m.docsEdited = msg.docsEdited
+	m.deployDiffs = msg.diffs
+	var diffs []string
+	for _, d := range m.deployDiffs {
+		if d.Diff != "" {
+			diffs = append(diffs, colorDiff(d.Diff))
+		}
+	}
+	m.outputView = viewport.New(m.outputWidth(), 0)
+	m.outputView.SetContent(strings.Join(diffs, "\n"))
+	m.outputView.Height = m.outputHeight()
+}
+
+// configOnlyChange reports whether only the configuration changed since the
+// last deploy, so the deployed binary can be kept
+func (m Model) configOnlyChange() bool {
+	return m.deployedLock != nil && slices.Equal(m.lockChanges, []string{"config"})
+}
+
+// deploymentUnchanged reports whether the deployed skill matches what would be built
+func (m Model) deploymentUnchanged() bool {
+	return m.deployedLock != nil && len(m.lockChanges) == 0
+}
+
+// expectedLock returns the lock describing the build inputs of the current configuration
+func (m Model) expectedLock() *pipeline.Lock {
+	return pipeline.ExpectedLock(m.selectedSkill, m.configValues)
+}
+
+// skillExists checks if the deploy path already contains a skill
+func (m Model) skillExists() bool {
+	deployPath := m.getDeployPath()
+	if deployPath == "" {
+		return false
+	}
+
+	// Check if the bin directory with binary exists
+	binaryPath := filepath.Join(deployPath, "bin", m.selectedSkill.BinaryName())
+
+	_, err := os.Stat(binaryPath)
+	return err == nil
+}
+
+func (m Model) handleDoneView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "q", "esc":
+		m.quitting = true
+		return m, tea.Quit
+	case "r":
+		// Restart - go back to skill list
+		m.currentView = ViewSkillList
+		m.errorMsg = ""
+		m.statusMsg = ""
+		m.buildOutput = ""
+		m.vulnResult = nil
+		m.healthcheck = nil
+		m.deploySteps = nil
+		m.problems = nil
+		m.diagnoses = nil
+		return m, nil
+	case "f":
+		// Run the fix of a recognized build failure, then build again
+		if fix := m.buildFix(); fix != nil {
+			m.startBuildState()
+			m.buildStage = "Running " + fix.FixCommand()
+			return m, tea.Batch(m.spinner.Tick, m.runFix(*fix))
+		}
+	case "c":
+		// Copy the build log to the clipboard of the terminal (OSC 52)
+		if m.buildOutput != "" {
+			termenv.DefaultOutput().Copy(m.buildOutput)
+			m.logNote = "Build log copied to the clipboard"
+			return m, nil
+		}
+	case "w":
+		// Write the build log to a file
+		if m.buildOutput != "" {
+			path, err := pipeline.SaveBuildLog(m.selectedSkill.Name, m.buildOutput)
+			if err != nil {
+				m.logNote = "failed to save build log: " + err.Error()
+			} else {
+				m.logNote = "Build log saved to " + path
+			}
+			return m, nil
+		}
+	}
+
+	if len(m.problems) > 0 {
+		switch msg.String() {
+		case "v":
+			// Toggle between the problems and the raw build output
+			m.showOutput = !m.showOutput
+			return m, nil
+		case "up", "k":
+			if !m.showOutput {
+				if m.problemCursor > 0 {
+					m.problemCursor--
+				}
+				return m, nil
+			}
+		case "down", "j":
+			if !m.showOutput {
+				if m.problemCursor < len(m.problems)-1 {
+					m.problemCursor++
+				}
+				return m, nil
+			}
+		case "o":
+			// Open the selected problem in $EDITOR
+			p := m.problems[m.problemCursor]
+			return m, tea.ExecProcess(pipeline.EditorCommand(p.File, p.Line), func(err error) tea.Msg {
+				return editorClosedMsg{err: err}
+			})
+		}
+	}
+
+	// Scroll the build output
+	var cmd tea.Cmd
+	m.outputView, cmd = m.outputView.Update(msg)
+	return m, cmd
+}
+
+// buildFix returns the first diagnosis of the failed build with an automatic
+// fix, nil if there is none
+func (m Model) buildFix() *pipeline.Diagnosis {
+	for i := range m.diagnoses {
+		if len(m.diagnoses[i].Fix) > 0 {
+			return &m.diagnoses[i]
+		}
+	}
+	return nil
+}
+
+// setupInputsFromManifest creates input fields for skill environment variables
+func (m *Model) setupInputsFromManifest() {
+	if m.selectedSkill == nil {
+		return
+	}
+
+	// Create inputs for skill variables only
+	numInputs := len(m.selectedSkill.Variables)
+	m.configInputs = make([]textinput.Model, numInputs)
+	m.configLabels = make([]string, numInputs)
+
+	for i, v := range m.selectedSkill.Variables {
 		input := textinput.New()
 		input.Placeholder = v.Placeholder
 		if input.Placeholder == "" && v.Default != "" {
 			input.Placeholder = v.Default
 		}
+		if v.FromCommand() {
+			// Left empty, the value is taken from the command at deploy time
+			input.Placeholder = "$ " + v.Command
+		}
 		input.CharLimit = 200
-		input.Width = 50
+		input.Width = m.fitWidth(inputWidth, formIndent)
 
-		if v.Type == "secret" {
+		if v.Type == "secret" && v.Backend == "" {
+			// Tokens can be long, a pasted one must not be cut off
 			input.EchoMode = textinput.EchoPassword
+			input.CharLimit = 0
+		}
+		if v.Backend != "" && input.Placeholder == "" {
+			// The value is a reference, resolved at deploy time
+			input.Placeholder = pipeline.BackendPlaceholder(v.Backend)
 		}
 
 		// Load existing value if any
@@ -377,6 +2691,7 @@ func (m *Model) setupInputsFromManifest() {
 
 	// Focus first input
 	m.configFocus = 0
+	m.closeChoices()
 	for i := range m.configInputs {
 		m.configInputs[i].Blur()
 	}
@@ -387,14 +2702,14 @@ func (m *Model) setupInputsFromManifest() {
 
 // setupDeployInputs creates input fields for deploy settings
 func (m *Model) setupDeployInputs() {
-	m.deployInputs = make([]textinput.Model, 2)
-	m.deployLabels = make([]string, 2)
+	m.deployInputs = make([]textinput.Model, 3)
+	m.deployLabels = make([]string, 3)
 
 	// Skills Folder input
 	skillsFolderInput := textinput.New()
-	skillsFolderInput.Placeholder = "/path/to/.claude/skills/"
+	skillsFolderInput.Placeholder = "~/.claude/skills/"
 	skillsFolderInput.CharLimit = 200
-	skillsFolderInput.Width = 50
+	skillsFolderInput.Width = m.fitWidth(inputWidth, formIndent)
 	if m.skillsFolder != "" {
 		skillsFolderInput.SetValue(m.skillsFolder)
 	}
@@ -407,7 +2722,7 @@ func (m *Model) setupDeployInputs() {
 		skillNameInput.Placeholder = m.selectedSkill.Name
 	}
 	skillNameInput.CharLimit = 100
-	skillNameInput.Width = 50
+	skillNameInput.Width = m.fitWidth(inputWidth, formIndent)
 	if m.skillFolderName != "" {
 		skillNameInput.SetValue(m.skillFolderName)
 	} else if m.selectedSkill != nil {
@@ -416,6 +2731,19 @@ func (m *Model) setupDeployInputs() {
 	m.deployInputs[1] = skillNameInput
 	m.deployLabels[1] = "Skill Name"
 
+	// Profile input - the configuration is saved under this name
+	profileInput := textinput.New()
+	profileInput.Placeholder = config.DefaultProfile
+	profileInput.CharLimit = 50
+	profileInput.Width = m.fitWidth(inputWidth, formIndent)
+	if m.profileName != "" {
+		profileInput.SetValue(m.profileName)
+	} else {
+		profileInput.SetValue(config.DefaultProfile)
+	}
+	m.deployInputs[2] = profileInput
+	m.deployLabels[2] = "Profile"
+
 	// Focus first input
 	m.deployFocus = 0
 	for i := range m.deployInputs {
@@ -432,10 +2760,22 @@ func (m *Model) validateConfigInputs() bool {
 
 	// Validate required variables
 	for i, v := range m.selectedSkill.Variables {
-		if v.Required && m.configInputs[i].Value() == "" {
+		if v.Required && !v.FromCommand() && m.configInputs[i].Value() == "" {
 			m.errorMsg = v.Label + " is required"
 			return false
 		}
+		if value := m.configInputs[i].Value(); v.Backend != "" && value != "" {
+			if err := pipeline.CheckSecretRef(v.Backend, value); err != nil {
+				m.errorMsg = v.Label + ": " + err.Error()
+				return false
+			}
+		}
+		if value := m.configInputs[i].Value(); v.Name == pipeline.ProjectIDsVariable && v.Backend == "" && value != "" {
+			if _, err := pipeline.ParseProjectIDs(value); err != nil {
+				m.errorMsg = v.Label + ": " + err.Error()
+				return false
+			}
+		}
 	}
 
 	m.errorMsg = ""
@@ -449,6 +2789,10 @@ func (m *Model) validateDeployInputs() bool {
 		m.errorMsg = "Skills Folder is required"
 		return false
 	}
+	if !filepath.IsAbs(config.ExpandPath(skillsFolder)) {
+		m.errorMsg = "Skills Folder must be an absolute path (~ and $VARS are expanded)"
+		return false
+	}
 
 	// Validate skill name
 	skillName := m.deployInputs[1].Value()
@@ -457,6 +2801,13 @@ func (m *Model) validateDeployInputs() bool {
 		return false
 	}
 
+	// Validate profile name (used as key in the profiles file)
+	profileName := m.deployInputs[2].Value()
+	if profileName == "" {
+		m.errorMsg = "Profile is required"
+		return false
+	}
+
 	m.errorMsg = ""
 	return true
 }
@@ -466,15 +2817,69 @@ func (m *Model) saveConfigInputs() {
 		return
 	}
 
-	// Save variable values
+	// Save variable values. Pasted values often end in a newline, which
+	// the input turns into a space.
 	for i, v := range m.selectedSkill.Variables {
-		m.configValues[v.Name] = m.configInputs[i].Value()
+		m.configValues[v.Name] = strings.TrimSpace(m.configInputs[i].Value())
+	}
+}
+
+// isSecretInput reports whether config input i holds a masked secret
+func (m Model) isSecretInput(i int) bool {
+	if m.selectedSkill == nil || i >= len(m.selectedSkill.Variables) {
+		return false
+	}
+	v := m.selectedSkill.Variables[i]
+	return v.Type == "secret" && v.Backend == ""
+}
+
+// hasChoices reports whether config input i has a source: skill variable
+func (m Model) hasChoices(i int) bool {
+	return m.selectedSkill != nil && i < len(m.selectedSkill.Variables) && m.selectedSkill.Variables[i].HasChoices()
+}
+
+// closeChoices hides the choices of the focused variable, a list still
+// loading is dropped when it arrives
+func (m *Model) closeChoices() {
+	m.choices = nil
+	m.choiceCursor = 0
+	m.choicesLoading = false
+	m.choicesNote = ""
+}
+
+// handleChoices moves through the listed choices and sets the chosen value
+func (m Model) handleChoices(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.choiceCursor > 0 {
+			m.choiceCursor--
+		}
+	case "down", "j":
+		if m.choiceCursor < len(m.choices)-1 {
+			m.choiceCursor++
+		}
+	case "enter":
+		input := &m.configInputs[m.configFocus]
+		input.SetValue(m.choices[m.choiceCursor].Value)
+		input.CursorEnd()
+		m.closeChoices()
+	case "esc":
+		m.closeChoices()
+	}
+	return m, nil
+}
+
+// hideSecret masks the focused secret input again after Ctrl+R revealed it
+func (m *Model) hideSecret() {
+	if m.isSecretInput(m.configFocus) {
+		m.configInputs[m.configFocus].EchoMode = textinput.EchoPassword
 	}
 }
 
 func (m *Model) saveDeployInputs() {
-	m.skillsFolder = m.deployInputs[0].Value()
+	m.skillsFolder = config.ExpandPath(m.deployInputs[0].Value())
 	m.skillFolderName = m.deployInputs[1].Value()
+	m.profileName = m.deployInputs[2].Value()
 
 	// Persist skills folder for next session
 	if m.config != nil && m.skillsFolder != "" {
@@ -504,6 +2909,198 @@ func (m Model) updateDeployInputs(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// openFolderPicker shows the directory browser at path, or at its nearest
+// existing parent. note tells why it was opened.
+func (m *Model) openFolderPicker(path, note string) {
+	dir := path
+	for dir != "" {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			dir = ""
+			break
+		}
+		dir = parent
+	}
+	if dir == "" || !filepath.IsAbs(dir) {
+		dir, _ = os.UserHomeDir()
+	}
+
+	m.currentView = ViewFolderPicker
+	m.errorMsg = ""
+	m.pickerCreating = false
+	m.pickerNote = note
+	m.loadPickerDir(dir)
+}
+
+// loadPickerDir lists the subdirectories of dir in the directory browser.
+// It stays in the current directory if dir cannot be read.
+func (m *Model) loadPickerDir(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		m.pickerNote = err.Error()
+		return
+	}
+	m.pickerDir = dir
+	m.pickerEntries = nil
+	m.pickerCursor = 0
+	for _, e := range entries {
+		if isDir(filepath.Join(dir, e.Name()), e) {
+			m.pickerEntries = append(m.pickerEntries, e.Name())
+		}
+	}
+}
+
+// isDir reports whether a directory entry is a directory or a symlink to one
+func isDir(path string, e os.DirEntry) bool {
+	if e.IsDir() {
+		return true
+	}
+	if e.Type()&os.ModeSymlink == 0 {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// handleFolderPicker navigates the directory browser and hands the chosen
+// folder to the Skills Folder input
+func (m Model) handleFolderPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.pickerCreating {
+		switch msg.String() {
+		case "esc":
+			m.pickerCreating = false
+			return m, nil
+		case "enter":
+			// Create the directory (nested paths allowed) and open it
+			name := strings.TrimSpace(m.pickerInput.Value())
+			m.pickerCreating = false
+			if name == "" {
+				return m, nil
+			}
+			dir := config.ExpandPath(name)
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(m.pickerDir, dir)
+			}
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				m.pickerNote = "failed to create directory: " + err.Error()
+				return m, nil
+			}
+			m.pickerNote = ""
+			m.loadPickerDir(dir)
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.pickerInput, cmd = m.pickerInput.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.pickerCursor > 0 {
+			m.pickerCursor--
+		}
+	case "down", "j":
+		if m.pickerCursor < len(m.pickerEntries)-1 {
+			m.pickerCursor++
+		}
+	case "right", "l", "enter":
+		if len(m.pickerEntries) > 0 {
+			m.pickerNote = ""
+			m.loadPickerDir(filepath.Join(m.pickerDir, m.pickerEntries[m.pickerCursor]))
+		}
+	case "left", "h", "backspace":
+		// Go up, keeping the directory we came from selected
+		parent := filepath.Dir(m.pickerDir)
+		if parent != m.pickerDir {
+			from := filepath.Base(m.pickerDir)
+			m.pickerNote = ""
+			m.loadPickerDir(parent)
+			if i := slices.Index(m.pickerEntries, from); i >= 0 {
+				m.pickerCursor = i
+			}
+		}
+	case "~":
+		if home, err := os.UserHomeDir(); err == nil {
+			m.pickerNote = ""
+			m.loadPickerDir(home)
+		}
+	case "n":
+		m.pickerCreating = true
+		m.pickerInput = textinput.New()
+		m.pickerInput.Placeholder = "skills"
+		m.pickerInput.CharLimit = 200
+		m.pickerInput.Width = m.fitWidth(shortInputWidth, formIndent)
+		m.pickerInput.Focus()
+		return m, textinput.Blink
+	case "s", " ":
+		// Use the current directory as skills folder
+		m.deployInputs[0].SetValue(m.pickerDir)
+		m.deployInputs[0].CursorEnd()
+		m.currentView = ViewDeploy
+		m.pickerNote = ""
+		return m, textinput.Blink
+	case "esc":
+		// Back to typing the path
+		m.currentView = ViewDeploy
+		m.pickerNote = ""
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
+// completeDir completes the last element of a directory path typed in the
+// Skills Folder input to the longest prefix shared by the matching
+// subdirectories, adding a "/" for a single match. ~ and $VARS are kept.
+// It reports false if nothing could be completed.
+func completeDir(value string) (string, bool) {
+	if value == "~" {
+		return "~/", true
+	}
+	typedDir, prefix := "", value
+	if i := strings.LastIndex(value, "/"); i >= 0 {
+		typedDir, prefix = value[:i+1], value[i+1:]
+	}
+	dir := config.ExpandPath(typedDir)
+	if dir == "" {
+		return "", false
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+
+	var matches []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		if isDir(filepath.Join(dir, name), e) {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	completed := matches[0]
+	for _, name := range matches[1:] {
+		for !strings.HasPrefix(name, completed) {
+			completed = completed[:len(completed)-1]
+		}
+	}
+	if len(matches) == 1 {
+		completed += "/"
+	}
+	if completed == prefix {
+		return "", false
+	}
+	return typedDir + completed, true
+}
+
 // GetProjectRoot returns the project root, finding it if needed
 func GetProjectRoot() string {
 	// Try to find project root by looking for go.mod
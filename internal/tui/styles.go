@@ -1,60 +1,160 @@
 // Package tui provides the terminal user interface for SkillFactory
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"os"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/petervogelmann/skillfactory/internal/config"
+)
+
+// palette holds the colors of a theme
+type palette struct {
+	primary lipgloss.TerminalColor // Logo, borders, labels and the selection
+	accent  lipgloss.TerminalColor // Subtitles
+	text    lipgloss.TerminalColor // Titles and normal text
+	muted   lipgloss.TerminalColor // Hints, help and table headers
+	err     lipgloss.TerminalColor
+	success lipgloss.TerminalColor
+}
+
+var (
+	darkPalette = palette{
+		primary: lipgloss.Color("#7C3AED"), // Purple
+		accent:  lipgloss.Color("#A78BFA"), // Light purple
+		text:    lipgloss.Color("#FFFFFF"),
+		muted:   lipgloss.Color("#6B7280"), // Gray
+		err:     lipgloss.Color("#EF4444"), // Red
+		success: lipgloss.Color("#10B981"), // Green
+	}
+
+	lightPalette = palette{
+		primary: lipgloss.Color("#6D28D9"), // Dark purple
+		accent:  lipgloss.Color("#7C3AED"), // Purple
+		text:    lipgloss.Color("#111827"), // Near black
+		muted:   lipgloss.Color("#4B5563"), // Dark gray
+		err:     lipgloss.Color("#B91C1C"), // Dark red
+		success: lipgloss.Color("#047857"), // Dark green
+	}
+
+	// High contrast keeps the terminal's own foreground for text and uses
+	// saturated colors that stay readable on either background
+	highContrastPalette = palette{
+		primary: lipgloss.AdaptiveColor{Light: "#0000AF", Dark: "#FFFF00"}, // Blue / yellow
+		accent:  lipgloss.NoColor{},
+		text:    lipgloss.NoColor{},
+		muted:   lipgloss.NoColor{},
+		err:     lipgloss.AdaptiveColor{Light: "#AF0000", Dark: "#FF5F5F"},
+		success: lipgloss.AdaptiveColor{Light: "#005F00", Dark: "#5FFF5F"},
+	}
+)
+
+// autoPalette picks the light or dark color of every role by the terminal
+// background, which lipgloss detects on first use
+func autoPalette() palette {
+	adaptive := func(light, dark lipgloss.TerminalColor) lipgloss.TerminalColor {
+		return lipgloss.AdaptiveColor{Light: string(light.(lipgloss.Color)), Dark: string(dark.(lipgloss.Color))}
+	}
+	return palette{
+		primary: adaptive(lightPalette.primary, darkPalette.primary),
+		accent:  adaptive(lightPalette.accent, darkPalette.accent),
+		text:    adaptive(lightPalette.text, darkPalette.text),
+		muted:   adaptive(lightPalette.muted, darkPalette.muted),
+		err:     adaptive(lightPalette.err, darkPalette.err),
+		success: adaptive(lightPalette.success, darkPalette.success),
+	}
+}
+
+// Styles of the views, set from the palette of the theme by applyTheme
 var (
-	// Colors
-	primaryColor   = lipgloss.Color("#7C3AED") // Purple
-	accentColor    = lipgloss.Color("#A78BFA") // Light Purple
-	highlightColor = lipgloss.Color("#C4B5FD") // Even lighter purple
-	secondaryColor = lipgloss.Color("#10B981") // Green
-	mutedColor     = lipgloss.Color("#6B7280") // Gray
-	errorColor     = lipgloss.Color("#EF4444") // Red
-	successColor   = lipgloss.Color("#10B981") // Green
-	darkBgColor    = lipgloss.Color("#1E1B4B") // Dark purple background
+	mutedColor lipgloss.TerminalColor
 
 	// Header styles (like Claude Code)
+	logoStyle     lipgloss.Style
+	titleStyle    lipgloss.Style
+	subtitleStyle lipgloss.Style
+
+	// Content styles
+	selectedStyle   lipgloss.Style
+	normalStyle     lipgloss.Style
+	mutedStyle      lipgloss.Style
+	successStyle    lipgloss.Style
+	errorStyle      lipgloss.Style
+	boxStyle        lipgloss.Style
+	inputLabelStyle lipgloss.Style
+	helpStyle       lipgloss.Style
+	versionStyle    lipgloss.Style
+)
+
+func init() {
+	setPalette(autoPalette())
+}
+
+// setPalette builds the styles from the colors of p
+func setPalette(p palette) {
+	mutedColor = p.muted
+
 	logoStyle = lipgloss.NewStyle().
-			Foreground(primaryColor)
+		Foreground(p.primary)
 
 	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#FFFFFF"))
+		Bold(true).
+		Foreground(p.text)
 
 	subtitleStyle = lipgloss.NewStyle().
-			Foreground(accentColor)
+		Foreground(p.accent)
 
-	// Content styles
 	selectedStyle = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Bold(true)
+		Foreground(p.primary).
+		Bold(true)
 
 	normalStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF"))
+		Foreground(p.text)
 
 	mutedStyle = lipgloss.NewStyle().
-			Foreground(mutedColor)
+		Foreground(p.muted)
 
 	successStyle = lipgloss.NewStyle().
-			Foreground(successColor)
+		Foreground(p.success)
 
 	errorStyle = lipgloss.NewStyle().
-			Foreground(errorColor)
+		Foreground(p.err)
 
 	boxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(primaryColor).
-			Padding(1, 2)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(p.primary).
+		Padding(1, 2)
 
 	inputLabelStyle = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Bold(true)
+		Foreground(p.primary).
+		Bold(true)
 
 	helpStyle = lipgloss.NewStyle().
-			Foreground(mutedColor)
+		Foreground(p.muted)
 
 	versionStyle = lipgloss.NewStyle().
-			Foreground(mutedColor).
-			Faint(true)
-)
+		Foreground(p.muted).
+		Faint(true)
+}
+
+// applyTheme switches the colors of the TUI to a config theme. NO_COLOR
+// (https://no-color.org) turns colors off whatever the theme.
+func applyTheme(theme string) {
+	profile := termenv.NewOutput(os.Stdout).EnvColorProfile()
+	if theme == config.ThemeMono || os.Getenv("NO_COLOR") != "" {
+		profile = termenv.Ascii
+	}
+	lipgloss.SetColorProfile(profile)
+
+	switch theme {
+	case config.ThemeDark:
+		setPalette(darkPalette)
+	case config.ThemeLight:
+		setPalette(lightPalette)
+	case config.ThemeHighContrast:
+		setPalette(highContrastPalette)
+	default:
+		setPalette(autoPalette())
+	}
+}
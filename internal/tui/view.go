@@ -3,7 +3,18 @@ package tui
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/skill"
 )
 
 // View renders the current view
@@ -21,9 +32,20 @@ func (m Model) View() string {
 	b.WriteString(" ")
 	b.WriteString(versionStyle.Render(m.version))
 	b.WriteString("\n")
-	b.WriteString("   ")
-	b.WriteString(subtitleStyle.Render("Build & deploy skills for Claude Code"))
-	b.WriteString("\n\n")
+	if !m.compact() {
+		b.WriteString("   ")
+		b.WriteString(subtitleStyle.Render("Build & deploy skills for Claude Code"))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// Help overlay instead of the view
+	if m.showHelp {
+		b.WriteString(m.renderKeyHelp())
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Any key: Close"))
+		return b.String()
+	}
 
 	// Main content based on current view
 	switch m.currentView {
@@ -41,12 +63,39 @@ func (m Model) View() string {
 		b.WriteString(m.renderBuilding())
 	case ViewDone:
 		b.WriteString(m.renderDone())
+	case ViewErrorDetail:
+		b.WriteString(m.renderErrorDetail())
+	case ViewQueue:
+		b.WriteString(m.renderQueue())
+	case ViewQuickFix:
+		b.WriteString(m.renderQuickFix())
+	case ViewEditManifest:
+		b.WriteString(m.renderEditManifest())
+	case ViewProfile:
+		b.WriteString(m.renderProfile())
+	case ViewRemove:
+		b.WriteString(m.renderRemove())
+	case ViewDeployed:
+		b.WriteString(m.renderDeployed())
+	case ViewBundles:
+		b.WriteString(m.renderBundles())
+	case ViewBundleEdit:
+		b.WriteString(m.renderBundleEdit())
+	case ViewFolderPicker:
+		b.WriteString(m.renderFolderPicker())
+	case ViewSearch:
+		b.WriteString(m.renderSearch())
+	case ViewSettings:
+		b.WriteString(m.renderSettings())
 	}
 
 	// Error message
 	if m.errorMsg != "" {
 		b.WriteString("\n")
-		b.WriteString(errorStyle.Render("✗ " + m.errorMsg))
+		b.WriteString(errorStyle.Render(m.fitLine("✗ " + m.errorMsg)))
+	} else if m.statusMsg != "" && (m.currentView == ViewSkillList || m.currentView == ViewDeployed || m.currentView == ViewBundles) {
+		b.WriteString("\n")
+		b.WriteString(successStyle.Render(m.fitLine("✓ " + m.statusMsg)))
 	}
 
 	// Help
@@ -59,7 +108,7 @@ func (m Model) View() string {
 func (m Model) renderSkillList() string {
 	var b strings.Builder
 
-	b.WriteString(inputLabelStyle.Render("Available Skills"))
+	b.WriteString(renderTabs(ViewSkillList))
 	b.WriteString("\n\n")
 
 	if len(m.manifests) == 0 && len(m.skillErrors) == 0 {
@@ -67,49 +116,165 @@ func (m Model) renderSkillList() string {
 		b.WriteString("\n")
 		b.WriteString(mutedStyle.Render("  Add a skill.yaml to register a skill"))
 	} else {
-		// Valid skills
-		for i, manifest := range m.manifests {
-			cursor := "  "
-			style := normalStyle
-			if i == m.skillCursor {
-				cursor = "▸ "
-				style = selectedStyle
+		// Filter input and match count
+		filtered := m.filtering || m.skillFilter.Value() != ""
+		if filtered {
+			matches := 0
+			for i := 0; i < len(m.manifests)+len(m.skillErrors); i++ {
+				if m.skillMatches(i) {
+					matches++
+				}
+			}
+			b.WriteString("  ")
+			b.WriteString(m.skillFilter.View())
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("  %d of %d", matches, len(m.manifests)+len(m.skillErrors))))
+			b.WriteString("\n\n")
+			if matches == 0 {
+				b.WriteString(mutedStyle.Render("  No skills match"))
+				b.WriteString("\n")
 			}
+		}
 
-			b.WriteString(cursor)
-			b.WriteString(style.Render(manifest.Name))
-			b.WriteString("\n")
-			b.WriteString("    ")
-			b.WriteString(mutedStyle.Render(manifest.Description))
-			b.WriteString("\n")
+		b.WriteString(m.renderSkillTable())
+	}
+
+	return m.box(b.String())
+}
+
+// Status badges of the skill list
+const (
+	statusNew      = "new"
+	statusDeployed = "deployed"
+	statusOutdated = "outdated"
+	statusError    = "error"
+)
+
+// renderSkillTable renders the skills passing the filter as a table with
+// version, last deploy and status, followed by the details of the selected
+// skill
+func (m Model) renderSkillTable() string {
+	var b strings.Builder
+
+	// Rows of the visible skills, by index in manifests + skillErrors
+	var rows []table.Row
+	var indexes []int
+	nameWidth := len("Skill")
+	for i, manifest := range m.manifests {
+		if !m.skillMatches(i) {
+			continue
+		}
+		version := "-"
+		if manifest.Version != "" {
+			version = "v" + strings.TrimPrefix(manifest.Version, "v")
+		}
+		lastDeploy := "-"
+		if d, ok := m.lastDeploys[manifest.Name]; ok {
+			lastDeploy = d.At.Local().Format("2006-01-02 15:04") + "  " + config.ContractPath(d.Target)
 		}
+		rows = append(rows, table.Row{"", manifest.Name, version, lastDeploy, m.skillStatus(manifest)})
+		indexes = append(indexes, i)
+		nameWidth = max(nameWidth, len(manifest.Name))
+	}
+	for i, skillErr := range m.skillErrors {
+		if !m.skillMatches(len(m.manifests) + i) {
+			continue
+		}
+		rows = append(rows, table.Row{"", skillErr.Name, "-", "-", statusError})
+		indexes = append(indexes, len(m.manifests)+i)
+		nameWidth = max(nameWidth, len(skillErr.Name))
+	}
+	if len(rows) == 0 {
+		return ""
+	}
 
-		// Error skills
-		if len(m.skillErrors) > 0 {
-			b.WriteString("\n")
-			b.WriteString(errorStyle.Render("Skills with Errors"))
-			b.WriteString("\n\n")
+	// Keep the cursor inside the visible window
+	height := 15
+	if m.height > 0 {
+		height = max(m.height-24, 5)
+	}
+	cursor := max(slices.Index(indexes, m.skillCursor), 0)
+	start := 0
+	if cursor >= height {
+		start = cursor - height + 1
+	}
+	end := min(start+height, len(rows))
 
-			for i, skillErr := range m.skillErrors {
-				idx := len(m.manifests) + i
-				cursor := "  "
-				style := mutedStyle
-				if idx == m.skillCursor {
-					cursor = "▸ "
-					style = errorStyle
-				}
+	nameWidth = min(nameWidth, 24)
+	versionWidth, statusWidth := 10, len(statusDeployed)
+	deployWidth := max(m.outputWidth()-nameWidth-versionWidth-statusWidth-11, 20)
+	styles := table.DefaultStyles()
+	styles.Header = styles.Header.Foreground(mutedColor)
+	styles.Selected = selectedStyle
+	rows[cursor][0] = "▸"
+	columns := []table.Column{
+		{Title: "", Width: 1}, // Cursor
+		{Title: "Skill", Width: nameWidth},
+		{Title: "Version", Width: versionWidth},
+		{Title: "Last deployed", Width: deployWidth},
+		{Title: "Status", Width: statusWidth},
+	}
+	visible := rows[start:end]
+	if m.compact() {
+		// Drop the Last deployed column to fit narrow terminals
+		columns = slices.Delete(columns, 3, 4)
+		visible = make([]table.Row, 0, end-start)
+		for _, row := range rows[start:end] {
+			visible = append(visible, slices.Delete(slices.Clone(row), 3, 4))
+		}
+	}
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows(visible),
+		table.WithHeight(end-start+1),
+		table.WithStyles(styles),
+	)
+	t.SetCursor(cursor - start)
+	b.WriteString(t.View())
+	b.WriteString("\n")
+	if len(rows) > height {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  %d of %d", cursor+1, len(rows))))
+		b.WriteString("\n")
+	}
 
-				b.WriteString(cursor)
-				b.WriteString(style.Render(skillErr.Name))
-				b.WriteString("\n")
-				b.WriteString("    ")
-				b.WriteString(mutedStyle.Render(skillErr.Error.Error()))
-				b.WriteString("\n")
-			}
+	// Details of the selected skill
+	b.WriteString("\n")
+	if m.skillCursor < len(m.manifests) {
+		manifest := m.manifests[m.skillCursor]
+		b.WriteString("  ")
+		b.WriteString(m.wrapIndent(mutedStyle.Render(manifest.Description), 2))
+		if deployed, ok := m.deployedVersions[manifest.Name]; ok {
+			b.WriteString(" ")
+			b.WriteString(renderVersionStatus(manifest.Version, deployed))
 		}
+		return b.String()
+	}
+	skillErr := m.skillErrors[m.skillCursor-len(m.manifests)]
+	if len(skillErr.Issues) == 0 {
+		b.WriteString(errorStyle.Render("  " + string(skillErr.Kind) + ": " + skillErr.Error.Error()))
+	} else {
+		b.WriteString(errorStyle.Render("  " + string(skillErr.Kind)))
+	}
+	if fixable := countFixable(skillErr.Issues); fixable > 0 {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf(" (%d quick fixes)", fixable)))
+	}
+	for _, issue := range skillErr.Issues {
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("    " + issue.String()))
 	}
+	return b.String()
+}
 
-	return boxStyle.Render(b.String())
+// skillStatus returns the status badge of a skill in the skill list
+func (m Model) skillStatus(manifest *skill.Manifest) string {
+	deployed, inFolder := m.deployedVersions[manifest.Name]
+	_, recorded := m.lastDeploys[manifest.Name]
+	switch {
+	case inFolder && skill.VersionStatus(manifest.Version, deployed) == "outdated":
+		return statusOutdated
+	case inFolder || recorded:
+		return statusDeployed
+	}
+	return statusNew
 }
 
 func (m Model) renderConfig() string {
@@ -122,6 +287,7 @@ func (m Model) renderConfig() string {
 	}
 	b.WriteString("\n\n")
 
+	focus := 0
 	for i, input := range m.configInputs {
 		// Label with focus indicator
 		labelStyle := mutedStyle
@@ -129,6 +295,7 @@ func (m Model) renderConfig() string {
 		if i == m.configFocus {
 			labelStyle = inputLabelStyle
 			prefix = "▸ "
+			focus = lineCount(&b)
 		}
 
 		// Show label
@@ -137,9 +304,14 @@ func (m Model) renderConfig() string {
 		// Add required indicator
 		if m.selectedSkill != nil && i < len(m.selectedSkill.Variables) {
 			v := m.selectedSkill.Variables[i]
-			if v.Required {
+			if v.FromCommand() {
+				label += " (from command)"
+			} else if v.Required {
 				label += " *"
 			}
+			if v.Backend != "" {
+				label += " (" + pipeline.BackendNames[v.Backend] + " reference)"
+			}
 		}
 
 		b.WriteString(prefix)
@@ -149,179 +321,1517 @@ func (m Model) renderConfig() string {
 		// Input field
 		b.WriteString("  ")
 		b.WriteString(input.View())
-		b.WriteString("\n\n")
+		b.WriteString("\n")
+
+		// Show the skills folder with ~ and $VARS expanded
+		if i == 0 {
+			if expanded := config.ExpandPath(input.Value()); expanded != input.Value() {
+				b.WriteString(mutedStyle.Render("  → " + expanded))
+				b.WriteString("\n")
+			}
+		}
+
+		if i == m.configFocus && m.hasChoices(i) {
+			b.WriteString(m.renderChoices())
+		}
+
+		// The length of a masked secret shows whether a paste was complete
+		if i == m.configFocus && m.isSecretInput(i) && input.Value() != "" {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("  %d characters", len([]rune(strings.TrimSpace(input.Value()))))))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
 	}
 
 	b.WriteString(mutedStyle.Render("  * required"))
 
-	return boxStyle.Render(b.String())
+	return m.box(m.window(b.String(), focus, 3))
 }
 
-func (m Model) renderDeploy() string {
+// choicesHeight is the number of choices shown at once under a variable
+const choicesHeight = 8
+
+// renderChoices shows the choices listed for the focused variable with
+// Ctrl+L, or why there are none
+func (m Model) renderChoices() string {
 	var b strings.Builder
+	switch {
+	case m.choicesLoading:
+		b.WriteString(mutedStyle.Render("  Listing choices..."))
+		b.WriteString("\n")
+	case m.choicesNote != "":
+		b.WriteString(errorStyle.Render("  " + m.wrapIndent(m.choicesNote, 2)))
+		b.WriteString("\n")
+	}
 
-	b.WriteString(inputLabelStyle.Render("Step 2: Deploy Settings"))
+	// Keep the cursor inside the visible window
+	start := 0
+	if m.choiceCursor >= choicesHeight {
+		start = m.choiceCursor - choicesHeight + 1
+	}
+	end := min(start+choicesHeight, len(m.choices))
+	for i := start; i < end; i++ {
+		c := m.choices[i]
+		line := c.Value
+		if c.Label != "" {
+			line += "  " + c.Label
+		}
+		if i == m.choiceCursor {
+			b.WriteString(selectedStyle.Render("  ▸ " + line))
+		} else {
+			b.WriteString(normalStyle.Render("    " + line))
+		}
+		b.WriteString("\n")
+	}
+	if len(m.choices) > choicesHeight {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("    %d of %d", m.choiceCursor+1, len(m.choices))))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (m Model) renderProfile() string {
+	var b strings.Builder
+
+	b.WriteString(inputLabelStyle.Render("Profiles: " + m.selectedSkill.Name))
 	b.WriteString("\n\n")
 
-	for i, input := range m.deployInputs {
-		// Label with focus indicator
-		labelStyle := mutedStyle
-		prefix := "  "
-		if i == m.deployFocus {
-			labelStyle = inputLabelStyle
-			prefix = "▸ "
+	for i, name := range m.profileNames {
+		cursor := "  "
+		style := normalStyle
+		if i == m.profileCursor {
+			cursor = "▸ "
+			style = selectedStyle
 		}
+		b.WriteString(cursor)
+		b.WriteString(style.Render(name))
+		b.WriteString("\n")
 
-		// Show label - all deploy fields are required
-		label := m.deployLabels[i] + " *"
+		profile := m.profiles[name]
+		target := profile.SkillFolderName
+		if profile.SkillsFolder != "" {
+			target = filepath.Join(profile.SkillsFolder, profile.SkillFolderName)
+		}
+		b.WriteString("    ")
+		b.WriteString(mutedStyle.Render(target))
+		b.WriteString("\n")
+	}
 
-		b.WriteString(prefix)
-		b.WriteString(labelStyle.Render(label))
+	cursor := "  "
+	style := mutedStyle
+	if m.profileCursor == len(m.profileNames) {
+		cursor = "▸ "
+		style = selectedStyle
+	}
+	b.WriteString("\n")
+	b.WriteString(cursor)
+	b.WriteString(style.Render("+ New profile"))
+
+	return m.box(b.String())
+}
+
+func (m Model) renderBundles() string {
+	var b strings.Builder
+
+	b.WriteString(inputLabelStyle.Render("Bundles (" + skill.BundleFile + ")"))
+	b.WriteString("\n\n")
+
+	for i, bundle := range m.bundles {
+		cursor := "  "
+		style := normalStyle
+		if i == m.bundleCursor {
+			cursor = "▸ "
+			style = selectedStyle
+		}
+		b.WriteString(cursor)
+		b.WriteString(style.Render(bundle.Name))
+		if bundle.Description != "" {
+			b.WriteString(" ")
+			b.WriteString(mutedStyle.Render(bundle.Description))
+		}
 		b.WriteString("\n")
 
-		// Input field
-		b.WriteString("  ")
-		b.WriteString(input.View())
-		b.WriteString("\n\n")
+		detail := strings.Join(bundle.Skills, ", ")
+		if bundle.Target != "" {
+			detail += " → " + bundle.Target
+		}
+		b.WriteString("    ")
+		b.WriteString(mutedStyle.Render(detail))
+		b.WriteString("\n")
 	}
 
-	b.WriteString(mutedStyle.Render("  * required"))
+	cursor := "  "
+	style := mutedStyle
+	if m.bundleCursor == len(m.bundles) {
+		cursor = "▸ "
+		style = selectedStyle
+	}
+	if len(m.bundles) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString(cursor)
+	b.WriteString(style.Render("+ New bundle"))
 
-	return boxStyle.Render(b.String())
+	return m.box(b.String())
 }
 
-func (m Model) renderConfirm() string {
+func (m Model) renderBundleEdit() string {
 	var b strings.Builder
 
-	b.WriteString(inputLabelStyle.Render("Step 3: Confirm"))
+	title := "New bundle"
+	if m.bundleOrig != "" {
+		title = "Bundle: " + m.bundleOrig
+	}
+	b.WriteString(inputLabelStyle.Render(title))
 	b.WriteString("\n\n")
 
-	// Show skill info
-	if m.selectedSkill != nil {
-		b.WriteString(mutedStyle.Render("  Skill:         "))
-		b.WriteString(normalStyle.Render(m.selectedSkill.Name))
-		b.WriteString("\n\n")
+	focus := 0
+	for i, input := range m.bundleInputs {
+		if i == m.bundleFocus {
+			focus = lineCount(&b)
+		}
+		b.WriteString(fmt.Sprintf("  %-14s ", bundleInputLabels[i]))
+		b.WriteString(input.View())
+		b.WriteString("\n")
+	}
 
-		// Show all configured values
-		if len(m.selectedSkill.Variables) > 0 {
-			b.WriteString(mutedStyle.Render("  Environment:"))
-			b.WriteString("\n")
-			for _, v := range m.selectedSkill.Variables {
-				value := m.configValues[v.Name]
-				if v.Type == "secret" && len(value) > 8 {
-					value = value[:8] + "..."
-				}
-				b.WriteString(mutedStyle.Render(fmt.Sprintf("    %-12s ", v.Label+":")))
-				b.WriteString(normalStyle.Render(value))
-				b.WriteString("\n")
-			}
-			b.WriteString("\n")
+	// checkbox renders a focusable checklist row
+	row := len(m.bundleInputs)
+	checkbox := func(checked bool, label, detail string) {
+		cursor := "  "
+		style := normalStyle
+		if row == m.bundleFocus {
+			cursor = "▸ "
+			style = selectedStyle
+			focus = lineCount(&b)
+		}
+		mark := "[ ]"
+		if checked {
+			mark = "[x]"
 		}
+		b.WriteString(cursor + style.Render(mark+" "+label))
+		if detail != "" {
+			b.WriteString(" ")
+			b.WriteString(mutedStyle.Render(detail))
+		}
+		b.WriteString("\n")
+		row++
 	}
 
-	// Deploy configuration
-	b.WriteString(mutedStyle.Render("  Deploy:"))
 	b.WriteString("\n")
-	b.WriteString(mutedStyle.Render("    Skills Folder: "))
-	b.WriteString(normalStyle.Render(m.skillsFolder))
+	b.WriteString(inputLabelStyle.Render("  Skills"))
 	b.WriteString("\n")
-	b.WriteString(mutedStyle.Render("    Skill Name:    "))
-	b.WriteString(normalStyle.Render(m.skillFolderName))
-	b.WriteString("\n")
-	b.WriteString(mutedStyle.Render("    Target:        "))
-	b.WriteString(successStyle.Render(m.getDeployPath()))
-	b.WriteString("\n\n")
+	for _, manifest := range m.manifests {
+		checkbox(slices.Contains(m.bundleEdit.Skills, manifest.Name), manifest.Name, manifest.Description)
+	}
 
-	b.WriteString(normalStyle.Render("  Build and deploy this skill?"))
 	b.WriteString("\n")
-	b.WriteString(mutedStyle.Render("  [Y] Yes  [N] Back"))
+	b.WriteString(inputLabelStyle.Render("  Shared variables"))
+	b.WriteString("\n")
+	vars := m.bundleVariables()
+	if len(vars) == 0 {
+		b.WriteString(mutedStyle.Render("  Select skills with variables to share them"))
+		b.WriteString("\n")
+	}
+	for _, v := range vars {
+		checkbox(slices.Contains(m.bundleEdit.Shared, v.Name), v.Name, strings.Join(v.Skills, ", "))
+	}
+	b.WriteString(mutedStyle.Render("  Shared variables are entered once for all skills of the bundle"))
 
-	return boxStyle.Render(b.String())
+	return m.box(m.window(b.String(), focus, 1))
 }
 
-func (m Model) renderBuilding() string {
+// themeHints describe the themes in the Settings view
+var themeHints = map[string]string{
+	config.ThemeAuto:         "dark or light by the terminal background",
+	config.ThemeDark:         "for dark backgrounds",
+	config.ThemeLight:        "for light backgrounds",
+	config.ThemeHighContrast: "strong colors, terminal text color",
+	config.ThemeMono:         "no colors",
+}
+
+// renderSettings renders the global options of the config file
+func (m Model) renderSettings() string {
 	var b strings.Builder
 
-	b.WriteString(inputLabelStyle.Render("Building..."))
+	b.WriteString(inputLabelStyle.Render("Settings"))
+	if path, err := config.SettingsPath(); err == nil {
+		b.WriteString("  ")
+		b.WriteString(mutedStyle.Render(config.ContractPath(path)))
+	}
 	b.WriteString("\n\n")
 
-	skillName := "skill"
-	if m.selectedSkill != nil {
-		skillName = m.selectedSkill.Name
+	// override notes an environment variable taking precedence over a row
+	override := func(envVar string) {
+		if os.Getenv(envVar) != "" {
+			b.WriteString(mutedStyle.Render("    " + envVar + " overrides this"))
+			b.WriteString("\n")
+		}
+	}
+
+	focus := 0
+	envVars := []string{config.SkillsFolderEnvVar, config.ParallelBuildsEnvVar, "", ""}
+	for i, input := range m.settingsInputs {
+		cursor := "  "
+		style := mutedStyle
+		if i == m.settingsFocus {
+			cursor = "▸ "
+			style = inputLabelStyle
+			focus = lineCount(&b)
+		}
+		b.WriteString(cursor + style.Render(fmt.Sprintf("%-24s", settingsInputLabels[i])))
+		b.WriteString(input.View())
+		b.WriteString("\n")
+		if i == 0 {
+			if expanded := config.ExpandPath(input.Value()); expanded != input.Value() {
+				b.WriteString(mutedStyle.Render(fmt.Sprintf("  %-24s→ %s", "", expanded)))
+				b.WriteString("\n")
+			}
+		}
+		override(envVars[i])
+	}
+	b.WriteString("\n")
+
+	// choice renders a focusable row switched with Space
+	row := len(m.settingsInputs)
+	choice := func(label, value, detail string) {
+		cursor := "  "
+		style := normalStyle
+		if row == m.settingsFocus {
+			cursor = "▸ "
+			style = selectedStyle
+			focus = lineCount(&b)
+		}
+		b.WriteString(cursor + style.Render(fmt.Sprintf("%-24s%s", label, value)))
+		if detail != "" && m.compact() {
+			// Below the row, the value column is too narrow for it
+			b.WriteString("\n    ")
+			b.WriteString(m.wrapIndent(mutedStyle.Render(detail), 4))
+		} else if detail != "" {
+			b.WriteString("  ")
+			b.WriteString(mutedStyle.Render(detail))
+		}
+		b.WriteString("\n")
+		row++
+	}
+	checkbox := func(checked bool) string {
+		if checked {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+
+	e := m.settingsEdit
+	choice("Theme", "‹ "+e.Theme+" ›", themeHints[e.Theme])
+	override(config.ThemeEnvVar)
+	if os.Getenv("NO_COLOR") != "" && e.Theme != config.ThemeMono {
+		b.WriteString(mutedStyle.Render("    NO_COLOR turns the colors off"))
+		b.WriteString("\n")
 	}
-	b.WriteString(mutedStyle.Render("  Compiling " + skillName + "..."))
+	choice("Tests before deploy", checkbox(e.TestBeforeDeploy), "go test ./... before every build")
+	choice("Keep dist/", checkbox(e.KeepDist), "keep the built binary after a deploy")
+
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("  A skills folder saved in the Deploy step takes precedence over the default"))
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("  Builds are archived in dist/builds, backups kept in ~/.skillfactory/backups; skillfactory clean prunes them"))
 
-	return boxStyle.Render(b.String())
+	return m.box(m.window(b.String(), focus, 1))
 }
 
-func (m Model) renderDone() string {
+// renderTabs renders the tab bar of the skill list and the Deployed view
+func renderTabs(active View) string {
+	skills, deployed := mutedStyle, mutedStyle
+	if active == ViewDeployed {
+		deployed = inputLabelStyle
+	} else {
+		skills = inputLabelStyle
+	}
+	return skills.Render("Available Skills") + mutedStyle.Render("  │  ") + deployed.Render("Deployed")
+}
+
+// renderSearch renders the search input and the matches in the deployed skills
+func (m Model) renderSearch() string {
 	var b strings.Builder
 
-	if m.statusMsg != "" {
-		b.WriteString(successStyle.Render("✓ " + m.statusMsg))
-		b.WriteString("\n\n")
+	b.WriteString(inputLabelStyle.Render("Search Deployed Skills"))
+	b.WriteString("\n\n  ")
+	b.WriteString(m.searchInput.View())
+	b.WriteString("\n\n")
 
-		b.WriteString(mutedStyle.Render("  Deployed to: "))
-		b.WriteString(normalStyle.Render(m.getDeployPath()))
-		b.WriteString("\n\n")
+	query := strings.TrimSpace(m.searchInput.Value())
+	switch {
+	case query == "":
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  Searches SKILL.md and the commands of %d deployed skills", len(m.searchIndex))))
+		return m.box(b.String())
+	case len(m.searchResults) == 0:
+		b.WriteString(mutedStyle.Render("  No matches"))
+		return m.box(b.String())
+	}
 
-		b.WriteString(mutedStyle.Render("  The skill is now ready to use!"))
-	} else if m.errorMsg != "" {
-		b.WriteString(errorStyle.Render("✗ Build/Deploy failed"))
-		b.WriteString("\n\n")
-		if m.buildOutput != "" {
-			b.WriteString(mutedStyle.Render("  Output: " + m.buildOutput))
+	// Keep the cursor inside the visible window
+	height := 15
+	if m.height > 0 {
+		height = max(m.height-20, 5)
+	}
+	start := 0
+	if m.searchCursor >= height {
+		start = m.searchCursor - height + 1
+	}
+	end := min(start+height, len(m.searchResults))
+
+	width := m.outputWidth()
+	for i := start; i < end; i++ {
+		r := m.searchResults[i]
+		location := r.Folder + "  " + r.Command
+		if r.Command == "" {
+			location = fmt.Sprintf("%s  %s:%d", r.Folder, r.File, r.Line)
 		}
+		text := truncate(r.Text, max(width-len(location)-6, 10))
+		if i == m.searchCursor {
+			b.WriteString(selectedStyle.Render("  ▸ " + location))
+			b.WriteString("  " + normalStyle.Render(text))
+		} else {
+			b.WriteString(mutedStyle.Render("    " + location))
+			b.WriteString("  " + mutedStyle.Render(text))
+		}
+		b.WriteString("\n")
 	}
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("  %d matches", len(m.searchResults))))
 
-	return boxStyle.Render(b.String())
+	return m.box(b.String())
 }
 
-func (m Model) renderOverwrite() string {
+func (m Model) renderDeployed() string {
 	var b strings.Builder
 
-	b.WriteString(errorStyle.Render("⚠️  Skill already exists"))
+	b.WriteString(renderTabs(ViewDeployed))
 	b.WriteString("\n\n")
 
-	skillName := "skill"
-	if m.selectedSkill != nil {
-		skillName = m.selectedSkill.Name
+	switch {
+	case m.skillsFolder == "":
+		b.WriteString(mutedStyle.Render("  No skills folder configured yet"))
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("  Deploy a skill once to set it"))
+	case len(m.deployedSkills) == 0:
+		b.WriteString(mutedStyle.Render("  No skills deployed in " + m.skillsFolder))
+	default:
+		for i, d := range m.deployedSkills {
+			cursor := "  "
+			style := normalStyle
+			if i == m.deployedCursor {
+				cursor = "▸ "
+				style = selectedStyle
+			}
+
+			b.WriteString(cursor)
+			b.WriteString(style.Render(d.Folder))
+			if d.Version != "" {
+				b.WriteString(" ")
+				b.WriteString(versionStyle.Render("v" + strings.TrimPrefix(d.Version, "v")))
+			}
+			if !d.SourceExists {
+				b.WriteString(" ")
+				b.WriteString(errorStyle.Render("(source missing)"))
+			}
+			b.WriteString("\n")
+
+			var details []string
+			if d.Name != d.Folder {
+				details = append(details, "skill "+d.Name)
+			}
+			if d.Binary != "" {
+				details = append(details, pipeline.FormatSize(d.BinarySize))
+			} else {
+				details = append(details, "no binary")
+			}
+			if !d.DeployedAt.IsZero() {
+				details = append(details, "deployed "+d.DeployedAt.Local().Format("2006-01-02 15:04"))
+			}
+			b.WriteString("    ")
+			b.WriteString(mutedStyle.Render(strings.Join(details, " • ")))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("  " + m.skillsFolder))
 	}
 
-	b.WriteString(mutedStyle.Render("  The skill \""))
-	b.WriteString(normalStyle.Render(skillName))
-	b.WriteString(mutedStyle.Render("\" already exists at:"))
+	return m.box(b.String())
+}
+
+func (m Model) renderRemove() string {
+	var b strings.Builder
+
+	b.WriteString(errorStyle.Render("Remove deployed skill"))
+	b.WriteString("\n\n")
+	b.WriteString(mutedStyle.Render("  The following will be removed from "))
+	b.WriteString(normalStyle.Render(m.removePath))
+	b.WriteString(mutedStyle.Render(":"))
 	b.WriteString("\n")
-	b.WriteString("  ")
-	b.WriteString(normalStyle.Render(m.getDeployPath()))
+	for _, path := range m.removePaths {
+		b.WriteString(normalStyle.Render("    " + filepath.Base(path)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("  Other files in the skill folder are kept."))
 	b.WriteString("\n\n")
 
-	b.WriteString(normalStyle.Render("  Overwrite?"))
+	b.WriteString(normalStyle.Render("  Remove?"))
 	b.WriteString("\n")
-	b.WriteString(mutedStyle.Render("  [Y] Yes, overwrite  [N] Cancel"))
+	b.WriteString(mutedStyle.Render("  [Y] Yes  [N] Cancel"))
 
-	return boxStyle.Render(b.String())
+	return m.box(b.String())
+}
+
+func (m Model) renderDeploy() string {
+	var b strings.Builder
+
+	b.WriteString(inputLabelStyle.Render("Step 2: Deploy Settings"))
+	b.WriteString("\n\n")
+
+	focus := 0
+	for i, input := range m.deployInputs {
+		// Label with focus indicator
+		labelStyle := mutedStyle
+		prefix := "  "
+		if i == m.deployFocus {
+			labelStyle = inputLabelStyle
+			prefix = "▸ "
+			focus = lineCount(&b)
+		}
+
+		// Show label - all deploy fields are required
+		label := m.deployLabels[i] + " *"
+
+		b.WriteString(prefix)
+		b.WriteString(labelStyle.Render(label))
+		b.WriteString("\n")
+
+		// Input field
+		b.WriteString("  ")
+		b.WriteString(input.View())
+		b.WriteString("\n")
+
+		// Show the skills folder with ~ and $VARS expanded
+		if i == 0 {
+			if expanded := config.ExpandPath(input.Value()); expanded != input.Value() {
+				b.WriteString(mutedStyle.Render("  → " + expanded))
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(mutedStyle.Render("  * required"))
+
+	return m.box(m.window(b.String(), focus, 3))
+}
+
+// renderFolderPicker renders the directory browser for the skills folder
+func (m Model) renderFolderPicker() string {
+	var b strings.Builder
+
+	b.WriteString(inputLabelStyle.Render("Choose Skills Folder"))
+	b.WriteString("\n\n")
+	b.WriteString(mutedStyle.Render("  In: "))
+	b.WriteString(normalStyle.Render(m.pickerDir))
+	b.WriteString("\n")
+	if m.pickerNote != "" {
+		b.WriteString(errorStyle.Render("  " + m.pickerNote))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if len(m.pickerEntries) == 0 {
+		b.WriteString(mutedStyle.Render("  No subdirectories"))
+		b.WriteString("\n")
+	}
+
+	// Keep the cursor inside the visible window
+	height := 15
+	if m.height > 0 {
+		height = max(m.height-20, 5)
+	}
+	start := 0
+	if m.pickerCursor >= height {
+		start = m.pickerCursor - height + 1
+	}
+	end := min(start+height, len(m.pickerEntries))
+	for i := start; i < end; i++ {
+		if i == m.pickerCursor {
+			b.WriteString(selectedStyle.Render("  ▸ " + m.pickerEntries[i] + "/"))
+		} else {
+			b.WriteString(normalStyle.Render("    " + m.pickerEntries[i] + "/"))
+		}
+		b.WriteString("\n")
+	}
+	if len(m.pickerEntries) > height {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  %d of %d", m.pickerCursor+1, len(m.pickerEntries))))
+		b.WriteString("\n")
+	}
+
+	if m.pickerCreating {
+		b.WriteString("\n")
+		b.WriteString(inputLabelStyle.Render("  New directory"))
+		b.WriteString("\n  ")
+		b.WriteString(m.pickerInput.View())
+		b.WriteString("\n")
+	}
+
+	return m.box(b.String())
+}
+
+func (m Model) renderConfirm() string {
+	var b strings.Builder
+
+	b.WriteString(inputLabelStyle.Render("Step 3: Confirm"))
+	b.WriteString("\n\n")
+
+	// Show skill info
+	if m.selectedSkill != nil {
+		b.WriteString(m.detail("  Skill:         ", normalStyle.Render(m.selectedSkill.Name)))
+		b.WriteString("\n")
+
+		// Show all configured values
+		if len(m.selectedSkill.Variables) > 0 {
+			b.WriteString(mutedStyle.Render("  Environment:"))
+			b.WriteString("\n")
+			for _, v := range m.selectedSkill.Variables {
+				value := m.configValues[v.Name]
+				if v.Type == "secret" && len(value) > 8 {
+					value = value[:8] + "..."
+				}
+				b.WriteString(m.detail(fmt.Sprintf("    %-12s ", v.Label+":"), normalStyle.Render(value)))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	// Deploy configuration
+	b.WriteString(mutedStyle.Render("  Deploy:"))
+	b.WriteString("\n")
+	b.WriteString(m.detail("    Skills Folder: ", normalStyle.Render(m.skillsFolder)))
+	b.WriteString(m.detail("    Skill Name:    ", normalStyle.Render(m.skillFolderName)))
+	b.WriteString(m.detail("    Target:        ", successStyle.Render(m.getDeployPath())))
+	if m.selectedSkill != nil {
+		b.WriteString(m.detail("    Binary:        ", normalStyle.Render(filepath.Join("bin", m.selectedSkill.BinaryName()))))
+	}
+	b.WriteString(m.detail("    Profile:       ", normalStyle.Render(m.profileName)))
+	if m.selectedSkill != nil && m.hasSecrets() {
+		storage := ".env file"
+		if m.useKeychain() {
+			storage = "OS keychain"
+		}
+		b.WriteString(m.detail("    Secrets:       ", normalStyle.Render(storage)+mutedStyle.Render("  [K] Toggle")))
+	}
+	envMode := "plaintext"
+	if m.encryptEnv() {
+		envMode = "encrypted (.env.enc)"
+	}
+	b.WriteString(m.detail("    .env:          ", normalStyle.Render(envMode)+mutedStyle.Render("  [E] Toggle")))
+	if m.selectedSkill != nil {
+		tests := "off"
+		if m.runTests() {
+			tests = "go test ./... before build"
+		}
+		source := mutedStyle.Render("  [T] Toggle")
+		if m.selectedSkill.Build.Test {
+			source = mutedStyle.Render("  (skill.yaml)")
+		}
+		b.WriteString(m.detail("    Tests:         ", normalStyle.Render(tests)+source))
+	}
+	if m.gitInfo.Commit != "" {
+		b.WriteString(m.detail("    Git:           ", normalStyle.Render(m.gitInfo.String())))
+	}
+	b.WriteString("\n")
+	b.WriteString(m.renderEnvPreview())
+
+	if m.gitInfo.Dirty {
+		b.WriteString(errorStyle.Render("  ⚠ " + m.wrapIndent("The skill source has uncommitted changes.", 4)))
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("    " + m.wrapIndent("The deployment cannot be reproduced from the recorded commit.", 4)))
+		b.WriteString("\n\n")
+	}
+
+	// The question stays visible when the summary is cropped
+	question := lineCount(&b)
+	b.WriteString(normalStyle.Render("  Build and deploy this skill?"))
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("  [Y] Yes  [N] Back"))
+
+	return m.box(m.window(b.String(), question, 1))
+}
+
+// renderEnvPreview renders the .env the deploy writes, collapsed to one line
+// unless expanded with V, and the variables left empty
+func (m Model) renderEnvPreview() string {
+	if m.selectedSkill == nil {
+		return ""
+	}
+	var b strings.Builder
+
+	name := ".env"
+	if m.encryptEnv() {
+		name = ".env.enc"
+	}
+	if !m.showEnvPreview {
+		b.WriteString(mutedStyle.Render("  " + name + " preview  [V] Show"))
+		b.WriteString("\n")
+	} else {
+		secrets := "[R] Reveal secrets"
+		if m.revealEnv {
+			secrets = "[R] Mask secrets"
+		}
+		b.WriteString(mutedStyle.Render("  " + name + " preview  [V] Hide  " + secrets))
+		b.WriteString("\n")
+		content := pipeline.PreviewEnvFile(m.deployOptions(), m.revealEnv)
+		for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+			style := normalStyle
+			if strings.HasPrefix(line, "#") {
+				style = mutedStyle
+			}
+			b.WriteString(style.Render("    " + line))
+			b.WriteString("\n")
+		}
+	}
+
+	// Empty variables are not written to the .env
+	for _, v := range m.selectedSkill.Variables {
+		if m.configValues[v.Name] != "" {
+			continue
+		}
+		switch {
+		case v.FromCommand():
+			if m.showEnvPreview {
+				b.WriteString(mutedStyle.Render("    " + m.wrapIndent(v.Name+" is set from `"+v.Command+"` at deploy", 4)))
+				b.WriteString("\n")
+			}
+		case v.Required:
+			b.WriteString(errorStyle.Render("  ⚠ " + m.wrapIndent(v.Name+" is empty but required", 4)))
+			b.WriteString("\n")
+		default:
+			b.WriteString(mutedStyle.Render("  " + m.wrapIndent(v.Name+" is empty and not written", 2)))
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (m Model) renderBuilding() string {
+	var b strings.Builder
+
+	b.WriteString(inputLabelStyle.Render("Building..."))
+	b.WriteString("\n\n")
+
+	skillName := "skill"
+	if m.selectedSkill != nil {
+		skillName = m.selectedSkill.Name
+	}
+	b.WriteString("  " + m.spinner.View() + " ")
+	if m.buildStage != "" {
+		b.WriteString(mutedStyle.Render(m.buildStage + " (" + skillName + ")..."))
+	} else {
+		b.WriteString(mutedStyle.Render("Compiling " + skillName + "..."))
+	}
+	if len(m.deploySteps) > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(m.renderDeploySteps())
+	}
+
+	// Output streamed by the hooks and go build
+	if strings.TrimSpace(m.outputView.View()) != "" {
+		b.WriteString("\n\n")
+		b.WriteString(m.renderOutput())
+	}
+
+	return m.box(b.String())
+}
+
+// renderQueue shows the skills of a multi-skill deploy with their state and
+// elapsed time
+func (m Model) renderQueue() string {
+	var b strings.Builder
+
+	counts := make(map[string]int)
+	for _, item := range m.queue {
+		counts[item.state]++
+	}
+	finished := counts[queueDone] + counts[queueFailed] + counts[queueCanceled]
+	b.WriteString(inputLabelStyle.Render(fmt.Sprintf("Deploying bundle %s (%d/%d)", m.queueBundle, finished, len(m.queue))))
+	b.WriteString("\n\n")
+
+	nameWidth := 0
+	for _, item := range m.queue {
+		nameWidth = max(nameWidth, len(item.name))
+	}
+	for _, item := range m.queue {
+		icon, style := "○", mutedStyle
+		switch item.state {
+		case queueBuilding, queueDeploying:
+			icon, style = m.spinner.View(), normalStyle
+		case queueDone:
+			icon, style = successStyle.Render("✓"), successStyle
+		case queueFailed:
+			icon, style = errorStyle.Render("✗"), errorStyle
+		case queueCanceled:
+			icon = "–"
+		}
+		elapsed := ""
+		switch {
+		case !item.finished.IsZero():
+			elapsed = pipeline.FormatDuration(item.finished.Sub(item.started))
+		case !item.started.IsZero():
+			elapsed = pipeline.FormatDuration(time.Since(item.started).Truncate(time.Second))
+		}
+		b.WriteString(fmt.Sprintf("  %s %s  %s  %s\n",
+			icon,
+			normalStyle.Render(fmt.Sprintf("%-*s", nameWidth, item.name)),
+			style.Render(fmt.Sprintf("%-9s", item.state)),
+			mutedStyle.Render(elapsed)))
+
+		if item.err != nil {
+			b.WriteString("    ")
+			b.WriteString(m.wrapIndent(errorStyle.Render(item.err.Error()), 4))
+			b.WriteString("\n")
+			// The end of the output usually holds the cause
+			lines := strings.Split(strings.TrimRight(item.output, "\n"), "\n")
+			for _, line := range lines[max(len(lines)-5, 0):] {
+				if line != "" {
+					b.WriteString(mutedStyle.Render(m.fitLine("      " + line)))
+					b.WriteString("\n")
+				}
+			}
+		}
+	}
+
+	if !m.queueRunning() {
+		b.WriteString("\n")
+		summary := fmt.Sprintf("%d deployed, %d failed", counts[queueDone], counts[queueFailed])
+		if counts[queueCanceled] > 0 {
+			summary += fmt.Sprintf(", %d canceled", counts[queueCanceled])
+		}
+		if counts[queueFailed] > 0 {
+			b.WriteString(errorStyle.Render("✗ " + summary))
+		} else {
+			b.WriteString(successStyle.Render("✓ " + summary))
+		}
+	}
+
+	return m.box(b.String())
+}
+
+func (m Model) renderDone() string {
+	var b strings.Builder
+
+	if m.statusMsg != "" {
+		b.WriteString(successStyle.Render("✓ " + m.statusMsg))
+		b.WriteString("\n\n")
+
+		b.WriteString(mutedStyle.Render("  Deployed to: "))
+		b.WriteString(normalStyle.Render(m.getDeployPath()))
+		b.WriteString("\n")
+		if m.buildTrend != "" {
+			b.WriteString(mutedStyle.Render("  Build time:  "))
+			b.WriteString(normalStyle.Render(m.buildTrend))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+
+		b.WriteString(mutedStyle.Render("  The skill is now ready to use!"))
+
+		if len(m.deploySteps) > 0 {
+			b.WriteString("\n\n")
+			b.WriteString(inputLabelStyle.Render("  Deploy Steps"))
+			b.WriteString("\n")
+			b.WriteString(m.renderDeploySteps())
+		}
+
+		// Show the full output of the hooks and go build
+		if m.buildOutput != "" {
+			b.WriteString("\n\n")
+			b.WriteString(inputLabelStyle.Render("  Build Log"))
+			b.WriteString("\n")
+			b.WriteString(m.renderOutput())
+		}
+	} else if m.errorMsg != "" {
+		if failed := pipeline.FailedStep(m.deploySteps); failed != nil {
+			b.WriteString(errorStyle.Render("✗ Deploy failed: " + failed.Name))
+			b.WriteString("\n\n")
+			b.WriteString(m.renderDeploySteps())
+			b.WriteString("\n\n")
+		} else {
+			b.WriteString(errorStyle.Render("✗ Build/Deploy failed"))
+			b.WriteString("\n\n")
+		}
+		b.WriteString(m.renderDiagnoses())
+		if len(m.problems) > 0 && !m.showOutput {
+			b.WriteString(m.renderProblems())
+		} else if m.buildOutput != "" {
+			b.WriteString(inputLabelStyle.Render("  Output"))
+			b.WriteString("\n")
+			b.WriteString(m.renderOutput())
+		}
+	}
+
+	if m.logNote != "" {
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("  " + m.logNote))
+	}
+
+	b.WriteString(m.renderVulnReport())
+	b.WriteString(m.renderHealthcheck())
+
+	return m.box(b.String())
+}
+
+// renderDeploySteps lists the steps of the deploy with their state and
+// duration, the error of a failed step below it
+func (m Model) renderDeploySteps() string {
+	var lines []string
+	for _, step := range m.deploySteps {
+		icon, style := "○", mutedStyle
+		switch step.State {
+		case pipeline.StepRunning:
+			icon, style = m.spinner.View(), normalStyle
+		case pipeline.StepDone:
+			icon, style = successStyle.Render("✓"), normalStyle
+		case pipeline.StepFailed:
+			icon, style = errorStyle.Render("✗"), errorStyle
+		}
+		line := "  " + icon + " " + style.Render(step.Name)
+		if step.State == pipeline.StepDone || step.State == pipeline.StepFailed {
+			line += mutedStyle.Render("  " + pipeline.FormatDuration(step.Duration))
+		}
+		lines = append(lines, line)
+		if step.Err != nil {
+			lines = append(lines, errorStyle.Render(m.fitLine("    "+step.Err.Error())))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderDiagnoses renders the recognized causes of a failed build with
+// their remedies
+func (m Model) renderDiagnoses() string {
+	if len(m.diagnoses) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(inputLabelStyle.Render("  Suggestions"))
+	b.WriteString("\n")
+	for _, d := range m.diagnoses {
+		b.WriteString(normalStyle.Render("  • " + d.Cause))
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("    " + d.Advice))
+		b.WriteString("\n")
+	}
+	if fix := m.buildFix(); fix != nil {
+		b.WriteString(mutedStyle.Render("  [F] Run " + fix.FixCommand() + " and build again"))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// renderOutput renders the scrollable build output of the Building and Done views
+func (m Model) renderOutput() string {
+	out := mutedStyle.Render(indent(m.outputView.View(), "  "))
+	if m.outputView.TotalLineCount() > m.outputView.VisibleLineCount() {
+		out += "\n" + mutedStyle.Render(fmt.Sprintf("  ↑/↓ to scroll (%3.f%%)", m.outputView.ScrollPercent()*100))
+	}
+	return out
+}
+
+// renderProblems renders the compiler errors of a failed build as a list
+func (m Model) renderProblems() string {
+	var b strings.Builder
+
+	b.WriteString(inputLabelStyle.Render(fmt.Sprintf("  Problems (%d)", len(m.problems))))
+	b.WriteString("\n")
+
+	// Keep the cursor inside the visible window
+	height := 15
+	if m.height > 0 {
+		height = max(m.height-22, 5)
+	}
+	start := 0
+	if m.problemCursor >= height {
+		start = m.problemCursor - height + 1
+	}
+	end := min(start+height, len(m.problems))
+
+	width := m.outputWidth()
+	for i := start; i < end; i++ {
+		p := m.problems[i]
+		location := p.Location(m.selectedSkill.Path)
+		message, _, _ := strings.Cut(p.Message, "\n")
+		if len(location)+len(message)+6 > width {
+			message = truncate(message, max(width-len(location)-6, 10))
+		}
+
+		if i == m.problemCursor {
+			b.WriteString(selectedStyle.Render("  ▸ " + location))
+			b.WriteString("  " + normalStyle.Render(message))
+		} else {
+			b.WriteString(mutedStyle.Render("    " + location))
+			b.WriteString("  " + mutedStyle.Render(message))
+		}
+		b.WriteString("\n")
+	}
+
+	// Show follow-up lines (e.g. have/want) of the selected problem
+	if _, details, ok := strings.Cut(m.problems[m.problemCursor].Message, "\n"); ok {
+		b.WriteString(mutedStyle.Render(indent(details, "      ")))
+		b.WriteString("\n")
+	}
+	if len(m.problems) > height {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("    %d/%d", m.problemCursor+1, len(m.problems))))
+		b.WriteString("\n")
+	}
+
+	if m.editorErr != "" {
+		b.WriteString(errorStyle.Render("  " + m.editorErr))
+		b.WriteString("\n")
+	}
+	b.WriteString(mutedStyle.Render("  [O] Open in editor  [V] Show output"))
+	return b.String()
+}
+
+// truncate shortens s to at most n runes, ending with "..."
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-3]) + "..."
+}
+
+// indent prefixes every line of s
+func indent(s string, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
+
+// renderVersionStatus renders a badge comparing deployed and source version
+func renderVersionStatus(source, deployed string) string {
+	status := skill.VersionStatus(source, deployed)
+	switch status {
+	case "up to date":
+		return successStyle.Render("(" + status + ")")
+	case "outdated":
+		return errorStyle.Render("(deployed " + deployed + ", " + status + ")")
+	case "newer":
+		return normalStyle.Render("(deployed " + deployed + " is newer)")
+	default:
+		return mutedStyle.Render("(deployed " + deployed + ")")
+	}
+}
+
+// renderVulnReport renders the govulncheck result for the Done view
+func (m Model) renderVulnReport() string {
+	if m.vulnResult == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n\n")
+	b.WriteString(inputLabelStyle.Render("  Vulnerability Scan"))
+	b.WriteString("\n")
+
+	switch {
+	case !m.vulnResult.Ran:
+		b.WriteString(mutedStyle.Render("  Skipped: " + m.vulnResult.Output))
+	case m.vulnResult.Vulnerable:
+		b.WriteString(errorStyle.Render(fmt.Sprintf("  ⚠ %d known vulnerabilities: %s", len(m.vulnResult.IDs), strings.Join(m.vulnResult.IDs, ", "))))
+	default:
+		b.WriteString(successStyle.Render("  ✓ No known vulnerabilities"))
+	}
+
+	return b.String()
+}
+
+// healthcheckLines is the output of the healthcheck shown in the Done view
+const healthcheckLines = 12
+
+// renderHealthcheck shows the result of the healthcheck command after a deploy
+func (m Model) renderHealthcheck() string {
+	if m.healthcheck == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n\n")
+	b.WriteString(inputLabelStyle.Render("  Healthcheck"))
+	b.WriteString(mutedStyle.Render(" " + m.healthcheck.Command))
+	b.WriteString("\n")
+
+	if m.healthcheck.Err != nil {
+		b.WriteString(errorStyle.Render(m.fitLine("  ✗ " + m.healthcheck.Err.Error())))
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("  Check the configuration, e.g. the API URL and credentials"))
+		return b.String()
+	}
+	b.WriteString(successStyle.Render("  ✓ OK"))
+	lines := strings.Split(m.healthcheck.Output, "\n")
+	if m.healthcheck.Output != "" {
+		b.WriteString("\n")
+		shown := lines[:min(len(lines), healthcheckLines)]
+		b.WriteString(mutedStyle.Render(indent(strings.Join(shown, "\n"), "  ")))
+	}
+	if len(lines) > healthcheckLines {
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  … %d more lines", len(lines)-healthcheckLines)))
+	}
+	return b.String()
+}
+
+func (m Model) renderOverwrite() string {
+	var b strings.Builder
+
+	b.WriteString(errorStyle.Render("⚠️  Skill already exists"))
+	b.WriteString("\n\n")
+
+	skillName := "skill"
+	if m.selectedSkill != nil {
+		skillName = m.selectedSkill.Name
+	}
+
+	b.WriteString(mutedStyle.Render("  The skill \""))
+	b.WriteString(normalStyle.Render(skillName))
+	b.WriteString(mutedStyle.Render("\" already exists at:"))
+	b.WriteString("\n")
+	b.WriteString("  ")
+	b.WriteString(normalStyle.Render(m.getDeployPath()))
+	b.WriteString("\n\n")
+
+	if m.checkingDeploy {
+		b.WriteString(mutedStyle.Render("  Comparing with the deployed skill..."))
+		b.WriteString("\n\n")
+		b.WriteString(mutedStyle.Render("  [N] Cancel"))
+		return m.box(b.String())
+	}
+
+	// Compare deployed and source version
+	if m.selectedSkill != nil {
+		deployed := m.deployedVersion
+		if deployed == "" {
+			deployed = "unknown"
+		}
+		b.WriteString(mutedStyle.Render("  Version: deployed "))
+		b.WriteString(normalStyle.Render(deployed))
+		b.WriteString(mutedStyle.Render(" → source "))
+		b.WriteString(normalStyle.Render(m.selectedSkill.Version))
+		// An unknown deployed version cannot be compared
+		if m.deployedVersion != "" {
+			b.WriteString(" ")
+			b.WriteString(renderVersionStatus(m.selectedSkill.Version, m.deployedVersion))
+		}
+		b.WriteString("\n")
+	}
+
+	// Compare with deploy.lock of the existing deployment
+	switch {
+	case m.deployedLock == nil:
+		b.WriteString(mutedStyle.Render("  No " + pipeline.LockFile + " found, cannot compare with deployed build"))
+	case m.deploymentUnchanged():
+		b.WriteString(successStyle.Render("  ✓ Deployed build is identical (built " + m.deployedLock.BuiltAt.Local().Format("2006-01-02 15:04") + ")"))
+	default:
+		b.WriteString(normalStyle.Render("  Changed since last deploy: " + strings.Join(m.lockChanges, ", ")))
+	}
+	b.WriteString("\n\n")
+
+	if m.platformWarning != "" {
+		b.WriteString(errorStyle.Render("  ⚠ " + m.platformWarning))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(m.renderDeployDiffs())
+
+	if m.docsEdited {
+		b.WriteString(errorStyle.Render("  SKILL.md was edited by hand since the last deploy"))
+		b.WriteString("\n\n")
+		b.WriteString(normalStyle.Render("  Overwrite?"))
+		b.WriteString("\n")
+		if m.configOnlyChange() {
+			b.WriteString(mutedStyle.Render("  [Y] Overwrite edits  [K] Keep edited SKILL.md  [M] Merge edits  [C] Config only  [D] Docs only  [N] Cancel"))
+		} else {
+			b.WriteString(mutedStyle.Render("  [Y] Overwrite edits  [K] Keep edited SKILL.md  [M] Merge edits  [D] Docs only  [N] Cancel"))
+		}
+		return m.box(b.String())
+	}
+
+	b.WriteString(normalStyle.Render("  Overwrite?"))
+	b.WriteString("\n")
+	if m.deploymentUnchanged() {
+		b.WriteString(mutedStyle.Render("  [Y] Yes, overwrite  [S] Skip (unchanged)  [D] Docs only  [N] Cancel"))
+	} else if m.configOnlyChange() {
+		b.WriteString(mutedStyle.Render("  [Y] Yes, rebuild and overwrite  [C] Config only (keep binary)  [D] Docs only  [N] Cancel"))
+	} else {
+		b.WriteString(mutedStyle.Render("  [Y] Yes, overwrite  [D] Docs only  [N] Cancel"))
+	}
+
+	return m.box(b.String())
+}
+
+// renderDeployDiffs summarizes the changes of the deployed files and shows
+// their diff in the scrollable viewport
+func (m Model) renderDeployDiffs() string {
+	if len(m.deployDiffs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(normalStyle.Render("  Files:"))
+	b.WriteString("\n")
+	for _, d := range m.deployDiffs {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("    %-10s ", d.Name)))
+		switch {
+		case d.Note != "":
+			b.WriteString(mutedStyle.Render(d.Note))
+		case d.Diff == "":
+			b.WriteString(mutedStyle.Render("unchanged"))
+		default:
+			b.WriteString(successStyle.Render(fmt.Sprintf("+%d", d.Added)))
+			b.WriteString(" ")
+			b.WriteString(errorStyle.Render(fmt.Sprintf("-%d", d.Removed)))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if m.outputView.TotalLineCount() > 0 && strings.TrimSpace(m.outputView.View()) != "" {
+		b.WriteString(indent(m.outputView.View(), "  "))
+		b.WriteString("\n")
+		if m.outputView.TotalLineCount() > m.outputView.VisibleLineCount() {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("  ↑/↓ to scroll (%3.f%%)", m.outputView.ScrollPercent()*100)))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// colorDiff colors the added and removed lines of a unified diff
+func colorDiff(diff string) string {
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "@@"):
+			lines[i] = subtitleStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = successStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = errorStyle.Render(line)
+		default:
+			lines[i] = mutedStyle.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func countFixable(issues []skill.Issue) int {
+	n := 0
+	for _, issue := range issues {
+		if issue.Fixable {
+			n++
+		}
+	}
+	return n
+}
+
+func (m Model) renderErrorDetail() string {
+	var b strings.Builder
+	skillErr := m.selectedError
+
+	b.WriteString(inputLabelStyle.Render("Skill Error: " + skillErr.Name))
+	b.WriteString("\n")
+	location := skillErr.File()
+	if line := skillErr.Line(); line > 0 {
+		location += fmt.Sprintf(":%d", line)
+	}
+	b.WriteString(mutedStyle.Render("  " + location))
+	b.WriteString("\n\n")
+
+	b.WriteString(errorStyle.Render("  " + string(skillErr.Kind)))
+	b.WriteString("\n")
+	b.WriteString("  ")
+	b.WriteString(m.wrapIndent(skillErr.Error.Error(), 2))
+
+	if len(skillErr.Issues) > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  %d issues:", len(skillErr.Issues))))
+		for _, issue := range skillErr.Issues {
+			b.WriteString("\n")
+			b.WriteString("    • ")
+			b.WriteString(m.wrapIndent(issue.String(), 6))
+			if issue.Fixable {
+				b.WriteString(mutedStyle.Render(" (quick fix)"))
+			}
+		}
+	}
+
+	b.WriteString("\n\n")
+	b.WriteString(mutedStyle.Render("  Fix skill.yaml in your editor, then reload the skills."))
+	if m.statusMsg != "" {
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("  " + m.statusMsg))
+	}
+	if m.editorErr != "" {
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render("  " + m.editorErr))
+	}
+
+	return m.box(b.String())
+}
+
+func (m Model) renderQuickFix() string {
+	var b strings.Builder
+
+	b.WriteString(inputLabelStyle.Render("Quick Fix: " + m.selectedError.Name))
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("  " + filepath.Join(m.selectedError.Path, "skill.yaml")))
+	b.WriteString("\n\n")
+
+	issue := m.fixIssues[m.fixIndex]
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("  Issue %d of %d", m.fixIndex+1, len(m.fixIssues))))
+	b.WriteString("\n")
+	b.WriteString(errorStyle.Render("  " + issue.String()))
+	b.WriteString("\n\n")
+
+	b.WriteString(inputLabelStyle.Render("▸ New value for " + issue.Field))
+	b.WriteString("\n")
+	b.WriteString("  ")
+	b.WriteString(m.fixInput.View())
+
+	// Issues that need manual editing
+	var manual []string
+	for _, i := range m.selectedError.Issues {
+		if !i.Fixable {
+			manual = append(manual, i.String())
+		}
+	}
+	if len(manual) > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(mutedStyle.Render("  Fix manually:"))
+		for _, i := range manual {
+			b.WriteString("\n")
+			b.WriteString(mutedStyle.Render("    " + i))
+		}
+	}
+
+	return m.box(b.String())
+}
+
+func (m Model) renderEditManifest() string {
+	var b strings.Builder
+
+	b.WriteString(inputLabelStyle.Render("Edit Manifest: " + m.editSkill.Name))
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("  " + filepath.Join(m.editSkill.Path, "skill.yaml")))
+	b.WriteString("\n\n")
+
+	// field renders a labeled single-line input
+	focus := 0
+	field := func(i int) {
+		f := m.editFields[i]
+		labelStyle, prefix := mutedStyle, "  "
+		if i == m.editFocus {
+			labelStyle, prefix = inputLabelStyle, "▸ "
+			focus = lineCount(&b)
+		}
+		b.WriteString(prefix)
+		b.WriteString(labelStyle.Render(fmt.Sprintf("%-12s ", f.label)))
+		b.WriteString(f.input.View())
+		b.WriteString("\n")
+	}
+
+	i := 0
+	for ; i < editSkillFields; i++ {
+		field(i)
+	}
+
+	// Variables table, one row per variable
+	b.WriteString("\n")
+	b.WriteString(inputLabelStyle.Render("  Variables"))
+	b.WriteString("\n")
+	if len(m.edit.Variables) == 0 {
+		b.WriteString(mutedStyle.Render("    No variables (Ctrl+N to add one)"))
+		b.WriteString("\n")
+	} else {
+		var header strings.Builder
+		for _, col := range editVariableColumns {
+			header.WriteString(fmt.Sprintf("%-*s", col.width, col.label))
+		}
+		b.WriteString(mutedStyle.Render("    " + header.String()))
+		b.WriteString("\n")
+	}
+	for row := 0; row < len(m.edit.Variables); row++ {
+		prefix := "    "
+		if m.editFields[m.editFocus].variable == row {
+			prefix = "  ▸ "
+			focus = lineCount(&b)
+		}
+		b.WriteString(prefix)
+		for _, col := range editVariableColumns {
+			f := m.editFields[i]
+			cell := f.input.View()
+			if i != m.editFocus {
+				cell = mutedStyle.Render(truncate(f.input.Value(), col.width-2))
+			}
+			b.WriteString(cell + strings.Repeat(" ", max(col.width-lipgloss.Width(cell), 1)))
+			i++
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(inputLabelStyle.Render("  Build"))
+	b.WriteString("\n")
+	for ; i < len(m.editFields); i++ {
+		field(i)
+	}
+
+	if m.errorMsg != "" {
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render("  " + m.errorMsg))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("  Comments and other fields in skill.yaml are kept. Empty values remove the field."))
+
+	return m.box(m.window(b.String(), focus, 0))
 }
 
 func (m Model) renderHelp() string {
+	scope := m.keyScope()
+	k := func(actions ...string) string {
+		return m.keys.hint(scope, actions...)
+	}
+	label := func(action string) string {
+		return m.keys.label(scope, action)
+	}
 	var help string
 
 	switch m.currentView {
 	case ViewSkillList:
-		help = "↑/↓: Navigate • Enter: Select • q: Quit"
+		help = "↑/↓: Navigate • " + k("select", "filter", "edit", "bundles", "settings", "deployed", "remove", "quit")
+		if m.filtering {
+			help = "Type to filter • ↑/↓: Navigate • " + k("apply", "clear")
+		} else if m.skillFilter.Value() != "" {
+			help = "↑/↓: Navigate • " + k("select") + " • " + label("filter") + ": Edit filter • " + k("clear", "edit", "quit")
+		}
 	case ViewConfig:
-		help = "↑/↓/Tab: Navigate • Enter: Next Step • Esc: Back"
+		help = "↑/↓/Tab: Navigate • " + k("continue", "back")
+		if m.isSecretInput(m.configFocus) {
+			help = k("reveal", "paste") + " • ↑/↓/Tab: Navigate • " + k("continue", "back")
+		} else if m.hasChoices(m.configFocus) {
+			help = k("choices") + " • ↑/↓/Tab: Navigate • " + k("continue", "back")
+		}
+		if m.choices != nil {
+			help = "↑/↓: Select • " + k("choose", "close")
+		}
 	case ViewDeploy:
-		help = "↑/↓/Tab: Navigate • Enter: Next Step • Esc: Back"
+		help = "↑/↓/Tab: Navigate • " + k("continue", "back")
+		if m.deployFocus == 0 {
+			help = label("next") + ": Complete path • " + k("browse") + " • ↑/↓: Navigate • " + k("continue", "back")
+		}
+	case ViewFolderPicker:
+		help = "↑/↓: Select • " + k("open", "parent", "use", "new", "home", "back")
+		if m.pickerCreating {
+			help = k("create", "cancel")
+		}
 	case ViewConfirm:
-		help = "Y/Enter: Build & Deploy • N/Esc: Back"
+		help = k("deploy", "keychain", "encrypt", "tests", "env", "back")
+		if m.showEnvPreview {
+			help = k("deploy", "keychain", "encrypt", "tests", "env", "reveal", "back")
+		}
 	case ViewOverwrite:
-		help = "Y: Overwrite • N/Esc: Cancel"
+		help = k("overwrite") + " • " + label("docs") + ": Regenerate SKILL.md only • ↑/↓: Scroll diff • " + k("cancel")
+		if m.docsEdited {
+			help = k("overwrite", "keep", "merge", "docs") + " • ↑/↓: Scroll diff • " + k("cancel")
+			if m.configOnlyChange() {
+				help = k("overwrite", "keep", "merge", "config", "docs") + " • ↑/↓: Scroll diff • " + k("cancel")
+			}
+		} else if m.deploymentUnchanged() {
+			help = k("overwrite", "skip", "docs") + " • ↑/↓: Scroll diff • " + k("cancel")
+		} else if m.configOnlyChange() {
+			help = k("overwrite", "config", "docs") + " • ↑/↓: Scroll diff • " + k("cancel")
+		}
 	case ViewBuilding:
-		help = "Building..."
+		help = "Building... • ↑/↓: Scroll output"
+		if m.building {
+			help += " • " + k("cancel")
+		}
 	case ViewDone:
-		help = "Enter/q: Quit • R: Configure another skill"
+		help = k("quit", "restart") + " • ↑/↓: Scroll output"
+		if len(m.problems) > 0 && !m.showOutput {
+			help = "↑/↓: Select problem • " + k("open", "output", "quit", "restart")
+		} else if len(m.problems) > 0 {
+			help = "↑/↓: Scroll output • " + label("output") + ": Show problems • " + k("quit", "restart")
+		}
+		if m.buildOutput != "" {
+			help += " • " + k("copy", "save")
+		}
+		if m.buildFix() != nil {
+			help = k("fix") + " • " + help
+		}
+	case ViewErrorDetail:
+		help = k("open", "reload", "back", "quit")
+		if countFixable(m.selectedError.Issues) > 0 {
+			help = k("fix") + " • " + help
+		}
+	case ViewQuickFix:
+		help = k("apply", "skip", "back")
+	case ViewEditManifest:
+		help = "Tab/↑/↓: Navigate • " + k("add", "delete", "save", "cancel")
+	case ViewProfile:
+		help = "↑/↓: Navigate • " + k("select", "back")
+	case ViewRemove:
+		help = k("remove", "cancel")
+	case ViewDeployed:
+		help = "↑/↓: Navigate • " + k("search", "edit", "skills", "remove", "quit")
+	case ViewSearch:
+		help = "Type to search • ↑/↓: Navigate • " + k("show", "back")
+	case ViewBundles:
+		help = "↑/↓: Navigate • " + k("edit", "deploy", "back", "quit")
+	case ViewQueue:
+		if m.queueRunning() {
+			help = k("cancel")
+		} else {
+			help = k("back", "quit")
+		}
+	case ViewBundleEdit:
+		help = "Tab/↑/↓: Navigate • " + k("toggle", "save", "cancel")
+	case ViewSettings:
+		help = "Tab/↑/↓: Navigate • " + k("toggle", "save", "cancel")
+	}
+	help += " • " + m.keys.helpKey(scope) + ": Help"
+
+	return helpStyle.Render(wrapHints(help, m.width))
+}
+
+// renderKeyHelp renders the help overlay with all key bindings of the
+// current view
+func (m Model) renderKeyHelp() string {
+	scope := m.keyScope()
+	h := help.New()
+	h.FullSeparator = "     "
+	h.Styles.FullKey = inputLabelStyle
+	h.Styles.FullDesc = normalStyle
+	h.Styles.FullSeparator = mutedStyle
+
+	var b strings.Builder
+	b.WriteString(inputLabelStyle.Render("Keys: " + m.keys.scopes[scope].title))
+	b.WriteString("\n\n")
+	rows := 6
+	if m.compact() {
+		// One column, side by side they would not fit
+		rows = len(m.keys.scopes[scope].defs)
 	}
+	b.WriteString(h.FullHelpView(m.keys.columns(scope, rows)))
+	b.WriteString("\n\n")
+	b.WriteString(mutedStyle.Render(m.keys.hint(globalScope, "help") + " • Ctrl+C: Quit"))
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("Change a binding in the config file: keys: {" + scope + ".<action>: <keys>}"))
 
-	return helpStyle.Render(help)
+	return m.box(b.String())
 }
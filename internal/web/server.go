@@ -0,0 +1,486 @@
+// Package web serves the local web UI of `skillfactory serve`: the skills
+// with their deployed versions, profile editing, deploys and the event log,
+// as a JSON API under /api and a single page using it. The API is meant for
+// automation as well, e.g. other tools or Claude triggering and watching
+// deployments with the access token.
+package web
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/petervogelmann/skillfactory/internal/config"
+	"github.com/petervogelmann/skillfactory/internal/pipeline"
+	"github.com/petervogelmann/skillfactory/internal/skill"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// TokenCookie holds the access token in the browser after the first visit
+// with ?token=
+const TokenCookie = "skillfactory_token"
+
+// maxHistory is the number of events returned by /api/history
+const maxHistory = 50
+
+// maxWait limits the ?wait= of /api/deployments/{id}
+const maxWait = 10 * time.Minute
+
+// DeployFunc builds and deploys a skill with a saved profile, writing the
+// progress to out. docsMode handles a hand-edited SKILL.md, see
+// pipeline.DocsModes.
+type DeployFunc func(out io.Writer, manifest *skill.Manifest, profile, docsMode string) error
+
+// Options configure a Server
+type Options struct {
+	ProjectRoot  string
+	SkillsFolder string // Skills folder for the deployed versions, may be empty
+	Token        string // Access token required by every request
+	Deploy       DeployFunc
+}
+
+// Server is the web UI. One deploy runs at a time.
+type Server struct {
+	opts Options
+
+	mu          sync.Mutex
+	deployments []*deployment // Deploys started by this server, the ID is the index + 1
+}
+
+// New creates a Server
+func New(opts Options) *Server {
+	return &Server{opts: opts}
+}
+
+// Handler returns the handler of the UI and the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /api/skills", s.handleSkills)
+	mux.HandleFunc("GET /api/skills/{name}", s.handleSkill)
+	mux.HandleFunc("GET /api/skills/{name}/profiles/{profile}", s.handleProfile)
+	mux.HandleFunc("PUT /api/skills/{name}/profiles/{profile}", s.handleSaveProfile)
+	mux.HandleFunc("POST /api/deploy", s.handleDeploy)
+	mux.HandleFunc("GET /api/deployments", s.handleDeployments)
+	mux.HandleFunc("GET /api/deployments/{id}", s.handleDeployment)
+	mux.HandleFunc("GET /api/history", s.handleHistory)
+	return s.authorize(mux)
+}
+
+// authorize requires the access token as bearer token or cookie. Opening
+// /?token=... sets the cookie, so the printed URL logs the browser in.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("token"); token != "" && r.URL.Path == "/" && s.validToken(token) {
+			http.SetCookie(w, &http.Cookie{
+				Name:     TokenCookie,
+				Value:    token,
+				Path:     "/",
+				HttpOnly: true,
+				SameSite: http.SameSiteStrictMode,
+			})
+			http.Redirect(w, r, "/", http.StatusSeeOther)
+			return
+		}
+
+		token := ""
+		if auth := r.Header.Get("Authorization"); len(auth) > 7 && auth[:7] == "Bearer " {
+			token = auth[7:]
+		} else if cookie, err := r.Cookie(TokenCookie); err == nil {
+			token = cookie.Value
+		}
+		if !s.validToken(token) {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token, open the URL printed by skillfactory serve"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validToken compares token with the access token in constant time
+func (s *Server) validToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) == 1
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(indexHTML)
+}
+
+// skillInfo is a skill of /api/skills and /api/skills/{name}
+type skillInfo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Version     string         `json:"version,omitempty"`
+	Deployed    string         `json:"deployed,omitempty"` // Deployed version
+	Status      string         `json:"status,omitempty"`   // See skill.VersionStatus
+	Variables   []variableInfo `json:"variables,omitempty"`
+	Profiles    []string       `json:"profiles,omitempty"`
+	Error       string         `json:"error,omitempty"` // Why the skill failed to load
+}
+
+// variableInfo is a variable of a skill
+type variableInfo struct {
+	Name        string `json:"name"`
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Placeholder string `json:"placeholder,omitempty"`
+	Command     string `json:"command,omitempty"` // Set from this command at deploy if empty
+}
+
+func (s *Server) handleSkills(w http.ResponseWriter, r *http.Request) {
+	manifests, skillErrors, err := skill.DiscoverSkills(s.opts.ProjectRoot)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	skills := make([]skillInfo, 0, len(manifests)+len(skillErrors))
+	for _, manifest := range manifests {
+		skills = append(skills, s.skillInfo(manifest))
+	}
+	for _, e := range skillErrors {
+		skills = append(skills, skillInfo{Name: e.Name, Error: fmt.Sprintf("%s: %v", e.Kind, e.Error)})
+	}
+	writeJSON(w, http.StatusOK, skills)
+}
+
+func (s *Server) handleSkill(w http.ResponseWriter, r *http.Request) {
+	manifest, err := skill.FindSkill(s.opts.ProjectRoot, r.PathValue("name"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.skillInfo(manifest))
+}
+
+// skillInfo describes a loaded skill
+func (s *Server) skillInfo(manifest *skill.Manifest) skillInfo {
+	info := skillInfo{
+		Name:        manifest.Name,
+		Description: manifest.Description,
+		Version:     manifest.Version,
+	}
+	if s.opts.SkillsFolder != "" {
+		info.Deployed = pipeline.DeployedVersion(filepath.Join(s.opts.SkillsFolder, manifest.Name), manifest.BinaryName())
+	}
+	info.Status = skill.VersionStatus(manifest.Version, info.Deployed)
+	for _, v := range manifest.Variables {
+		variable := variableInfo{
+			Name:        v.Name,
+			Label:       v.Label,
+			Description: v.Description,
+			Type:        v.Type,
+			Required:    v.Required,
+			Default:     v.Default,
+			Placeholder: v.Placeholder,
+		}
+		if v.FromCommand() {
+			variable.Command = v.Command
+		}
+		info.Variables = append(info.Variables, variable)
+	}
+	if profiles, err := config.LoadProfiles(manifest.Name); err == nil {
+		info.Profiles = config.ProfileNames(profiles)
+	}
+	return info
+}
+
+// profileInfo is a profile of /api/skills/{name}/profiles/{profile}. Secret
+// values are never sent to the browser: Secrets lists the secret variables
+// with a value, and a secret left empty on save keeps its value.
+type profileInfo struct {
+	Values          map[string]string `json:"values"`
+	Secrets         []string          `json:"secrets,omitempty"`
+	SkillsFolder    string            `json:"skills_folder,omitempty"`
+	SkillFolderName string            `json:"skill_folder_name,omitempty"`
+	New             bool              `json:"new,omitempty"` // Not saved yet, values are the defaults
+}
+
+func (s *Server) handleProfile(w http.ResponseWriter, r *http.Request) {
+	manifest, err := skill.FindSkill(s.opts.ProjectRoot, r.PathValue("name"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	profiles, err := config.LoadProfiles(manifest.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	info := profileInfo{Values: make(map[string]string)}
+	profile, ok := profiles[r.PathValue("profile")]
+	if !ok {
+		info.New = true
+		for _, v := range manifest.Variables {
+			info.Values[v.Name] = v.Default
+		}
+		writeJSON(w, http.StatusOK, info)
+		return
+	}
+	info.SkillsFolder = profile.SkillsFolder
+	info.SkillFolderName = profile.SkillFolderName
+	for _, v := range manifest.Variables {
+		value := profile.Values[v.Name]
+		if v.Type == "secret" {
+			if value != "" {
+				info.Secrets = append(info.Secrets, v.Name)
+			}
+			value = ""
+		}
+		info.Values[v.Name] = value
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Server) handleSaveProfile(w http.ResponseWriter, r *http.Request) {
+	manifest, err := skill.FindSkill(s.opts.ProjectRoot, r.PathValue("name"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	name := r.PathValue("profile")
+
+	var req profileInfo
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid profile: %w", err))
+		return
+	}
+	variables := make(map[string]skill.Variable, len(manifest.Variables))
+	for _, v := range manifest.Variables {
+		variables[v.Name] = v
+	}
+	for key := range req.Values {
+		if _, ok := variables[key]; !ok {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("unknown variable %s", key))
+			return
+		}
+	}
+
+	// Keep the stored secrets not entered again
+	values := make(map[string]string)
+	if existing, err := config.LoadProfile(manifest.Name, name); err == nil {
+		if values, err = pipeline.ProfileValues(existing); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	for key, value := range req.Values {
+		if variables[key].Type == "secret" && value == "" {
+			continue
+		}
+		values[key] = value
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	profile := &config.Profile{
+		Values:          values,
+		SkillsFolder:    req.SkillsFolder,
+		SkillFolderName: req.SkillFolderName,
+	}
+	if err := pipeline.SaveProfile(manifest, name, profile, cfg.UseKeychain()); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deployment is a deploy started through the API
+type deployment struct {
+	ID       int       `json:"id"`
+	Skill    string    `json:"skill"`
+	Profile  string    `json:"profile"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished,omitzero"`
+	State    string    `json:"state"` // "running", "success" or "failed"
+	Error    string    `json:"error,omitempty"`
+	Output   string    `json:"output,omitempty"` // Only in /api/deployments/{id}
+
+	output []byte        // Written by deploymentWriter
+	done   chan struct{} // Closed when the deploy finished
+}
+
+// Deployment states
+const (
+	stateRunning = "running"
+	stateSuccess = "success"
+	stateFailed  = "failed"
+)
+
+// deployRequest is the body of /api/deploy
+type deployRequest struct {
+	Skill   string `json:"skill"`
+	Profile string `json:"profile,omitempty"` // Saved profile, default "default"
+	Docs    string `json:"docs,omitempty"`    // Handling of a hand-edited SKILL.md
+}
+
+// handleDeploy starts a deploy and answers 202 with the deployment, its
+// progress is polled from the Location header
+func (s *Server) handleDeploy(w http.ResponseWriter, r *http.Request) {
+	req := deployRequest{Profile: config.DefaultProfile}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid deploy request: %w", err))
+		return
+	}
+	if req.Skill == "" {
+		writeError(w, http.StatusBadRequest, errors.New("invalid deploy request: skill is required"))
+		return
+	}
+	manifest, err := skill.FindSkill(s.opts.ProjectRoot, req.Skill)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if _, err := config.LoadProfile(manifest.Name, req.Profile); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	s.mu.Lock()
+	for _, d := range s.deployments {
+		if d.State == stateRunning {
+			s.mu.Unlock()
+			writeError(w, http.StatusConflict, fmt.Errorf("deploy %d of %s is still running", d.ID, d.Skill))
+			return
+		}
+	}
+	d := &deployment{
+		ID:      len(s.deployments) + 1,
+		Skill:   manifest.Name,
+		Profile: req.Profile,
+		Started: time.Now().UTC(),
+		State:   stateRunning,
+		done:    make(chan struct{}),
+	}
+	s.deployments = append(s.deployments, d)
+	status := *d
+	s.mu.Unlock()
+
+	go func() {
+		err := s.opts.Deploy(deploymentWriter{s, d}, manifest, req.Profile, req.Docs)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		d.Finished = time.Now().UTC()
+		d.State = stateSuccess
+		if err != nil {
+			d.State = stateFailed
+			d.Error = err.Error()
+		}
+		close(d.done)
+	}()
+	w.Header().Set("Location", fmt.Sprintf("/api/deployments/%d", d.ID))
+	writeJSON(w, http.StatusAccepted, status)
+}
+
+// deploymentWriter appends the output of a deploy to its deployment
+type deploymentWriter struct {
+	s *Server
+	d *deployment
+}
+
+func (w deploymentWriter) Write(p []byte) (int, error) {
+	w.s.mu.Lock()
+	defer w.s.mu.Unlock()
+	w.d.output = append(w.d.output, p...)
+	return len(p), nil
+}
+
+// handleDeployments lists the deploys of this server, newest first and
+// without their output. ?skill= and ?state= filter them.
+func (s *Server) handleDeployments(w http.ResponseWriter, r *http.Request) {
+	skillName, state := r.URL.Query().Get("skill"), r.URL.Query().Get("state")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]deployment, 0, len(s.deployments))
+	for i := len(s.deployments) - 1; i >= 0; i-- {
+		d := s.deployments[i]
+		if (skillName != "" && d.Skill != skillName) || (state != "" && d.State != state) {
+			continue
+		}
+		list = append(list, *d)
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleDeployment returns a deploy with its output. ?wait=30s holds the
+// response until the deploy finished or the duration passed, so scripts
+// need not poll.
+func (s *Server) handleDeployment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	s.mu.Lock()
+	if err != nil || id < 1 || id > len(s.deployments) {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, fmt.Errorf("no deployment %s", r.PathValue("id")))
+		return
+	}
+	d := s.deployments[id-1]
+	s.mu.Unlock()
+
+	if value := r.URL.Query().Get("wait"); value != "" {
+		wait, err := time.ParseDuration(value)
+		if err != nil || wait < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid wait %q, expected a duration like 30s", value))
+			return
+		}
+		timer := time.NewTimer(min(wait, maxWait))
+		defer timer.Stop()
+		select {
+		case <-d.done:
+		case <-timer.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	s.mu.Lock()
+	status := *d
+	status.Output = string(d.output)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	events, err := pipeline.LoadEvents(pipeline.EventFilter{Skill: r.URL.Query().Get("skill")})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	// Newest first, without the build output
+	history := make([]pipeline.Event, 0, min(len(events), maxHistory))
+	for i := len(events) - 1; i >= 0 && len(history) < maxHistory; i-- {
+		e := events[i]
+		e.Output = ""
+		history = append(history, e)
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+// writeJSON writes v as JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes {"error": msg} with status
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,161 @@
+package skillkit
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// CLI is the root command of a skill: the standard flags, the serve daemon,
+// the hidden __docs command and ErrorOutput on stderr. C is the API client;
+// --strict is offered if it has a SetStrict(io.Writer) method, --debug if it
+// has a SetDebug(io.Writer, bool) method.
+//
+//	func main() {
+//		skillkit.CLI[*client.Client]{
+//			Name:      "vikunja",
+//			Short:     "Vikunja CLI for Claude Code",
+//			Version:   version,
+//			NewClient: client.New,
+//			Commands: func(c *client.Client, printJSON func(interface{}) error) []*cobra.Command {
+//				return []*cobra.Command{tasks.RegisterCommands(c, printJSON)}
+//			},
+//		}.Main()
+//	}
+type CLI[C any] struct {
+	Name    string
+	Short   string
+	Version string
+
+	// NewClient creates the client from the environment. If it fails, the
+	// commands are registered with the zero client for --help and fail with
+	// its error when run.
+	NewClient func() (C, error)
+
+	// Commands returns the top-level commands of the skill
+	Commands func(client C, printJSON func(interface{}) error) []*cobra.Command
+}
+
+// strictClient is a client reporting schema drift with --strict
+type strictClient interface {
+	SetStrict(w io.Writer)
+}
+
+// debugClient is a client logging its requests with --debug
+type debugClient interface {
+	SetDebug(w io.Writer, bodies bool)
+}
+
+// Main loads the .env, forwards the invocation to a running serve daemon if
+// requested, runs it otherwise and exits with its code
+func (c CLI[C]) Main() {
+	// Load .env from same directory as binary and resolve keychain secrets
+	if err := LoadEnv(); err != nil {
+		WriteError(os.Stderr, err)
+	}
+	if code, ok := RunViaDaemon(c.Name); ok {
+		os.Exit(code)
+	}
+	os.Exit(c.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// Run executes one invocation. It is also the handler of the serve daemon.
+func (c CLI[C]) Run(args []string, stdout, stderr io.Writer) int {
+	printJSON := JSONPrinter(stdout)
+
+	// Errors are reported as ErrorOutput only, without cobra's text and usage
+	rootCmd := &cobra.Command{
+		Use:           c.Name,
+		Short:         c.Short,
+		Version:       c.Version,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(stdout)
+	rootCmd.SetErr(stderr)
+	rootCmd.PersistentFlags().Bool(NoEnvFileFlag, false, "Skip loading .env (use the process environment only)")
+	rootCmd.PersistentFlags().Bool(ViaDaemonFlag, false, "Run through a running serve daemon (falls back to in-process)")
+	var zero C
+	if _, ok := any(zero).(strictClient); ok {
+		rootCmd.PersistentFlags().Bool(StrictFlag, false, "Fail on unknown response fields and warn about missing ones (detects API changes)")
+	}
+	if _, ok := any(zero).(debugClient); ok {
+		rootCmd.PersistentFlags().String(DebugFlag, "", "Log API requests to stderr: method, URL, status, latency (--debug=body: also redacted bodies)")
+		rootCmd.PersistentFlags().Lookup(DebugFlag).NoOptDefVal = "true"
+	}
+
+	// Create client (will fail later if env vars missing)
+	client, err := c.NewClient()
+	if err != nil {
+		// Only fail if actually trying to run a command
+		client = zero
+		rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+			return err
+		}
+	} else {
+		rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+			if s, ok := any(client).(strictClient); ok && Strict(cmd) {
+				s.SetStrict(cmd.ErrOrStderr())
+			}
+			if d, ok := any(client).(debugClient); ok {
+				if enabled, bodies := Debug(cmd); enabled {
+					d.SetDebug(cmd.ErrOrStderr(), bodies)
+				}
+			}
+			return nil
+		}
+	}
+	rootCmd.AddCommand(c.Commands(client, printJSON)...)
+	rootCmd.AddCommand(c.serveCommand(), DocsCommand(rootCmd))
+
+	markUsageErrors(rootCmd)
+
+	if err := Execute(rootCmd); err != nil {
+		WriteError(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// markUsageErrors wraps the flag and argument errors of cmd and its
+// subcommands in UsageError
+func markUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &UsageError{Err: err}
+	})
+	var mark func(c *cobra.Command)
+	mark = func(c *cobra.Command) {
+		if args := c.Args; args != nil {
+			c.Args = func(cmd *cobra.Command, a []string) error {
+				if err := args(cmd, a); err != nil {
+					return &UsageError{Err: err}
+				}
+				return nil
+			}
+		}
+		for _, sub := range c.Commands() {
+			mark(sub)
+		}
+	}
+	mark(cmd)
+}
+
+// serveCommand creates the serve command that keeps the skill resident
+func (c CLI[C]) serveCommand() *cobra.Command {
+	var socket string
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Keep running and execute invocations sent with --via-daemon",
+		Long: `Keep the skill resident and execute invocations received on a unix socket.
+Calls with --via-daemon are forwarded to it, saving process startup and
+TLS handshakes. Invocations run one at a time.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return Serve(socket, c.Run)
+		},
+	}
+	cmd.Flags().StringVar(&socket, "socket", DaemonSocketPath(c.Name), "Unix socket path")
+	return cmd
+}
@@ -0,0 +1,81 @@
+package skillkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// TimeoutFlag cancels an invocation after a duration, e.g. --timeout 30s
+const TimeoutFlag = "timeout"
+
+// ErrInterrupted is the cause of a command context canceled by SIGINT or SIGTERM
+var ErrInterrupted = errors.New("interrupted")
+
+// ErrTimeout is the cause of a command context canceled by --timeout
+var ErrTimeout = errors.New("timed out")
+
+// Execute runs root with a context that is canceled on SIGINT or SIGTERM and
+// after the --timeout it registers on root. Commands pass cmd.Context() to
+// their API calls, so an interrupted call returns right away; context.Cause
+// reports ErrInterrupted or the timeout. A second SIGINT exits immediately.
+// The running command selects the redaction rules of JSONPrinter.
+// Call it after setting root's PersistentPreRunE.
+func Execute(root *cobra.Command) error {
+	root.PersistentFlags().Duration(TimeoutFlag, 0, "Cancel the command after this duration, e.g. 30s (0: no limit)")
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			cancel(ErrInterrupted)
+			// Restore the default handling for commands not watching the context
+			signal.Stop(signals)
+		case <-ctx.Done():
+		}
+	}()
+
+	var timer *time.Timer
+	preRun := root.PersistentPreRunE
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		setActiveCommand(cmd)
+		if timeout, _ := cmd.Flags().GetDuration(TimeoutFlag); timeout > 0 {
+			timer = time.AfterFunc(timeout, func() {
+				cancel(fmt.Errorf("%w after %s", ErrTimeout, timeout))
+			})
+		}
+		if preRun != nil {
+			return preRun(cmd, args)
+		}
+		return nil
+	}
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+		activeCommand.Store("")
+	}()
+
+	return root.ExecuteContext(ctx)
+}
+
+// ContextError returns the cause of ctx's cancellation, e.g. ErrInterrupted,
+// as "request canceled: <cause>", or nil if ctx is not done. API clients
+// report it instead of the error of the aborted request.
+func ContextError(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return fmt.Errorf("request canceled: %w", context.Cause(ctx))
+}
@@ -0,0 +1,19 @@
+package skillkit
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// WriteCSV writes a header and rows as CSV (RFC 4180, comma separated),
+// e.g. for exports meant for spreadsheets
+func WriteCSV(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
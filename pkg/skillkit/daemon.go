@@ -0,0 +1,199 @@
+package skillkit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// ViaDaemonFlag forwards an invocation to a running "serve" daemon
+const ViaDaemonFlag = "via-daemon"
+
+// DaemonSocketEnvVar overrides the daemon socket path
+const DaemonSocketEnvVar = "SKILLKIT_DAEMON_SOCKET"
+
+// ErrDaemonUnavailable is returned by CallDaemon when no daemon listens on the socket
+var ErrDaemonUnavailable = errors.New("skill daemon not running")
+
+// Runner executes one skill invocation with the given arguments and returns the exit code
+type Runner func(args []string, stdout, stderr io.Writer) int
+
+// daemonRequest is sent by the client as a single JSON line
+type daemonRequest struct {
+	Args []string `json:"args"`
+}
+
+// daemonFrame is a chunk of output or the final exit code, one JSON line each
+type daemonFrame struct {
+	Stream string `json:"stream,omitempty"` // "stdout" or "stderr"
+	Data   string `json:"data,omitempty"`
+	Exit   *int   `json:"exit,omitempty"`
+}
+
+// DaemonSocketPath returns the socket of a skill daemon: SKILLKIT_DAEMON_SOCKET
+// if set, otherwise skillkit-<skill>-<uid>.sock in the temp directory
+func DaemonSocketPath(skill string) string {
+	if path := os.Getenv(DaemonSocketEnvVar); path != "" {
+		return path
+	}
+	return filepath.Join(os.TempDir(), "skillkit-"+skill+"-"+strconv.Itoa(os.Getuid())+".sock")
+}
+
+// Serve keeps the skill resident and runs invocations received on a unix
+// socket until SIGINT or SIGTERM. Invocations run one at a time; HTTP
+// clients and their connections are reused across invocations.
+func Serve(socketPath string, run Runner) error {
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		conn.Close()
+		return fmt.Errorf("daemon already running on %s", socketPath)
+	}
+	os.Remove(socketPath)
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	defer os.Remove(socketPath)
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Listening on %s\n", socketPath)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		listener.Close()
+	}()
+
+	var mu sync.Mutex
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			mu.Lock()
+			defer mu.Unlock()
+			serveConn(conn, run)
+		}()
+	}
+}
+
+// serveConn runs the invocation requested on conn and streams its output back
+func serveConn(conn net.Conn, run Runner) {
+	var req daemonRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+
+	enc := &frameEncoder{enc: json.NewEncoder(conn)}
+	code := run(req.Args, enc.stream("stdout"), enc.stream("stderr"))
+	enc.encode(daemonFrame{Exit: &code})
+}
+
+// frameEncoder serializes output frames of both streams onto one connection
+type frameEncoder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (e *frameEncoder) encode(f daemonFrame) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.enc.Encode(f)
+}
+
+func (e *frameEncoder) stream(name string) io.Writer {
+	return frameWriter{e, name}
+}
+
+type frameWriter struct {
+	enc    *frameEncoder
+	stream string
+}
+
+func (w frameWriter) Write(p []byte) (int, error) {
+	if err := w.enc.encode(daemonFrame{Stream: w.stream, Data: string(p)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// CallDaemon runs an invocation on the daemon listening on socketPath and
+// copies its output to stdout and stderr
+func CallDaemon(socketPath string, args []string, stdout, stderr io.Writer) (int, error) {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDaemonUnavailable, err)
+	}
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(daemonRequest{Args: args}); err != nil {
+		return 0, err
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	for {
+		var f daemonFrame
+		if err := dec.Decode(&f); err != nil {
+			return 0, fmt.Errorf("daemon connection closed: %w", err)
+		}
+		switch {
+		case f.Exit != nil:
+			return *f.Exit, nil
+		case f.Stream == "stderr":
+			io.WriteString(stderr, f.Data)
+		default:
+			io.WriteString(stdout, f.Data)
+		}
+	}
+}
+
+// RunViaDaemon forwards the invocation to the skill's daemon when --via-daemon
+// is given. ok is false if the flag is absent or no daemon is reachable, in
+// which case the skill runs in-process as usual. Errors after the invocation
+// was sent are reported with exit code 1 instead, so commands never run twice.
+func RunViaDaemon(skill string) (code int, ok bool) {
+	var args []string
+	found := false
+	for i, arg := range os.Args[1:] {
+		if arg == "--" {
+			args = append(args, os.Args[1+i:]...)
+			break
+		}
+		if arg == "--"+ViaDaemonFlag {
+			found = true
+			continue
+		}
+		args = append(args, arg)
+	}
+	if !found {
+		return 0, false
+	}
+
+	code, err := CallDaemon(DaemonSocketPath(skill), args, os.Stdout, os.Stderr)
+	if errors.Is(err, ErrDaemonUnavailable) {
+		return 0, false
+	}
+	if err != nil {
+		WriteError(os.Stderr, err)
+		return 1, true
+	}
+	return code, true
+}
@@ -0,0 +1,136 @@
+package skillkit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLayout is the plain date format used by skill CLIs (YYYY-MM-DD)
+const DateLayout = "2006-01-02"
+
+// DateTimeLayout is a date with local time of day (YYYY-MM-DDTHH:MM)
+const DateTimeLayout = "2006-01-02T15:04"
+
+// DateFormats describes the accepted date input for flag usage strings
+const DateFormats = "YYYY-MM-DD, YYYY-MM-DDTHH:MM, today, tomorrow, yesterday, +3d, -1w or a weekday"
+
+// Today returns the current local date as YYYY-MM-DD
+func Today() string {
+	return time.Now().Format(DateLayout)
+}
+
+// DaysAgo returns the local date n days before today as YYYY-MM-DD
+func DaysAgo(n int) string {
+	return time.Now().AddDate(0, 0, -n).Format(DateLayout)
+}
+
+// ParseDate parses an absolute or relative date. Dates without a time zone
+// are local time (honoring TZ), dates without a time of day are midnight.
+//
+// Accepted input:
+//   - YYYY-MM-DD and YYYY-MM-DDTHH:MM
+//   - RFC3339 (keeps its offset)
+//   - today, tomorrow, yesterday
+//   - +3d, -2w: days or weeks from today
+//   - monday ... sunday (or mon ... sun): the next such day, never today
+func ParseDate(s string) (time.Time, error) {
+	return parseDate(s, time.Now())
+}
+
+// parseDate parses s relative to now
+func parseDate(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(DateTimeLayout, s, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(DateLayout, s, now.Location()); err == nil {
+		return t, nil
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	word := strings.ToLower(s)
+	switch word {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	if days, ok := relativeDays(word); ok {
+		return today.AddDate(0, 0, days), nil
+	}
+	if weekday, ok := parseWeekday(word); ok {
+		ahead := (int(weekday) - int(today.Weekday()) + 7) % 7
+		if ahead == 0 {
+			ahead = 7
+		}
+		return today.AddDate(0, 0, ahead), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (expected %s)", s, DateFormats)
+}
+
+// relativeDays parses "+3d" or "-2w" into a number of days
+func relativeDays(s string) (int, bool) {
+	if len(s) < 3 || (s[0] != '+' && s[0] != '-') {
+		return 0, false
+	}
+	unit := 1
+	switch s[len(s)-1] {
+	case 'd':
+	case 'w':
+		unit = 7
+	default:
+		return 0, false
+	}
+	n, err := strconv.Atoi(s[1 : len(s)-1])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	if s[0] == '-' {
+		n = -n
+	}
+	return n * unit, true
+}
+
+// parseWeekday parses an English weekday name or its three-letter abbreviation
+func parseWeekday(s string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// PlainDate normalizes a date to YYYY-MM-DD, e.g. for APIs taking calendar
+// days. Empty input stays empty.
+func PlainDate(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	t, err := ParseDate(s)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(DateLayout), nil
+}
+
+// RFC3339Date normalizes a date to RFC3339, e.g. for APIs taking timestamps.
+// Empty input stays empty.
+func RFC3339Date(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	t, err := ParseDate(s)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(time.RFC3339), nil
+}
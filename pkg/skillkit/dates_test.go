@@ -0,0 +1,199 @@
+package skillkit
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // Europe/Berlin without a system zoneinfo
+)
+
+func TestParseDate(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Wednesday afternoon
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)
+	// Saturday before the switch to summer time on 2026-03-29
+	beforeDST := time.Date(2026, 3, 28, 12, 0, 0, 0, berlin)
+
+	tests := []struct {
+		name  string
+		input string
+		now   time.Time
+		want  time.Time
+	}{
+		{"rfc3339 utc", "2026-11-01T08:15:00Z", now, time.Date(2026, 11, 1, 8, 15, 0, 0, time.UTC)},
+		{"rfc3339 offset", "2026-11-01T08:15:00+02:00", now, time.Date(2026, 11, 1, 6, 15, 0, 0, time.UTC)},
+		{"plain date", "2026-11-01", now, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"plain date in location", "2026-11-01", beforeDST, time.Date(2026, 11, 1, 0, 0, 0, 0, berlin)},
+		{"date with time", "2026-11-01T09:45", now, time.Date(2026, 11, 1, 9, 45, 0, 0, time.UTC)},
+		{"surrounding space", " 2026-11-01 ", now, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"today", "today", now, time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)},
+		{"tomorrow", "Tomorrow", now, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"yesterday", "yesterday", now, time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC)},
+		{"plus days", "+3d", now, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{"minus days", "-1d", now, time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC)},
+		{"plus weeks", "+2w", now, time.Date(2026, 10, 28, 0, 0, 0, 0, time.UTC)},
+		{"minus weeks", "-1w", now, time.Date(2026, 10, 7, 0, 0, 0, 0, time.UTC)},
+		{"zero days", "+0d", now, time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)},
+		{"weekday later this week", "friday", now, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{"weekday next week", "monday", now, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{"weekday today is next week", "wednesday", now, time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC)},
+		{"weekday abbreviation", "Thu", now, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"day before dst", "+1d", beforeDST, time.Date(2026, 3, 29, 0, 0, 0, 0, berlin)},
+		{"day after dst", "+2d", beforeDST, time.Date(2026, 3, 30, 0, 0, 0, 0, berlin)},
+		{"week across dst", "+1w", beforeDST, time.Date(2026, 4, 4, 0, 0, 0, 0, berlin)},
+		{"weekday across dst", "monday", beforeDST, time.Date(2026, 3, 30, 0, 0, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDate(tt.input, tt.now)
+			if err != nil {
+				t.Fatalf("parseDate(%q) failed: %v", tt.input, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDate(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDateDSTOffset(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 28, 12, 0, 0, 0, berlin)
+	for input, want := range map[string]string{
+		"today": "2026-03-28T00:00:00+01:00",
+		"+2d":   "2026-03-30T00:00:00+02:00",
+	} {
+		got, err := parseDate(input, now)
+		if err != nil {
+			t.Fatalf("parseDate(%q) failed: %v", input, err)
+		}
+		// Relative days stay at local midnight, not 24h steps
+		if s := got.Format(time.RFC3339); s != want {
+			t.Errorf("parseDate(%q) = %s, want %s", input, s, want)
+		}
+	}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)
+	for _, input := range []string{
+		"",
+		"   ",
+		"soon",
+		"2026-13-01",
+		"2026-02-30",
+		"14.10.2026",
+		"+d",
+		"3d",
+		"+3m",
+		"+-3d",
+		"+x3d",
+		"mo",
+		"2026-11-01T25:00",
+	} {
+		if got, err := parseDate(input, now); err == nil {
+			t.Errorf("parseDate(%q) = %s, want error", input, got)
+		} else if !strings.Contains(err.Error(), DateFormats) {
+			t.Errorf("parseDate(%q) error %q does not list the formats", input, err)
+		}
+	}
+}
+
+// legacyVikunjaDate is the formatDate of skills/vikunja/tasks before it
+// used RFC3339Date, for inputs it handled without its fallbacks
+func legacyVikunjaDate(date string) string {
+	if date == "" {
+		return ""
+	}
+	if strings.Contains(date, "T") && (strings.Contains(date, "Z") || strings.Contains(date, "+") || strings.Contains(date, "-") && strings.Count(date, "-") > 2) {
+		return date
+	}
+	if strings.Contains(date, "T") {
+		t, _ := time.ParseInLocation("2006-01-02T15:04", date, time.Local)
+		return t.Format(time.RFC3339)
+	}
+	t, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+	return t.Format(time.RFC3339)
+}
+
+// setLocal sets time.Local for the test, PlainDate and RFC3339Date use it
+func setLocal(t *testing.T, name string) {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = saved })
+}
+
+func TestRFC3339DateMatchesVikunja(t *testing.T) {
+	for _, zone := range []string{"UTC", "Europe/Berlin", "America/New_York"} {
+		t.Run(zone, func(t *testing.T) {
+			setLocal(t, zone)
+			for _, input := range []string{
+				"",
+				"2026-10-14",
+				"2026-03-29",
+				"2026-03-30",
+				"2026-10-14T09:30",
+				"2026-03-29T03:15",
+				"2026-10-14T09:30:00Z",
+				"2026-10-14T09:30:00+02:00",
+				"2026-10-14T09:30:00-05:00",
+			} {
+				got, err := RFC3339Date(input)
+				if err != nil {
+					t.Fatalf("RFC3339Date(%q) failed: %v", input, err)
+				}
+				if want := legacyVikunjaDate(input); got != want {
+					t.Errorf("RFC3339Date(%q) = %q, vikunja sent %q", input, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPlainDateMatchesHabitwire(t *testing.T) {
+	setLocal(t, "Europe/Berlin")
+	// habitwire sent its --date and --from/--to values unchanged
+	for _, input := range []string{"", "2026-10-14", "2026-03-29", "2026-12-31", "2028-02-29"} {
+		got, err := PlainDate(input)
+		if err != nil {
+			t.Fatalf("PlainDate(%q) failed: %v", input, err)
+		}
+		if got != input {
+			t.Errorf("PlainDate(%q) = %q, habitwire sent %q", input, got, input)
+		}
+	}
+}
+
+func TestPlainDateRoundTrip(t *testing.T) {
+	setLocal(t, "America/New_York")
+	for _, input := range []string{"2026-03-08", "2026-11-01", "2026-10-14T23:30"} {
+		rfc, err := RFC3339Date(input)
+		if err != nil {
+			t.Fatalf("RFC3339Date(%q) failed: %v", input, err)
+		}
+		plain, err := PlainDate(rfc)
+		if err != nil {
+			t.Fatalf("PlainDate(%q) failed: %v", rfc, err)
+		}
+		if want := input[:len(DateLayout)]; plain != want {
+			t.Errorf("PlainDate(RFC3339Date(%q)) = %q, want %q", input, plain, want)
+		}
+	}
+	if _, err := PlainDate("not a date"); err == nil {
+		t.Error("PlainDate accepted an invalid date")
+	}
+	if _, err := RFC3339Date("not a date"); err == nil {
+		t.Error("RFC3339Date accepted an invalid date")
+	}
+}
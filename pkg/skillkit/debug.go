@@ -0,0 +1,124 @@
+package skillkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// DebugFlag logs the API requests of a command to stderr: --debug for
+// method, URL, status and latency, --debug=body also for the bodies.
+// CLI registers it for clients with a SetDebug(io.Writer, bool) method.
+const DebugFlag = "debug"
+
+// DebugEnvVar enables the request log like --debug when set to a true value,
+// or to "body" to include the bodies
+const DebugEnvVar = "SKILL_DEBUG"
+
+// DebugBody is the value of --debug and SKILL_DEBUG that also logs bodies
+const DebugBody = "body"
+
+// maxDebugBody is the size up to which a body is logged
+const maxDebugBody = 4096
+
+// debugRedactFields are always redacted in logged bodies, in addition to
+// the redact rules of the skill
+var debugRedactFields = []string{"password", "token", "api_key", "apikey", "key", "secret", "access_token", "refresh_token"}
+
+// Debug reports whether --debug or SKILL_DEBUG enables the request log for
+// cmd, and whether it includes the bodies
+func Debug(cmd *cobra.Command) (enabled, bodies bool) {
+	value := os.Getenv(DebugEnvVar)
+	if flag := cmd.Flags().Lookup(DebugFlag); flag != nil && flag.Changed {
+		value = flag.Value.String()
+	}
+	if value == DebugBody {
+		return true, true
+	}
+	enabled, _ = strconv.ParseBool(value)
+	return enabled, false
+}
+
+// debugRecord is a line of the request log
+type debugRecord struct {
+	Debug        string          `json:"debug"` // Always "http"
+	Method       string          `json:"method"`
+	URL          string          `json:"url"`
+	Attempt      int             `json:"attempt"`
+	Status       int             `json:"status,omitempty"`
+	LatencyMS    int64           `json:"latency_ms"`
+	RequestError string          `json:"request_error,omitempty"`
+	RequestBody  json.RawMessage `json:"request_body,omitempty"`
+	ResponseBody json.RawMessage `json:"response_body,omitempty"`
+}
+
+// SetDebug enables the request log (--debug): every attempt is written to w
+// as {"debug": "http", ...} line, with the redacted bodies if bodies is set.
+// Headers are never logged.
+func (c *HTTPClient) SetDebug(w io.Writer, bodies bool) {
+	c.debug = w
+	c.debugBodies = bodies
+}
+
+// logRequest writes an attempt to the request log if enabled
+func (c *HTTPClient) logRequest(method, endpoint string, attempt int, latency time.Duration, status int, payload, respBody []byte, err error) {
+	if c.debug == nil {
+		return
+	}
+	record := debugRecord{
+		Debug:     "http",
+		Method:    method,
+		URL:       c.BaseURL + endpoint,
+		Attempt:   attempt + 1,
+		Status:    status,
+		LatencyMS: latency.Milliseconds(),
+	}
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr):
+		respBody = []byte(apiErr.Body)
+	case err != nil:
+		record.RequestError = err.Error()
+	}
+	if c.debugBodies {
+		record.RequestBody = debugBody(payload)
+		record.ResponseBody = debugBody(respBody)
+	}
+	json.NewEncoder(c.debug).Encode(record)
+}
+
+// debugBody returns a body for the request log: JSON redacted by the rules
+// of the running command and debugRedactFields, other content as string,
+// cut at maxDebugBody. JSON is cut after redaction, and left out if it
+// cannot be redacted.
+func debugBody(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	text := string(body)
+	if json.Valid(body) {
+		rules, _ := RedactRules()
+		rules = append(rules, RedactRule{Fields: debugRedactFields})
+		command, _ := activeCommand.Load().(string)
+		redacted, err := Redact(body, rules, command)
+		if err != nil {
+			data, _ := json.Marshal(fmt.Sprintf("<%d bytes>", len(body)))
+			return data
+		}
+		if len(redacted) <= maxDebugBody {
+			return redacted
+		}
+		text = string(redacted)
+	}
+	if len(text) > maxDebugBody {
+		text = text[:maxDebugBody] + "..."
+	}
+	data, _ := json.Marshal(text)
+	return data
+}
@@ -0,0 +1,182 @@
+package skillkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// StrictFlag enables strict decoding of API responses, see DecodeJSON.
+// Skills register it as persistent flag.
+const StrictFlag = "strict"
+
+// StrictEnvVar enables strict decoding when set to a true value, e.g. in the
+// .env of a development deploy
+const StrictEnvVar = "SKILLKIT_STRICT"
+
+// Strict reports whether --strict is set on cmd or SKILLKIT_STRICT in the environment
+func Strict(cmd *cobra.Command) bool {
+	if strict, _ := strconv.ParseBool(os.Getenv(StrictEnvVar)); strict {
+		return true
+	}
+	strict, _ := cmd.Flags().GetBool(StrictFlag)
+	return strict
+}
+
+// DecodeJSON unmarshals an API response into v. In strict mode, fields of the
+// response that v does not declare and fields v declares without omitempty
+// that the response lacks are written to warnings as {"warning": ...} lines,
+// and unknown fields fail the decoding. A changed server API is noticed
+// right away instead of producing half-empty lean output.
+func DecodeJSON(data []byte, v interface{}, strict bool, warnings io.Writer) error {
+	if !strict {
+		return json.Unmarshal(data, v)
+	}
+
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	drift := map[string]bool{}
+	if t != nil {
+		schemaDrift(raw, t, "", drift)
+	}
+	messages := make([]string, 0, len(drift))
+	for message := range drift {
+		messages = append(messages, message)
+	}
+	sort.Strings(messages)
+	for _, message := range messages {
+		PrintWarning(warnings, fmt.Sprintf("%s: %s", t, message))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("strict decoding of %s: %w", t, err)
+	}
+	return nil
+}
+
+// schemaDrift compares a decoded JSON value with the type it is unmarshaled
+// into and adds "unexpected field x" and "missing field y" to drift. Elements
+// of arrays share the path "items[].x".
+func schemaDrift(raw interface{}, t reflect.Type, path string, drift map[string]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if items, ok := raw.([]interface{}); ok {
+			for _, item := range items {
+				schemaDrift(item, t.Elem(), path+"[]", drift)
+			}
+		}
+	case reflect.Map:
+		if values, ok := raw.(map[string]interface{}); ok {
+			for _, value := range values {
+				schemaDrift(value, t.Elem(), path+".*", drift)
+			}
+		}
+	case reflect.Struct:
+		object, ok := raw.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		for key, value := range object {
+			field, ok := lookupField(fields, key)
+			if !ok {
+				drift["unexpected field "+joinPath(path, key)] = true
+				continue
+			}
+			schemaDrift(value, field.typ, joinPath(path, key), drift)
+		}
+		for name, field := range fields {
+			if _, ok := lookupField(object, name); !ok && !field.omitempty {
+				drift["missing field "+joinPath(path, name)] = true
+			}
+		}
+	}
+}
+
+// unmarshalerType is skipped by schemaDrift: types decoding themselves may
+// accept any fields
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// lookupField finds a key like encoding/json does: an exact match first,
+// otherwise ignoring case
+func lookupField[V any](m map[string]V, key string) (V, bool) {
+	if value, ok := m[key]; ok {
+		return value, true
+	}
+	for name, value := range m {
+		if strings.EqualFold(name, key) {
+			return value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// jsonField is a struct field as seen by encoding/json
+type jsonField struct {
+	typ       reflect.Type
+	omitempty bool
+}
+
+// jsonFields returns the fields of struct type t by JSON name, including the
+// fields of embedded structs
+func jsonFields(t reflect.Type) map[string]jsonField {
+	fields := map[string]jsonField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for name, field := range jsonFields(embedded) {
+					if _, ok := fields[name]; !ok {
+						fields[name] = field
+					}
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = jsonField{typ: f.Type, omitempty: strings.Contains(options, "omitempty")}
+	}
+	return fields
+}
+
+// joinPath appends a field name to a JSON path
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
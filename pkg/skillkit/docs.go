@@ -0,0 +1,118 @@
+package skillkit
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// DocsCommandName is the hidden command SkillFactory runs to document a skill
+const DocsCommandName = "__docs"
+
+// Docs describes the commands of a skill for SKILL.md generation
+type Docs struct {
+	Name     string       `json:"name"`
+	Version  string       `json:"version,omitempty"`
+	Commands []CommandDoc `json:"commands"`
+}
+
+// CommandDoc describes a runnable command
+type CommandDoc struct {
+	Path        string    `json:"path"`  // Command path without the binary, e.g. "tasks create"
+	Usage       string    `json:"usage"` // e.g. "tasks create [flags]"
+	Description string    `json:"description,omitempty"`
+	Flags       []FlagDoc `json:"flags,omitempty"`
+}
+
+// FlagDoc describes a flag of a command
+type FlagDoc struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	Type      string `json:"type"` // e.g. "string", "bool", "int64", "duration"
+	Default   string `json:"default,omitempty"`
+	Usage     string `json:"usage,omitempty"`
+}
+
+// DocsCommand returns the hidden __docs command printing the Docs of root as
+// JSON. It works without configuration, even when root's PersistentPreRunE
+// fails because of missing environment variables.
+//
+//	rootCmd.AddCommand(skillkit.DocsCommand(rootCmd))
+func DocsCommand(root *cobra.Command) *cobra.Command {
+	return &cobra.Command{
+		Use:    DocsCommandName,
+		Short:  "Print command metadata as JSON (used by SkillFactory)",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(DescribeCommands(root))
+		},
+	}
+}
+
+// DescribeCommands collects the runnable, visible commands below root
+func DescribeCommands(root *cobra.Command) Docs {
+	docs := Docs{Name: root.Name(), Version: root.Version}
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		for _, sub := range cmd.Commands() {
+			if sub.Hidden || !sub.IsAvailableCommand() || sub.Name() == "help" || sub.Name() == "completion" {
+				continue
+			}
+			if sub.Runnable() {
+				docs.Commands = append(docs.Commands, describeCommand(root, sub))
+			}
+			walk(sub)
+		}
+	}
+	walk(root)
+	return docs
+}
+
+// describeCommand documents a single command
+func describeCommand(root, cmd *cobra.Command) CommandDoc {
+	trim := func(s string) string {
+		return strings.TrimSpace(strings.TrimPrefix(s, root.Name()))
+	}
+	doc := CommandDoc{
+		Path:        trim(cmd.CommandPath()),
+		Usage:       trim(cmd.UseLine()),
+		Description: strings.TrimSpace(cmd.Long),
+	}
+	if doc.Description == "" {
+		doc.Description = strings.TrimSpace(cmd.Short)
+	}
+
+	cmd.NonInheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Name == "help" {
+			return
+		}
+		flag := FlagDoc{
+			Name:      f.Name,
+			Shorthand: f.Shorthand,
+			Type:      f.Value.Type(),
+			Usage:     f.Usage,
+		}
+		if !isZeroDefault(f.DefValue) {
+			flag.Default = f.DefValue
+		}
+		doc.Flags = append(doc.Flags, flag)
+	})
+	return doc
+}
+
+// isZeroDefault reports whether a flag default is the zero value of its type
+func isZeroDefault(value string) bool {
+	switch value {
+	case "", "0", "false", "[]", "0s", "<nil>":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+// Package skillkit contains helpers shared by the skills built with SkillFactory
+package skillkit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// NoEnvFileFlag skips loading the .env files when passed on the command line.
+// Skills register it as persistent flag so cobra accepts it.
+const NoEnvFileFlag = "no-env-file"
+
+// NoEnvFileEnvVar skips loading the .env files when set to a true value, e.g.
+// for callers that already provide the full environment
+const NoEnvFileEnvVar = "SKILLKIT_NO_ENV_FILE"
+
+// ErrMissingEnv is returned by RequireEnv for an unset variable
+var ErrMissingEnv = errors.New("environment variable is required")
+
+// envFileVars are the variables LoadEnv set from the .env or .env.enc of the
+// running skill. Exec does not pass them on to sibling skills.
+var envFileVars = make(map[string]bool)
+
+// LoadEnv loads the .env (or the encrypted .env.enc) next to the executable and
+// resolves keychain references. Variables that are already set in the
+// environment are not overwritten. With --no-env-file or SKILLKIT_NO_ENV_FILE
+// only keychain references in the process environment are resolved.
+func LoadEnv() error {
+	if SkipEnvFile() {
+		return ResolveEnv()
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	binDir := filepath.Dir(exe)
+
+	if err := loadEncryptedEnv(binDir); err != nil {
+		return err
+	}
+
+	values, err := godotenv.Read(filepath.Join(binDir, ".env"))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to load .env: %w", err)
+	}
+	setEnvFileVars(values)
+	return ResolveEnv()
+}
+
+// RequireEnv returns the value of an environment variable the skill cannot
+// run without, or an error naming it
+func RequireEnv(name string) (string, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return "", fmt.Errorf("%s %w", name, ErrMissingEnv)
+	}
+	return value, nil
+}
+
+// SkipEnvFile reports whether --no-env-file or SKILLKIT_NO_ENV_FILE is set.
+// It reads os.Args directly since LoadEnv runs before flags are parsed.
+func SkipEnvFile() bool {
+	if skip, _ := strconv.ParseBool(os.Getenv(NoEnvFileEnvVar)); skip {
+		return true
+	}
+	for _, arg := range os.Args[1:] {
+		if arg == "--" {
+			break
+		}
+		if arg == "--"+NoEnvFileFlag || arg == "--"+NoEnvFileFlag+"=true" {
+			return true
+		}
+	}
+	return false
+}
+
+// loadEncryptedEnv decrypts .env.enc in binDir if present. The skill folder
+// (parent of bin/) selects the keychain entry holding the passphrase.
+func loadEncryptedEnv(binDir string) error {
+	data, err := os.ReadFile(filepath.Join(binDir, EncryptedEnvFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	passphrase, err := EnvPassphrase(filepath.Base(filepath.Dir(binDir)))
+	if err != nil {
+		return err
+	}
+	plaintext, err := DecryptEnv(data, passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", EncryptedEnvFile, err)
+	}
+
+	values, err := godotenv.Unmarshal(string(plaintext))
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", EncryptedEnvFile, err)
+	}
+	setEnvFileVars(values)
+	return nil
+}
+
+// setEnvFileVars sets the values of an .env file that are not set in the
+// environment yet
+func setEnvFileVars(values map[string]string) {
+	for name, value := range values {
+		if _, ok := os.LookupEnv(name); !ok {
+			os.Setenv(name, value)
+			envFileVars[name] = true
+		}
+	}
+}
+
+// ResolveEnv replaces environment values of the form "keychain:service/account"
+// with the secret stored in the OS keychain
+func ResolveEnv() error {
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		service, account, ok := ParseKeychainRef(value)
+		if !ok {
+			continue
+		}
+		secret, err := GetSecret(service, account)
+		if err != nil {
+			return fmt.Errorf("failed to read %s from keychain: %w", name, err)
+		}
+		os.Setenv(name, secret)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package skillkit
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// EncryptedEnvFile is the encrypted variant of the .env next to the binary
+	EncryptedEnvFile = ".env.enc"
+
+	// PassphraseEnvVar overrides the passphrase used to decrypt .env.enc
+	PassphraseEnvVar = "SKILL_ENV_PASSPHRASE"
+
+	// PassphraseAccount is the keychain account holding the .env.enc passphrase
+	PassphraseAccount = "ENV_PASSPHRASE"
+)
+
+// File format: magic, salt, nonce, AES-256-GCM ciphertext
+var envMagic = []byte("SKILLENV1")
+
+const (
+	saltSize       = 16
+	kdfIterations  = 200000
+	envKeySize     = 32
+	passphraseSize = 32
+)
+
+// KeychainService returns the keychain service used for a deployed skill folder
+func KeychainService(skillFolder string) string {
+	return "skillfactory." + skillFolder
+}
+
+// EncryptEnv encrypts .env content with a passphrase
+func EncryptEnv(plaintext []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := envCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte{}, envMagic...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, envMagic), nil
+}
+
+// DecryptEnv decrypts content created by EncryptEnv
+func DecryptEnv(data []byte, passphrase string) ([]byte, error) {
+	if !bytes.HasPrefix(data, envMagic) {
+		return nil, errors.New("not an encrypted .env file")
+	}
+	data = data[len(envMagic):]
+	if len(data) < saltSize {
+		return nil, errors.New("encrypted .env is truncated")
+	}
+	salt, data := data[:saltSize], data[saltSize:]
+
+	gcm, err := envCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("encrypted .env is truncated")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, envMagic)
+	if err != nil {
+		return nil, errors.New("wrong passphrase or corrupted .env.enc")
+	}
+	return plaintext, nil
+}
+
+// GeneratePassphrase returns a random passphrase for .env.enc
+func GeneratePassphrase() (string, error) {
+	b := make([]byte, passphraseSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// EnvPassphrase returns the passphrase for .env.enc from SKILL_ENV_PASSPHRASE
+// or the keychain entry of the skill folder
+func EnvPassphrase(skillFolder string) (string, error) {
+	if p := os.Getenv(PassphraseEnvVar); p != "" {
+		return p, nil
+	}
+	p, err := GetSecret(KeychainService(skillFolder), PassphraseAccount)
+	if err != nil {
+		return "", fmt.Errorf("no passphrase for %s: set %s or store it in the keychain (%w)", EncryptedEnvFile, PassphraseEnvVar, err)
+	}
+	return p, nil
+}
+
+// envCipher derives the AES-GCM cipher from passphrase and salt
+func envCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key := pbkdf2.Key([]byte(passphrase), salt, kdfIterations, envKeySize, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
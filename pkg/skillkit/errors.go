@@ -0,0 +1,167 @@
+package skillkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Error codes of ErrorOutput
+const (
+	CodeError         = "error"          // Not classified
+	CodeInvalidInput  = "invalid_input"  // Wrong flags or arguments, see UsageError
+	CodeMissingConfig = "missing_config" // Required environment variable not set
+	CodeUnauthorized  = "unauthorized"   // 401, check the token or API key
+	CodeForbidden     = "forbidden"      // 403
+	CodeNotFound      = "not_found"      // 404
+	CodeConflict      = "conflict"       // 409
+	CodeRateLimited   = "rate_limited"   // 429
+	CodeAPIError      = "api_error"      // Other 4xx
+	CodeServerError   = "server_error"   // 5xx
+	CodeNetworkError  = "network_error"  // No response from the server
+	CodeTimeout       = "timeout"        // --timeout expired or 408
+	CodeInterrupted   = "interrupted"    // SIGINT or SIGTERM
+	CodeSkillError    = "skill_error"    // Sibling skill failed without a code
+)
+
+// ErrorOutput is the error contract of skills: one JSON line on stderr that
+// Claude can act on without parsing the message. Error repeats Message for
+// readers of the former {"error": ...} output. Retryable reports whether the
+// same call may succeed later; the client has already retried it as far as
+// its RetryPolicy allows.
+type ErrorOutput struct {
+	Error      string `json:"error"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	HTTPStatus int    `json:"http_status,omitempty"`
+	Method     string `json:"method,omitempty"`
+	Endpoint   string `json:"endpoint,omitempty"`
+	Retryable  bool   `json:"retryable"`
+}
+
+// APIError is returned by HTTPClient for 4xx and 5xx responses
+type APIError struct {
+	Method     string
+	Endpoint   string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// RequestError is returned by HTTPClient if a request got no response, e.g.
+// on connection errors or when the command context was canceled
+type RequestError struct {
+	Method   string
+	Endpoint string
+	Err      error
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// UsageError marks invalid flags or arguments (code invalid_input). Flag
+// parsing and argument validation errors of CLI commands are marked
+// automatically.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// Usagef returns a UsageError formatted like fmt.Errorf, e.g.
+// skillkit.Usagef("--title is required")
+func Usagef(format string, args ...interface{}) error {
+	return &UsageError{Err: fmt.Errorf(format, args...)}
+}
+
+// WriteError writes the ErrorOutput of err to w as one line
+func WriteError(w io.Writer, err error) {
+	json.NewEncoder(w).Encode(NewErrorOutput(err))
+}
+
+// NewErrorOutput classifies err by the error types of skillkit found in its
+// chain
+func NewErrorOutput(err error) ErrorOutput {
+	out := ErrorOutput{Error: err.Error(), Code: CodeError, Message: err.Error()}
+
+	var apiErr *APIError
+	var reqErr *RequestError
+	var usageErr *UsageError
+	var skillErr *SkillError
+	switch {
+	case errors.As(err, &apiErr):
+		out.HTTPStatus = apiErr.StatusCode
+		out.Method, out.Endpoint = apiErr.Method, apiErr.Endpoint
+		out.Code = statusCode(apiErr.StatusCode)
+		switch out.Code {
+		case CodeRateLimited:
+			out.Retryable = true
+		case CodeServerError, CodeTimeout:
+			out.Retryable = idempotent(apiErr.Method)
+		}
+	case errors.As(err, &reqErr):
+		out.Method, out.Endpoint = reqErr.Method, reqErr.Endpoint
+		switch {
+		case errors.Is(err, ErrInterrupted):
+			out.Code = CodeInterrupted
+		case errors.Is(err, ErrTimeout):
+			out.Code = CodeTimeout
+			out.Retryable = idempotent(reqErr.Method)
+		default:
+			out.Code = CodeNetworkError
+			out.Retryable = idempotent(reqErr.Method) || dialError(err)
+		}
+	case errors.As(err, &skillErr):
+		out.Code = CodeSkillError
+		if skillErr.Code != "" {
+			out.Code = skillErr.Code
+		}
+		out.Retryable = skillErr.Retryable
+	case errors.As(err, &usageErr):
+		out.Code = CodeInvalidInput
+	case errors.Is(err, ErrMissingEnv):
+		out.Code = CodeMissingConfig
+	case errors.Is(err, ErrInterrupted):
+		out.Code = CodeInterrupted
+	case errors.Is(err, ErrTimeout):
+		out.Code = CodeTimeout
+	}
+	return out
+}
+
+// statusCode returns the error code of an HTTP status
+func statusCode(status int) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return CodeUnauthorized
+	case status == http.StatusForbidden:
+		return CodeForbidden
+	case status == http.StatusNotFound:
+		return CodeNotFound
+	case status == http.StatusRequestTimeout:
+		return CodeTimeout
+	case status == http.StatusConflict:
+		return CodeConflict
+	case status == http.StatusTooManyRequests:
+		return CodeRateLimited
+	case status >= 500:
+		return CodeServerError
+	}
+	return CodeAPIError
+}
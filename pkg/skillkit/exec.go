@@ -0,0 +1,234 @@
+package skillkit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// SkillsDirEnvVar overrides the skills folder used to resolve sibling skills
+const SkillsDirEnvVar = "SKILLFACTORY_SKILLS_DIR"
+
+// ErrSkillNotFound is returned when a sibling skill is not deployed
+var ErrSkillNotFound = errors.New("skill not found")
+
+// SkillError is returned by Exec when a sibling skill exits with an error
+type SkillError struct {
+	Skill     string
+	ExitCode  int
+	Message   string // "error" field of the skill's JSON error output, or its raw stderr
+	Code      string // "code" of the skill's ErrorOutput, empty for other output
+	Retryable bool   // "retryable" of the skill's ErrorOutput
+}
+
+func (e *SkillError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("skill %s exited with code %d", e.Skill, e.ExitCode)
+	}
+	return fmt.Sprintf("skill %s: %s", e.Skill, e.Message)
+}
+
+// SkillsDir returns the folder the running skill is deployed in
+// (<skills folder>/<skill>/bin/<binary>), or SKILLFACTORY_SKILLS_DIR if set
+func SkillsDir() (string, error) {
+	if dir := os.Getenv(SkillsDirEnvVar); dir != "" {
+		return dir, nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return filepath.Dir(filepath.Dir(filepath.Dir(exe))), nil
+}
+
+// SkillBinary resolves the binary of a deployed sibling skill. name is the
+// skill folder name or the skill name recorded in its deploy.lock.
+func SkillBinary(name string) (string, error) {
+	skillsDir, err := SkillsDir()
+	if err != nil {
+		return "", err
+	}
+
+	if binary, ok := findBinary(filepath.Join(skillsDir, name, "bin"), name); ok {
+		return binary, nil
+	}
+
+	// The skill may be deployed under a different folder name
+	entries, err := os.ReadDir(skillsDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read skills folder: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || lockedSkill(filepath.Join(skillsDir, entry.Name())) != name {
+			continue
+		}
+		if binary, ok := findBinary(filepath.Join(skillsDir, entry.Name(), "bin"), name); ok {
+			return binary, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s (in %s)", ErrSkillNotFound, name, skillsDir)
+}
+
+// Exec runs a deployed sibling skill with args and returns its stdout. A
+// non-nil input is encoded as JSON and piped to the skill's stdin. Canceling
+// ctx, e.g. by Ctrl+C or the --timeout of the calling skill, kills the skill.
+// The skill gets the environment of the caller without the variables of the
+// caller's .env and SKILLKIT_*, so it loads its own .env and redact rules.
+func Exec(ctx context.Context, name string, input interface{}, args ...string) ([]byte, error) {
+	binary, err := SkillBinary(name)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Env = siblingEnv()
+	if input != nil {
+		data, err := json.Marshal(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode input: %w", err)
+		}
+		cmd.Stdin = bytes.NewReader(data)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ContextError(ctx); ctxErr != nil {
+			return nil, fmt.Errorf("skill %s: %w", name, ctxErr)
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("failed to run skill %s: %w", name, err)
+		}
+		skillErr := &SkillError{Skill: name, ExitCode: exitErr.ExitCode()}
+		if out, ok := errorOutput(stderr.Bytes()); ok {
+			skillErr.Message, skillErr.Code, skillErr.Retryable = out.Error, out.Code, out.Retryable
+		} else {
+			skillErr.Message = strings.TrimSpace(stderr.String())
+		}
+		return stdout.Bytes(), skillErr
+	}
+	return stdout.Bytes(), nil
+}
+
+// ExecJSON runs a sibling skill like Exec and decodes its JSON output into out.
+// Skills printing one object per line (NDJSON) are decoded into a slice.
+func ExecJSON(ctx context.Context, name string, input interface{}, out interface{}, args ...string) error {
+	data, err := Exec(ctx, name, input, args...)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var values []json.RawMessage
+	for {
+		var v json.RawMessage
+		if err := dec.Decode(&v); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to decode output of skill %s: %w", name, err)
+		}
+		values = append(values, v)
+	}
+
+	switch len(values) {
+	case 0:
+		return fmt.Errorf("skill %s returned no output", name)
+	case 1:
+		data = values[0]
+	default:
+		data, _ = json.Marshal(values)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode output of skill %s: %w", name, err)
+	}
+	return nil
+}
+
+// siblingEnv returns the environment for a sibling skill: the process
+// environment without the variables of the caller's .env and SKILLKIT_*
+func siblingEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if envFileVars[name] || strings.HasPrefix(name, "SKILLKIT_") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+// findBinary returns the skill binary in binDir: the file named after the
+// skill, or the only executable file
+func findBinary(binDir, name string) (string, bool) {
+	candidate := filepath.Join(binDir, name)
+	if runtime.GOOS == "windows" {
+		candidate += ".exe"
+	}
+	if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+		return candidate, true
+	}
+
+	entries, err := os.ReadDir(binDir)
+	if err != nil {
+		return "", false
+	}
+	var found []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if (runtime.GOOS == "windows" && strings.HasSuffix(entry.Name(), ".exe")) || info.Mode()&0111 != 0 {
+			found = append(found, filepath.Join(binDir, entry.Name()))
+		}
+	}
+	if len(found) != 1 {
+		return "", false
+	}
+	return found[0], true
+}
+
+// lockedSkill returns the skill name recorded in a deployed skill's deploy.lock
+func lockedSkill(deployPath string) string {
+	data, err := os.ReadFile(filepath.Join(deployPath, "deploy.lock"))
+	if err != nil {
+		return ""
+	}
+	var lock struct {
+		Skill string `json:"skill"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return ""
+	}
+	return lock.Skill
+}
+
+// errorOutput returns the first {"error": "..."} line of a skill's stderr
+func errorOutput(stderr []byte) (ErrorOutput, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(stderr))
+	for scanner.Scan() {
+		var out ErrorOutput
+		if json.Unmarshal(scanner.Bytes(), &out) == nil && out.Error != "" {
+			return out, true
+		}
+	}
+	return ErrorOutput{}, false
+}
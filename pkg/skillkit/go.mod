@@ -0,0 +1,12 @@
+module github.com/petervogelmann/skillfactory/pkg/skillkit
+
+go 1.21
+
+require (
+	github.com/joho/godotenv v1.5.1
+	github.com/spf13/cobra v1.8.0
+	github.com/spf13/pflag v1.0.5
+	golang.org/x/crypto v0.31.0
+)
+
+require github.com/inconshreveable/mousetrap v1.1.0 // indirect
@@ -0,0 +1,262 @@
+package skillkit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// RetriesEnvVar sets how often a failed API request is retried, e.g.
+// SKILL_HTTP_RETRIES=0 to fail on the first error
+const RetriesEnvVar = "SKILL_HTTP_RETRIES"
+
+// BackoffEnvVar sets the wait before the first retry, e.g. SKILL_HTTP_BACKOFF=1s.
+// It doubles with every further retry.
+const BackoffEnvVar = "SKILL_HTTP_BACKOFF"
+
+const (
+	defaultRetries = 2
+	defaultBackoff = 500 * time.Millisecond
+	maxRetryWait   = 30 * time.Second // Also caps Retry-After
+	gzipMinSize    = 1024             // Request body size from which GzipRequests compresses
+)
+
+// RetryPolicy controls the retries of HTTPClient. Requests are retried on
+// 429 and on connection errors before the request was sent; idempotent
+// requests (GET, HEAD, OPTIONS) also on other network errors and 5xx.
+type RetryPolicy struct {
+	Retries int           // Retries after the first attempt
+	Backoff time.Duration // Wait before the first retry, doubled per retry, with jitter
+}
+
+// RetryPolicyFromEnv returns the policy set by SKILL_HTTP_RETRIES and
+// SKILL_HTTP_BACKOFF. Invalid values keep the default of 2 retries after 500ms.
+func RetryPolicyFromEnv() RetryPolicy {
+	policy := RetryPolicy{Retries: defaultRetries, Backoff: defaultBackoff}
+	if n, err := strconv.Atoi(os.Getenv(RetriesEnvVar)); err == nil && n >= 0 {
+		policy.Retries = n
+	}
+	if d, err := time.ParseDuration(os.Getenv(BackoffEnvVar)); err == nil && d >= 0 {
+		policy.Backoff = d
+	}
+	return policy
+}
+
+// wait returns the jittered wait before retry n (0 for the first retry),
+// between half and the full exponential backoff
+func (p RetryPolicy) wait(n int) time.Duration {
+	if p.Backoff == 0 {
+		return 0
+	}
+	d := p.Backoff << n
+	if d <= 0 || d > maxRetryWait {
+		d = maxRetryWait
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// HTTPClient is the core of the skill API clients: JSON requests against a
+// base URL with fixed headers, retries with backoff and strict decoding.
+// Skills embed it in their client and set the authentication header:
+//
+//	c := skillkit.NewHTTPClient(baseURL, http.DefaultTransport)
+//	c.Header.Set("Authorization", "Bearer "+token)
+//	return &Client{HTTPClient: c}, nil
+type HTTPClient struct {
+	BaseURL      string      // Prepended to the endpoint of every request
+	Header       http.Header // Sent with every request
+	GzipRequests bool        // Compress request bodies (the server must accept Content-Encoding: gzip)
+	Retry        RetryPolicy
+
+	httpClient  *http.Client
+	strict      io.Writer // Receives schema drift warnings in strict mode, see SetStrict
+	debug       io.Writer // Receives the request log, see SetDebug
+	debugBodies bool
+}
+
+// NewHTTPClient creates a client sending JSON to baseURL through transport,
+// wrapped for SKILL_RECORD / SKILL_REPLAY, with the retry policy of the
+// environment and a timeout of 30s per attempt
+func NewHTTPClient(baseURL string, transport http.RoundTripper) *HTTPClient {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	return &HTTPClient{
+		BaseURL: baseURL,
+		Header:  header,
+		Retry:   RetryPolicyFromEnv(),
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: Transport(transport),
+		},
+	}
+}
+
+// Request performs an HTTP request and returns the response body. Failed
+// requests return an *APIError with the status or a *RequestError if there
+// was no response. Canceling ctx aborts the request, also while waiting for a
+// retry; the error then reports the cause, e.g. an interrupt or the --timeout.
+func (c *HTTPClient) Request(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+	var raw, payload []byte
+	compressed := false
+	if body != nil {
+		var err error
+		if raw, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		payload = raw
+		if c.GzipRequests && len(raw) >= gzipMinSize {
+			if payload, err = gzipBytes(raw); err != nil {
+				return nil, fmt.Errorf("failed to compress request body: %w", err)
+			}
+			compressed = true
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		respBody, status, retryAfter, err := c.do(ctx, method, endpoint, payload, compressed)
+		c.logRequest(method, endpoint, attempt, time.Since(start), status, raw, respBody, err)
+		if err == nil || retryAfter < 0 || attempt >= c.Retry.Retries {
+			return respBody, err
+		}
+		wait := c.Retry.wait(attempt)
+		if retryAfter > 0 {
+			wait = min(retryAfter, maxRetryWait)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, &RequestError{Method: method, Endpoint: endpoint, Err: ContextError(ctx)}
+		case <-time.After(wait):
+		}
+	}
+}
+
+// do performs one attempt and returns the response status, 0 without a
+// response. retryAfter is negative if the error must not be retried,
+// otherwise the wait the server asked for with Retry-After (0: none).
+func (c *HTTPClient) do(ctx context.Context, method, endpoint string, payload []byte, compressed bool) (body []byte, status int, retryAfter time.Duration, err error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+endpoint, reqBody)
+	if err != nil {
+		return nil, 0, -1, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range c.Header {
+		req.Header[name] = values
+	}
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctxErr := ContextError(ctx); ctxErr != nil {
+			return nil, 0, -1, &RequestError{Method: method, Endpoint: endpoint, Err: ctxErr}
+		}
+		if !idempotent(method) && !dialError(err) {
+			retryAfter = -1
+		}
+		return nil, 0, retryAfter, &RequestError{Method: method, Endpoint: endpoint, Err: fmt.Errorf("request failed: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if ctxErr := ContextError(ctx); ctxErr != nil {
+			return nil, resp.StatusCode, -1, &RequestError{Method: method, Endpoint: endpoint, Err: ctxErr}
+		}
+		return nil, resp.StatusCode, -1, &RequestError{Method: method, Endpoint: endpoint, Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	if resp.StatusCode >= 400 {
+		err := &APIError{Method: method, Endpoint: endpoint, StatusCode: resp.StatusCode, Body: string(respBody)}
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			return nil, resp.StatusCode, parseRetryAfter(resp.Header.Get("Retry-After")), err
+		case resp.StatusCode >= 500 && idempotent(method):
+			return nil, resp.StatusCode, parseRetryAfter(resp.Header.Get("Retry-After")), err
+		}
+		return nil, resp.StatusCode, -1, err
+	}
+	return respBody, resp.StatusCode, 0, nil
+}
+
+// SetStrict enables strict decoding of responses (--strict): unknown fields
+// fail, unexpected and missing fields are reported to warnings
+func (c *HTTPClient) SetStrict(warnings io.Writer) {
+	c.strict = warnings
+}
+
+// Decode unmarshals a response into v, strictly after SetStrict
+func (c *HTTPClient) Decode(data []byte, v interface{}) error {
+	return DecodeJSON(data, v, c.strict != nil, c.strict)
+}
+
+// Get performs a GET request
+func (c *HTTPClient) Get(ctx context.Context, endpoint string) ([]byte, error) {
+	return c.Request(ctx, http.MethodGet, endpoint, nil)
+}
+
+// Post performs a POST request
+func (c *HTTPClient) Post(ctx context.Context, endpoint string, body interface{}) ([]byte, error) {
+	return c.Request(ctx, http.MethodPost, endpoint, body)
+}
+
+// Put performs a PUT request
+func (c *HTTPClient) Put(ctx context.Context, endpoint string, body interface{}) ([]byte, error) {
+	return c.Request(ctx, http.MethodPut, endpoint, body)
+}
+
+// Delete performs a DELETE request
+func (c *HTTPClient) Delete(ctx context.Context, endpoint string) ([]byte, error) {
+	return c.Request(ctx, http.MethodDelete, endpoint, nil)
+}
+
+// idempotent reports whether a request of method can be repeated after the
+// server may have processed it. PUT and DELETE are not included: some APIs,
+// e.g. Vikunja, create resources with PUT.
+func idempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// dialError reports whether err happened while connecting, before anything
+// was sent
+func dialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// parseRetryAfter returns the wait of a Retry-After header in seconds, 0 if
+// it is missing or an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// gzipBytes compresses data with gzip
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
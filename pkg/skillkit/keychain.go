@@ -0,0 +1,40 @@
+package skillkit
+
+import (
+	"errors"
+	"strings"
+)
+
+// KeychainPrefix marks .env values that are stored in the OS keychain
+const KeychainPrefix = "keychain:"
+
+// ErrKeychainUnavailable is returned when no supported keychain exists on this system
+var ErrKeychainUnavailable = errors.New("no supported OS keychain available")
+
+// KeychainRef returns the .env value referencing a keychain entry
+func KeychainRef(service, account string) string {
+	return KeychainPrefix + service + "/" + account
+}
+
+// ParseKeychainRef splits a "keychain:service/account" value
+func ParseKeychainRef(value string) (service, account string, ok bool) {
+	ref, found := strings.CutPrefix(value, KeychainPrefix)
+	if !found {
+		return "", "", false
+	}
+	service, account, ok = strings.Cut(ref, "/")
+	if !ok || service == "" || account == "" {
+		return "", "", false
+	}
+	return service, account, true
+}
+
+// SetSecret stores a secret in the OS keychain, replacing an existing entry
+func SetSecret(service, account, secret string) error {
+	return setSecret(service, account, secret)
+}
+
+// GetSecret reads a secret from the OS keychain
+func GetSecret(service, account string) (string, error) {
+	return getSecret(service, account)
+}
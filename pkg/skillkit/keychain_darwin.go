@@ -0,0 +1,47 @@
+package skillkit
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// macOS Keychain via the security CLI
+
+// setSecret sends the command to security -i on stdin, keeping the secret
+// off the argv where other users could read it with ps
+func setSecret(service, account, secret string) error {
+	if strings.ContainsAny(secret, "\r\n") {
+		return fmt.Errorf("security add-generic-password: secrets with line breaks are not supported")
+	}
+	command := strings.Join([]string{
+		"add-generic-password", "-U",
+		"-s", securityQuote(service),
+		"-a", securityQuote(account),
+		"-w", securityQuote(secret),
+	}, " ")
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(command + "\n")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("security add-generic-password: %s", strings.TrimSpace(string(output)))
+	}
+	// security -i reports failed commands on its output only, check the entry
+	if stored, err := getSecret(service, account); err != nil || stored != secret {
+		return fmt.Errorf("security add-generic-password: %s", strings.TrimSpace(strings.TrimPrefix(string(output), "security>")))
+	}
+	return nil
+}
+
+// securityQuote quotes an argument for the command line of security -i
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func getSecret(service, account string) (string, error) {
+	output, err := exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	if err != nil {
+		return "", fmt.Errorf("keychain entry %s/%s not found", service, account)
+	}
+	return strings.TrimSuffix(string(output), "\n"), nil
+}
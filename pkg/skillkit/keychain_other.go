@@ -0,0 +1,11 @@
+//go:build !darwin && !linux && !freebsd && !openbsd && !netbsd && !windows
+
+package skillkit
+
+func setSecret(service, account, secret string) error {
+	return ErrKeychainUnavailable
+}
+
+func getSecret(service, account string) (string, error) {
+	return "", ErrKeychainUnavailable
+}
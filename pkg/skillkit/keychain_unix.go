@@ -0,0 +1,34 @@
+//go:build linux || freebsd || openbsd || netbsd
+
+package skillkit
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Secret Service (GNOME Keyring, KWallet) via the secret-tool CLI
+
+func setSecret(service, account, secret string) error {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return ErrKeychainUnavailable
+	}
+	cmd := exec.Command("secret-tool", "store", "--label="+service+"/"+account, "service", service, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool store: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func getSecret(service, account string) (string, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "", ErrKeychainUnavailable
+	}
+	output, err := exec.Command("secret-tool", "lookup", "service", service, "account", account).Output()
+	if err != nil {
+		return "", fmt.Errorf("keychain entry %s/%s not found", service, account)
+	}
+	return strings.TrimSuffix(string(output), "\n"), nil
+}
@@ -0,0 +1,38 @@
+package skillkit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseID parses the numeric ID argument of a command
+func ParseID(s string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID: %s", s)
+	}
+	return id, nil
+}
+
+// ParseIntList parses a comma-separated list of numbers like "1,2, 3", e.g.
+// IDs or weekdays; empty entries are skipped
+func ParseIntList[T ~int | ~int64](s string) ([]T, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	result := make([]T, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s': %w", p, err)
+		}
+		result = append(result, T(n))
+	}
+	return result, nil
+}
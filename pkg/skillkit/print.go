@@ -0,0 +1,51 @@
+package skillkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONPrinter returns the printJSON function passed to RegisterCommands. Each
+// value is written as a single line so output stays NDJSON friendly. Fields
+// declared by SKILLKIT_REDACT for the command run by Execute are redacted
+// before they are written.
+func JSONPrinter(w io.Writer) func(interface{}) error {
+	return func(v interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		rules, err := RedactRules()
+		if err != nil {
+			return err
+		}
+		command, _ := activeCommand.Load().(string)
+		if data, err = Redact(data, rules, command); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+}
+
+// PrintError writes an unclassified ErrorOutput with msg to w. Use
+// WriteError for errors, it reports their code.
+func PrintError(w io.Writer, msg string) {
+	json.NewEncoder(w).Encode(ErrorOutput{Error: msg, Code: CodeError, Message: msg})
+}
+
+// PrintWarning writes {"warning": msg} to w
+func PrintWarning(w io.Writer, msg string) {
+	json.NewEncoder(w).Encode(map[string]string{"warning": msg})
+}
+
+// LeanSlice converts API objects to their lean output, e.g.
+// LeanSlice(tasks, (*Task).ToLean)
+func LeanSlice[T, L any](items []T, toLean func(*T) L) []L {
+	result := make([]L, len(items))
+	for i := range items {
+		result[i] = toLean(&items[i])
+	}
+	return result
+}
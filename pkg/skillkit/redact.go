@@ -0,0 +1,193 @@
+package skillkit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/spf13/cobra"
+)
+
+// RedactEnvVar holds the redaction rules of a skill as JSON array of
+// RedactRule. SkillFactory writes it to the deployed .env from the redact
+// section of skill.yaml.
+const RedactEnvVar = "SKILLKIT_REDACT"
+
+// Redacted replaces the value of a redacted field
+const Redacted = "[redacted]"
+
+// RedactRule declares fields whose values never reach the output of a command
+type RedactRule struct {
+	Command string   `json:"command,omitempty"` // Command below the root, e.g. "tasks list"; "tasks" covers all its subcommands, empty all commands
+	Fields  []string `json:"fields"`            // Field names matched at any depth, or dotted paths like "owner.email"
+	Hash    bool     `json:"hash,omitempty"`    // Replace values with a short hash instead of Redacted, so equal values stay recognizable
+}
+
+// activeCommand is the command run by Execute, e.g. "tasks list", for the
+// rules of JSONPrinter
+var activeCommand atomic.Value
+
+// setActiveCommand records cmd as the running command
+func setActiveCommand(cmd *cobra.Command) {
+	path := ""
+	if cmd.HasParent() {
+		path = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	}
+	activeCommand.Store(path)
+}
+
+// RedactRules returns the rules of SKILLKIT_REDACT
+func RedactRules() ([]RedactRule, error) {
+	value := os.Getenv(RedactEnvVar)
+	if value == "" {
+		return nil, nil
+	}
+	var rules []RedactRule
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", RedactEnvVar, err)
+	}
+	return rules, nil
+}
+
+// Redact replaces the values of the fields the rules declare for command in
+// the JSON document data. Fields keep their order; null and empty values are
+// kept since they reveal nothing.
+func Redact(data []byte, rules []RedactRule, command string) ([]byte, error) {
+	var active []RedactRule
+	for _, rule := range rules {
+		if rule.Command == "" || command == rule.Command || strings.HasPrefix(command, rule.Command+" ") {
+			active = append(active, rule)
+		}
+	}
+	if len(active) == 0 {
+		return data, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out bytes.Buffer
+	if err := redactValue(dec, &out, nil, active); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// redactValue copies the next value of dec to out, redacting the fields
+// below it. path holds the object keys leading to the value.
+func redactValue(dec *json.Decoder, out *bytes.Buffer, path []string, rules []RedactRule) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return writeJSON(out, tok)
+	}
+
+	switch delim {
+	case '{':
+		out.WriteByte('{')
+		for i := 0; dec.More(); i++ {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key := tok.(string)
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if err := writeJSON(out, key); err != nil {
+				return err
+			}
+			out.WriteByte(':')
+
+			fieldPath := append(path[:len(path):len(path)], key)
+			if rule, ok := matchRule(rules, fieldPath); ok {
+				var raw json.RawMessage
+				if err := dec.Decode(&raw); err != nil {
+					return err
+				}
+				if err := writeJSON(out, redactedValue(raw, rule.Hash)); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := redactValue(dec, out, fieldPath, rules); err != nil {
+				return err
+			}
+		}
+		out.WriteByte('}')
+	case '[':
+		out.WriteByte('[')
+		for i := 0; dec.More(); i++ {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if err := redactValue(dec, out, path, rules); err != nil {
+				return err
+			}
+		}
+		out.WriteByte(']')
+	}
+	// Closing delimiter
+	_, err = dec.Token()
+	return err
+}
+
+// matchRule returns the rule redacting the field at path: a field name
+// matches the last key, a dotted path the last keys
+func matchRule(rules []RedactRule, path []string) (RedactRule, bool) {
+	for _, rule := range rules {
+		for _, field := range rule.Fields {
+			keys := strings.Split(field, ".")
+			if len(keys) > len(path) {
+				continue
+			}
+			tail := path[len(path)-len(keys):]
+			matched := true
+			for i, key := range keys {
+				if !strings.EqualFold(key, tail[i]) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				return rule, true
+			}
+		}
+	}
+	return RedactRule{}, false
+}
+
+// redactedValue returns the replacement of a field value: Redacted, or
+// "sha256:" and 12 hex digits of the hash of the value
+func redactedValue(raw json.RawMessage, hash bool) interface{} {
+	var s string
+	isString := json.Unmarshal(raw, &s) == nil
+	if string(raw) == "null" || (isString && s == "") {
+		return raw
+	}
+	if !hash {
+		return Redacted
+	}
+	if !isString {
+		s = string(raw)
+	}
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// writeJSON appends the JSON encoding of v to out
+func writeJSON(out *bytes.Buffer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	out.Write(data)
+	return nil
+}
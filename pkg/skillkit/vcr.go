@@ -0,0 +1,179 @@
+package skillkit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RecordEnvVar names a directory the HTTP interactions of a skill are
+// recorded to, one fixture file per request
+const RecordEnvVar = "SKILL_RECORD"
+
+// ReplayEnvVar names a directory of fixtures recorded with SKILL_RECORD.
+// Requests are answered from them instead of the network.
+const ReplayEnvVar = "SKILL_REPLAY"
+
+// Interaction is a recorded HTTP request and its response. The host and
+// the request headers are not recorded, so fixtures carry no credentials
+// and replay against any base URL.
+type Interaction struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"` // Path and query
+	Status int             `json:"status"`
+	Type   string          `json:"content_type,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"` // JSON responses
+	Text   string          `json:"text,omitempty"` // Other responses
+}
+
+// Transport returns base wrapped to record interactions with SKILL_RECORD or
+// replay them with SKILL_REPLAY, or base itself without either. API clients
+// use it for their http.Client:
+//
+//	httpClient: &http.Client{Transport: skillkit.Transport(http.DefaultTransport)}
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if dir := os.Getenv(ReplayEnvVar); dir != "" {
+		return &vcr{dir: dir, replay: true, played: map[string]int{}}
+	}
+	if dir := os.Getenv(RecordEnvVar); dir != "" {
+		return &vcr{dir: dir, base: base, recorded: map[string][]Interaction{}}
+	}
+	return base
+}
+
+// vcr records or replays interactions. Identical requests share a fixture
+// file holding their responses in order, so a repeated poll replays the
+// recorded sequence; the last response is repeated when it is exhausted.
+type vcr struct {
+	dir    string
+	base   http.RoundTripper
+	replay bool
+
+	mu       sync.Mutex
+	recorded map[string][]Interaction // Fixture file → interactions recorded by this process
+	played   map[string]int           // Fixture file → responses replayed
+}
+
+func (v *vcr) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	file := filepath.Join(v.dir, fixtureName(req, body))
+
+	if v.replay {
+		return v.play(req, file)
+	}
+	return v.record(req, file)
+}
+
+// play answers req from its fixture file
+func (v *vcr) play(req *http.Request, file string) (*http.Response, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("no fixture for %s %s in %s (record it with %s)", req.Method, req.URL.RequestURI(), v.dir, RecordEnvVar)
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(data, &interactions); err != nil || len(interactions) == 0 {
+		return nil, fmt.Errorf("invalid fixture %s: %v", file, err)
+	}
+
+	v.mu.Lock()
+	i := min(v.played[file], len(interactions)-1)
+	v.played[file]++
+	v.mu.Unlock()
+
+	in := interactions[i]
+	body := []byte(in.Text)
+	if len(in.Body) > 0 {
+		body = in.Body
+	}
+	header := http.Header{}
+	if in.Type != "" {
+		header.Set("Content-Type", in.Type)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		StatusCode:    in.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// record sends req and appends the response to its fixture file. The first
+// request of a process overwrites a fixture from an earlier recording.
+func (v *vcr) record(req *http.Request, file string) (*http.Response, error) {
+	resp, err := v.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	in := Interaction{
+		Method: req.Method,
+		URL:    req.URL.RequestURI(),
+		Status: resp.StatusCode,
+		Type:   resp.Header.Get("Content-Type"),
+	}
+	if json.Valid(body) {
+		in.Body = body
+	} else {
+		in.Text = string(body)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.recorded[file] = append(v.recorded[file], in)
+	data, err := json.MarshalIndent(v.recorded[file], "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(v.dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to record fixture: %w", err)
+	}
+	return resp, nil
+}
+
+// unsafeFileChars are replaced in fixture file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fixtureName returns the fixture file of a request, e.g.
+// "GET_tasks_42_3f9c2a1b.json": method, path and a hash of the path, query
+// and body
+func fixtureName(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	path := strings.Trim(unsafeFileChars.ReplaceAllString(req.URL.Path, "_"), "_")
+	if len(path) > 80 {
+		path = path[:80]
+	}
+	return fmt.Sprintf("%s_%s_%s.json", req.Method, path, hex.EncodeToString(hash.Sum(nil))[:8])
+}
@@ -0,0 +1,106 @@
+package brief
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats of the today command
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// RegisterTodayCommand creates the today command
+func RegisterTodayCommand(s *Service, printJSON func(interface{}) error) *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "today",
+		Short: "Show today's brief (tasks due, pending habits, events)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			brief := s.Today(cmd.Context())
+			switch format {
+			case FormatJSON:
+				return printJSON(brief)
+			case FormatMarkdown:
+				fmt.Fprint(cmd.OutOrStdout(), Markdown(brief))
+				return nil
+			default:
+				return fmt.Errorf("invalid format %q (expected %s or %s)", format, FormatJSON, FormatMarkdown)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&format, "format", "f", FormatJSON, "Output format: json or markdown")
+	return cmd
+}
+
+// RegisterSourcesCommand creates the sources command
+func RegisterSourcesCommand(s *Service, printJSON func(interface{}) error) *cobra.Command {
+	return &cobra.Command{
+		Use:   "sources",
+		Short: "Show which deployed skills the brief is built from",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printJSON(s.Sources())
+		},
+	}
+}
+
+// Markdown renders a brief as Markdown
+func Markdown(b *Brief) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Briefing %s\n\n", b.Date)
+
+	sb.WriteString("## Tasks due\n\n")
+	if len(b.Tasks) == 0 {
+		sb.WriteString("- none\n")
+	}
+	for _, t := range b.Tasks {
+		line := fmt.Sprintf("- %s (#%d", t.Title, t.ID)
+		if t.DueDate != nil {
+			line += ", due " + strings.SplitN(*t.DueDate, "T", 2)[0]
+		}
+		if t.Priority > 0 {
+			line += fmt.Sprintf(", priority %d", t.Priority)
+		}
+		sb.WriteString(line + ")\n")
+	}
+
+	sb.WriteString("\n## Habits pending\n\n")
+	if len(b.HabitsPending) == 0 {
+		sb.WriteString("- none\n")
+	}
+	for _, h := range b.HabitsPending {
+		if h.TargetValue != nil {
+			fmt.Fprintf(&sb, "- %s (target %s)\n", h.Title, strings.TrimSpace(fmt.Sprintf("%g %s", *h.TargetValue, h.Unit)))
+		} else {
+			fmt.Fprintf(&sb, "- %s\n", h.Title)
+		}
+	}
+
+	if len(b.Events) > 0 {
+		sb.WriteString("\n## Events\n\n")
+		for _, e := range b.Events {
+			line := "- "
+			if e.Start != "" {
+				line += e.Start + " "
+			}
+			line += e.Name()
+			if e.Location != "" {
+				line += " @ " + e.Location
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	if len(b.Errors) > 0 {
+		sb.WriteString("\n## Unavailable\n\n")
+		for _, source := range []string{SourceTasks, SourceHabits, SourceCalendar} {
+			if msg, ok := b.Errors[source]; ok {
+				fmt.Fprintf(&sb, "- %s: %s\n", source, msg)
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,190 @@
+package brief
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+)
+
+// tasksDueFilter selects open tasks due today or earlier
+const tasksDueFilter = "due_date < now/d+1d"
+
+// SourcesFromEnv reads the configured sources from the environment
+func SourcesFromEnv() Sources {
+	return Sources{
+		TasksSkill:      os.Getenv("BRIEFING_TASKS_SKILL"),
+		HabitsSkill:     os.Getenv("BRIEFING_HABITS_SKILL"),
+		CalendarCommand: os.Getenv("BRIEFING_CALENDAR_COMMAND"),
+	}
+}
+
+// Service builds briefs from sibling skills
+type Service struct {
+	sources Sources
+}
+
+// NewService creates a new brief service
+func NewService(sources Sources) *Service {
+	return &Service{sources: sources}
+}
+
+// Today builds the brief for the current day. Failing sources are recorded in
+// Brief.Errors so the remaining sections are still returned; once ctx is
+// canceled the remaining sources fail with the cause.
+func (s *Service) Today(ctx context.Context) *Brief {
+	today := skillkit.Today()
+	brief := &Brief{
+		Date:          today,
+		Tasks:         []Task{},
+		HabitsPending: []Habit{},
+	}
+	fail := func(source string, err error) {
+		if brief.Errors == nil {
+			brief.Errors = make(map[string]string)
+		}
+		brief.Errors[source] = err.Error()
+	}
+
+	if s.sources.TasksSkill != "" {
+		tasks, err := s.tasksDue(ctx)
+		if err != nil {
+			fail(SourceTasks, err)
+		} else {
+			brief.Tasks = tasks
+		}
+	}
+	if s.sources.HabitsSkill != "" {
+		habits, err := s.habitsPending(ctx, today)
+		if err != nil {
+			fail(SourceHabits, err)
+		} else {
+			brief.HabitsPending = habits
+		}
+	}
+	if s.sources.CalendarCommand != "" {
+		events, err := s.events(ctx)
+		if err != nil {
+			fail(SourceCalendar, err)
+		} else {
+			brief.Events = events
+		}
+	}
+	return brief
+}
+
+// Sources resolves the configured sources to deployed skill binaries
+func (s *Service) Sources() []SourceStatus {
+	calendarSkill := ""
+	if args := splitCommand(s.sources.CalendarCommand); len(args) > 0 {
+		calendarSkill = args[0]
+	}
+
+	var statuses []SourceStatus
+	for _, src := range []SourceStatus{
+		{Source: SourceTasks, Skill: s.sources.TasksSkill},
+		{Source: SourceHabits, Skill: s.sources.HabitsSkill},
+		{Source: SourceCalendar, Skill: calendarSkill},
+	} {
+		if src.Skill == "" {
+			src.Error = "disabled"
+		} else if binary, err := skillkit.SkillBinary(src.Skill); err != nil {
+			src.Error = err.Error()
+		} else {
+			src.Binary = binary
+		}
+		statuses = append(statuses, src)
+	}
+	return statuses
+}
+
+// tasksDue lists open Vikunja tasks that are due today or overdue
+func (s *Service) tasksDue(ctx context.Context) ([]Task, error) {
+	var tasks []Task
+	err := skillkit.ExecJSON(ctx, s.sources.TasksSkill, nil, &tasks,
+		"tasks", "list", "--filter", tasksDueFilter, "--sort", "due_date", "--order", "asc")
+	if err != nil {
+		return nil, err
+	}
+	if tasks == nil {
+		tasks = []Task{}
+	}
+	return tasks, nil
+}
+
+// habitsPending lists HabitWire habits due today without a check-in or skip for today
+func (s *Service) habitsPending(ctx context.Context, today string) ([]Habit, error) {
+	var habits []Habit
+	if err := skillkit.ExecJSON(ctx, s.sources.HabitsSkill, nil, &habits, "habits", "list", "--today"); err != nil {
+		return nil, err
+	}
+
+	pending := []Habit{}
+	for _, h := range habits {
+		var checkIns []checkIn
+		err := skillkit.ExecJSON(ctx, s.sources.HabitsSkill, nil, &checkIns,
+			"habits", "checkins", h.ID, "--from", today, "--to", today)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get check-ins for %s: %w", h.Title, err)
+		}
+		if !hasCheckIn(checkIns, today) {
+			pending = append(pending, h)
+		}
+	}
+	return pending, nil
+}
+
+// events runs the configured calendar command
+func (s *Service) events(ctx context.Context) ([]Event, error) {
+	args := splitCommand(s.sources.CalendarCommand)
+	var events []Event
+	if err := skillkit.ExecJSON(ctx, args[0], nil, &events, args[1:]...); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// hasCheckIn reports whether check-ins contain an entry for date
+func hasCheckIn(checkIns []checkIn, date string) bool {
+	for _, c := range checkIns {
+		if strings.HasPrefix(c.Date, date) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitCommand splits a command line into fields, honoring single and double quotes
+func splitCommand(command string) []string {
+	var fields []string
+	var field strings.Builder
+	var quote rune
+	inField := false
+
+	for _, r := range command {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			field.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inField = true
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(r)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
@@ -0,0 +1,73 @@
+// Package brief aggregates tasks, habits and events from sibling skills into a daily brief
+package brief
+
+// Source names used in Brief.Errors and the sources command
+const (
+	SourceTasks    = "tasks"
+	SourceHabits   = "habits"
+	SourceCalendar = "calendar"
+)
+
+// Sources configures the sibling skills a brief is built from. Empty values disable a source.
+type Sources struct {
+	TasksSkill      string // Vikunja skill name
+	HabitsSkill     string // HabitWire skill name
+	CalendarCommand string // Skill name followed by arguments, printing events as JSON
+}
+
+// Task is the subset of a Vikunja lean task used in the brief
+type Task struct {
+	ID        int64   `json:"id"`
+	Title     string  `json:"title"`
+	Priority  int     `json:"priority,omitempty"`
+	DueDate   *string `json:"due_date,omitempty"`
+	ProjectID int64   `json:"project_id"`
+}
+
+// Habit is the subset of a HabitWire lean habit used in the brief
+type Habit struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Unit        string   `json:"unit,omitempty"`
+	TargetValue *float64 `json:"target_value,omitempty"`
+}
+
+// checkIn is the subset of a HabitWire lean check-in needed to detect pending habits
+type checkIn struct {
+	Date    string `json:"date"`
+	Skipped bool   `json:"skipped,omitempty"`
+}
+
+// Event is a calendar event. Calendar skills may use title or summary.
+type Event struct {
+	Title    string `json:"title"`
+	Summary  string `json:"summary,omitempty"`
+	Start    string `json:"start,omitempty"`
+	End      string `json:"end,omitempty"`
+	Location string `json:"location,omitempty"`
+}
+
+// Name returns the event title, falling back to the summary
+func (e Event) Name() string {
+	if e.Title != "" {
+		return e.Title
+	}
+	return e.Summary
+}
+
+// Brief is the aggregated daily briefing
+type Brief struct {
+	Date          string            `json:"date"`
+	Tasks         []Task            `json:"tasks"`
+	HabitsPending []Habit           `json:"habits_pending"`
+	Events        []Event           `json:"events,omitempty"`
+	Errors        map[string]string `json:"errors,omitempty"` // Source name to error message
+}
+
+// SourceStatus describes how a source resolves to a deployed skill binary
+type SourceStatus struct {
+	Source string `json:"source"`
+	Skill  string `json:"skill,omitempty"`
+	Binary string `json:"binary,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,28 @@
+// Daily Briefing Skill - aggregates sibling skills for Claude Code
+package main
+
+import (
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+	"github.com/petervogelmann/skillfactory/skills/briefing/brief"
+	"github.com/spf13/cobra"
+)
+
+// version is set via ldflags at build time (see build.ldflags in skill.yaml)
+var version = "dev"
+
+func main() {
+	skillkit.CLI[*brief.Service]{
+		Name:    "briefing",
+		Short:   "Daily briefing for Claude Code",
+		Version: version,
+		NewClient: func() (*brief.Service, error) {
+			return brief.NewService(brief.SourcesFromEnv()), nil
+		},
+		Commands: func(service *brief.Service, printJSON func(interface{}) error) []*cobra.Command {
+			return []*cobra.Command{
+				brief.RegisterTodayCommand(service, printJSON),
+				brief.RegisterSourcesCommand(service, printJSON),
+			}
+		},
+	}.Main()
+}
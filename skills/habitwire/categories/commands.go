@@ -1,10 +1,9 @@
 package categories
 
 import (
-	"fmt"
-
 	"habitwire/client"
 
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
 	"github.com/spf13/cobra"
 )
 
@@ -22,7 +21,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Use:   "list",
 		Short: "List all categories",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			categories, err := service.List()
+			categories, err := service.List(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -36,7 +35,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Short: "Get a category by ID",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			category, err := service.Get(args[0])
+			category, err := service.Get(cmd.Context(), args[0])
 			if err != nil {
 				return err
 			}
@@ -54,7 +53,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Short: "Create a new category",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if createName == "" {
-				return fmt.Errorf("--name is required")
+				return skillkit.Usagef("--name is required")
 			}
 			req := CreateCategoryRequest{
 				Name:  createName,
@@ -64,7 +63,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 			if cmd.Flags().Changed("sort-order") {
 				req.SortOrder = createSortOrder
 			}
-			category, err := service.Create(req)
+			category, err := service.Create(cmd.Context(), req)
 			if err != nil {
 				return err
 			}
@@ -99,7 +98,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 			if cmd.Flags().Changed("sort-order") {
 				req.SortOrder = &updateSortOrder
 			}
-			category, err := service.Update(args[0], req)
+			category, err := service.Update(cmd.Context(), args[0], req)
 			if err != nil {
 				return err
 			}
@@ -117,7 +116,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Short: "Delete a category",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := service.Delete(args[0]); err != nil {
+			if err := service.Delete(cmd.Context(), args[0]); err != nil {
 				return err
 			}
 			return printJSON(map[string]bool{"deleted": true})
@@ -131,7 +130,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Long:  "Reorder categories by providing category IDs in the desired order.",
 		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := service.Reorder(args); err != nil {
+			if err := service.Reorder(cmd.Context(), args); err != nil {
 				return err
 			}
 			return printJSON(map[string]bool{"reordered": true})
@@ -2,7 +2,7 @@
 package categories
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 
 	"habitwire/client"
@@ -19,14 +19,14 @@ func NewService(c *client.Client) *Service {
 }
 
 // List retrieves all categories
-func (s *Service) List() ([]Category, error) {
-	data, err := s.client.Get("/categories")
+func (s *Service) List(ctx context.Context) ([]Category, error) {
+	data, err := s.client.Get(ctx, "/categories")
 	if err != nil {
 		return nil, err
 	}
 
 	var categories []Category
-	if err := json.Unmarshal(data, &categories); err != nil {
+	if err := s.client.Decode(data, &categories); err != nil {
 		return nil, fmt.Errorf("failed to parse categories: %w", err)
 	}
 
@@ -34,16 +34,16 @@ func (s *Service) List() ([]Category, error) {
 }
 
 // Get retrieves a single category by ID
-func (s *Service) Get(categoryID string) (*Category, error) {
+func (s *Service) Get(ctx context.Context, categoryID string) (*Category, error) {
 	endpoint := fmt.Sprintf("/categories/%s", categoryID)
 
-	data, err := s.client.Get(endpoint)
+	data, err := s.client.Get(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
 	var category Category
-	if err := json.Unmarshal(data, &category); err != nil {
+	if err := s.client.Decode(data, &category); err != nil {
 		return nil, fmt.Errorf("failed to parse category: %w", err)
 	}
 
@@ -51,14 +51,14 @@ func (s *Service) Get(categoryID string) (*Category, error) {
 }
 
 // Create creates a new category
-func (s *Service) Create(req CreateCategoryRequest) (*Category, error) {
-	data, err := s.client.Post("/categories", req)
+func (s *Service) Create(ctx context.Context, req CreateCategoryRequest) (*Category, error) {
+	data, err := s.client.Post(ctx, "/categories", req)
 	if err != nil {
 		return nil, err
 	}
 
 	var category Category
-	if err := json.Unmarshal(data, &category); err != nil {
+	if err := s.client.Decode(data, &category); err != nil {
 		return nil, fmt.Errorf("failed to parse created category: %w", err)
 	}
 
@@ -66,16 +66,16 @@ func (s *Service) Create(req CreateCategoryRequest) (*Category, error) {
 }
 
 // Update updates an existing category
-func (s *Service) Update(categoryID string, req UpdateCategoryRequest) (*Category, error) {
+func (s *Service) Update(ctx context.Context, categoryID string, req UpdateCategoryRequest) (*Category, error) {
 	endpoint := fmt.Sprintf("/categories/%s", categoryID)
 
-	data, err := s.client.Put(endpoint, req)
+	data, err := s.client.Put(ctx, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
 
 	var category Category
-	if err := json.Unmarshal(data, &category); err != nil {
+	if err := s.client.Decode(data, &category); err != nil {
 		return nil, fmt.Errorf("failed to parse updated category: %w", err)
 	}
 
@@ -83,14 +83,14 @@ func (s *Service) Update(categoryID string, req UpdateCategoryRequest) (*Categor
 }
 
 // Delete deletes a category
-func (s *Service) Delete(categoryID string) error {
+func (s *Service) Delete(ctx context.Context, categoryID string) error {
 	endpoint := fmt.Sprintf("/categories/%s", categoryID)
-	_, err := s.client.Delete(endpoint)
+	_, err := s.client.Delete(ctx, endpoint)
 	return err
 }
 
 // Reorder reorders categories
-func (s *Service) Reorder(ids []string) error {
-	_, err := s.client.Put("/categories/reorder", map[string][]string{"ids": ids})
+func (s *Service) Reorder(ctx context.Context, ids []string) error {
+	_, err := s.client.Put(ctx, "/categories/reorder", map[string][]string{"ids": ids})
 	return err
 }
@@ -1,6 +1,8 @@
 // Package categories provides category-related types and operations for HabitWire API
 package categories
 
+import "github.com/petervogelmann/skillfactory/pkg/skillkit"
+
 // Category represents a HabitWire category
 type Category struct {
 	ID        string `json:"id,omitempty"`
@@ -48,9 +50,5 @@ func (c *Category) ToLean() CategoryLean {
 
 // ToLeanSlice converts a slice of Categories to lean output
 func ToLeanSlice(categories []Category) []CategoryLean {
-	result := make([]CategoryLean, len(categories))
-	for i := range categories {
-		result[i] = categories[i].ToLean()
-	}
-	return result
+	return skillkit.LeanSlice(categories, (*Category).ToLean)
 }
@@ -3,11 +3,16 @@ module habitwire
 go 1.21
 
 require (
-	github.com/joho/godotenv v1.5.1
+	github.com/petervogelmann/skillfactory/pkg/skillkit v0.0.0
 	github.com/spf13/cobra v1.8.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	golang.org/x/crypto v0.31.0 // indirect
 )
+
+replace github.com/petervogelmann/skillfactory/pkg/skillkit => ../../pkg/skillkit
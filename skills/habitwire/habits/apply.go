@@ -0,0 +1,291 @@
+package habits
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"habitwire/categories"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Definition is a declarative habit list for habitwire apply, read from
+// YAML or JSON
+type Definition struct {
+	Habits []HabitSpec `yaml:"habits"`
+}
+
+// HabitSpec declares a habit, matched by title. Fields left out keep the
+// value of an existing habit, except description, unit, category and icon,
+// which are cleared.
+type HabitSpec struct {
+	Title          string   `yaml:"title"`
+	Description    string   `yaml:"description"`
+	Type           string   `yaml:"type"`      // SIMPLE (default) or TARGET
+	Frequency      string   `yaml:"frequency"` // DAILY, WEEKLY or CUSTOM
+	FrequencyValue *int     `yaml:"frequency_value"`
+	ActiveDays     []int    `yaml:"active_days"`
+	Target         *float64 `yaml:"target"`
+	Increment      *float64 `yaml:"increment"`
+	Unit           string   `yaml:"unit"`
+	Category       string   `yaml:"category"` // Category name or ID, created if missing
+	Icon           string   `yaml:"icon"`
+}
+
+// ApplyResult lists what habitwire apply changed, or would change with
+// --dry-run
+type ApplyResult struct {
+	Changes   []ApplyChange `json:"changes"`
+	Unmanaged []HabitLean   `json:"unmanaged,omitempty"` // Active habits missing in the file, archived with --prune
+	Unchanged int           `json:"unchanged"`
+	DryRun    bool          `json:"dry_run,omitempty"`
+}
+
+// ApplyChange is a created, updated or archived habit, or a created category
+type ApplyChange struct {
+	Kind   string   `json:"kind"` // habit or category
+	ID     string   `json:"id,omitempty"`
+	Title  string   `json:"title"`
+	Action string   `json:"action"` // created, updated or archived
+	Fields []string `json:"fields,omitempty"`
+}
+
+// ParseDefinition reads a habit definition file and checks its habits
+func ParseDefinition(r io.Reader) (*Definition, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	var d Definition
+	if err := dec.Decode(&d); err != nil {
+		return nil, fmt.Errorf("invalid habit definition: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range d.Habits {
+		h := &d.Habits[i]
+		h.Title = strings.TrimSpace(h.Title)
+		h.Type = strings.ToUpper(h.Type)
+		if h.Type == "" {
+			h.Type = "SIMPLE"
+		}
+		h.Frequency = strings.ToUpper(h.Frequency)
+		if err := h.validate(); err != nil {
+			return nil, fmt.Errorf("invalid habit definition: %w", err)
+		}
+		if seen[h.Title] {
+			return nil, fmt.Errorf("invalid habit definition: habit %q is declared twice", h.Title)
+		}
+		seen[h.Title] = true
+	}
+	return &d, nil
+}
+
+// validate checks the rules of the API for a habit spec
+func (h HabitSpec) validate() error {
+	switch {
+	case h.Title == "":
+		return fmt.Errorf("habit without title")
+	case h.Type != "SIMPLE" && h.Type != "TARGET":
+		return fmt.Errorf("habit %q: type must be SIMPLE or TARGET", h.Title)
+	case h.Frequency != "DAILY" && h.Frequency != "WEEKLY" && h.Frequency != "CUSTOM":
+		return fmt.Errorf("habit %q: frequency must be DAILY, WEEKLY or CUSTOM", h.Title)
+	case h.Frequency != "DAILY" && len(h.ActiveDays) == 0:
+		return fmt.Errorf("habit %q: active_days are required for %s", h.Title, h.Frequency)
+	case h.Type == "TARGET" && h.Target == nil:
+		return fmt.Errorf("habit %q: target is required for TARGET", h.Title)
+	}
+	for _, day := range h.ActiveDays {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("habit %q: active day %d is not 0-6", h.Title, day)
+		}
+	}
+	return nil
+}
+
+// Apply reconciles the active habits with a definition: missing habits are
+// created, changed ones updated, and with prune the active habits missing in
+// the definition are archived. Habits are matched by title; archived habits
+// are not considered. With dryRun nothing is changed.
+func (s *Service) Apply(ctx context.Context, d *Definition, prune, dryRun bool) (*ApplyResult, error) {
+	result := &ApplyResult{Changes: []ApplyChange{}, DryRun: dryRun}
+	active, err := s.List(ctx, "", false)
+	if err != nil {
+		return nil, err
+	}
+	categoryIDs, err := s.applyCategories(ctx, d, result, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, spec := range d.Habits {
+		categoryID := categoryIDs[strings.ToLower(spec.Category)]
+		i := slices.IndexFunc(active, func(h Habit) bool { return h.Title == spec.Title })
+		if i < 0 {
+			change := ApplyChange{Kind: "habit", Title: spec.Title, Action: "created"}
+			if !dryRun {
+				habit, err := s.Create(ctx, spec.createRequest(categoryID))
+				if err != nil {
+					return nil, fmt.Errorf("failed to create habit %s: %w", spec.Title, err)
+				}
+				change.ID = habit.ID
+			}
+			result.Changes = append(result.Changes, change)
+			continue
+		}
+
+		habit := active[i]
+		req, fields := spec.updateRequest(habit, categoryID)
+		if len(fields) == 0 {
+			result.Unchanged++
+			continue
+		}
+		if !dryRun {
+			if _, err := s.Update(ctx, habit.ID, req); err != nil {
+				return nil, fmt.Errorf("failed to update habit %s: %w", spec.Title, err)
+			}
+		}
+		result.Changes = append(result.Changes, ApplyChange{Kind: "habit", ID: habit.ID, Title: spec.Title, Action: "updated", Fields: fields})
+	}
+
+	for _, habit := range active {
+		declared := slices.ContainsFunc(d.Habits, func(spec HabitSpec) bool { return spec.Title == habit.Title })
+		switch {
+		case declared:
+		case !prune:
+			result.Unmanaged = append(result.Unmanaged, habit.ToLean())
+		default:
+			if !dryRun {
+				if err := s.Delete(ctx, habit.ID); err != nil {
+					return nil, fmt.Errorf("failed to archive habit %s: %w", habit.Title, err)
+				}
+			}
+			result.Changes = append(result.Changes, ApplyChange{Kind: "habit", ID: habit.ID, Title: habit.Title, Action: "archived"})
+		}
+	}
+	return result, nil
+}
+
+// applyCategories resolves the categories of the definition by name or ID,
+// creating missing ones, and returns their IDs by the lowercase reference
+func (s *Service) applyCategories(ctx context.Context, d *Definition, result *ApplyResult, dryRun bool) (map[string]string, error) {
+	ids := make(map[string]string)
+	if !slices.ContainsFunc(d.Habits, func(spec HabitSpec) bool { return spec.Category != "" }) {
+		return ids, nil
+	}
+	categoryService := categories.NewService(s.client)
+	existing, err := categoryService.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, spec := range d.Habits {
+		ref := strings.ToLower(spec.Category)
+		if _, ok := ids[ref]; ok || ref == "" {
+			continue
+		}
+		i := slices.IndexFunc(existing, func(c categories.Category) bool {
+			return c.ID == spec.Category || strings.EqualFold(c.Name, spec.Category)
+		})
+		if i >= 0 {
+			ids[ref] = existing[i].ID
+			continue
+		}
+
+		ids[ref] = ""
+		change := ApplyChange{Kind: "category", Title: spec.Category, Action: "created"}
+		if !dryRun {
+			category, err := categoryService.Create(ctx, categories.CreateCategoryRequest{Name: spec.Category})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create category %s: %w", spec.Category, err)
+			}
+			ids[ref] = category.ID
+			change.ID = category.ID
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	return ids, nil
+}
+
+// createRequest converts a spec to a creation request
+func (h HabitSpec) createRequest(categoryID string) CreateHabitRequest {
+	req := CreateHabitRequest{
+		Title:            h.Title,
+		Description:      h.Description,
+		HabitType:        h.Type,
+		FrequencyType:    h.Frequency,
+		ActiveDays:       h.ActiveDays,
+		TargetValue:      h.Target,
+		DefaultIncrement: h.Increment,
+		Unit:             h.Unit,
+		Icon:             h.Icon,
+	}
+	if h.FrequencyValue != nil {
+		req.FrequencyValue = *h.FrequencyValue
+	}
+	if categoryID != "" {
+		req.CategoryID = &categoryID
+	}
+	return req
+}
+
+// updateRequest returns the update bringing habit in line with the spec and
+// the names of the changed fields, none if it matches
+func (h HabitSpec) updateRequest(habit Habit, categoryID string) (UpdateHabitRequest, []string) {
+	var req UpdateHabitRequest
+	var fields []string
+	if habit.Description != h.Description {
+		req.Description = &h.Description
+		fields = append(fields, "description")
+	}
+	if habit.HabitType != h.Type {
+		req.HabitType = h.Type
+		fields = append(fields, "habit_type")
+	}
+	if habit.FrequencyType != h.Frequency {
+		req.FrequencyType = h.Frequency
+		fields = append(fields, "frequency_type")
+	}
+	if h.FrequencyValue != nil && habit.FrequencyValue != *h.FrequencyValue {
+		req.FrequencyValue = h.FrequencyValue
+		fields = append(fields, "frequency_value")
+	}
+	if h.ActiveDays != nil && !sameDays(habit.ActiveDays, h.ActiveDays) {
+		req.ActiveDays = h.ActiveDays
+		fields = append(fields, "active_days")
+	}
+	if h.Target != nil && (habit.TargetValue == nil || *habit.TargetValue != *h.Target) {
+		req.TargetValue = h.Target
+		fields = append(fields, "target_value")
+	}
+	if h.Increment != nil && (habit.DefaultIncrement == nil || *habit.DefaultIncrement != *h.Increment) {
+		req.DefaultIncrement = h.Increment
+		fields = append(fields, "default_increment")
+	}
+	if habit.Unit != h.Unit {
+		req.Unit = &h.Unit
+		fields = append(fields, "unit")
+	}
+	current := ""
+	if habit.CategoryID != nil {
+		current = *habit.CategoryID
+	}
+	if h.Category != "" && current != categoryID || h.Category == "" && current != "" {
+		req.CategoryID = &categoryID
+		fields = append(fields, "category_id")
+	}
+	if habit.Icon != h.Icon {
+		req.Icon = &h.Icon
+		fields = append(fields, "icon")
+	}
+	return req, fields
+}
+
+// sameDays reports whether two active day lists contain the same days
+func sameDays(a, b []int) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
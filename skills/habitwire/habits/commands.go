@@ -1,12 +1,12 @@
 package habits
 
 import (
-	"fmt"
-	"strconv"
+	"os"
 	"strings"
 
 	"habitwire/client"
 
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
 	"github.com/spf13/cobra"
 )
 
@@ -26,7 +26,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Use:   "list",
 		Short: "List active habits",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			habits, err := service.List(listCategory, listToday)
+			habits, err := service.List(cmd.Context(), listCategory, listToday)
 			if err != nil {
 				return err
 			}
@@ -42,7 +42,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Use:   "list-all",
 		Short: "List all habits including archived",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			habits, err := service.ListAll(listAllCategory)
+			habits, err := service.ListAll(cmd.Context(), listAllCategory)
 			if err != nil {
 				return err
 			}
@@ -56,7 +56,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Use:   "list-archived",
 		Short: "List only archived habits",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			habits, err := service.ListArchived()
+			habits, err := service.ListArchived(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -70,7 +70,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Short: "Get a habit by ID",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			habit, err := service.Get(args[0])
+			habit, err := service.Get(cmd.Context(), args[0])
 			if err != nil {
 				return err
 			}
@@ -109,10 +109,10 @@ Active days (for WEEKLY/CUSTOM):
   Example: --active-days "1,3,5" for Mon/Wed/Fri`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if createTitle == "" {
-				return fmt.Errorf("--title is required")
+				return skillkit.Usagef("--title is required")
 			}
 			if createFrequency == "" {
-				return fmt.Errorf("--frequency is required")
+				return skillkit.Usagef("--frequency is required")
 			}
 
 			req := CreateHabitRequest{
@@ -128,9 +128,9 @@ Active days (for WEEKLY/CUSTOM):
 				req.FrequencyValue = createFrequencyValue
 			}
 			if createActiveDays != "" {
-				days, err := parseIntList(createActiveDays)
+				days, err := skillkit.ParseIntList[int](createActiveDays)
 				if err != nil {
-					return fmt.Errorf("invalid active-days: %w", err)
+					return skillkit.Usagef("invalid active-days: %w", err)
 				}
 				req.ActiveDays = days
 			}
@@ -150,7 +150,7 @@ Active days (for WEEKLY/CUSTOM):
 				req.Icon = createIcon
 			}
 
-			habit, err := service.Create(req)
+			habit, err := service.Create(cmd.Context(), req)
 			if err != nil {
 				return err
 			}
@@ -204,9 +204,9 @@ Active days (for WEEKLY/CUSTOM):
 				req.FrequencyValue = &updateFrequencyValue
 			}
 			if cmd.Flags().Changed("active-days") {
-				days, err := parseIntList(updateActiveDays)
+				days, err := skillkit.ParseIntList[int](updateActiveDays)
 				if err != nil {
-					return fmt.Errorf("invalid active-days: %w", err)
+					return skillkit.Usagef("invalid active-days: %w", err)
 				}
 				req.ActiveDays = days
 			}
@@ -226,7 +226,7 @@ Active days (for WEEKLY/CUSTOM):
 				req.Icon = &updateIcon
 			}
 
-			habit, err := service.Update(args[0], req)
+			habit, err := service.Update(cmd.Context(), args[0], req)
 			if err != nil {
 				return err
 			}
@@ -251,7 +251,7 @@ Active days (for WEEKLY/CUSTOM):
 		Short: "Delete (archive) a habit",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := service.Delete(args[0]); err != nil {
+			if err := service.Delete(cmd.Context(), args[0]); err != nil {
 				return err
 			}
 			return printJSON(map[string]bool{"archived": true})
@@ -270,7 +270,7 @@ Active days (for WEEKLY/CUSTOM):
   - total_checkins: Total number of completed check-ins`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			stats, err := service.GetStats(args[0], statsToday)
+			stats, err := service.GetStats(cmd.Context(), args[0], statsToday)
 			if err != nil {
 				return err
 			}
@@ -286,7 +286,7 @@ Active days (for WEEKLY/CUSTOM):
 		Long:  "Reorder habits by providing habit IDs in the desired order.",
 		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := service.Reorder(args); err != nil {
+			if err := service.Reorder(cmd.Context(), args); err != nil {
 				return err
 			}
 			return printJSON(map[string]bool{"reordered": true})
@@ -315,17 +315,52 @@ Date defaults to today if not specified.`,
 			if cmd.Flags().Changed("value") {
 				req.Value = &checkValue
 			}
-			checkin, err := service.Check(args[0], req)
+			checkin, err := service.Check(cmd.Context(), args[0], req)
 			if err != nil {
 				return err
 			}
 			return printJSON(checkin.ToLean())
 		},
 	}
-	checkCmd.Flags().StringVar(&checkDate, "date", "", "Check-in date (YYYY-MM-DD, defaults to today)")
+	checkCmd.Flags().StringVar(&checkDate, "date", "", "Check-in date (YYYY-MM-DD or relative, defaults to today)")
 	checkCmd.Flags().Float64VarP(&checkValue, "value", "v", 0, "Value for TARGET habits")
 	checkCmd.Flags().StringVarP(&checkNotes, "notes", "n", "", "Optional notes")
 
+	// edit-checkin
+	var editDate string
+	var editValue float64
+	var editNotes string
+	editCheckinCmd := &cobra.Command{
+		Use:   "edit-checkin [id]",
+		Short: "Correct value or notes of an existing check-in",
+		Long: `Correct the value and/or notes of an existing check-in.
+
+Fields not given keep their current value, so correcting a value keeps
+the notes and the streak is not touched. Fails if there is no check-in
+on the date or it is skipped.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var req EditCheckInRequest
+			if cmd.Flags().Changed("value") {
+				req.Value = &editValue
+			}
+			if cmd.Flags().Changed("notes") {
+				req.Notes = &editNotes
+			}
+			if req.Value == nil && req.Notes == nil {
+				return skillkit.Usagef("--value or --notes is required")
+			}
+			checkin, err := service.EditCheckIn(cmd.Context(), args[0], editDate, req)
+			if err != nil {
+				return err
+			}
+			return printJSON(checkin.ToLean())
+		},
+	}
+	editCheckinCmd.Flags().StringVar(&editDate, "date", "", "Check-in date (YYYY-MM-DD or relative, defaults to today)")
+	editCheckinCmd.Flags().Float64VarP(&editValue, "value", "v", 0, "New value")
+	editCheckinCmd.Flags().StringVarP(&editNotes, "notes", "n", "", "New notes")
+
 	// uncheck
 	var uncheckDate string
 	uncheckCmd := &cobra.Command{
@@ -336,13 +371,13 @@ Date defaults to today if not specified.`,
 			req := UncheckRequest{
 				Date: uncheckDate,
 			}
-			if err := service.Uncheck(args[0], req); err != nil {
+			if err := service.Uncheck(cmd.Context(), args[0], req); err != nil {
 				return err
 			}
 			return printJSON(map[string]bool{"unchecked": true})
 		},
 	}
-	uncheckCmd.Flags().StringVar(&uncheckDate, "date", "", "Date to uncheck (YYYY-MM-DD, defaults to today)")
+	uncheckCmd.Flags().StringVar(&uncheckDate, "date", "", "Date to uncheck (YYYY-MM-DD or relative, defaults to today)")
 
 	// skip
 	var skipDate string
@@ -361,14 +396,14 @@ Skipped check-ins behavior depends on user settings:
 				Date:   skipDate,
 				Reason: skipReason,
 			}
-			checkin, err := service.Skip(args[0], req)
+			checkin, err := service.Skip(cmd.Context(), args[0], req)
 			if err != nil {
 				return err
 			}
 			return printJSON(checkin.ToLean())
 		},
 	}
-	skipCmd.Flags().StringVar(&skipDate, "date", "", "Date to skip (YYYY-MM-DD, defaults to today)")
+	skipCmd.Flags().StringVar(&skipDate, "date", "", "Date to skip (YYYY-MM-DD or relative, defaults to today)")
 	skipCmd.Flags().StringVarP(&skipReason, "reason", "r", "", "Reason for skipping")
 
 	// checkins - get check-in history
@@ -379,15 +414,59 @@ Skipped check-ins behavior depends on user settings:
 		Short: "Get check-in history for a habit",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			checkins, err := service.GetCheckIns(args[0], checkinsFrom, checkinsTo)
+			checkins, err := service.GetCheckIns(cmd.Context(), args[0], checkinsFrom, checkinsTo)
 			if err != nil {
 				return err
 			}
 			return printJSON(CheckInsToLeanSlice(checkins))
 		},
 	}
-	checkinsCmd.Flags().StringVar(&checkinsFrom, "from", "", "Start date (YYYY-MM-DD)")
-	checkinsCmd.Flags().StringVar(&checkinsTo, "to", "", "End date (YYYY-MM-DD)")
+	checkinsCmd.Flags().StringVar(&checkinsFrom, "from", "", "Start date (YYYY-MM-DD or relative, e.g. -7d)")
+	checkinsCmd.Flags().StringVar(&checkinsTo, "to", "", "End date (YYYY-MM-DD or relative)")
+
+	// export-csv - check-in history for spreadsheets
+	var exportFrom string
+	var exportTo string
+	var exportFile string
+	exportCSVCmd := &cobra.Command{
+		Use:   "export-csv [id]",
+		Short: "Export the check-ins of a habit as CSV",
+		Long: `Export the check-in history of a habit as CSV with the columns date,
+value, skipped and notes (the skip reason for skipped days without notes).
+
+Without --file the CSV is written to stdout.
+
+Examples:
+  habitwire habits export-csv abc123 --from -30d --file water.csv
+  habitwire habits export-csv abc123 --from 2026-01-01 --to 2026-03-31`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			checkins, err := service.GetCheckIns(cmd.Context(), args[0], exportFrom, exportTo)
+			if err != nil {
+				return err
+			}
+			rows := CheckInRows(checkins)
+			if exportFile == "" {
+				return skillkit.WriteCSV(cmd.OutOrStdout(), CheckInColumns, rows)
+			}
+
+			f, err := os.Create(exportFile)
+			if err != nil {
+				return err
+			}
+			if err := skillkit.WriteCSV(f, CheckInColumns, rows); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			return printJSON(map[string]interface{}{"file": exportFile, "rows": len(rows)})
+		},
+	}
+	exportCSVCmd.Flags().StringVar(&exportFrom, "from", "", "Start date (YYYY-MM-DD or relative, e.g. -30d)")
+	exportCSVCmd.Flags().StringVar(&exportTo, "to", "", "End date (YYYY-MM-DD or relative)")
+	exportCSVCmd.Flags().StringVar(&exportFile, "file", "", "Output file (default: stdout)")
 
 	cmd.AddCommand(
 		listCmd,
@@ -400,30 +479,109 @@ Skipped check-ins behavior depends on user settings:
 		statsCmd,
 		reorderCmd,
 		checkCmd,
+		editCheckinCmd,
 		uncheckCmd,
 		skipCmd,
 		checkinsCmd,
+		exportCSVCmd,
 	)
 	return cmd
 }
 
-// parseIntList parses a comma-separated list of integers
-func parseIntList(s string) ([]int, error) {
-	if s == "" {
-		return nil, nil
+// RegisterApplyCommand creates the top-level apply command syncing the
+// habits with a definition file
+func RegisterApplyCommand(c *client.Client, printJSON func(interface{}) error) *cobra.Command {
+	service := NewService(c)
+	var file string
+	var prune, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Sync the habits with a YAML or JSON definition file",
+		Long: `Make a definition file the source of truth for the habit list: habits
+missing in HabitWire are created, changed ones updated. Habits are matched
+by title. Active habits not in the file are listed as unmanaged, --prune
+archives them. Categories are referenced by name and created if missing.
+
+Definition:
+  habits:
+    - title: Drink water
+      type: TARGET
+      frequency: DAILY
+      target: 2000
+      increment: 250
+      unit: ml
+      category: Health
+      icon: droplet
+    - title: Gym
+      frequency: WEEKLY
+      active_days: [1, 3, 5]
+
+Examples:
+  habitwire apply --file ~/dotfiles/habits.yaml --dry-run
+  habitwire apply --file ~/dotfiles/habits.yaml --prune`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if file == "" {
+				return skillkit.Usagef("--file is required")
+			}
+
+			in := cmd.InOrStdin()
+			if file != "-" {
+				f, err := os.Open(file)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				in = f
+			}
+			definition, err := ParseDefinition(in)
+			if err != nil {
+				return err
+			}
+
+			result, err := service.Apply(cmd.Context(), definition, prune, dryRun)
+			if err != nil {
+				return err
+			}
+			return printJSON(result)
+		},
 	}
-	parts := strings.Split(s, ",")
-	result := make([]int, 0, len(parts))
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		if p == "" {
-			continue
-		}
-		n, err := strconv.Atoi(p)
-		if err != nil {
-			return nil, fmt.Errorf("invalid number '%s': %w", p, err)
-		}
-		result = append(result, n)
+	cmd.Flags().StringVar(&file, "file", "", "Definition file, - for stdin (required)")
+	cmd.Flags().BoolVar(&prune, "prune", false, "Archive active habits missing in the file")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the changes without making them")
+	return cmd
+}
+
+// RegisterCheckinsCommand creates the top-level checkins command querying
+// check-ins across all habits
+func RegisterCheckinsCommand(c *client.Client, printJSON func(interface{}) error) *cobra.Command {
+	service := NewService(c)
+	var from, to, category string
+
+	cmd := &cobra.Command{
+		Use:   "checkins",
+		Short: "Get check-ins of all habits in a date range",
+		Long: `Get the check-ins of all active habits within a date range, grouped by
+habit. Habits without check-ins in the range are omitted. --to defaults
+to today.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if from == "" {
+				return skillkit.Usagef("--from is required")
+			}
+			if to == "" {
+				to = skillkit.Today()
+			}
+			result, err := service.CheckInsByHabit(cmd.Context(), category, from, to)
+			if err != nil {
+				return err
+			}
+			return printJSON(result)
+		},
 	}
-	return result, nil
+	cmd.Flags().StringVar(&from, "from", "", "Start date (YYYY-MM-DD or relative, e.g. -7d; required)")
+	cmd.Flags().StringVar(&to, "to", "", "End date (YYYY-MM-DD or relative, defaults to today)")
+	cmd.Flags().StringVar(&category, "category", "", "Only habits of this category ID")
+	return cmd
 }
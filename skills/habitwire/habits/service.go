@@ -2,11 +2,14 @@
 package habits
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/url"
+	"strings"
 
 	"habitwire/client"
+
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
 )
 
 // Service handles habit operations
@@ -20,7 +23,7 @@ func NewService(c *client.Client) *Service {
 }
 
 // List retrieves all active habits
-func (s *Service) List(categoryID string, today bool) ([]Habit, error) {
+func (s *Service) List(ctx context.Context, categoryID string, today bool) ([]Habit, error) {
 	endpoint := "/habits"
 	params := url.Values{}
 	if categoryID != "" {
@@ -33,13 +36,13 @@ func (s *Service) List(categoryID string, today bool) ([]Habit, error) {
 		endpoint += "?" + params.Encode()
 	}
 
-	data, err := s.client.Get(endpoint)
+	data, err := s.client.Get(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
 	var habits []Habit
-	if err := json.Unmarshal(data, &habits); err != nil {
+	if err := s.client.Decode(data, &habits); err != nil {
 		return nil, fmt.Errorf("failed to parse habits: %w", err)
 	}
 
@@ -47,19 +50,19 @@ func (s *Service) List(categoryID string, today bool) ([]Habit, error) {
 }
 
 // ListAll retrieves all habits including archived
-func (s *Service) ListAll(categoryID string) ([]Habit, error) {
+func (s *Service) ListAll(ctx context.Context, categoryID string) ([]Habit, error) {
 	endpoint := "/habits/all"
 	if categoryID != "" {
 		endpoint += "?category=" + url.QueryEscape(categoryID)
 	}
 
-	data, err := s.client.Get(endpoint)
+	data, err := s.client.Get(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
 	var habits []Habit
-	if err := json.Unmarshal(data, &habits); err != nil {
+	if err := s.client.Decode(data, &habits); err != nil {
 		return nil, fmt.Errorf("failed to parse habits: %w", err)
 	}
 
@@ -67,14 +70,14 @@ func (s *Service) ListAll(categoryID string) ([]Habit, error) {
 }
 
 // ListArchived retrieves only archived habits
-func (s *Service) ListArchived() ([]Habit, error) {
-	data, err := s.client.Get("/habits/archived")
+func (s *Service) ListArchived(ctx context.Context) ([]Habit, error) {
+	data, err := s.client.Get(ctx, "/habits/archived")
 	if err != nil {
 		return nil, err
 	}
 
 	var habits []Habit
-	if err := json.Unmarshal(data, &habits); err != nil {
+	if err := s.client.Decode(data, &habits); err != nil {
 		return nil, fmt.Errorf("failed to parse habits: %w", err)
 	}
 
@@ -82,16 +85,16 @@ func (s *Service) ListArchived() ([]Habit, error) {
 }
 
 // Get retrieves a single habit by ID
-func (s *Service) Get(habitID string) (*Habit, error) {
+func (s *Service) Get(ctx context.Context, habitID string) (*Habit, error) {
 	endpoint := fmt.Sprintf("/habits/%s", habitID)
 
-	data, err := s.client.Get(endpoint)
+	data, err := s.client.Get(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
 	var habit Habit
-	if err := json.Unmarshal(data, &habit); err != nil {
+	if err := s.client.Decode(data, &habit); err != nil {
 		return nil, fmt.Errorf("failed to parse habit: %w", err)
 	}
 
@@ -99,14 +102,14 @@ func (s *Service) Get(habitID string) (*Habit, error) {
 }
 
 // Create creates a new habit
-func (s *Service) Create(req CreateHabitRequest) (*Habit, error) {
-	data, err := s.client.Post("/habits", req)
+func (s *Service) Create(ctx context.Context, req CreateHabitRequest) (*Habit, error) {
+	data, err := s.client.Post(ctx, "/habits", req)
 	if err != nil {
 		return nil, err
 	}
 
 	var habit Habit
-	if err := json.Unmarshal(data, &habit); err != nil {
+	if err := s.client.Decode(data, &habit); err != nil {
 		return nil, fmt.Errorf("failed to parse created habit: %w", err)
 	}
 
@@ -114,16 +117,16 @@ func (s *Service) Create(req CreateHabitRequest) (*Habit, error) {
 }
 
 // Update updates an existing habit
-func (s *Service) Update(habitID string, req UpdateHabitRequest) (*Habit, error) {
+func (s *Service) Update(ctx context.Context, habitID string, req UpdateHabitRequest) (*Habit, error) {
 	endpoint := fmt.Sprintf("/habits/%s", habitID)
 
-	data, err := s.client.Put(endpoint, req)
+	data, err := s.client.Put(ctx, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
 
 	var habit Habit
-	if err := json.Unmarshal(data, &habit); err != nil {
+	if err := s.client.Decode(data, &habit); err != nil {
 		return nil, fmt.Errorf("failed to parse updated habit: %w", err)
 	}
 
@@ -131,26 +134,26 @@ func (s *Service) Update(habitID string, req UpdateHabitRequest) (*Habit, error)
 }
 
 // Delete archives a habit (soft delete)
-func (s *Service) Delete(habitID string) error {
+func (s *Service) Delete(ctx context.Context, habitID string) error {
 	endpoint := fmt.Sprintf("/habits/%s", habitID)
-	_, err := s.client.Delete(endpoint)
+	_, err := s.client.Delete(ctx, endpoint)
 	return err
 }
 
 // GetStats retrieves statistics for a habit
-func (s *Service) GetStats(habitID string, today bool) (*HabitStats, error) {
+func (s *Service) GetStats(ctx context.Context, habitID string, today bool) (*HabitStats, error) {
 	endpoint := fmt.Sprintf("/habits/%s/stats", habitID)
 	if today {
 		endpoint += "?today=true"
 	}
 
-	data, err := s.client.Get(endpoint)
+	data, err := s.client.Get(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
 	var stats HabitStats
-	if err := json.Unmarshal(data, &stats); err != nil {
+	if err := s.client.Decode(data, &stats); err != nil {
 		return nil, fmt.Errorf("failed to parse stats: %w", err)
 	}
 
@@ -158,56 +161,156 @@ func (s *Service) GetStats(habitID string, today bool) (*HabitStats, error) {
 }
 
 // Reorder reorders habits
-func (s *Service) Reorder(ids []string) error {
-	_, err := s.client.Put("/habits/reorder", map[string][]string{"ids": ids})
+func (s *Service) Reorder(ctx context.Context, ids []string) error {
+	_, err := s.client.Put(ctx, "/habits/reorder", map[string][]string{"ids": ids})
 	return err
 }
 
 // Check records a check-in for a habit
-func (s *Service) Check(habitID string, req CheckRequest) (*CheckIn, error) {
+func (s *Service) Check(ctx context.Context, habitID string, req CheckRequest) (*CheckIn, error) {
 	endpoint := fmt.Sprintf("/habits/%s/check", habitID)
 
-	data, err := s.client.Post(endpoint, req)
+	var err error
+	if req.Date, err = skillkit.PlainDate(req.Date); err != nil {
+		return nil, err
+	}
+
+	data, err := s.client.Post(ctx, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
 
 	var checkin CheckIn
-	if err := json.Unmarshal(data, &checkin); err != nil {
+	if err := s.client.Decode(data, &checkin); err != nil {
 		return nil, fmt.Errorf("failed to parse check-in: %w", err)
 	}
 
 	return &checkin, nil
 }
 
+// EditCheckIn changes value and/or notes of the existing check-in on date
+// (YYYY-MM-DD, today if empty). Unchanged fields are sent again, so the
+// check upsert keeps them instead of dropping the notes.
+func (s *Service) EditCheckIn(ctx context.Context, habitID, date string, req EditCheckInRequest) (*CheckIn, error) {
+	if date == "" {
+		date = skillkit.Today()
+	}
+	date, err := skillkit.PlainDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	checkins, err := s.GetCheckIns(ctx, habitID, date, date)
+	if err != nil {
+		return nil, err
+	}
+	var existing *CheckIn
+	for i := range checkins {
+		if strings.HasPrefix(checkins[i].Date, date) {
+			existing = &checkins[i]
+			break
+		}
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("no check-in on %s", date)
+	}
+	if existing.Skipped {
+		return nil, fmt.Errorf("check-in on %s is skipped, use uncheck and check instead", date)
+	}
+
+	check := CheckRequest{Date: date, Value: existing.Value, Notes: existing.Notes}
+	if req.Value != nil {
+		check.Value = req.Value
+	}
+	if req.Notes != nil {
+		check.Notes = *req.Notes
+	}
+	return s.Check(ctx, habitID, check)
+}
+
 // Uncheck removes a check-in for a habit
-func (s *Service) Uncheck(habitID string, req UncheckRequest) error {
+func (s *Service) Uncheck(ctx context.Context, habitID string, req UncheckRequest) error {
 	endpoint := fmt.Sprintf("/habits/%s/uncheck", habitID)
-	_, err := s.client.Post(endpoint, req)
+
+	var err error
+	if req.Date, err = skillkit.PlainDate(req.Date); err != nil {
+		return err
+	}
+
+	_, err = s.client.Post(ctx, endpoint, req)
 	return err
 }
 
 // Skip marks a habit as skipped for a date
-func (s *Service) Skip(habitID string, req SkipRequest) (*CheckIn, error) {
+func (s *Service) Skip(ctx context.Context, habitID string, req SkipRequest) (*CheckIn, error) {
 	endpoint := fmt.Sprintf("/habits/%s/skip", habitID)
 
-	data, err := s.client.Post(endpoint, req)
+	var err error
+	if req.Date, err = skillkit.PlainDate(req.Date); err != nil {
+		return nil, err
+	}
+
+	data, err := s.client.Post(ctx, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
 
 	var checkin CheckIn
-	if err := json.Unmarshal(data, &checkin); err != nil {
+	if err := s.client.Decode(data, &checkin); err != nil {
 		return nil, fmt.Errorf("failed to parse skip response: %w", err)
 	}
 
 	return &checkin, nil
 }
 
+// CheckInsByHabit retrieves the check-ins of all active habits (optionally of
+// one category) within a date range, grouped by habit. Habits without
+// check-ins in the range are left out.
+func (s *Service) CheckInsByHabit(ctx context.Context, categoryID, from, to string) ([]HabitCheckIns, error) {
+	var err error
+	if from, err = skillkit.PlainDate(from); err != nil {
+		return nil, err
+	}
+	if to, err = skillkit.PlainDate(to); err != nil {
+		return nil, err
+	}
+
+	habits, err := s.List(ctx, categoryID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []HabitCheckIns{}
+	for _, h := range habits {
+		checkins, err := s.GetCheckIns(ctx, h.ID, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("check-ins of habit %s: %w", h.ID, err)
+		}
+		if len(checkins) == 0 {
+			continue
+		}
+		result = append(result, HabitCheckIns{
+			HabitID:  h.ID,
+			Title:    h.Title,
+			Unit:     h.Unit,
+			CheckIns: CheckInsToLeanSlice(checkins),
+		})
+	}
+	return result, nil
+}
+
 // GetCheckIns retrieves check-ins for a habit within a date range
-func (s *Service) GetCheckIns(habitID string, from, to string) ([]CheckIn, error) {
+func (s *Service) GetCheckIns(ctx context.Context, habitID string, from, to string) ([]CheckIn, error) {
 	endpoint := fmt.Sprintf("/habits/%s/checkins", habitID)
 
+	var err error
+	if from, err = skillkit.PlainDate(from); err != nil {
+		return nil, err
+	}
+	if to, err = skillkit.PlainDate(to); err != nil {
+		return nil, err
+	}
+
 	params := url.Values{}
 	if from != "" {
 		params.Set("from", from)
@@ -219,13 +322,13 @@ func (s *Service) GetCheckIns(habitID string, from, to string) ([]CheckIn, error
 		endpoint += "?" + params.Encode()
 	}
 
-	data, err := s.client.Get(endpoint)
+	data, err := s.client.Get(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
 	var checkins []CheckIn
-	if err := json.Unmarshal(data, &checkins); err != nil {
+	if err := s.client.Decode(data, &checkins); err != nil {
 		return nil, fmt.Errorf("failed to parse check-ins: %w", err)
 	}
 
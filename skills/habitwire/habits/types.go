@@ -1,6 +1,12 @@
 // Package habits provides habit-related types and operations for HabitWire API
 package habits
 
+import (
+	"strconv"
+
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+)
+
 // CheckIn represents a habit check-in
 type CheckIn struct {
 	ID         string   `json:"id,omitempty"`
@@ -20,6 +26,14 @@ type CheckInLean struct {
 	Skipped bool     `json:"skipped,omitempty"`
 }
 
+// HabitCheckIns groups the check-ins of one habit for the checkins query
+type HabitCheckIns struct {
+	HabitID  string        `json:"habit_id"`
+	Title    string        `json:"title"`
+	Unit     string        `json:"unit,omitempty"`
+	CheckIns []CheckInLean `json:"checkins"`
+}
+
 // Habit represents a HabitWire habit
 type Habit struct {
 	ID               string    `json:"id,omitempty"`
@@ -96,6 +110,12 @@ type CheckRequest struct {
 	Notes string   `json:"notes,omitempty"`
 }
 
+// EditCheckInRequest holds the check-in fields to change; nil fields keep their value
+type EditCheckInRequest struct {
+	Value *float64
+	Notes *string
+}
+
 // UncheckRequest represents an uncheck request
 type UncheckRequest struct {
 	Date string `json:"date,omitempty"`
@@ -122,11 +142,7 @@ func (h *Habit) ToLean() HabitLean {
 
 // ToLeanSlice converts a slice of Habits to lean output
 func ToLeanSlice(habits []Habit) []HabitLean {
-	result := make([]HabitLean, len(habits))
-	for i := range habits {
-		result[i] = habits[i].ToLean()
-	}
-	return result
+	return skillkit.LeanSlice(habits, (*Habit).ToLean)
 }
 
 // ToLean converts a full CheckIn to lean output
@@ -140,9 +156,25 @@ func (c *CheckIn) ToLean() CheckInLean {
 
 // CheckInsToLeanSlice converts a slice of CheckIns to lean output
 func CheckInsToLeanSlice(checkins []CheckIn) []CheckInLean {
-	result := make([]CheckInLean, len(checkins))
-	for i := range checkins {
-		result[i] = checkins[i].ToLean()
+	return skillkit.LeanSlice(checkins, (*CheckIn).ToLean)
+}
+
+// CheckInColumns are the columns of habits export-csv
+var CheckInColumns = []string{"date", "value", "skipped", "notes"}
+
+// CheckInRows converts check-ins to CSV rows matching CheckInColumns
+func CheckInRows(checkins []CheckIn) [][]string {
+	rows := make([][]string, len(checkins))
+	for i, c := range checkins {
+		value := ""
+		if c.Value != nil {
+			value = strconv.FormatFloat(*c.Value, 'f', -1, 64)
+		}
+		notes := c.Notes
+		if notes == "" && c.Skipped {
+			notes = c.SkipReason
+		}
+		rows[i] = []string{c.Date, value, strconv.FormatBool(c.Skipped), notes}
 	}
-	return result
+	return rows
 }
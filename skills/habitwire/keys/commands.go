@@ -1,10 +1,9 @@
 package keys
 
 import (
-	"fmt"
-
 	"habitwire/client"
 
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
 	"github.com/spf13/cobra"
 )
 
@@ -22,7 +21,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Use:   "list",
 		Short: "List all API keys",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			keys, err := service.List()
+			keys, err := service.List(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -38,9 +37,9 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Long:  "Create a new API key. The key value is only shown once upon creation.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if createName == "" {
-				return fmt.Errorf("--name is required")
+				return skillkit.Usagef("--name is required")
 			}
-			key, err := service.Create(CreateKeyRequest{Name: createName})
+			key, err := service.Create(cmd.Context(), CreateKeyRequest{Name: createName})
 			if err != nil {
 				return err
 			}
@@ -55,7 +54,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Short: "Delete an API key",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := service.Delete(args[0]); err != nil {
+			if err := service.Delete(cmd.Context(), args[0]); err != nil {
 				return err
 			}
 			return printJSON(map[string]bool{"deleted": true})
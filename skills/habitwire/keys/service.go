@@ -2,7 +2,7 @@
 package keys
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 
 	"habitwire/client"
@@ -19,14 +19,14 @@ func NewService(c *client.Client) *Service {
 }
 
 // List retrieves all API keys
-func (s *Service) List() ([]APIKey, error) {
-	data, err := s.client.Get("/keys")
+func (s *Service) List(ctx context.Context) ([]APIKey, error) {
+	data, err := s.client.Get(ctx, "/keys")
 	if err != nil {
 		return nil, err
 	}
 
 	var keys []APIKey
-	if err := json.Unmarshal(data, &keys); err != nil {
+	if err := s.client.Decode(data, &keys); err != nil {
 		return nil, fmt.Errorf("failed to parse keys: %w", err)
 	}
 
@@ -34,14 +34,14 @@ func (s *Service) List() ([]APIKey, error) {
 }
 
 // Create creates a new API key
-func (s *Service) Create(req CreateKeyRequest) (*APIKey, error) {
-	data, err := s.client.Post("/keys", req)
+func (s *Service) Create(ctx context.Context, req CreateKeyRequest) (*APIKey, error) {
+	data, err := s.client.Post(ctx, "/keys", req)
 	if err != nil {
 		return nil, err
 	}
 
 	var key APIKey
-	if err := json.Unmarshal(data, &key); err != nil {
+	if err := s.client.Decode(data, &key); err != nil {
 		return nil, fmt.Errorf("failed to parse created key: %w", err)
 	}
 
@@ -49,8 +49,8 @@ func (s *Service) Create(req CreateKeyRequest) (*APIKey, error) {
 }
 
 // Delete deletes an API key
-func (s *Service) Delete(keyID string) error {
+func (s *Service) Delete(ctx context.Context, keyID string) error {
 	endpoint := fmt.Sprintf("/keys/%s", keyID)
-	_, err := s.client.Delete(endpoint)
+	_, err := s.client.Delete(ctx, endpoint)
 	return err
 }
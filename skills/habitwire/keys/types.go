@@ -1,6 +1,8 @@
 // Package keys provides API key management for HabitWire
 package keys
 
+import "github.com/petervogelmann/skillfactory/pkg/skillkit"
+
 // APIKey represents an API key
 type APIKey struct {
 	ID        string `json:"id"`
@@ -33,9 +35,5 @@ func (k *APIKey) ToLean() APIKeyLean {
 
 // ToLeanSlice converts a slice of APIKeys to lean output
 func ToLeanSlice(keys []APIKey) []APIKeyLean {
-	result := make([]APIKeyLean, len(keys))
-	for i := range keys {
-		result[i] = keys[i].ToLean()
-	}
-	return result
+	return skillkit.LeanSlice(keys, (*APIKey).ToLean)
 }
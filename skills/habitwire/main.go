@@ -2,75 +2,37 @@
 package main
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
-	"path/filepath"
-
 	"habitwire/categories"
 	"habitwire/client"
 	"habitwire/habits"
 	"habitwire/keys"
 	"habitwire/system"
+	"habitwire/watch"
 
-	"github.com/joho/godotenv"
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	// Load .env from same directory as binary
-	if exe, err := os.Executable(); err == nil {
-		envPath := filepath.Join(filepath.Dir(exe), ".env")
-		godotenv.Load(envPath)
-	}
-}
+// version is set via ldflags at build time (see build.ldflags in skill.yaml)
+var version = "dev"
 
 func main() {
-	rootCmd := &cobra.Command{
-		Use:   "habitwire",
-		Short: "HabitWire CLI for Claude Code",
-	}
-
-	// Create client (will fail later if env vars missing)
-	apiClient, err := client.New()
-	if err != nil {
-		// Register commands anyway for --help to work
-		rootCmd.AddCommand(
-			habits.RegisterCommands(nil, printJSON),
-			categories.RegisterCommands(nil, printJSON),
-			keys.RegisterCommands(nil, printJSON),
-			system.RegisterHealthCommand(nil, printJSON),
-			system.RegisterExportCommand(nil, printJSON),
-		)
-		// Only fail if actually trying to run a command
-		rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-			return err
-		}
-	} else {
-		rootCmd.AddCommand(
-			habits.RegisterCommands(apiClient, printJSON),
-			categories.RegisterCommands(apiClient, printJSON),
-			keys.RegisterCommands(apiClient, printJSON),
-			system.RegisterHealthCommand(apiClient, printJSON),
-			system.RegisterExportCommand(apiClient, printJSON),
-		)
-	}
-
-	if err := rootCmd.Execute(); err != nil {
-		printError(err.Error())
-		os.Exit(1)
-	}
-}
-
-func printJSON(v interface{}) error {
-	data, err := json.Marshal(v)
-	if err != nil {
-		return err
-	}
-	fmt.Println(string(data))
-	return nil
-}
-
-func printError(msg string) {
-	json.NewEncoder(os.Stderr).Encode(map[string]string{"error": msg})
+	skillkit.CLI[*client.Client]{
+		Name:      "habitwire",
+		Short:     "HabitWire CLI for Claude Code",
+		Version:   version,
+		NewClient: client.New,
+		Commands: func(apiClient *client.Client, printJSON func(interface{}) error) []*cobra.Command {
+			return []*cobra.Command{
+				habits.RegisterCommands(apiClient, printJSON),
+				habits.RegisterCheckinsCommand(apiClient, printJSON),
+				habits.RegisterApplyCommand(apiClient, printJSON),
+				categories.RegisterCommands(apiClient, printJSON),
+				keys.RegisterCommands(apiClient, printJSON),
+				system.RegisterHealthCommand(apiClient, printJSON),
+				system.RegisterExportCommand(apiClient, printJSON),
+				watch.RegisterCommand(apiClient, printJSON),
+			}
+		},
+	}.Main()
 }
@@ -29,12 +29,12 @@ func RegisterHealthCommand(c *client.Client, printJSON func(interface{}) error)
 		Use:   "health",
 		Short: "Check API health status",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			data, err := c.Get("/health")
+			data, err := c.Get(cmd.Context(), "/health")
 			if err != nil {
 				return err
 			}
 			var health HealthResponse
-			if err := json.Unmarshal(data, &health); err != nil {
+			if err := c.Decode(data, &health); err != nil {
 				return fmt.Errorf("failed to parse health response: %w", err)
 			}
 			return printJSON(health)
@@ -55,20 +55,20 @@ func RegisterExportCommand(c *client.Client, printJSON func(interface{}) error)
 			if format != "" {
 				endpoint += "?format=" + format
 			}
-			data, err := c.Get(endpoint)
+			data, err := c.Get(cmd.Context(), endpoint)
 			if err != nil {
 				return err
 			}
 			// For CSV format, just print raw output
 			if format == "csv" {
-				fmt.Println(string(data))
+				fmt.Fprintln(cmd.OutOrStdout(), string(data))
 				return nil
 			}
 			// For JSON format, parse and re-print
 			var export ExportData
-			if err := json.Unmarshal(data, &export); err != nil {
+			if err := c.Decode(data, &export); err != nil {
 				// If parsing fails, just output raw data
-				fmt.Println(string(data))
+				fmt.Fprintln(cmd.OutOrStdout(), string(data))
 				return nil
 			}
 			return printJSON(export)
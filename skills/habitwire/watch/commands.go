@@ -0,0 +1,117 @@
+package watch
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"habitwire/client"
+	"habitwire/habits"
+
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+	"github.com/spf13/cobra"
+)
+
+// RegisterCommand creates the watch command
+func RegisterCommand(c *client.Client, printJSON func(interface{}) error) *cobra.Command {
+	var interval time.Duration
+	var execCmd string
+	var once bool
+	var days int
+	var statePath string
+	var emitExisting bool
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Poll for check-in changes and emit events",
+		Long: `Poll HabitWire for new, changed and removed check-ins and print one JSON
+event per line (NDJSON). With --exec the given shell command runs for every
+event with the event JSON on stdin and HABITWIRE_EVENT_* environment variables.
+
+The HabitWire API has no webhooks, so this is a local polling bridge. The
+first run records the current check-ins as baseline without emitting them.
+
+Examples:
+  habitwire watch --interval 5m
+  habitwire watch --interval 5m --exec 'notify-send "$HABITWIRE_EVENT_HABIT"'
+  habitwire watch --once   # single poll, e.g. from cron`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval < time.Second {
+				return skillkit.Usagef("--interval must be at least 1s")
+			}
+			service := NewService(habits.NewService(c), statePath)
+
+			poll := func() error {
+				events, err := service.Poll(cmd.Context(), days, emitExisting)
+				if err != nil {
+					return err
+				}
+				emitExisting = false
+				for _, event := range events {
+					if err := printJSON(event); err != nil {
+						return err
+					}
+					if execCmd != "" {
+						runHook(execCmd, event)
+					}
+				}
+				return nil
+			}
+
+			if once {
+				return poll()
+			}
+
+			// Watch until interrupted or the --timeout expires
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				// Keep watching on transient API errors
+				if err := poll(); err != nil && cmd.Context().Err() == nil {
+					skillkit.WriteError(cmd.ErrOrStderr(), err)
+				}
+				select {
+				case <-cmd.Context().Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+	cmd.Flags().DurationVarP(&interval, "interval", "i", 5*time.Minute, "Poll interval")
+	cmd.Flags().StringVarP(&execCmd, "exec", "e", "", "Shell command to run for each event")
+	cmd.Flags().BoolVar(&once, "once", false, "Poll once and exit")
+	cmd.Flags().IntVar(&days, "days", 1, "Also watch check-ins of the last N days")
+	cmd.Flags().StringVar(&statePath, "state", DefaultStatePath(), "State file of seen check-ins")
+	cmd.Flags().BoolVar(&emitExisting, "emit-existing", false, "Emit existing check-ins on the first run")
+	return cmd
+}
+
+// runHook runs the --exec command for an event; failures are reported but do not stop watching
+func runHook(command string, event Event) {
+	data, _ := json.Marshal(event)
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = strings.NewReader(string(data))
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"HABITWIRE_EVENT="+string(data),
+		"HABITWIRE_EVENT_TYPE="+event.Type,
+		"HABITWIRE_EVENT_HABIT_ID="+event.HabitID,
+		"HABITWIRE_EVENT_HABIT="+event.Habit,
+		"HABITWIRE_EVENT_DATE="+event.Date,
+	)
+	if err := cmd.Run(); err != nil {
+		skillkit.PrintError(os.Stderr, "exec failed: "+err.Error())
+	}
+}
@@ -0,0 +1,160 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"habitwire/habits"
+
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+)
+
+// Service polls HabitWire for check-in changes
+type Service struct {
+	habits    *habits.Service
+	statePath string
+}
+
+// NewService creates a new watch service persisting its state at statePath
+func NewService(h *habits.Service, statePath string) *Service {
+	return &Service{habits: h, statePath: statePath}
+}
+
+// DefaultStatePath returns the state file in the user cache directory
+func DefaultStatePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "habitwire", "watch-state.json")
+}
+
+// Poll compares the check-ins of the last days with the stored state and
+// returns the changes. Without stored state the current check-ins become the
+// baseline and are only returned if emitExisting is set.
+func (s *Service) Poll(ctx context.Context, days int, emitExisting bool) ([]Event, error) {
+	state, found, err := s.loadState()
+	if err != nil {
+		return nil, err
+	}
+
+	habitList, err := s.habits.List(ctx, "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	from := skillkit.DaysAgo(days)
+	to := skillkit.Today()
+
+	var events []Event
+	current := make(map[string]string)
+	for _, habit := range habitList {
+		checkins, err := s.habits.GetCheckIns(ctx, habit.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range checkins {
+			key := habit.ID + "|" + c.Date
+			fp := fingerprint(c)
+			current[key] = fp
+
+			previous, seen := state.Seen[key]
+			switch {
+			case seen && previous == fp:
+				continue
+			case !found && !emitExisting:
+				continue
+			case seen:
+				events = append(events, newEvent(EventUpdate, habit, c))
+			case c.Skipped:
+				events = append(events, newEvent(EventSkip, habit, c))
+			default:
+				events = append(events, newEvent(EventCheckIn, habit, c))
+			}
+		}
+	}
+
+	// Check-ins inside the window that disappeared were unchecked
+	titles := make(map[string]string, len(habitList))
+	for _, habit := range habitList {
+		titles[habit.ID] = habit.Title
+	}
+	for key := range state.Seen {
+		habitID, date, _ := strings.Cut(key, "|")
+		if _, ok := current[key]; ok || date < from {
+			continue
+		}
+		if title, ok := titles[habitID]; ok {
+			events = append(events, Event{Type: EventUncheck, HabitID: habitID, Habit: title, Date: date})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Date < events[j].Date })
+
+	state.Seen = current
+	if err := s.saveState(state); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// fingerprint identifies the content of a check-in
+func fingerprint(c habits.CheckIn) string {
+	value := ""
+	if c.Value != nil {
+		value = fmt.Sprintf("%g", *c.Value)
+	}
+	return fmt.Sprintf("%s|%t|%s|%s", value, c.Skipped, c.Notes, c.SkipReason)
+}
+
+func newEvent(eventType string, habit habits.Habit, c habits.CheckIn) Event {
+	return Event{
+		Type:       eventType,
+		HabitID:    habit.ID,
+		Habit:      habit.Title,
+		Date:       c.Date,
+		Value:      c.Value,
+		Notes:      c.Notes,
+		SkipReason: c.SkipReason,
+	}
+}
+
+// loadState reads the state file, reporting whether it existed
+func (s *Service) loadState() (*State, bool, error) {
+	state := &State{Seen: make(map[string]string)}
+
+	data, err := os.ReadFile(s.statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to read watch state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, false, fmt.Errorf("failed to parse watch state: %w", err)
+	}
+	if state.Seen == nil {
+		state.Seen = make(map[string]string)
+	}
+	return state, true, nil
+}
+
+// saveState writes the state file
+func (s *Service) saveState(state *State) error {
+	if err := os.MkdirAll(filepath.Dir(s.statePath), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.statePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write watch state: %w", err)
+	}
+	return nil
+}
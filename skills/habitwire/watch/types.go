@@ -0,0 +1,27 @@
+// Package watch provides a polling bridge that emits check-in events for HabitWire
+package watch
+
+// Event types emitted by the watcher
+const (
+	EventCheckIn = "checkin"
+	EventSkip    = "skip"
+	EventUpdate  = "update"
+	EventUncheck = "uncheck"
+)
+
+// Event represents a detected check-in change
+type Event struct {
+	Type       string   `json:"type"`
+	HabitID    string   `json:"habit_id"`
+	Habit      string   `json:"habit"`
+	Date       string   `json:"date"`
+	Value      *float64 `json:"value,omitempty"`
+	Notes      string   `json:"notes,omitempty"`
+	SkipReason string   `json:"skip_reason,omitempty"`
+}
+
+// State holds the check-ins seen by previous polls
+type State struct {
+	// Seen maps "habitID|date" to a fingerprint of the check-in
+	Seen map[string]string `json:"seen"`
+}
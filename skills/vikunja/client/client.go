@@ -2,114 +2,68 @@
 package client
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
+
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
 )
 
+// transport is shared by all clients so connections are reused across requests.
+// Compressed responses are requested and decoded transparently.
+var transport = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+	ForceAttemptHTTP2:     true,
+	MaxIdleConns:          16,
+	MaxIdleConnsPerHost:   8,
+	IdleConnTimeout:       90 * time.Second,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ExpectContinueTimeout: 1 * time.Second,
+}
+
 // Config holds Vikunja API configuration
 type Config struct {
-	BaseURL string
-	Token   string
+	BaseURL      string
+	Token        string
+	GzipRequests bool // Compress request bodies (the server must accept Content-Encoding: gzip)
 }
 
-// Client wraps HTTP client for Vikunja API
+// Client wraps HTTP client for Vikunja API. Requests, retries (SKILL_HTTP_RETRIES)
+// and decoding are provided by skillkit.HTTPClient.
 type Client struct {
-	config     Config
-	httpClient *http.Client
+	*skillkit.HTTPClient
 }
 
 // New creates a new Vikunja API client from environment
 func New() (*Client, error) {
-	baseURL := os.Getenv("VIKUNJA_URL")
-	if baseURL == "" {
-		return nil, fmt.Errorf("VIKUNJA_URL environment variable is required")
-	}
-
-	token := os.Getenv("VIKUNJA_TOKEN")
-	if token == "" {
-		return nil, fmt.Errorf("VIKUNJA_TOKEN environment variable is required")
-	}
-
-	return &Client{
-		config: Config{
-			BaseURL: baseURL,
-			Token:   token,
-		},
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-	}, nil
-}
-
-// NewWithConfig creates a client with explicit config
-func NewWithConfig(config Config) *Client {
-	return &Client{
-		config: config,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-	}
-}
-
-// Request performs an HTTP request to the Vikunja API
-func (c *Client) Request(method, endpoint string, body interface{}) ([]byte, error) {
-	var reqBody io.Reader
-	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		reqBody = bytes.NewReader(jsonBody)
-	}
-
-	url := c.config.BaseURL + endpoint
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+c.config.Token)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
+	baseURL, err := skillkit.RequireEnv("VIKUNJA_URL")
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
+	token, err := skillkit.RequireEnv("VIKUNJA_TOKEN")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
-	}
+	gzipRequests, _ := strconv.ParseBool(os.Getenv("VIKUNJA_GZIP_REQUESTS"))
 
-	return respBody, nil
+	return NewWithConfig(Config{
+		BaseURL:      baseURL,
+		Token:        token,
+		GzipRequests: gzipRequests,
+	}), nil
 }
 
-// Get performs a GET request
-func (c *Client) Get(endpoint string) ([]byte, error) {
-	return c.Request(http.MethodGet, endpoint, nil)
-}
-
-// Post performs a POST request
-func (c *Client) Post(endpoint string, body interface{}) ([]byte, error) {
-	return c.Request(http.MethodPost, endpoint, body)
-}
-
-// Put performs a PUT request
-func (c *Client) Put(endpoint string, body interface{}) ([]byte, error) {
-	return c.Request(http.MethodPut, endpoint, body)
-}
-
-// Delete performs a DELETE request
-func (c *Client) Delete(endpoint string) ([]byte, error) {
-	return c.Request(http.MethodDelete, endpoint, nil)
+// NewWithConfig creates a client with explicit config
+func NewWithConfig(config Config) *Client {
+	c := skillkit.NewHTTPClient(config.BaseURL, transport)
+	c.Header.Set("Authorization", "Bearer "+config.Token)
+	c.GzipRequests = config.GzipRequests
+	return &Client{HTTPClient: c}
 }
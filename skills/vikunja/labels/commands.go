@@ -1,8 +1,7 @@
 package labels
 
 import (
-	"fmt"
-
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
 	"github.com/petervogelmann/skillfactory/skills/vikunja/client"
 	"github.com/spf13/cobra"
 )
@@ -21,7 +20,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Use:   "list",
 		Short: "List all labels",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			labels, err := service.List()
+			labels, err := service.List(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -35,11 +34,11 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Short: "Get a label by ID",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			id, err := parseID(args[0])
+			id, err := skillkit.ParseID(args[0])
 			if err != nil {
 				return err
 			}
-			label, err := service.Get(id)
+			label, err := service.Get(cmd.Context(), id)
 			if err != nil {
 				return err
 			}
@@ -55,13 +54,13 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Short: "Create a new label",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if createTitle == "" {
-				return fmt.Errorf("--title is required")
+				return skillkit.Usagef("--title is required")
 			}
 			req := CreateLabelRequest{
 				Title:    createTitle,
 				HexColor: createColor,
 			}
-			label, err := service.Create(req)
+			label, err := service.Create(cmd.Context(), req)
 			if err != nil {
 				return err
 			}
@@ -79,7 +78,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Short: "Update a label",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			id, err := parseID(args[0])
+			id, err := skillkit.ParseID(args[0])
 			if err != nil {
 				return err
 			}
@@ -87,7 +86,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 				Title:    updateTitle,
 				HexColor: updateColor,
 			}
-			label, err := service.Update(id, req)
+			label, err := service.Update(cmd.Context(), id, req)
 			if err != nil {
 				return err
 			}
@@ -103,11 +102,11 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Short: "Delete a label",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			id, err := parseID(args[0])
+			id, err := skillkit.ParseID(args[0])
 			if err != nil {
 				return err
 			}
-			if err := service.Delete(id); err != nil {
+			if err := service.Delete(cmd.Context(), id); err != nil {
 				return err
 			}
 			return printJSON(map[string]bool{"deleted": true})
@@ -117,12 +116,3 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 	cmd.AddCommand(listCmd, getCmd, createCmd, updateCmd, deleteCmd)
 	return cmd
 }
-
-func parseID(s string) (int64, error) {
-	var id int64
-	_, err := fmt.Sscanf(s, "%d", &id)
-	if err != nil {
-		return 0, fmt.Errorf("invalid ID: %s", s)
-	}
-	return id, nil
-}
@@ -2,8 +2,11 @@
 package labels
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"hash/fnv"
+	"net/url"
+	"strings"
 
 	"github.com/petervogelmann/skillfactory/skills/vikunja/client"
 )
@@ -19,31 +22,81 @@ func NewService(c *client.Client) *Service {
 }
 
 // List retrieves all labels
-func (s *Service) List() ([]Label, error) {
-	data, err := s.client.Get("/labels")
+func (s *Service) List(ctx context.Context) ([]Label, error) {
+	data, err := s.client.Get(ctx, "/labels")
 	if err != nil {
 		return nil, err
 	}
 
 	var labels []Label
-	if err := json.Unmarshal(data, &labels); err != nil {
+	if err := s.client.Decode(data, &labels); err != nil {
 		return nil, fmt.Errorf("failed to parse labels: %w", err)
 	}
 
 	return labels, nil
 }
 
+// Search retrieves the labels whose title contains query
+func (s *Service) Search(ctx context.Context, query string) ([]Label, error) {
+	data, err := s.client.Get(ctx, "/labels?s="+url.QueryEscape(query))
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []Label
+	if err := s.client.Decode(data, &labels); err != nil {
+		return nil, fmt.Errorf("failed to parse labels: %w", err)
+	}
+
+	return labels, nil
+}
+
+// FindOrCreate returns the label with the given title (case-insensitive),
+// creating it if it does not exist. New labels get name.HexColor or a color
+// derived from the title. created reports whether the label was created.
+func (s *Service) FindOrCreate(ctx context.Context, name LabelName) (label *Label, created bool, err error) {
+	matches, err := s.Search(ctx, name.Title)
+	if err != nil {
+		return nil, false, err
+	}
+	for i := range matches {
+		if strings.EqualFold(matches[i].Title, name.Title) {
+			return &matches[i], false, nil
+		}
+	}
+
+	color := name.HexColor
+	if color == "" {
+		color = DefaultColor(name.Title)
+	}
+	label, err = s.Create(ctx, CreateLabelRequest{Title: name.Title, HexColor: color})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create label %s: %w", name.Title, err)
+	}
+	return label, true, nil
+}
+
+// palette holds the colors of labels created without a color
+var palette = []string{"e11d48", "ea580c", "ca8a04", "16a34a", "0891b2", "2563eb", "7c3aed", "db2777"}
+
+// DefaultColor picks a palette color for a label title, the same for every call
+func DefaultColor(title string) string {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(title)))
+	return palette[h.Sum32()%uint32(len(palette))]
+}
+
 // Get retrieves a single label by ID
-func (s *Service) Get(labelID int64) (*Label, error) {
+func (s *Service) Get(ctx context.Context, labelID int64) (*Label, error) {
 	endpoint := fmt.Sprintf("/labels/%d", labelID)
 
-	data, err := s.client.Get(endpoint)
+	data, err := s.client.Get(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
 	var label Label
-	if err := json.Unmarshal(data, &label); err != nil {
+	if err := s.client.Decode(data, &label); err != nil {
 		return nil, fmt.Errorf("failed to parse label: %w", err)
 	}
 
@@ -51,14 +104,14 @@ func (s *Service) Get(labelID int64) (*Label, error) {
 }
 
 // Create creates a new label
-func (s *Service) Create(req CreateLabelRequest) (*Label, error) {
-	data, err := s.client.Put("/labels", req)
+func (s *Service) Create(ctx context.Context, req CreateLabelRequest) (*Label, error) {
+	data, err := s.client.Put(ctx, "/labels", req)
 	if err != nil {
 		return nil, err
 	}
 
 	var label Label
-	if err := json.Unmarshal(data, &label); err != nil {
+	if err := s.client.Decode(data, &label); err != nil {
 		return nil, fmt.Errorf("failed to parse created label: %w", err)
 	}
 
@@ -66,16 +119,16 @@ func (s *Service) Create(req CreateLabelRequest) (*Label, error) {
 }
 
 // Update updates an existing label
-func (s *Service) Update(labelID int64, req UpdateLabelRequest) (*Label, error) {
+func (s *Service) Update(ctx context.Context, labelID int64, req UpdateLabelRequest) (*Label, error) {
 	endpoint := fmt.Sprintf("/labels/%d", labelID)
 
-	data, err := s.client.Post(endpoint, req)
+	data, err := s.client.Post(ctx, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
 
 	var label Label
-	if err := json.Unmarshal(data, &label); err != nil {
+	if err := s.client.Decode(data, &label); err != nil {
 		return nil, fmt.Errorf("failed to parse updated label: %w", err)
 	}
 
@@ -83,8 +136,8 @@ func (s *Service) Update(labelID int64, req UpdateLabelRequest) (*Label, error)
 }
 
 // Delete deletes a label
-func (s *Service) Delete(labelID int64) error {
+func (s *Service) Delete(ctx context.Context, labelID int64) error {
 	endpoint := fmt.Sprintf("/labels/%d", labelID)
-	_, err := s.client.Delete(endpoint)
+	_, err := s.client.Delete(ctx, endpoint)
 	return err
 }
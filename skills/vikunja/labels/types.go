@@ -1,6 +1,13 @@
 // Package labels provides label-related types and operations for Vikunja API
 package labels
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+)
+
 // Label represents a Vikunja label (based on OpenAPI spec models.Label)
 type Label struct {
 	ID          int64  `json:"id,omitempty"`
@@ -43,9 +50,43 @@ func (l *Label) ToLean() LabelLean {
 
 // ToLeanSlice converts a slice of Labels to lean output
 func ToLeanSlice(labels []Label) []LabelLean {
-	result := make([]LabelLean, len(labels))
-	for i := range labels {
-		result[i] = labels[i].ToLean()
+	return skillkit.LeanSlice(labels, (*Label).ToLean)
+}
+
+// LabelName is a label referenced by title, with an optional color for creation
+type LabelName struct {
+	Title    string
+	HexColor string
+}
+
+// ParseNames parses a comma-separated list of label titles, each optionally
+// followed by a color: "urgent:#ff0000,home"
+func ParseNames(s string) ([]LabelName, error) {
+	var names []LabelName
+	for _, part := range strings.Split(s, ",") {
+		title, color, _ := strings.Cut(strings.TrimSpace(part), ":")
+		title = strings.TrimSpace(title)
+		color = strings.TrimPrefix(strings.TrimSpace(color), "#")
+		if title == "" {
+			continue
+		}
+		if color != "" && !IsHexColor(color) {
+			return nil, fmt.Errorf("invalid color '%s' for label %s", color, title)
+		}
+		names = append(names, LabelName{Title: title, HexColor: color})
+	}
+	return names, nil
+}
+
+// IsHexColor reports whether s is a 6 digit hex color without "#"
+func IsHexColor(s string) bool {
+	if len(s) != 6 {
+		return false
+	}
+	for _, c := range strings.ToLower(s) {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
 	}
-	return result
+	return true
 }
@@ -0,0 +1,344 @@
+package projects
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/petervogelmann/skillfactory/skills/vikunja/labels"
+	"github.com/petervogelmann/skillfactory/skills/vikunja/tasks"
+	"gopkg.in/yaml.v3"
+)
+
+// Template is a declarative project setup for projects apply, read from
+// YAML or JSON
+type Template struct {
+	Title       string         `yaml:"title"`
+	Description string         `yaml:"description"`
+	HexColor    string         `yaml:"hex_color"`
+	Buckets     []string       `yaml:"buckets"` // Kanban buckets, in this order
+	Labels      []string       `yaml:"labels"`  // Label titles, optionally "title:#color"
+	Tasks       []TemplateTask `yaml:"tasks"`
+}
+
+// TemplateTask is a seed task of a template, matched by title
+type TemplateTask struct {
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Priority    *int     `yaml:"priority"`
+	Due         string   `yaml:"due"` // Only set on creation, may be relative (+3d)
+	Labels      []string `yaml:"labels"`
+	Bucket      string   `yaml:"bucket"`
+}
+
+// ApplyResult lists what projects apply changed
+type ApplyResult struct {
+	Project   ProjectLean   `json:"project"`
+	Changes   []ApplyChange `json:"changes"`
+	Unchanged int           `json:"unchanged"` // Buckets, labels and tasks already as declared
+}
+
+// ApplyChange is a created or updated part of the project
+type ApplyChange struct {
+	Kind   string   `json:"kind"`   // project, bucket, label or task
+	Title  string   `json:"title"`  // Title of the project, bucket, label or task
+	Action string   `json:"action"` // created or updated
+	Fields []string `json:"fields,omitempty"`
+}
+
+// ParseTemplate reads a template and checks that it is complete
+func ParseTemplate(r io.Reader) (*Template, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	var t Template
+	if err := dec.Decode(&t); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	t.Title = strings.TrimSpace(t.Title)
+	if t.Title == "" {
+		return nil, fmt.Errorf("invalid template: title is required")
+	}
+	t.HexColor = strings.TrimPrefix(t.HexColor, "#")
+	if t.HexColor != "" && !labels.IsHexColor(t.HexColor) {
+		return nil, fmt.Errorf("invalid template: hex_color '%s'", t.HexColor)
+	}
+	seen := make(map[string]bool)
+	for i, task := range t.Tasks {
+		switch {
+		case strings.TrimSpace(task.Title) == "":
+			return nil, fmt.Errorf("invalid template: task %d has no title", i+1)
+		case seen[task.Title]:
+			return nil, fmt.Errorf("invalid template: task %q is declared twice", task.Title)
+		case task.Bucket != "" && !slices.Contains(t.Buckets, task.Bucket):
+			return nil, fmt.Errorf("invalid template: bucket %q of task %q is not in buckets", task.Bucket, task.Title)
+		case task.Priority != nil && (*task.Priority < 0 || *task.Priority > 5):
+			return nil, fmt.Errorf("invalid template: priority of task %q must be 0-5", task.Title)
+		}
+		seen[task.Title] = true
+	}
+	return &t, nil
+}
+
+// Apply creates the project of a template or brings an existing one with
+// the same title in line with it: missing buckets, labels and tasks are
+// created, changed colors, descriptions, priorities, labels and buckets are
+// updated. Nothing is deleted, so buckets, labels and tasks added by hand
+// are kept; applying a template twice changes nothing the second time.
+func (s *Service) Apply(ctx context.Context, t *Template) (*ApplyResult, error) {
+	result := &ApplyResult{Changes: []ApplyChange{}}
+	project, err := s.applyProject(ctx, t, result)
+	if err != nil {
+		return nil, err
+	}
+	result.Project = project.ToLean()
+
+	labelIDs, err := s.applyLabels(ctx, t, result)
+	if err != nil {
+		return nil, err
+	}
+
+	a := applier{Service: s, project: project, result: result, labelIDs: labelIDs}
+	if len(t.Buckets) > 0 {
+		if err := a.applyBuckets(ctx, t.Buckets); err != nil {
+			return nil, err
+		}
+	}
+	for _, task := range t.Tasks {
+		if err := a.applyTask(ctx, task); err != nil {
+			return nil, fmt.Errorf("task %q: %w", task.Title, err)
+		}
+	}
+	return result, nil
+}
+
+// applyProject finds the project by title or creates it, and updates its
+// description and color
+func (s *Service) applyProject(ctx context.Context, t *Template, result *ApplyResult) (*Project, error) {
+	projects, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(projects, func(p Project) bool { return p.Title == t.Title })
+	if i < 0 {
+		project, err := s.Create(ctx, CreateProjectRequest{Title: t.Title, Description: t.Description, HexColor: t.HexColor})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create project %s: %w", t.Title, err)
+		}
+		result.Changes = append(result.Changes, ApplyChange{Kind: "project", Title: t.Title, Action: "created"})
+		return project, nil
+	}
+
+	project := &projects[i]
+	var fields []string
+	if t.Description != "" && project.Description != t.Description {
+		project.Description = t.Description
+		fields = append(fields, "description")
+	}
+	if t.HexColor != "" && !strings.EqualFold(project.HexColor, t.HexColor) {
+		project.HexColor = t.HexColor
+		fields = append(fields, "hex_color")
+	}
+	if len(fields) == 0 {
+		return project, nil
+	}
+	project, err = s.Update(ctx, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update project %s: %w", t.Title, err)
+	}
+	result.Changes = append(result.Changes, ApplyChange{Kind: "project", Title: t.Title, Action: "updated", Fields: fields})
+	return project, nil
+}
+
+// applyLabels finds or creates the labels of the template and its tasks and
+// returns their IDs by lowercase title. Colors are only updated for the
+// labels of the template.
+func (s *Service) applyLabels(ctx context.Context, t *Template, result *ApplyResult) (map[string]int64, error) {
+	declared, err := labels.ParseNames(strings.Join(t.Labels, ","))
+	if err != nil {
+		return nil, err
+	}
+	names := slices.Clone(declared)
+	for _, task := range t.Tasks {
+		taskNames, err := labels.ParseNames(strings.Join(task.Labels, ","))
+		if err != nil {
+			return nil, fmt.Errorf("task %q: %w", task.Title, err)
+		}
+		names = append(names, taskNames...)
+	}
+
+	labelService := labels.NewService(s.client)
+	ids := make(map[string]int64)
+	for i, name := range names {
+		key := strings.ToLower(name.Title)
+		if _, ok := ids[key]; ok {
+			continue
+		}
+		label, created, err := labelService.FindOrCreate(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		ids[key] = label.ID
+		switch {
+		case created:
+			result.Changes = append(result.Changes, ApplyChange{Kind: "label", Title: label.Title, Action: "created"})
+		case i < len(declared) && name.HexColor != "" && !strings.EqualFold(label.HexColor, name.HexColor):
+			req := labels.UpdateLabelRequest{Title: label.Title, HexColor: name.HexColor}
+			if _, err := labelService.Update(ctx, label.ID, req); err != nil {
+				return nil, fmt.Errorf("failed to update label %s: %w", label.Title, err)
+			}
+			result.Changes = append(result.Changes, ApplyChange{Kind: "label", Title: label.Title, Action: "updated", Fields: []string{"hex_color"}})
+		default:
+			if i < len(declared) {
+				result.Unchanged++
+			}
+		}
+	}
+	return ids, nil
+}
+
+// applier holds the state of Apply after the project and labels exist
+type applier struct {
+	*Service
+	project  *Project
+	result   *ApplyResult
+	labelIDs map[string]int64 // By lowercase title
+
+	viewID      int64            // Kanban view, 0 without buckets in the template
+	buckets     map[string]int64 // Bucket IDs by title
+	taskBuckets map[int64]int64  // Bucket ID by task ID
+}
+
+// applyBuckets creates the missing buckets in the kanban view of the
+// project and records which bucket each task is in
+func (a *applier) applyBuckets(ctx context.Context, titles []string) error {
+	views, err := a.Views(ctx, a.project.ID)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(views, func(v View) bool { return v.ViewKind == ViewKanban })
+	if i < 0 {
+		return fmt.Errorf("project %s has no kanban view for the buckets", a.project.Title)
+	}
+	a.viewID = views[i].ID
+
+	existing, err := a.Buckets(ctx, a.project.ID, a.viewID)
+	if err != nil {
+		return err
+	}
+	a.buckets = make(map[string]int64)
+	a.taskBuckets = make(map[int64]int64)
+	for _, b := range existing {
+		a.buckets[b.Title] = b.ID
+		for _, task := range b.Tasks {
+			a.taskBuckets[task.ID] = b.ID
+		}
+	}
+
+	for _, title := range titles {
+		if _, ok := a.buckets[title]; ok {
+			a.result.Unchanged++
+			continue
+		}
+		bucket, err := a.CreateBucket(ctx, a.project.ID, a.viewID, title)
+		if err != nil {
+			return fmt.Errorf("failed to create bucket %s: %w", title, err)
+		}
+		a.buckets[title] = bucket.ID
+		a.result.Changes = append(a.result.Changes, ApplyChange{Kind: "bucket", Title: title, Action: "created"})
+	}
+	return nil
+}
+
+// applyTask creates a seed task or updates the declared fields of the task
+// with its title
+func (a *applier) applyTask(ctx context.Context, t TemplateTask) error {
+	taskService := tasks.NewService(a.client)
+	found, err := taskService.List(ctx, tasks.ListOptions{ProjectID: a.project.ID, IncludeDone: true, Search: t.Title})
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(found, func(task tasks.Task) bool { return task.Title == t.Title })
+
+	if i < 0 {
+		req := tasks.CreateTaskRequest{Title: t.Title, Description: t.Description, DueDate: t.Due}
+		if t.Priority != nil {
+			req.Priority = *t.Priority
+		}
+		task, err := taskService.Create(ctx, a.project.ID, req)
+		if err != nil {
+			return err
+		}
+		if _, err := a.applyTaskLabels(ctx, task, t.Labels); err != nil {
+			return err
+		}
+		if t.Bucket != "" {
+			if err := a.MoveToBucket(ctx, a.project.ID, a.viewID, a.buckets[t.Bucket], task.ID); err != nil {
+				return fmt.Errorf("failed to move to bucket %s: %w", t.Bucket, err)
+			}
+		}
+		a.result.Changes = append(a.result.Changes, ApplyChange{Kind: "task", Title: t.Title, Action: "created"})
+		return nil
+	}
+
+	task := &found[i]
+	var fields []string
+	var req tasks.UpdateTaskRequest
+	if t.Description != "" && task.Description != t.Description {
+		req.Description = &t.Description
+		fields = append(fields, "description")
+	}
+	if t.Priority != nil && task.Priority != *t.Priority {
+		req.Priority = t.Priority
+		fields = append(fields, "priority")
+	}
+	if len(fields) > 0 {
+		if _, err := taskService.Update(ctx, task.ID, req); err != nil {
+			return err
+		}
+	}
+	added, err := a.applyTaskLabels(ctx, task, t.Labels)
+	if err != nil {
+		return err
+	}
+	if added {
+		fields = append(fields, "labels")
+	}
+	if bucketID := a.buckets[t.Bucket]; t.Bucket != "" && a.taskBuckets[task.ID] != bucketID {
+		if err := a.MoveToBucket(ctx, a.project.ID, a.viewID, bucketID, task.ID); err != nil {
+			return fmt.Errorf("failed to move to bucket %s: %w", t.Bucket, err)
+		}
+		fields = append(fields, "bucket")
+	}
+
+	if len(fields) == 0 {
+		a.result.Unchanged++
+		return nil
+	}
+	a.result.Changes = append(a.result.Changes, ApplyChange{Kind: "task", Title: t.Title, Action: "updated", Fields: fields})
+	return nil
+}
+
+// applyTaskLabels adds the declared labels missing on a task and reports
+// whether there were any. Other labels of the task are kept.
+func (a *applier) applyTaskLabels(ctx context.Context, task *tasks.Task, names []string) (bool, error) {
+	parsed, err := labels.ParseNames(strings.Join(names, ","))
+	if err != nil {
+		return false, err
+	}
+	taskService := tasks.NewService(a.client)
+	added := false
+	for _, name := range parsed {
+		id := a.labelIDs[strings.ToLower(name.Title)]
+		if slices.ContainsFunc(task.Labels, func(l tasks.Label) bool { return l.ID == id }) {
+			continue
+		}
+		if err := taskService.AddLabel(ctx, task.ID, id); err != nil {
+			return false, fmt.Errorf("failed to add label %s: %w", name.Title, err)
+		}
+		added = true
+	}
+	return added, nil
+}
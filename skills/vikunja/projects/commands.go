@@ -1,8 +1,9 @@
 package projects
 
 import (
-	"fmt"
+	"os"
 
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
 	"github.com/petervogelmann/skillfactory/skills/vikunja/client"
 	"github.com/spf13/cobra"
 )
@@ -21,7 +22,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Use:   "list",
 		Short: "List all projects",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			projects, err := service.List()
+			projects, err := service.List(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -35,11 +36,11 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		Short: "Get a project by ID",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			id, err := parseID(args[0])
+			id, err := skillkit.ParseID(args[0])
 			if err != nil {
 				return err
 			}
-			project, err := service.Get(id)
+			project, err := service.Get(cmd.Context(), id)
 			if err != nil {
 				return err
 			}
@@ -47,15 +48,61 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 		},
 	}
 
-	cmd.AddCommand(listCmd, getCmd)
-	return cmd
-}
+	// apply
+	var applyFile string
+	applyCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Create or update a project from a YAML or JSON template",
+		Long: `Set up a project with its kanban buckets, labels and seed tasks from a
+template. The project is matched by title: a missing one is created, an
+existing one gets the missing buckets, labels and tasks and the declared
+description, colors, priorities, labels and buckets of its tasks. Nothing
+is deleted, and applying the same template again changes nothing.
+
+Template:
+  title: Umzug
+  description: Alles rund um den Umzug
+  hex_color: "#1973ff"
+  buckets: [Backlog, Diese Woche, Erledigt]
+  labels: ["@telefon", "dringend:#e11d48"]
+  tasks:
+    - title: Nachsendeauftrag stellen
+      priority: 3
+      due: +14d            # Only set when the task is created
+      labels: ["@telefon"]
+      bucket: Backlog
 
-func parseID(s string) (int64, error) {
-	var id int64
-	_, err := fmt.Sscanf(s, "%d", &id)
-	if err != nil {
-		return 0, fmt.Errorf("invalid ID: %s", s)
+Examples:
+  vikunja projects apply --file umzug.yaml
+  cat umzug.json | vikunja projects apply --file -`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if applyFile == "" {
+				return skillkit.Usagef("--file is required")
+			}
+
+			in := cmd.InOrStdin()
+			if applyFile != "-" {
+				f, err := os.Open(applyFile)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				in = f
+			}
+			template, err := ParseTemplate(in)
+			if err != nil {
+				return err
+			}
+
+			result, err := service.Apply(cmd.Context(), template)
+			if err != nil {
+				return err
+			}
+			return printJSON(result)
+		},
 	}
-	return id, nil
+	applyCmd.Flags().StringVar(&applyFile, "file", "", "Template file, - for stdin (required)")
+
+	cmd.AddCommand(listCmd, getCmd, applyCmd)
+	return cmd
 }
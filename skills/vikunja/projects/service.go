@@ -2,7 +2,7 @@
 package projects
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 
 	"github.com/petervogelmann/skillfactory/skills/vikunja/client"
@@ -19,14 +19,14 @@ func NewService(c *client.Client) *Service {
 }
 
 // List retrieves all projects
-func (s *Service) List() ([]Project, error) {
-	data, err := s.client.Get("/projects")
+func (s *Service) List(ctx context.Context) ([]Project, error) {
+	data, err := s.client.Get(ctx, "/projects")
 	if err != nil {
 		return nil, err
 	}
 
 	var projects []Project
-	if err := json.Unmarshal(data, &projects); err != nil {
+	if err := s.client.Decode(data, &projects); err != nil {
 		return nil, fmt.Errorf("failed to parse projects: %w", err)
 	}
 
@@ -34,18 +34,112 @@ func (s *Service) List() ([]Project, error) {
 }
 
 // Get retrieves a single project by ID
-func (s *Service) Get(projectID int64) (*Project, error) {
+func (s *Service) Get(ctx context.Context, projectID int64) (*Project, error) {
 	endpoint := fmt.Sprintf("/projects/%d", projectID)
 
-	data, err := s.client.Get(endpoint)
+	data, err := s.client.Get(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
 	var project Project
-	if err := json.Unmarshal(data, &project); err != nil {
+	if err := s.client.Decode(data, &project); err != nil {
 		return nil, fmt.Errorf("failed to parse project: %w", err)
 	}
 
 	return &project, nil
 }
+
+// Create creates a new project
+func (s *Service) Create(ctx context.Context, req CreateProjectRequest) (*Project, error) {
+	data, err := s.client.Put(ctx, "/projects", req)
+	if err != nil {
+		return nil, err
+	}
+
+	var project Project
+	if err := s.client.Decode(data, &project); err != nil {
+		return nil, fmt.Errorf("failed to parse created project: %w", err)
+	}
+
+	return &project, nil
+}
+
+// Update sends the full project object to replace the stored one
+func (s *Service) Update(ctx context.Context, project *Project) (*Project, error) {
+	endpoint := fmt.Sprintf("/projects/%d", project.ID)
+
+	data, err := s.client.Post(ctx, endpoint, project)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated Project
+	if err := s.client.Decode(data, &updated); err != nil {
+		return nil, fmt.Errorf("failed to parse updated project: %w", err)
+	}
+
+	return &updated, nil
+}
+
+// Views retrieves the views of a project
+func (s *Service) Views(ctx context.Context, projectID int64) ([]View, error) {
+	endpoint := fmt.Sprintf("/projects/%d/views", projectID)
+
+	data, err := s.client.Get(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var views []View
+	if err := s.client.Decode(data, &views); err != nil {
+		return nil, fmt.Errorf("failed to parse project views: %w", err)
+	}
+
+	return views, nil
+}
+
+// Buckets retrieves the buckets of a kanban view with their tasks
+func (s *Service) Buckets(ctx context.Context, projectID, viewID int64) ([]Bucket, error) {
+	endpoint := fmt.Sprintf("/projects/%d/views/%d/tasks", projectID, viewID)
+
+	data, err := s.client.Get(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []Bucket
+	if err := s.client.Decode(data, &buckets); err != nil {
+		return nil, fmt.Errorf("failed to parse buckets: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// CreateBucket adds a bucket to a kanban view
+func (s *Service) CreateBucket(ctx context.Context, projectID, viewID int64, title string) (*Bucket, error) {
+	endpoint := fmt.Sprintf("/projects/%d/views/%d/buckets", projectID, viewID)
+
+	data, err := s.client.Put(ctx, endpoint, map[string]string{"title": title})
+	if err != nil {
+		return nil, err
+	}
+
+	var bucket Bucket
+	if err := s.client.Decode(data, &bucket); err != nil {
+		return nil, fmt.Errorf("failed to parse created bucket: %w", err)
+	}
+
+	return &bucket, nil
+}
+
+// MoveToBucket moves a task into a bucket of a kanban view
+func (s *Service) MoveToBucket(ctx context.Context, projectID, viewID, bucketID, taskID int64) error {
+	endpoint := fmt.Sprintf("/projects/%d/views/%d/buckets/%d/tasks", projectID, viewID, bucketID)
+	_, err := s.client.Post(ctx, endpoint, map[string]int64{
+		"task_id":         taskID,
+		"bucket_id":       bucketID,
+		"project_view_id": viewID,
+	})
+	return err
+}
@@ -1,6 +1,13 @@
 // Package projects provides project-related types and operations for Vikunja API
 package projects
 
+import (
+	"encoding/json"
+
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
+	"github.com/petervogelmann/skillfactory/skills/vikunja/tasks"
+)
+
 // Project represents a Vikunja project (based on OpenAPI spec models.Project)
 type Project struct {
 	ID              int64   `json:"id,omitempty"`
@@ -16,6 +23,48 @@ type Project struct {
 	Updated         string  `json:"updated,omitempty"`
 }
 
+// CreateProjectRequest represents a project creation request
+type CreateProjectRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	HexColor    string `json:"hex_color,omitempty"`
+}
+
+// View is a view of a project (list, gantt, table or kanban), based on
+// models.ProjectView; kanban views hold the buckets
+type View struct {
+	ID                      int64           `json:"id"`
+	Title                   string          `json:"title"`
+	ProjectID               int64           `json:"project_id"`
+	ViewKind                string          `json:"view_kind"`
+	Filter                  json.RawMessage `json:"filter,omitempty"`
+	Position                float64         `json:"position,omitempty"`
+	BucketConfigurationMode string          `json:"bucket_configuration_mode,omitempty"`
+	BucketConfiguration     json.RawMessage `json:"bucket_configuration,omitempty"`
+	DefaultBucketID         int64           `json:"default_bucket_id,omitempty"`
+	DoneBucketID            int64           `json:"done_bucket_id,omitempty"`
+	Created                 string          `json:"created,omitempty"`
+	Updated                 string          `json:"updated,omitempty"`
+}
+
+// ViewKanban is the view kind holding buckets
+const ViewKanban = "kanban"
+
+// Bucket is a column of a kanban view (based on models.Bucket), with its
+// tasks when listed through the view
+type Bucket struct {
+	ID            int64           `json:"id"`
+	Title         string          `json:"title"`
+	ProjectViewID int64           `json:"project_view_id,omitempty"`
+	Limit         int             `json:"limit,omitempty"`
+	Count         int             `json:"count,omitempty"`
+	Position      float64         `json:"position,omitempty"`
+	CreatedBy     json.RawMessage `json:"created_by,omitempty"`
+	Created       string          `json:"created,omitempty"`
+	Updated       string          `json:"updated,omitempty"`
+	Tasks         []tasks.Task    `json:"tasks,omitempty"`
+}
+
 // ProjectLean represents lean project output for CLI
 type ProjectLean struct {
 	ID    int64  `json:"id"`
@@ -32,9 +81,5 @@ func (p *Project) ToLean() ProjectLean {
 
 // ToLeanSlice converts a slice of Projects to lean output
 func ToLeanSlice(projects []Project) []ProjectLean {
-	result := make([]ProjectLean, len(projects))
-	for i := range projects {
-		result[i] = projects[i].ToLean()
-	}
-	return result
+	return skillkit.LeanSlice(projects, (*Project).ToLean)
 }
@@ -2,10 +2,13 @@ package tasks
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
+	"os"
+	"slices"
+	"time"
 
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
 	"github.com/petervogelmann/skillfactory/skills/vikunja/client"
+	"github.com/petervogelmann/skillfactory/skills/vikunja/labels"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +28,7 @@ func RegisterCommands(c *client.Client, printJSON func(interface{}) error) *cobr
 	var listSearch string
 	var listSortBy string
 	var listOrderBy string
+	var listFields string
 	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List tasks",
@@ -35,7 +39,11 @@ Filter examples (Vikunja filter syntax):
   --filter "due_date < now"
   --filter "assignees in user1"
 
-See https://vikunja.io/docs/filters for full filter documentation.`,
+See https://vikunja.io/docs/filters for full filter documentation.
+
+--fields limits the output to the given fields (the id is always kept).
+Without it, tasks of projects listed in VIKUNJA_PROJECT_FIELDS
+("3=title,priority,labels;7=title") use that project's field set.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts := ListOptions{
 				ProjectID:   listProjectID,
@@ -45,11 +53,26 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 				SortBy:      listSortBy,
 				OrderBy:     listOrderBy,
 			}
-			tasks, err := service.List(opts)
+			fields, err := ParseFields(listFields)
+			if err != nil {
+				return err
+			}
+			sets, err := projectFields()
+			if err != nil {
+				return err
+			}
+			tasks, err := service.List(cmd.Context(), opts)
+			if err != nil {
+				return err
+			}
+			if len(fields) == 0 && len(sets) == 0 {
+				return printJSON(ToLeanSlice(tasks))
+			}
+			selected, err := SelectFields(ToLeanSlice(tasks), fields, sets)
 			if err != nil {
 				return err
 			}
-			return printJSON(ToLeanSlice(tasks))
+			return printJSON(selected)
 		},
 	}
 	listCmd.Flags().Int64VarP(&listProjectID, "project", "p", 0, "Filter by project ID")
@@ -58,6 +81,7 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 	listCmd.Flags().StringVarP(&listSearch, "search", "s", "", "Search in task text")
 	listCmd.Flags().StringVar(&listSortBy, "sort", "", "Sort by field (id, title, due_date, priority, created, updated)")
 	listCmd.Flags().StringVar(&listOrderBy, "order", "", "Sort order (asc, desc)")
+	listCmd.Flags().StringVar(&listFields, "fields", "", "Comma-separated output fields (e.g. title,priority,labels)")
 
 	// get
 	getCmd := &cobra.Command{
@@ -65,11 +89,11 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 		Short: "Get a task by ID",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			id, err := parseID(args[0])
+			id, err := skillkit.ParseID(args[0])
 			if err != nil {
 				return err
 			}
-			task, err := service.Get(id)
+			task, err := service.Get(cmd.Context(), id)
 			if err != nil {
 				return err
 			}
@@ -88,16 +112,24 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 	var createFavorite bool
 	var createPercent int
 	var createLabels string
+	var createLabelNames string
 	var createProjectID int64
 	createCmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new task",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if createTitle == "" {
-				return fmt.Errorf("--title is required")
+				return skillkit.Usagef("--title is required")
 			}
 			if createProjectID == 0 {
-				return fmt.Errorf("--project is required")
+				id, err := defaultProject()
+				if err != nil {
+					return err
+				}
+				if id == 0 {
+					return skillkit.Usagef("--project is required (or configure %s)", DefaultProjectEnvVar)
+				}
+				createProjectID = id
 			}
 			req := CreateTaskRequest{
 				Title:       createTitle,
@@ -112,24 +144,38 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 			if cmd.Flags().Changed("favorite") {
 				req.IsFavorite = &createFavorite
 			}
-			task, err := service.Create(createProjectID, req)
+			task, err := service.Create(cmd.Context(), createProjectID, req)
 			if err != nil {
 				return err
 			}
 
 			// Add labels if specified
-			if createLabels != "" {
-				labelIDs, err := parseIDList(createLabels)
+			if createLabels != "" || createLabelNames != "" {
+				labelIDs, err := skillkit.ParseIntList[int64](createLabels)
 				if err != nil {
-					return fmt.Errorf("invalid labels: %w", err)
+					return skillkit.Usagef("invalid labels: %w", err)
+				}
+				names, err := labels.ParseNames(createLabelNames)
+				if err != nil {
+					return err
+				}
+				labelService := labels.NewService(c)
+				for _, name := range names {
+					label, _, err := labelService.FindOrCreate(cmd.Context(), name)
+					if err != nil {
+						return err
+					}
+					if !slices.Contains(labelIDs, label.ID) {
+						labelIDs = append(labelIDs, label.ID)
+					}
 				}
 				for _, labelID := range labelIDs {
-					if err := service.AddLabel(task.ID, labelID); err != nil {
+					if err := service.AddLabel(cmd.Context(), task.ID, labelID); err != nil {
 						return fmt.Errorf("failed to add label %d: %w", labelID, err)
 					}
 				}
 				// Refresh task to get labels
-				task, err = service.Get(task.ID)
+				task, err = service.Get(cmd.Context(), task.ID)
 				if err != nil {
 					return err
 				}
@@ -140,15 +186,16 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 	}
 	createCmd.Flags().StringVarP(&createTitle, "title", "t", "", "Task title (required)")
 	createCmd.Flags().StringVarP(&createDescription, "description", "d", "", "Task description")
-	createCmd.Flags().Int64VarP(&createProjectID, "project", "p", 0, "Project ID (required)")
+	createCmd.Flags().Int64VarP(&createProjectID, "project", "p", 0, "Project ID (default: $VIKUNJA_DEFAULT_PROJECT)")
 	createCmd.Flags().IntVar(&createPriority, "priority", 0, "Task priority (0-5)")
-	createCmd.Flags().StringVar(&createDue, "due", "", "Due date (YYYY-MM-DD or YYYY-MM-DDTHH:MM)")
-	createCmd.Flags().StringVar(&createStart, "start", "", "Start date (YYYY-MM-DD)")
-	createCmd.Flags().StringVar(&createEnd, "end", "", "End date (YYYY-MM-DD)")
+	createCmd.Flags().StringVar(&createDue, "due", "", "Due date (YYYY-MM-DD, YYYY-MM-DDTHH:MM, tomorrow, +3d, friday, ...)")
+	createCmd.Flags().StringVar(&createStart, "start", "", "Start date (YYYY-MM-DD or relative, e.g. today)")
+	createCmd.Flags().StringVar(&createEnd, "end", "", "End date (YYYY-MM-DD or relative, e.g. +1w)")
 	createCmd.Flags().StringVar(&createColor, "color", "", "Hex color (e.g., #ff5733)")
 	createCmd.Flags().BoolVar(&createFavorite, "favorite", false, "Mark as favorite")
 	createCmd.Flags().IntVar(&createPercent, "percent", 0, "Percent done (0-100)")
 	createCmd.Flags().StringVar(&createLabels, "labels", "", "Label IDs (comma-separated, e.g., 1,3,5)")
+	createCmd.Flags().StringVar(&createLabelNames, "label-names", "", "Label titles, created if missing (comma-separated, optional color, e.g., urgent:#ff0000,home)")
 
 	// done
 	doneCmd := &cobra.Command{
@@ -156,11 +203,11 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 		Short: "Mark a task as done",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			id, err := parseID(args[0])
+			id, err := skillkit.ParseID(args[0])
 			if err != nil {
 				return err
 			}
-			task, err := service.Done(id)
+			task, err := service.Done(cmd.Context(), id)
 			if err != nil {
 				return err
 			}
@@ -188,7 +235,7 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 		Short: "Update a task",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if updateID == 0 {
-				return fmt.Errorf("--id is required")
+				return skillkit.Usagef("--id is required")
 			}
 			req := UpdateTaskRequest{}
 			if updateTitle != "" {
@@ -235,7 +282,7 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 			if cmd.Flags().Changed("project") {
 				req.ProjectID = &updateProjectID
 			}
-			task, err := service.Update(updateID, req)
+			task, err := service.Update(cmd.Context(), updateID, req)
 			if err != nil {
 				return err
 			}
@@ -246,9 +293,9 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 	updateCmd.Flags().StringVarP(&updateTitle, "title", "t", "", "New title")
 	updateCmd.Flags().StringVarP(&updateDescription, "description", "d", "", "New description")
 	updateCmd.Flags().IntVar(&updatePriority, "priority", 0, "New priority (0-5)")
-	updateCmd.Flags().StringVar(&updateDue, "due", "", "Due date (YYYY-MM-DD or YYYY-MM-DDTHH:MM)")
-	updateCmd.Flags().StringVar(&updateStart, "start", "", "Start date (YYYY-MM-DD)")
-	updateCmd.Flags().StringVar(&updateEnd, "end", "", "End date (YYYY-MM-DD)")
+	updateCmd.Flags().StringVar(&updateDue, "due", "", "Due date (YYYY-MM-DD, YYYY-MM-DDTHH:MM, tomorrow, +3d, friday, ...)")
+	updateCmd.Flags().StringVar(&updateStart, "start", "", "Start date (YYYY-MM-DD or relative, e.g. today)")
+	updateCmd.Flags().StringVar(&updateEnd, "end", "", "End date (YYYY-MM-DD or relative, e.g. +1w)")
 	updateCmd.Flags().StringVar(&updateColor, "color", "", "Hex color (e.g., #ff5733)")
 	updateCmd.Flags().BoolVar(&updateFavorite, "favorite", false, "Mark as favorite")
 	updateCmd.Flags().BoolVar(&updateNoFavorite, "no-favorite", false, "Remove favorite")
@@ -263,11 +310,11 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 		Short: "Delete a task",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			id, err := parseID(args[0])
+			id, err := skillkit.ParseID(args[0])
 			if err != nil {
 				return err
 			}
-			if err := service.Delete(id); err != nil {
+			if err := service.Delete(cmd.Context(), id); err != nil {
 				return err
 			}
 			return printJSON(map[string]bool{"deleted": true})
@@ -280,11 +327,11 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 		Short: "List labels for a task",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			taskID, err := parseID(args[0])
+			taskID, err := skillkit.ParseID(args[0])
 			if err != nil {
 				return err
 			}
-			labels, err := service.GetLabels(taskID)
+			labels, err := service.GetLabels(cmd.Context(), taskID)
 			if err != nil {
 				return err
 			}
@@ -303,21 +350,44 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 		},
 	}
 
+	// tree - task with its subtasks
+	treeCmd := &cobra.Command{
+		Use:   "tree [id]",
+		Short: "Show a task with all subtasks as nested tree",
+		Long: `Show a task and its subtasks, resolved recursively, as nested JSON.
+
+Each node rolls up its descendants: subtasks_done/subtasks_total count
+all levels below it, all_done is true when the task and every
+descendant are done.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := skillkit.ParseID(args[0])
+			if err != nil {
+				return err
+			}
+			tree, err := service.Tree(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+			return printJSON(tree)
+		},
+	}
+
 	// add-label
 	addLabelCmd := &cobra.Command{
 		Use:   "add-label [task-id] [label-id]",
 		Short: "Add a label to a task",
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			taskID, err := parseID(args[0])
+			taskID, err := skillkit.ParseID(args[0])
 			if err != nil {
 				return err
 			}
-			labelID, err := parseID(args[1])
+			labelID, err := skillkit.ParseID(args[1])
 			if err != nil {
 				return err
 			}
-			if err := service.AddLabel(taskID, labelID); err != nil {
+			if err := service.AddLabel(cmd.Context(), taskID, labelID); err != nil {
 				return err
 			}
 			return printJSON(map[string]interface{}{
@@ -334,15 +404,15 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 		Short: "Remove a label from a task",
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			taskID, err := parseID(args[0])
+			taskID, err := skillkit.ParseID(args[0])
 			if err != nil {
 				return err
 			}
-			labelID, err := parseID(args[1])
+			labelID, err := skillkit.ParseID(args[1])
 			if err != nil {
 				return err
 			}
-			if err := service.RemoveLabel(taskID, labelID); err != nil {
+			if err := service.RemoveLabel(cmd.Context(), taskID, labelID); err != nil {
 				return err
 			}
 			return printJSON(map[string]interface{}{
@@ -353,36 +423,128 @@ See https://vikunja.io/docs/filters for full filter documentation.`,
 		},
 	}
 
-	cmd.AddCommand(listCmd, getCmd, createCmd, doneCmd, updateCmd, deleteCmd, labelsCmd, addLabelCmd, removeLabelCmd)
-	return cmd
-}
+	// watch
+	var watchProjectID int64
+	var watchInterval time.Duration
+	var watchOnce bool
+	var watchState string
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Poll for changed tasks and emit NDJSON events",
+		Long: `Poll for tasks changed since the last run and print one JSON event per line.
 
-func parseID(s string) (int64, error) {
-	var id int64
-	_, err := fmt.Sscanf(s, "%d", &id)
-	if err != nil {
-		return 0, fmt.Errorf("invalid ID: %s", s)
-	}
-	return id, nil
-}
+The updated timestamps of seen tasks are stored locally, so changes made
+while the watcher was not running are reported on the next start. The first
+run only records the current state.
+
+Examples:
+  vikunja tasks watch --project 5 --interval 60s
+  vikunja tasks watch --once   # single poll, e.g. from cron`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if watchInterval < time.Second {
+				return skillkit.Usagef("--interval must be at least 1s")
+			}
+			if watchState == "" {
+				watchState = DefaultWatchStatePath(watchProjectID)
+			}
 
-// parseIDList parses a comma-separated list of IDs
-func parseIDList(s string) ([]int64, error) {
-	if s == "" {
-		return nil, nil
+			poll := func() error {
+				events, err := service.Changes(cmd.Context(), watchProjectID, watchState)
+				if err != nil {
+					return err
+				}
+				for _, event := range events {
+					if err := printJSON(event); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			if watchOnce {
+				return poll()
+			}
+
+			// Watch until interrupted or the --timeout expires
+			ticker := time.NewTicker(watchInterval)
+			defer ticker.Stop()
+
+			for {
+				// Keep watching on transient API errors
+				if err := poll(); err != nil && cmd.Context().Err() == nil {
+					skillkit.WriteError(cmd.ErrOrStderr(), err)
+				}
+				select {
+				case <-cmd.Context().Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
 	}
-	parts := strings.Split(s, ",")
-	ids := make([]int64, 0, len(parts))
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		if p == "" {
-			continue
-		}
-		id, err := strconv.ParseInt(p, 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("invalid ID '%s': %w", p, err)
-		}
-		ids = append(ids, id)
+	watchCmd.Flags().Int64VarP(&watchProjectID, "project", "p", 0, "Only watch tasks of this project")
+	watchCmd.Flags().DurationVarP(&watchInterval, "interval", "i", 60*time.Second, "Poll interval")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "Poll once and exit")
+	watchCmd.Flags().StringVar(&watchState, "state", "", "State file (default: user cache dir)")
+
+	// import-ics
+	var importFile string
+	var importProjectID int64
+	var importDryRun bool
+	importCmd := &cobra.Command{
+		Use:   "import-ics",
+		Short: "Create tasks from the events and to-dos of an iCalendar file",
+		Long: `Create a task for each VEVENT and VTODO of an .ics file, with its summary
+as title, its description, and its due date (DUE of a to-do, start of an
+event).
+
+Completed to-dos and cancelled entries are skipped, as are entries that
+already exist as a task with the same title and due date in the project,
+so an exported or subscribed calendar can be imported again.
+
+Examples:
+  vikunja tasks import-ics --file deadlines.ics --project 3
+  curl -s https://example.com/cal.ics | vikunja tasks import-ics --file - --project 3 --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if importFile == "" {
+				return skillkit.Usagef("--file is required")
+			}
+			if importProjectID == 0 {
+				id, err := defaultProject()
+				if err != nil {
+					return err
+				}
+				if id == 0 {
+					return skillkit.Usagef("--project is required (or configure %s)", DefaultProjectEnvVar)
+				}
+				importProjectID = id
+			}
+
+			in := cmd.InOrStdin()
+			if importFile != "-" {
+				f, err := os.Open(importFile)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				in = f
+			}
+			entries, err := ParseICS(in)
+			if err != nil {
+				return skillkit.Usagef("invalid calendar: %w", err)
+			}
+
+			result, err := service.ImportICS(cmd.Context(), importProjectID, entries, importDryRun)
+			if err != nil {
+				return err
+			}
+			return printJSON(result)
+		},
 	}
-	return ids, nil
+	importCmd.Flags().StringVar(&importFile, "file", "", "iCalendar file, - for stdin (required)")
+	importCmd.Flags().Int64VarP(&importProjectID, "project", "p", 0, "Project ID (default: $VIKUNJA_DEFAULT_PROJECT)")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Show the tasks without creating them")
+
+	cmd.AddCommand(listCmd, getCmd, createCmd, doneCmd, updateCmd, deleteCmd, labelsCmd, addLabelCmd, removeLabelCmd, treeCmd, watchCmd, importCmd)
+	return cmd
 }
@@ -0,0 +1,129 @@
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ProjectFieldsEnvVar maps project IDs to the fields tasks list prints for
+// their tasks, e.g. "3=title,priority,labels;7=title"
+const ProjectFieldsEnvVar = "VIKUNJA_PROJECT_FIELDS"
+
+// DefaultProjectEnvVar is the project ID tasks create and import-ics use
+// without --project
+const DefaultProjectEnvVar = "VIKUNJA_DEFAULT_PROJECT"
+
+// defaultProject reads the project ID of VIKUNJA_DEFAULT_PROJECT, 0 if unset
+func defaultProject() (int64, error) {
+	value := strings.TrimSpace(os.Getenv(DefaultProjectEnvVar))
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s %q", DefaultProjectEnvVar, value)
+	}
+	return id, nil
+}
+
+// LeanFields returns the JSON field names of TaskLean
+func LeanFields() []string {
+	t := reflect.TypeOf(TaskLean{})
+	fields := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+// ParseFields parses a comma-separated list of TaskLean field names
+func ParseFields(s string) ([]string, error) {
+	known := LeanFields()
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !slices.Contains(known, f) {
+			return nil, fmt.Errorf("unknown field %q (available: %s)", f, strings.Join(known, ", "))
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// ParseProjectFields parses the VIKUNJA_PROJECT_FIELDS format
+// "<project id>=<field>,<field>;..." into field sets per project
+func ParseProjectFields(s string) (map[int64][]string, error) {
+	sets := make(map[int64][]string)
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		idStr, list, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid entry %q (expected <project id>=<fields>)", entry)
+		}
+		id, err := strconv.ParseInt(strings.TrimSpace(idStr), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid project ID %q", idStr)
+		}
+		fields, err := ParseFields(list)
+		if err != nil {
+			return nil, fmt.Errorf("project %d: %w", id, err)
+		}
+		sets[id] = fields
+	}
+	return sets, nil
+}
+
+// projectFields reads the field sets of VIKUNJA_PROJECT_FIELDS
+func projectFields() (map[int64][]string, error) {
+	sets, err := ParseProjectFields(os.Getenv(ProjectFieldsEnvVar))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ProjectFieldsEnvVar, err)
+	}
+	return sets, nil
+}
+
+// SelectFields reduces lean tasks to the given fields; tasks of a project
+// in sets use that project's fields instead when fields is empty. The id is
+// always kept. Tasks without a field set are returned unchanged.
+func SelectFields(tasks []TaskLean, fields []string, sets map[int64][]string) ([]any, error) {
+	result := make([]any, len(tasks))
+	for i, task := range tasks {
+		keep := fields
+		if len(keep) == 0 {
+			keep = sets[task.ProjectID]
+		}
+		if len(keep) == 0 {
+			result[i] = task
+			continue
+		}
+
+		data, err := json.Marshal(task)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, err
+		}
+		selected := map[string]json.RawMessage{"id": all["id"]}
+		for _, f := range keep {
+			if v, ok := all[f]; ok {
+				selected[f] = v
+			}
+		}
+		result[i] = selected
+	}
+	return result, nil
+}
@@ -0,0 +1,198 @@
+package tasks
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ICSEntry is a VEVENT or VTODO of an iCalendar file
+type ICSEntry struct {
+	Kind        string // VEVENT or VTODO
+	UID         string
+	Summary     string
+	Description string
+	Due         time.Time // DUE of a VTODO, DTSTART of a VEVENT; zero if missing
+	Status      string
+}
+
+// ImportResult is the output of tasks import-ics
+type ImportResult struct {
+	Created []TaskLean      `json:"created"`
+	Skipped []ImportSkipped `json:"skipped,omitempty"`
+	DryRun  bool            `json:"dry_run,omitempty"`
+}
+
+// ImportSkipped is a calendar entry that was not imported
+type ImportSkipped struct {
+	Title  string `json:"title"`
+	Reason string `json:"reason"`
+}
+
+// ParseICS reads the VEVENT and VTODO entries of an iCalendar file
+func ParseICS(r io.Reader) ([]ICSEntry, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ICSEntry
+	var current *ICSEntry
+	nested := 0 // Depth of components inside the entry, e.g. VALARM
+	for _, line := range lines {
+		name, params, value := splitICSLine(line)
+		switch {
+		case current == nil && name == "BEGIN" && (value == "VEVENT" || value == "VTODO"):
+			current = &ICSEntry{Kind: value}
+		case current == nil:
+			continue
+		case name == "BEGIN":
+			nested++
+		case name == "END" && nested > 0:
+			nested--
+		case nested > 0:
+			continue
+		case name == "END" && value == current.Kind:
+			entries = append(entries, *current)
+			current = nil
+		case name == "UID":
+			current.UID = value
+		case name == "SUMMARY":
+			current.Summary = unescapeICS(value)
+		case name == "DESCRIPTION":
+			current.Description = unescapeICS(value)
+		case name == "STATUS":
+			current.Status = strings.ToUpper(value)
+		case name == "DUE" || (name == "DTSTART" && current.Kind == "VEVENT"):
+			t, err := parseICSTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("%s of %q: %w", name, current.Summary, err)
+			}
+			current.Due = t
+		}
+	}
+	return entries, nil
+}
+
+// unfoldICS joins continuation lines (starting with a space or tab)
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// splitICSLine splits "DTSTART;TZID=Europe/Berlin:20260115T090000" into
+// name, parameters and value
+func splitICSLine(line string) (string, map[string]string, string) {
+	head, value, _ := strings.Cut(line, ":")
+	parts := strings.Split(head, ";")
+	params := make(map[string]string)
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, value
+}
+
+// parseICSTime parses a DATE or DATE-TIME value: UTC with a Z suffix, in
+// the TZID zone if given, local time otherwise
+func parseICSTime(value string, params map[string]string) (time.Time, error) {
+	loc := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	switch {
+	case params["VALUE"] == "DATE" || len(value) == 8:
+		return time.ParseInLocation("20060102", value, time.Local)
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse("20060102T150405Z", value)
+	default:
+		return time.ParseInLocation("20060102T150405", value, loc)
+	}
+}
+
+// unescapeICS resolves the escapes of iCalendar text values
+func unescapeICS(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+// ImportICS creates a task in projectID for each calendar entry. Completed
+// to-dos, cancelled entries and entries matching an existing task (same
+// title and due date) are skipped, so a calendar can be imported again.
+// With dryRun no tasks are created.
+func (s *Service) ImportICS(ctx context.Context, projectID int64, entries []ICSEntry, dryRun bool) (*ImportResult, error) {
+	result := &ImportResult{Created: []TaskLean{}, DryRun: dryRun}
+	for _, e := range entries {
+		title := strings.TrimSpace(e.Summary)
+		switch {
+		case title == "":
+			result.Skipped = append(result.Skipped, ImportSkipped{Title: e.UID, Reason: "no summary"})
+			continue
+		case e.Status == "COMPLETED" || e.Status == "CANCELLED":
+			result.Skipped = append(result.Skipped, ImportSkipped{Title: title, Reason: strings.ToLower(e.Status)})
+			continue
+		}
+
+		exists, err := s.hasTask(ctx, projectID, title, e.Due)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			result.Skipped = append(result.Skipped, ImportSkipped{Title: title, Reason: "already exists"})
+			continue
+		}
+
+		req := CreateTaskRequest{Title: title, Description: e.Description}
+		if !e.Due.IsZero() {
+			req.DueDate = e.Due.Format(time.RFC3339)
+		}
+		if dryRun {
+			task := Task{Title: req.Title, Description: req.Description, DueDate: req.DueDate, ProjectID: projectID}
+			result.Created = append(result.Created, task.ToLean())
+			continue
+		}
+		task, err := s.Create(ctx, projectID, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %q: %w", title, err)
+		}
+		result.Created = append(result.Created, task.ToLean())
+	}
+	return result, nil
+}
+
+// hasTask reports whether the project has a task (done or not) with the
+// given title and due date
+func (s *Service) hasTask(ctx context.Context, projectID int64, title string, due time.Time) (bool, error) {
+	tasks, err := s.List(ctx, ListOptions{ProjectID: projectID, IncludeDone: true, Search: title})
+	if err != nil {
+		return false, err
+	}
+	for _, t := range tasks {
+		if t.Title != title {
+			continue
+		}
+		existing, err := time.Parse(time.RFC3339, t.DueDate)
+		if err != nil || existing.Year() <= 1 {
+			existing = time.Time{}
+		}
+		if existing.Equal(due) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -2,44 +2,29 @@
 package tasks
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
-	"time"
 
+	"github.com/petervogelmann/skillfactory/pkg/skillkit"
 	"github.com/petervogelmann/skillfactory/skills/vikunja/client"
 )
 
-// formatDate converts date strings to RFC3339 format with local timezone
-// Supported formats:
-//   - YYYY-MM-DD (defaults to 00:00)
-//   - YYYY-MM-DDTHH:MM (e.g., 2025-12-13T12:00)
-//   - Full RFC3339 (passed through)
-func formatDate(date string) string {
-	if date == "" {
-		return ""
-	}
-	// Already complete RFC3339 (contains T and timezone)?
-	if strings.Contains(date, "T") && (strings.Contains(date, "Z") || strings.Contains(date, "+") || strings.Contains(date, "-") && strings.Count(date, "-") > 2) {
-		return date
-	}
-	// YYYY-MM-DDTHH:MM format?
-	if strings.Contains(date, "T") {
-		t, err := time.ParseInLocation("2006-01-02T15:04", date, time.Local)
+// formatDates converts due, start and end date to RFC3339 in place, see
+// skillkit.ParseDate for the accepted input
+func formatDates(due, start, end *string) error {
+	for _, d := range []struct {
+		name  string
+		value *string
+	}{{"due", due}, {"start", start}, {"end", end}} {
+		formatted, err := skillkit.RFC3339Date(*d.value)
 		if err != nil {
-			// Fallback: append seconds and Z
-			return date + ":00Z"
+			return fmt.Errorf("%s date: %w", d.name, err)
 		}
-		return t.Format(time.RFC3339)
+		*d.value = formatted
 	}
-	// YYYY-MM-DD → Parse as local time at 00:00, then output RFC3339
-	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
-	if err != nil {
-		// Fallback: just append T00:00:00Z
-		return date + "T00:00:00Z"
-	}
-	return t.Format(time.RFC3339)
+	return nil
 }
 
 // Service handles task operations
@@ -63,7 +48,7 @@ type ListOptions struct {
 }
 
 // List retrieves tasks with optional filters
-func (s *Service) List(opts ListOptions) ([]Task, error) {
+func (s *Service) List(ctx context.Context, opts ListOptions) ([]Task, error) {
 	var endpoint string
 
 	if opts.ProjectID > 0 {
@@ -104,13 +89,13 @@ func (s *Service) List(opts ListOptions) ([]Task, error) {
 		endpoint += "?" + params.Encode()
 	}
 
-	data, err := s.client.Get(endpoint)
+	data, err := s.client.Get(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
 	var tasks []Task
-	if err := json.Unmarshal(data, &tasks); err != nil {
+	if err := s.client.Decode(data, &tasks); err != nil {
 		return nil, fmt.Errorf("failed to parse tasks: %w", err)
 	}
 
@@ -118,38 +103,79 @@ func (s *Service) List(opts ListOptions) ([]Task, error) {
 }
 
 // Get retrieves a single task by ID
-func (s *Service) Get(taskID int64) (*Task, error) {
+func (s *Service) Get(ctx context.Context, taskID int64) (*Task, error) {
 	endpoint := fmt.Sprintf("/tasks/%d", taskID)
 
-	data, err := s.client.Get(endpoint)
+	data, err := s.client.Get(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
 	var task Task
-	if err := json.Unmarshal(data, &task); err != nil {
+	if err := s.client.Decode(data, &task); err != nil {
 		return nil, fmt.Errorf("failed to parse task: %w", err)
 	}
 
 	return &task, nil
 }
 
+// Tree retrieves a task and its subtasks recursively, rolling up the done
+// status. A task reached twice (e.g. through a relation cycle) is listed
+// only at its first position.
+func (s *Service) Tree(ctx context.Context, taskID int64) (*TaskTree, error) {
+	return s.tree(ctx, taskID, map[int64]bool{})
+}
+
+func (s *Service) tree(ctx context.Context, taskID int64, seen map[int64]bool) (*TaskTree, error) {
+	seen[taskID] = true
+	task, err := s.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	lean := task.ToLean()
+	node := &TaskTree{
+		ID:      task.ID,
+		Title:   task.Title,
+		Done:    task.Done,
+		DueDate: lean.DueDate,
+		AllDone: task.Done,
+	}
+	for _, sub := range task.RelatedTasks[RelationSubtask] {
+		if seen[sub.ID] {
+			continue
+		}
+		child, err := s.tree(ctx, sub.ID, seen)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get subtask %d: %w", sub.ID, err)
+		}
+		node.Subtasks = append(node.Subtasks, *child)
+		node.SubtasksTotal += 1 + child.SubtasksTotal
+		node.SubtasksDone += child.SubtasksDone
+		if child.Done {
+			node.SubtasksDone++
+		}
+		node.AllDone = node.AllDone && child.AllDone
+	}
+	return node, nil
+}
+
 // Create creates a new task in the specified project
-func (s *Service) Create(projectID int64, req CreateTaskRequest) (*Task, error) {
+func (s *Service) Create(ctx context.Context, projectID int64, req CreateTaskRequest) (*Task, error) {
 	endpoint := fmt.Sprintf("/projects/%d/tasks", projectID)
 
 	// Format dates to RFC3339
-	req.DueDate = formatDate(req.DueDate)
-	req.StartDate = formatDate(req.StartDate)
-	req.EndDate = formatDate(req.EndDate)
+	if err := formatDates(&req.DueDate, &req.StartDate, &req.EndDate); err != nil {
+		return nil, err
+	}
 
-	data, err := s.client.Put(endpoint, req)
+	data, err := s.client.Put(ctx, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
 
 	var task Task
-	if err := json.Unmarshal(data, &task); err != nil {
+	if err := s.client.Decode(data, &task); err != nil {
 		return nil, fmt.Errorf("failed to parse created task: %w", err)
 	}
 
@@ -158,9 +184,13 @@ func (s *Service) Create(projectID int64, req CreateTaskRequest) (*Task, error)
 
 // Update updates an existing task
 // It first fetches the current task, applies changes, then sends the full object
-func (s *Service) Update(taskID int64, req UpdateTaskRequest) (*Task, error) {
+func (s *Service) Update(ctx context.Context, taskID int64, req UpdateTaskRequest) (*Task, error) {
+	if err := formatDates(&req.DueDate, &req.StartDate, &req.EndDate); err != nil {
+		return nil, err
+	}
+
 	// First, get the current task
-	task, err := s.Get(taskID)
+	task, err := s.Get(ctx, taskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task for update: %w", err)
 	}
@@ -176,13 +206,13 @@ func (s *Service) Update(taskID int64, req UpdateTaskRequest) (*Task, error) {
 		task.Priority = *req.Priority
 	}
 	if req.DueDate != "" {
-		task.DueDate = formatDate(req.DueDate)
+		task.DueDate = req.DueDate
 	}
 	if req.StartDate != "" {
-		task.StartDate = formatDate(req.StartDate)
+		task.StartDate = req.StartDate
 	}
 	if req.EndDate != "" {
-		task.EndDate = formatDate(req.EndDate)
+		task.EndDate = req.EndDate
 	}
 	if req.HexColor != "" {
 		task.HexColor = req.HexColor
@@ -200,15 +230,16 @@ func (s *Service) Update(taskID int64, req UpdateTaskRequest) (*Task, error) {
 		task.ProjectID = *req.ProjectID
 	}
 
-	// Send the full task object
+	// Send the full task object; relations are changed through their own endpoints
+	task.RelatedTasks = nil
 	endpoint := fmt.Sprintf("/tasks/%d", taskID)
-	data, err := s.client.Post(endpoint, task)
+	data, err := s.client.Post(ctx, endpoint, task)
 	if err != nil {
 		return nil, err
 	}
 
 	var updatedTask Task
-	if err := json.Unmarshal(data, &updatedTask); err != nil {
+	if err := s.client.Decode(data, &updatedTask); err != nil {
 		return nil, fmt.Errorf("failed to parse updated task: %w", err)
 	}
 
@@ -216,15 +247,15 @@ func (s *Service) Update(taskID int64, req UpdateTaskRequest) (*Task, error) {
 }
 
 // Done marks a task as done
-func (s *Service) Done(taskID int64) (*Task, error) {
+func (s *Service) Done(ctx context.Context, taskID int64) (*Task, error) {
 	done := true
-	return s.Update(taskID, UpdateTaskRequest{Done: &done})
+	return s.Update(ctx, taskID, UpdateTaskRequest{Done: &done})
 }
 
 // Delete deletes a task
-func (s *Service) Delete(taskID int64) error {
+func (s *Service) Delete(ctx context.Context, taskID int64) error {
 	endpoint := fmt.Sprintf("/tasks/%d", taskID)
-	_, err := s.client.Delete(endpoint)
+	_, err := s.client.Delete(ctx, endpoint)
 	return err
 }
 
@@ -242,16 +273,16 @@ type TaskLabel struct {
 }
 
 // GetLabels retrieves all labels for a task
-func (s *Service) GetLabels(taskID int64) ([]TaskLabel, error) {
+func (s *Service) GetLabels(ctx context.Context, taskID int64) ([]TaskLabel, error) {
 	endpoint := fmt.Sprintf("/tasks/%d/labels", taskID)
 
-	data, err := s.client.Get(endpoint)
+	data, err := s.client.Get(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
 	var labels []TaskLabel
-	if err := json.Unmarshal(data, &labels); err != nil {
+	if err := s.client.Decode(data, &labels); err != nil {
 		return nil, fmt.Errorf("failed to parse labels: %w", err)
 	}
 
@@ -259,17 +290,17 @@ func (s *Service) GetLabels(taskID int64) ([]TaskLabel, error) {
 }
 
 // AddLabel adds a label to a task
-func (s *Service) AddLabel(taskID, labelID int64) error {
+func (s *Service) AddLabel(ctx context.Context, taskID, labelID int64) error {
 	endpoint := fmt.Sprintf("/tasks/%d/labels", taskID)
 
 	req := LabelTaskRequest{LabelID: labelID}
-	_, err := s.client.Put(endpoint, req)
+	_, err := s.client.Put(ctx, endpoint, req)
 	return err
 }
 
 // RemoveLabel removes a label from a task
-func (s *Service) RemoveLabel(taskID, labelID int64) error {
+func (s *Service) RemoveLabel(ctx context.Context, taskID, labelID int64) error {
 	endpoint := fmt.Sprintf("/tasks/%d/labels/%d", taskID, labelID)
-	_, err := s.client.Delete(endpoint)
+	_, err := s.client.Delete(ctx, endpoint)
 	return err
 }
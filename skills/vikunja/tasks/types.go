@@ -1,6 +1,8 @@
 // Package tasks provides task-related types and operations for Vikunja API
 package tasks
 
+import "github.com/petervogelmann/skillfactory/pkg/skillkit"
+
 // Label represents a label attached to a task
 type Label struct {
 	ID          int64  `json:"id"`
@@ -34,8 +36,14 @@ type Task struct {
 	Labels                 []Label `json:"labels,omitempty"`
 	Created                string  `json:"created,omitempty"`
 	Updated                string  `json:"updated,omitempty"`
+
+	// Related tasks by relation kind ("subtask", "parenttask", ...), read-only
+	RelatedTasks map[string][]Task `json:"related_tasks,omitempty"`
 }
 
+// RelationSubtask is the relation kind of a task's subtasks
+const RelationSubtask = "subtask"
+
 // TaskLean represents lean task output for CLI (minimal fields)
 type TaskLean struct {
 	ID          int64    `json:"id"`
@@ -52,6 +60,18 @@ type TaskLean struct {
 	IsFavorite  bool     `json:"is_favorite,omitempty"`
 }
 
+// TaskTree is a task with its subtasks resolved recursively (tasks tree)
+type TaskTree struct {
+	ID            int64      `json:"id"`
+	Title         string     `json:"title"`
+	Done          bool       `json:"done"`
+	DueDate       *string    `json:"due_date,omitempty"`
+	SubtasksDone  int        `json:"subtasks_done,omitempty"`  // Done descendants
+	SubtasksTotal int        `json:"subtasks_total,omitempty"` // All descendants
+	AllDone       bool       `json:"all_done"`                 // Task and all descendants done
+	Subtasks      []TaskTree `json:"subtasks,omitempty"`
+}
+
 // CreateTaskRequest represents a task creation request
 type CreateTaskRequest struct {
 	Title       string  `json:"title"`
@@ -126,9 +146,5 @@ func (t *Task) ToLean() TaskLean {
 
 // ToLeanSlice converts a slice of Tasks to lean output
 func ToLeanSlice(tasks []Task) []TaskLean {
-	result := make([]TaskLean, len(tasks))
-	for i := range tasks {
-		result[i] = tasks[i].ToLean()
-	}
-	return result
+	return skillkit.LeanSlice(tasks, (*Task).ToLean)
 }
@@ -0,0 +1,145 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Change event types emitted by tasks watch
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDone    = "done"
+)
+
+// ChangeEvent represents a changed task
+type ChangeEvent struct {
+	Type    string   `json:"type"`
+	Updated string   `json:"updated"`
+	Task    TaskLean `json:"task"`
+}
+
+// WatchState holds the updated timestamps seen by previous polls
+type WatchState struct {
+	Since string           `json:"since"` // Newest updated timestamp seen (RFC3339)
+	Tasks map[int64]string `json:"tasks"` // Task ID → updated timestamp
+}
+
+// DefaultWatchStatePath returns the state file for a project in the user cache directory
+func DefaultWatchStatePath(projectID int64) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	name := "watch-all.json"
+	if projectID > 0 {
+		name = fmt.Sprintf("watch-%d.json", projectID)
+	}
+	return filepath.Join(dir, "vikunja", name)
+}
+
+// Changes returns the tasks updated since the last poll recorded in statePath.
+// The first poll only records the current state and returns no changes.
+func (s *Service) Changes(ctx context.Context, projectID int64, statePath string) ([]ChangeEvent, error) {
+	state, found, err := loadWatchState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.List(ctx, ListOptions{
+		ProjectID:   projectID,
+		IncludeDone: true,
+		SortBy:      "updated",
+		OrderBy:     "desc",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	since, _ := time.Parse(time.RFC3339, state.Since)
+	newest := since
+
+	var events []ChangeEvent
+	for i := range tasks {
+		t := &tasks[i]
+		updated, err := time.Parse(time.RFC3339, t.Updated)
+		if err != nil {
+			continue
+		}
+		if updated.After(newest) {
+			newest = updated
+		}
+		if !found || updated.Before(since) || state.Tasks[t.ID] == t.Updated {
+			state.Tasks[t.ID] = t.Updated
+			continue
+		}
+		state.Tasks[t.ID] = t.Updated
+		events = append(events, ChangeEvent{
+			Type:    changeType(t, since),
+			Updated: t.Updated,
+			Task:    t.ToLean(),
+		})
+	}
+
+	// Oldest change first
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Updated < events[j].Updated })
+
+	if !newest.IsZero() {
+		state.Since = newest.Format(time.RFC3339)
+	}
+	if err := saveWatchState(statePath, state); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// changeType classifies a task change relative to the previous poll
+func changeType(t *Task, since time.Time) string {
+	if created, err := time.Parse(time.RFC3339, t.Created); err == nil && created.After(since) {
+		return ChangeCreated
+	}
+	if doneAt, err := time.Parse(time.RFC3339, t.DoneAt); err == nil && t.Done && !doneAt.Before(since) {
+		return ChangeDone
+	}
+	return ChangeUpdated
+}
+
+// loadWatchState reads the state file, reporting whether it existed
+func loadWatchState(path string) (*WatchState, bool, error) {
+	state := &WatchState{Tasks: make(map[int64]string)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to read watch state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, false, fmt.Errorf("failed to parse watch state: %w", err)
+	}
+	if state.Tasks == nil {
+		state.Tasks = make(map[int64]string)
+	}
+	return state, true, nil
+}
+
+// saveWatchState writes the state file
+func saveWatchState(path string, state *WatchState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write watch state: %w", err)
+	}
+	return nil
+}